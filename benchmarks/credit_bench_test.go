@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/tucredito/backend-api/internal/cache"
 	"github.com/tucredito/backend-api/internal/decision"
 	"github.com/tucredito/backend-api/internal/domain"
 	"github.com/tucredito/backend-api/internal/event"
@@ -13,7 +14,8 @@ import (
 	"go.uber.org/zap"
 )
 
-func setupCreditServiceBench(_ *testing.B) (service.CreditService, domain.CreateCreditInput) {
+// c enables the read-through client/bank caches (nil queries the repositories on every lookup)
+func setupCreditServiceBench(_ *testing.B, c cache.Cache) (service.CreditService, domain.CreateCreditInput) {
 	log, _ := zap.NewDevelopment()
 	client := &domain.Client{ID: "c1", FullName: "Test", Email: "a@b.com", Country: "US", BirthDate: time.Now()}
 	bank := &domain.Bank{ID: "b1", Name: "Bank", Type: domain.BankTypePrivate}
@@ -24,8 +26,10 @@ func setupCreditServiceBench(_ *testing.B) (service.CreditService, domain.Create
 		CreatedAt: time.Now(),
 	}
 	creditRepo := &mocks.CreditRepository{}
-	creditRepo.CreateFunc = func(ctx context.Context, input domain.CreateCreditInput) (*domain.Credit, error) {
-		return credit, nil
+	creditRepo.CreateFunc = func(ctx context.Context, input domain.CreateCreditInput, status domain.CreditStatus) (*domain.Credit, error) {
+		c := *credit
+		c.Status = status
+		return &c, nil
 	}
 	creditRepo.GetByIDFunc = func(ctx context.Context, id string) (*domain.Credit, error) {
 		return credit, nil
@@ -62,7 +66,9 @@ func setupCreditServiceBench(_ *testing.B) (service.CreditService, domain.Create
 	engine := decision.NewRuleEngine()
 	engine.RegisterRule(decision.PaymentRangeRule{})
 
-	svc := service.NewCreditService(creditRepo, clientRepo, bankRepo, nil, publisher, engine, log)
+	clientSvc := service.NewClientService(clientRepo, c)
+	bankSvc := service.NewBankService(bankRepo, c)
+	svc := service.NewCreditService(creditRepo, nil, clientSvc, bankSvc, c, publisher, nil, engine, log)
 	return svc, domain.CreateCreditInput{
 		ClientID: "c1", BankID: "b1",
 		MinPayment: 100, MaxPayment: 500, TermMonths: 12,
@@ -71,7 +77,20 @@ func setupCreditServiceBench(_ *testing.B) (service.CreditService, domain.Create
 }
 
 func BenchmarkCreditService_CreateSync(b *testing.B) {
-	svc, input := setupCreditServiceBench(b)
+	svc, input := setupCreditServiceBench(b, nil)
+	defer svc.Shutdown()
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = svc.CreateSync(ctx, input)
+	}
+}
+
+// The mock repositories answer instantly, so this measures the cache overhead (a few µs of JSON)
+// that replaces two Postgres round trips per creation in production
+func BenchmarkCreditService_CreateSync_CachedLookups(b *testing.B) {
+	svc, input := setupCreditServiceBench(b, cache.NewMemoryCache(0))
 	defer svc.Shutdown()
 	ctx := context.Background()
 
@@ -82,7 +101,7 @@ func BenchmarkCreditService_CreateSync(b *testing.B) {
 }
 
 func BenchmarkCreditService_Create(b *testing.B) {
-	svc, input := setupCreditServiceBench(b)
+	svc, input := setupCreditServiceBench(b, nil)
 	defer svc.Shutdown()
 	ctx := context.Background()
 
@@ -93,7 +112,7 @@ func BenchmarkCreditService_Create(b *testing.B) {
 }
 
 func BenchmarkCreditService_GetByID(b *testing.B) {
-	svc, _ := setupCreditServiceBench(b)
+	svc, _ := setupCreditServiceBench(b, nil)
 	defer svc.Shutdown()
 	ctx := context.Background()
 
@@ -104,7 +123,7 @@ func BenchmarkCreditService_GetByID(b *testing.B) {
 }
 
 func BenchmarkCreditService_Update(b *testing.B) {
-	svc, _ := setupCreditServiceBench(b)
+	svc, _ := setupCreditServiceBench(b, nil)
 	defer svc.Shutdown()
 	ctx := context.Background()
 	input := domain.UpdateCreditInput{
@@ -119,7 +138,7 @@ func BenchmarkCreditService_Update(b *testing.B) {
 }
 
 func BenchmarkCreditService_UpdateStatus(b *testing.B) {
-	svc, _ := setupCreditServiceBench(b)
+	svc, _ := setupCreditServiceBench(b, nil)
 	defer svc.Shutdown()
 	ctx := context.Background()
 
@@ -130,7 +149,7 @@ func BenchmarkCreditService_UpdateStatus(b *testing.B) {
 }
 
 func BenchmarkCreditService_Delete(b *testing.B) {
-	svc, _ := setupCreditServiceBench(b)
+	svc, _ := setupCreditServiceBench(b, nil)
 	defer svc.Shutdown()
 	ctx := context.Background()
 
@@ -141,7 +160,7 @@ func BenchmarkCreditService_Delete(b *testing.B) {
 }
 
 func BenchmarkCreditService_List(b *testing.B) {
-	svc, _ := setupCreditServiceBench(b)
+	svc, _ := setupCreditServiceBench(b, nil)
 	defer svc.Shutdown()
 	ctx := context.Background()
 
@@ -152,7 +171,7 @@ func BenchmarkCreditService_List(b *testing.B) {
 }
 
 func BenchmarkCreditService_ListByClientID(b *testing.B) {
-	svc, _ := setupCreditServiceBench(b)
+	svc, _ := setupCreditServiceBench(b, nil)
 	defer svc.Shutdown()
 	ctx := context.Background()
 
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/handler"
+	"github.com/tucredito/backend-api/internal/repository/postgres"
+	"github.com/tucredito/backend-api/internal/service"
+	"github.com/tucredito/backend-api/pkg/config"
+)
+
+const exportUsage = "usage: server export credits -out FILE|- [-format csv|ndjson] [-client-id ID] [-bank-id ID] [-status STATUS] [-from DATE] [-to DATE]"
+
+// Writes the credit portfolio to a local file (server export credits ...)
+func runExport(args []string) error {
+	if len(args) == 0 || args[0] != "credits" {
+		return errors.New(exportUsage)
+	}
+
+	fs := flag.NewFlagSet("export credits", flag.ContinueOnError)
+	out := fs.String("out", "", "output file path, or - for stdout")
+	format := fs.String("format", "csv", "csv or ndjson")
+	q := url.Values{}
+	for _, name := range []string{"client-id", "bank-id", "status", "from", "to"} {
+		key := strings.ReplaceAll(name, "-", "_")
+		fs.Func(name, "filter by "+key, func(v string) error {
+			q.Set(key, v)
+			return nil
+		})
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *out == "" {
+		return errors.New(exportUsage)
+	}
+
+	var exportFormat domain.ExportFormat
+	switch strings.ToLower(*format) {
+	case "csv":
+		exportFormat = domain.ExportFormatCSV
+	case "ndjson":
+		exportFormat = domain.ExportFormatNDJSON
+	default:
+		return fmt.Errorf("format must be csv or ndjson, got %q", *format)
+	}
+
+	filter, err := handler.ParseCreditExportFilter(q)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	cfg := config.Load()
+	pool, err := postgres.NewPool(ctx, cfg.DBConnString)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	svc := service.NewExportService(postgres.NewCreditExportRepository(pool))
+	if *out == "-" {
+		return svc.ExportCredits(ctx, exportFormat, filter, os.Stdout)
+	}
+	return writeFileAtomic(*out, func(w io.Writer) error {
+		return svc.ExportCredits(ctx, exportFormat, filter, w)
+	})
+}
+
+// Writes to a temp file next to path and renames it so a failed export never leaves a partial file
+func writeFileAtomic(path string, write func(w io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if err := write(tmp); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	_ "net/http/pprof"
 	"os"
@@ -16,6 +17,22 @@ import (
 )
 
 func main() {
+	// Subcommands run instead of the HTTP server
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		if err := runExport(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, "export:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, "migrate:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Load the configuration
 	cfg := config.Load()
 	log, err := logger.New(cfg.LogLevel)
@@ -30,11 +47,53 @@ func main() {
 	ctx := context.Background()
 	srv, err := server.New(ctx, &server.Config{
 		HTTPPort:     cfg.HTTPPort,
+		Storage:      cfg.Storage,
 		DBConnString: cfg.DBConnString,
 		RedisAddr:    cfg.RedisAddr,
 		RedisPass:    cfg.RedisPass,
 		RedisDB:      cfg.RedisDB,
-		Log:          log,
+		Auth: server.AuthConfig{
+			Disabled:    cfg.AuthDisabled,
+			JWTSecret:   cfg.JWTSecret,
+			JWKSFile:    cfg.JWKSFile,
+			JWTIssuer:   cfg.JWTIssuer,
+			JWTAudience: cfg.JWTAudience,
+			APIKeys:     cfg.APIKeys,
+		},
+		RateLimit: server.RateLimitConfig{
+			MaxRequests:     cfg.RateLimitMaxRequests,
+			WindowSeconds:   cfg.RateLimitWindowSeconds,
+			TenantLimits:    cfg.TenantRateLimits,
+			PrincipalLimits: cfg.PrincipalRateLimits,
+			Routes:          cfg.RouteRateLimits,
+		},
+		ClientIP: server.ClientIPConfig{
+			TrustedProxies:  cfg.TrustedProxies,
+			ForwardedHeader: cfg.ForwardedHeader,
+			Allow:           cfg.IPAllowlist,
+			Deny:            cfg.IPDenylist,
+		},
+		Cache: server.CacheConfig{
+			LocalCapacity: cfg.CacheLocalCapacity,
+			LocalTTL:      cfg.CacheLocalTTL,
+			RemoteTTL:     cfg.CacheRemoteTTL,
+		},
+		Tracing: server.TracingConfig{
+			Exporter:    cfg.TracingExporter,
+			Endpoint:    cfg.TracingEndpoint,
+			File:        cfg.TracingFile,
+			SampleRatio: cfg.TracingSampleRatio,
+			ServiceName: cfg.TracingService,
+		},
+		Webhooks: server.WebhookConfig{
+			MaxAttempts:      cfg.WebhookMaxAttempts,
+			RetryBaseSeconds: cfg.WebhookRetryBaseSeconds,
+			RetryMaxSeconds:  cfg.WebhookRetryMaxSeconds,
+			TimeoutSeconds:   cfg.WebhookTimeoutSeconds,
+		},
+		RequireIfMatch: cfg.RequireIfMatch,
+		AutoMigrate:    cfg.AutoMigrate,
+		Log:            log,
 	})
 	if err != nil {
 		log.Fatal("failed to create server", zap.Error(err))
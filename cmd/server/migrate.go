@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/tucredito/backend-api/internal/repository/postgres"
+	"github.com/tucredito/backend-api/migrations"
+	"github.com/tucredito/backend-api/pkg/config"
+)
+
+const migrateUsage = "usage: server migrate up | down [N] | version | force VERSION"
+
+// Applies or inspects the embedded migrations against DATABASE_URL (server migrate ...)
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	cfg := config.Load()
+	pool, err := postgres.NewPool(ctx, cfg.DBConnString)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	migrator, err := postgres.NewMigrator(pool, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		applied, err := migrator.Up(ctx)
+		for _, v := range applied {
+			fmt.Println("applied", v)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("no change")
+		}
+		return err
+	case args[0] == "down" && len(args) <= 2:
+		steps := 1
+		if len(args) == 2 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps <= 0 {
+				return fmt.Errorf("down: N must be a positive number, got %q", args[1])
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		for _, v := range reverted {
+			fmt.Println("reverted", v)
+		}
+		return err
+	case args[0] == "version" && len(args) == 1:
+		version, dirty, err := migrator.Version(ctx)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			fmt.Printf("%d (dirty)\n", version)
+		} else {
+			fmt.Println(version)
+		}
+		if version != migrator.Latest() {
+			fmt.Fprintf(os.Stderr, "binary expects version %d\n", migrator.Latest())
+		}
+		return nil
+	case args[0] == "force" && len(args) == 2:
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || version < 0 {
+			return fmt.Errorf("force: VERSION must be a migration number or 0, got %q", args[1])
+		}
+		return migrator.Force(ctx, version)
+	default:
+		return errors.New(migrateUsage)
+	}
+}
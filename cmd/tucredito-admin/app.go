@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tucredito/backend-api/internal/audit"
+	"github.com/tucredito/backend-api/internal/cache"
+	"github.com/tucredito/backend-api/internal/repository/postgres"
+	"github.com/tucredito/backend-api/internal/server"
+	"github.com/tucredito/backend-api/internal/service"
+	"github.com/tucredito/backend-api/pkg/config"
+	"go.uber.org/zap"
+)
+
+// The services of the API, wired to its database and cache
+type app struct {
+	out   *output
+	pool  *pgxpool.Pool
+	cache cache.Cache
+	// nil when Redis is unreachable: the cache is then local to this process and the API's copies are untouched
+	remote *cache.RedisCache
+
+	clients service.ClientService
+	banks   service.BankService
+	credits service.CreditService
+	audit   service.AuditService
+	replay  service.EventReplayService
+}
+
+/*
+	Connects to Postgres and Redis the way the server does and builds the same services
+	Without Redis the commands still run, but the API keeps serving cached credits until their TTL expires
+*/
+
+func newApp(ctx context.Context, cfg *config.Config, out *output) (*app, error) {
+	if cfg.Storage != "" && cfg.Storage != "postgres" {
+		return nil, fmt.Errorf("storage %q is local to the server process; the admin CLI needs STORAGE=postgres", cfg.Storage)
+	}
+	pool, err := postgres.NewPool(ctx, cfg.DBConnString)
+	if err != nil {
+		return nil, err
+	}
+
+	local := cache.NewMemoryCache(cfg.CacheLocalCapacity)
+	a := &app{out: out, pool: pool, cache: local}
+	if remote, err := cache.NewRedisCache(cfg.RedisAddr, cfg.RedisPass, cfg.RedisDB); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v; the API's cached entries are not invalidated\n", err)
+	} else {
+		a.cache = cache.NewTieredCache(local, remote, cache.TieredConfig{LocalTTL: cfg.CacheLocalTTL, RemoteTTL: cfg.CacheRemoteTTL})
+		a.remote = remote
+	}
+
+	// Same publisher, outbox and rules as the server; the server's dispatcher sends the queued webhooks
+	dom := server.NewDomain(postgres.NewWebhookRepository(pool), server.WebhookConfig{}, zap.NewNop())
+
+	creditRepo := postgres.NewCreditRepository(pool)
+	a.clients = service.NewClientService(postgres.NewClientRepository(pool), a.cache)
+	a.banks = service.NewBankService(postgres.NewBankRepository(pool), a.cache)
+	a.credits = service.NewCreditService(creditRepo, postgres.NewTxManager(pool), a.clients, a.banks, a.cache, dom.Publisher, dom.Webhooks, dom.Engine, zap.NewNop())
+	a.audit = service.NewAuditService(postgres.NewAuditRepository(pool))
+	a.replay = service.NewEventReplayService(postgres.NewAuditRepository(pool), creditRepo, dom.Publisher, dom.Webhooks)
+	return a, nil
+}
+
+// Records the operator as the actor of every audited change
+func (a *app) ctx(ctx context.Context) context.Context {
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	return audit.WithActor(ctx, "admin:"+name)
+}
+
+// Fails unless the cache is the API's shared one
+func (a *app) requireSharedCache() error {
+	if a.remote == nil {
+		return errors.New("redis is unreachable (check REDIS_ADDR); nothing was purged")
+	}
+	return nil
+}
+
+func (a *app) Close() {
+	a.credits.Shutdown()
+	if a.remote != nil {
+		_ = a.remote.Client().Close()
+	}
+	a.pool.Close()
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/tucredito/backend-api/internal/cache"
+)
+
+/*
+	cache purge PREFIX
+	Deletes the matching keys from Redis and tells every API instance to drop its local copies
+	The prefix is required: purging "lock:" or "ratelimit:" also releases locks and resets rate limits
+*/
+
+func parseCache(args []string) (action, error) {
+	if len(args) != 2 || args[0] != "purge" || args[1] == "" {
+		return nil, errUsage
+	}
+	prefix := args[1]
+	return func(ctx context.Context, a *app) error { return purgeCache(ctx, a, prefix) }, nil
+}
+
+func purgeCache(ctx context.Context, a *app, prefix string) error {
+	if err := a.requireSharedCache(); err != nil {
+		return err
+	}
+	deleter, ok := a.cache.(cache.PrefixDeleter)
+	if !ok {
+		return errors.New("the cache cannot delete by prefix")
+	}
+	n, err := deleter.DeletePrefix(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	t := &table{header: []string{"PREFIX", "DELETED"}}
+	t.add(prefix, strconv.Itoa(n))
+	return a.out.print(struct {
+		Prefix  string `json:"prefix"`
+		Deleted int    `json:"deleted"`
+	}{prefix, n}, t)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tucredito/backend-api/internal/decision"
+	"github.com/tucredito/backend-api/internal/domain"
+)
+
+// Number of audit entries shown by credits get
+const historyLimit = 100
+
+// credits list|get|set-status|redecide
+func parseCredits(args []string) (action, error) {
+	if len(args) == 0 {
+		return nil, errUsage
+	}
+	switch cmd, args := args[0], args[1:]; {
+	case cmd == "list":
+		fs := newFlagSet("credits list")
+		clientID := fs.String("client-id", "", "only the credits of this client")
+		limit := fs.Int("limit", 20, "page size")
+		offset := fs.Int("offset", 0, "rows to skip")
+		if err := fs.Parse(args); err != nil || fs.NArg() > 0 || *limit <= 0 || *offset < 0 {
+			return nil, errUsage
+		}
+		return func(ctx context.Context, a *app) error {
+			return listCredits(ctx, a, *clientID, *limit, *offset)
+		}, nil
+	case cmd == "get" && len(args) == 1:
+		return func(ctx context.Context, a *app) error { return getCredit(ctx, a, args[0]) }, nil
+	case cmd == "set-status" && len(args) == 2:
+		status := domain.CreditStatus(strings.ToUpper(args[1]))
+		if err := (domain.UpdateCreditStatusInput{Status: status}).Validate(); err != nil {
+			return nil, fmt.Errorf("status must be PENDING, APPROVED or REJECTED, got %q", args[1])
+		}
+		return func(ctx context.Context, a *app) error { return setCreditStatus(ctx, a, args[0], status) }, nil
+	case cmd == "redecide" && len(args) == 1:
+		return func(ctx context.Context, a *app) error { return redecideCredit(ctx, a, args[0]) }, nil
+	default:
+		return nil, errUsage
+	}
+}
+
+func listCredits(ctx context.Context, a *app, clientID string, limit, offset int) error {
+	var credits []*domain.Credit
+	var err error
+	if clientID != "" {
+		credits, err = a.credits.ListByClientID(ctx, clientID, limit, offset)
+	} else {
+		credits, err = a.credits.List(ctx, limit, offset)
+	}
+	if err != nil {
+		return err
+	}
+	if credits == nil {
+		credits = []*domain.Credit{}
+	}
+	return a.out.print(credits, creditTable(credits...))
+}
+
+// Shows the credit and its audit trail
+func getCredit(ctx context.Context, a *app, id string) error {
+	credit, err := a.credits.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if credit == nil {
+		return fmt.Errorf("credit %s not found", id)
+	}
+	history, err := a.audit.History(ctx, domain.AuditEntityCredit, id, historyLimit, 0)
+	if err != nil {
+		return err
+	}
+
+	details := &table{header: []string{"FIELD", "VALUE"}}
+	details.add("id", credit.ID)
+	details.add("client_id", credit.ClientID)
+	details.add("bank_id", credit.BankID)
+	details.add("credit_type", string(credit.CreditType))
+	details.add("status", string(credit.Status))
+	details.add("min_payment", formatMoney(credit.MinPayment))
+	details.add("max_payment", formatMoney(credit.MaxPayment))
+	details.add("term_months", strconv.Itoa(credit.TermMonths))
+	details.add("is_active", strconv.FormatBool(credit.IsActive))
+	details.add("version", strconv.Itoa(credit.Version))
+	details.add("created_at", formatTime(credit.CreatedAt))
+
+	changes := &table{title: "History", header: []string{"OCCURRED_AT", "ACTION", "ACTOR", "REQUEST_ID", "CHANGES"}}
+	for _, e := range history {
+		changes.add(formatTime(e.OccurredAt), string(e.Action), e.Actor, orDash(e.RequestID), string(e.Changes))
+	}
+	return a.out.print(struct {
+		Credit  *domain.Credit       `json:"credit"`
+		History []*domain.AuditEntry `json:"history"`
+	}{credit, history}, details, changes)
+}
+
+// Changes the status through the service, which publishes the event and invalidates the cached credit
+func setCreditStatus(ctx context.Context, a *app, id string, status domain.CreditStatus) error {
+	credit, err := a.credits.UpdateStatus(ctx, id, status)
+	if err != nil {
+		return err
+	}
+	if credit == nil {
+		return fmt.Errorf("credit %s not found", id)
+	}
+	return a.out.print(credit, creditTable(credit))
+}
+
+func redecideCredit(ctx context.Context, a *app, id string) error {
+	credit, result, err := a.credits.Redecide(ctx, id)
+	if err != nil {
+		return err
+	}
+	if credit == nil {
+		return fmt.Errorf("credit %s not found", id)
+	}
+	outcome := &table{title: "Decision", header: []string{"APPROVED", "RULE", "PRIORITY", "SCORE"}}
+	if result != nil {
+		outcome.add(strconv.FormatBool(result.Approved), orDash(result.RuleName), strconv.Itoa(result.Priority), strconv.FormatFloat(result.Score, 'f', 2, 64))
+	}
+	return a.out.print(struct {
+		Credit   *domain.Credit              `json:"credit"`
+		Decision *decision.EligibilityResult `json:"decision"`
+	}{credit, result}, creditTable(credit), outcome)
+}
+
+func creditTable(credits ...*domain.Credit) *table {
+	t := &table{header: []string{"ID", "CLIENT_ID", "BANK_ID", "TYPE", "STATUS", "MIN", "MAX", "TERM", "ACTIVE", "CREATED_AT"}}
+	for _, c := range credits {
+		t.add(c.ID, c.ClientID, c.BankID, string(c.CreditType), string(c.Status), formatMoney(c.MinPayment), formatMoney(c.MaxPayment),
+			strconv.Itoa(c.TermMonths), strconv.FormatBool(c.IsActive), formatTime(c.CreatedAt))
+	}
+	return t
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/tucredito/backend-api/internal/domain"
+)
+
+/*
+	events republish -from TIME -to TIME [-dry-run]
+	Rebuilds the credit events recorded in the audit trail within [from, to) and publishes them in order;
+	-dry-run only lists them
+*/
+
+func parseEvents(args []string) (action, error) {
+	if len(args) == 0 || args[0] != "republish" {
+		return nil, errUsage
+	}
+	fs := newFlagSet("events republish")
+	from := fs.String("from", "", "start of the range (YYYY-MM-DD or RFC 3339, inclusive)")
+	to := fs.String("to", "", "end of the range (YYYY-MM-DD or RFC 3339, exclusive)")
+	dryRun := fs.Bool("dry-run", false, "list the events without publishing them")
+	if err := fs.Parse(args[1:]); err != nil || fs.NArg() > 0 || *from == "" || *to == "" {
+		return nil, errUsage
+	}
+	start, err := parseTime("from", *from)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseTime("to", *to)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, a *app) error {
+		return republish(ctx, a, start, end, *dryRun)
+	}, nil
+}
+
+// An event with its payload shown as JSON rather than base64
+type eventView struct {
+	*domain.DomainEvent
+	Payload json.RawMessage `json:"payload"`
+}
+
+func republish(ctx context.Context, a *app, from, to time.Time, dryRun bool) error {
+	events, err := a.replay.Republish(ctx, from, to, dryRun)
+	// Events published before a failure are still reported
+	t := &table{header: []string{"OCCURRED_AT", "TYPE", "EVENT_ID", "CORRELATION_ID", "PAYLOAD"}}
+	views := make([]eventView, 0, len(events))
+	for _, evt := range events {
+		t.add(formatTime(evt.OccurredAt), string(evt.Type), evt.ID, orDash(evt.CorrelationID), string(evt.Payload))
+		views = append(views, eventView{DomainEvent: evt, Payload: evt.Payload})
+	}
+	if errPrint := a.out.print(views, t); errPrint != nil && err == nil {
+		err = errPrint
+	}
+	return err
+}
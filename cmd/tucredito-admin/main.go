@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/tucredito/backend-api/pkg/config"
+)
+
+const usage = `usage: tucredito-admin [-o table|json] COMMAND
+
+commands:
+  seed [-banks N] [-clients N] [-credits N]       create demo banks, clients and credits
+  credits list [-client-id ID] [-limit N] [-offset N]
+  credits get ID                                   show a credit and its change history
+  credits set-status ID PENDING|APPROVED|REJECTED  change the status through the credit service
+  credits redecide ID                              re-run the decision engine for a credit
+  events republish -from TIME -to TIME [-dry-run]  re-publish the credit events recorded in [from, to)
+  cache purge PREFIX                               delete the cached keys starting with PREFIX
+
+Configuration comes from the server's environment (DATABASE_URL, REDIS_ADDR, ...).`
+
+/*
+	Operations CLI working against the same Postgres and Redis as the API
+	Changes go through the services, so they are audited (actor admin:<os user>), publish their events and
+	invalidate the API's caches exactly like requests do
+*/
+
+func main() {
+	fs := flag.NewFlagSet("tucredito-admin", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	format := fs.String("o", formatTable, "output format: table or json")
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		os.Exit(2)
+	}
+	out, err := newOutput(os.Stdout, *format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if err := run(ctx, fs.Args(), out); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprintln(os.Stderr, usage)
+			os.Exit(2)
+		}
+		fmt.Fprintln(os.Stderr, "tucredito-admin:", err)
+		os.Exit(1)
+	}
+}
+
+// Returned by commands called with missing or unknown arguments
+var errUsage = errors.New("usage")
+
+// A parsed command, run once the app is connected
+type action func(ctx context.Context, a *app) error
+
+// Parses args into the action of a command; commands reject bad arguments before anything is connected
+func parse(args []string) (action, error) {
+	if len(args) == 0 {
+		return nil, errUsage
+	}
+	switch args[0] {
+	case "seed":
+		return parseSeed(args[1:])
+	case "credits":
+		return parseCredits(args[1:])
+	case "events":
+		return parseEvents(args[1:])
+	case "cache":
+		return parseCache(args[1:])
+	default:
+		return nil, errUsage
+	}
+}
+
+// Flag set of a command; parse errors are reported once, followed by the general usage
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {}
+	return fs
+}
+
+func run(ctx context.Context, args []string, out *output) error {
+	act, err := parse(args)
+	if err != nil {
+		return err
+	}
+	a, err := newApp(ctx, config.Load(), out)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+	return act(a.ctx(ctx), a)
+}
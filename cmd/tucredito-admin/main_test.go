@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/cache"
+	"github.com/tucredito/backend-api/internal/decision"
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/event"
+	"github.com/tucredito/backend-api/internal/repository/memory"
+	"github.com/tucredito/backend-api/internal/service"
+	"go.uber.org/zap"
+)
+
+// An app over memory repositories, writing JSON to the returned buffer
+func newTestApp(t *testing.T) (*app, *bytes.Buffer, *event.MockPublisher) {
+	store := memory.NewStore()
+	c := cache.NewMemoryCache(0)
+	publisher := event.NewMockPublisher()
+	engine := decision.NewRuleEngine()
+	engine.RegisterRule(decision.PaymentRangeRule{})
+	buf := &bytes.Buffer{}
+	a := &app{out: &output{w: buf, json: true}, cache: c}
+	a.clients = service.NewClientService(memory.NewClientRepository(store), c)
+	a.banks = service.NewBankService(memory.NewBankRepository(store), c)
+	a.credits = service.NewCreditService(memory.NewCreditRepository(store), memory.NewTxManager(store), a.clients, a.banks, c, publisher, nil, engine, zap.NewNop())
+	a.audit = service.NewAuditService(memory.NewAuditRepository(store))
+	a.replay = service.NewEventReplayService(memory.NewAuditRepository(store), memory.NewCreditRepository(store), publisher, nil)
+	t.Cleanup(a.credits.Shutdown)
+	return a, buf, publisher
+}
+
+func runAction(t *testing.T, a *app, buf *bytes.Buffer, v interface{}, args ...string) error {
+	t.Helper()
+	act, err := parse(args)
+	require.NoError(t, err)
+	buf.Reset()
+	if err := act(a.ctx(context.Background()), a); err != nil {
+		return err
+	}
+	return json.Unmarshal(buf.Bytes(), v)
+}
+
+func TestParse_RejectsBadArguments(t *testing.T) {
+	for _, args := range [][]string{
+		nil,
+		{"unknown"},
+		{"credits"},
+		{"credits", "get"},
+		{"credits", "list", "-limit", "0"},
+		{"credits", "set-status", "id"},
+		{"events", "republish", "-from", "2026-01-01"},
+		{"cache", "purge", ""},
+		{"seed", "-banks", "0"},
+	} {
+		_, err := parse(args)
+		assert.ErrorIs(t, err, errUsage, "%q", args)
+	}
+
+	_, err := parse([]string{"credits", "set-status", "id", "done"})
+	assert.ErrorContains(t, err, "status must be")
+	_, err = parse([]string{"events", "republish", "-from", "yesterday", "-to", "2026-01-01"})
+	assert.ErrorContains(t, err, "from must be")
+}
+
+func TestCommands(t *testing.T) {
+	a, buf, publisher := newTestApp(t)
+	start := time.Now().Add(-time.Minute)
+
+	var seededData seeded
+	require.NoError(t, runAction(t, a, buf, &seededData, "seed", "-banks", "2", "-clients", "2", "-credits", "1"))
+	require.Len(t, seededData.Banks, 2)
+	require.Len(t, seededData.Clients, 2)
+	require.Len(t, seededData.Credits, 2)
+	id := seededData.Credits[0].ID
+
+	var listed []*domain.Credit
+	require.NoError(t, runAction(t, a, buf, &listed, "credits", "list", "-client-id", seededData.Clients[0].ID))
+	require.Len(t, listed, 1)
+	assert.Equal(t, id, listed[0].ID)
+
+	var changed domain.Credit
+	require.NoError(t, runAction(t, a, buf, &changed, "credits", "set-status", id, "rejected"))
+	assert.Equal(t, domain.CreditStatusRejected, changed.Status)
+	last := publisher.Events()[len(publisher.Events())-1]
+	assert.Equal(t, domain.EventCreditRejected, last.Type, "the status change is published")
+
+	var redecided struct {
+		Credit   domain.Credit               `json:"credit"`
+		Decision *decision.EligibilityResult `json:"decision"`
+	}
+	require.NoError(t, runAction(t, a, buf, &redecided, "credits", "redecide", id))
+	require.NotNil(t, redecided.Decision)
+	assert.True(t, redecided.Decision.Approved)
+	assert.Equal(t, domain.CreditStatusRejected, redecided.Credit.Status, "a decided credit keeps its status")
+
+	var inspected struct {
+		Credit  domain.Credit        `json:"credit"`
+		History []*domain.AuditEntry `json:"history"`
+	}
+	require.NoError(t, runAction(t, a, buf, &inspected, "credits", "get", id))
+	require.Len(t, inspected.History, 2)
+	assert.Equal(t, domain.AuditActionUpdateStatus, inspected.History[1].Action)
+	assert.Contains(t, inspected.History[1].Actor, "admin:")
+
+	var replayed []struct {
+		Type    domain.EventType  `json:"type"`
+		Payload json.RawMessage   `json:"payload"`
+		Headers map[string]string `json:"headers"`
+	}
+	published := len(publisher.Events())
+	require.NoError(t, runAction(t, a, buf, &replayed, "events", "republish", "-from", start.Format(time.RFC3339), "-to", time.Now().Add(time.Minute).Format(time.RFC3339), "-dry-run"))
+	assert.Len(t, publisher.Events(), published, "a dry run publishes nothing")
+	// Two credits created (approved by the payment rule), then one rejected
+	require.Len(t, replayed, 5)
+	assert.Equal(t, domain.EventCreditRejected, replayed[4].Type)
+	assert.Equal(t, "true", replayed[4].Headers[event.HeaderReplay])
+	assert.Contains(t, string(replayed[4].Payload), id)
+
+	assert.ErrorContains(t, runAction(t, a, buf, nil, "cache", "purge", "credit:"), "redis is unreachable")
+	assert.ErrorContains(t, runAction(t, a, buf, nil, "credits", "get", "missing"), "not found")
+}
+
+func TestOutput_Table(t *testing.T) {
+	buf := &bytes.Buffer{}
+	out, err := newOutput(buf, "TABLE")
+	require.NoError(t, err)
+	first := &table{header: []string{"ID", "STATUS"}}
+	first.add("cr-1", "APPROVED")
+	second := &table{title: "History", header: []string{"ACTION"}}
+	second.add("CREATE")
+
+	require.NoError(t, out.print(nil, first, second))
+	assert.Equal(t, "ID    STATUS\ncr-1  APPROVED\n\nHistory\nACTION\nCREATE\n", buf.String())
+}
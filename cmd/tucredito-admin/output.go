@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Output formats selected with -o
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+// Writes command results as aligned tables or as one indented JSON document
+type output struct {
+	w    io.Writer
+	json bool
+}
+
+func newOutput(w io.Writer, format string) (*output, error) {
+	switch strings.ToLower(format) {
+	case formatTable:
+		return &output{w: w}, nil
+	case formatJSON:
+		return &output{w: w, json: true}, nil
+	default:
+		return nil, fmt.Errorf("output format must be %s or %s, got %q", formatTable, formatJSON, format)
+	}
+}
+
+// Rows under a header; a table without rows prints the header only
+type table struct {
+	title  string
+	header []string
+	rows   [][]string
+}
+
+func (t *table) add(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// Prints v as JSON, or the tables (separated by a blank line) in table format
+func (o *output) print(v interface{}, tables ...*table) error {
+	if o.json {
+		enc := json.NewEncoder(o.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	tw := tabwriter.NewWriter(o.w, 0, 0, 2, ' ', 0)
+	for i, t := range tables {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		if t.title != "" {
+			fmt.Fprintln(tw, t.title)
+		}
+		fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+		for _, row := range t.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+	}
+	return tw.Flush()
+}
+
+func formatMoney(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// Parses YYYY-MM-DD (midnight UTC) or RFC 3339
+func parseTime(name, v string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%s must be YYYY-MM-DD or RFC 3339, got %q", name, v)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tucredito/backend-api/internal/domain"
+)
+
+var (
+	demoBanks = []domain.CreateBankInput{
+		{Name: "Banco Andino", Type: domain.BankTypePrivate},
+		{Name: "Banco Nacional de Fomento", Type: domain.BankTypeGovernment},
+		{Name: "Caja Popular del Sur", Type: domain.BankTypePrivate},
+	}
+	demoNames     = []string{"Ana Torres", "Luis Gómez", "María Rojas", "Carlos Pérez", "Lucía Herrera", "Jorge Castro", "Sofía Vargas", "Diego Morales"}
+	demoCountries = []string{"CO", "MX", "PE", "CL", "AR"}
+	demoTypes     = []domain.CreditType{domain.CreditTypeAuto, domain.CreditTypeMortgage, domain.CreditTypeCommercial}
+)
+
+/*
+	seed [-banks N] [-clients N] [-credits N]
+	Creates demo data through the services, so it is audited and decided like API traffic
+	Every run adds new rows: client emails carry a run ID (demo.<run>.<n>@example.com) to stay unique
+*/
+
+func parseSeed(args []string) (action, error) {
+	fs := newFlagSet("seed")
+	banks := fs.Int("banks", len(demoBanks), "banks to create")
+	clients := fs.Int("clients", 8, "clients to create")
+	credits := fs.Int("credits", 2, "credits per client, spread across the banks")
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 || *banks <= 0 || *clients < 0 || *credits < 0 {
+		return nil, errUsage
+	}
+	return func(ctx context.Context, a *app) error {
+		return seed(ctx, a, *banks, *clients, *credits)
+	}, nil
+}
+
+type seeded struct {
+	Banks   []*domain.Bank   `json:"banks"`
+	Clients []*domain.Client `json:"clients"`
+	Credits []*domain.Credit `json:"credits"`
+}
+
+func seed(ctx context.Context, a *app, banks, clients, creditsPerClient int) error {
+	run := strings.SplitN(uuid.New().String(), "-", 2)[0]
+	out := seeded{Banks: []*domain.Bank{}, Clients: []*domain.Client{}, Credits: []*domain.Credit{}}
+
+	for i := 0; i < banks; i++ {
+		input := demoBanks[i%len(demoBanks)]
+		if i >= len(demoBanks) {
+			input.Name = fmt.Sprintf("%s %d", input.Name, i/len(demoBanks)+1)
+		}
+		bank, err := a.banks.Create(ctx, input)
+		if err != nil {
+			return fmt.Errorf("bank %q: %w", input.Name, err)
+		}
+		out.Banks = append(out.Banks, bank)
+	}
+
+	for i := 0; i < clients; i++ {
+		client, err := a.clients.Create(ctx, domain.CreateClientInput{
+			FullName:  demoNames[i%len(demoNames)],
+			Email:     fmt.Sprintf("demo.%s.%d@example.com", run, i+1),
+			BirthDate: time.Date(1970+(i*7)%35, time.Month(i%12+1), i%28+1, 0, 0, 0, 0, time.UTC),
+			Country:   demoCountries[i%len(demoCountries)],
+		})
+		if err != nil {
+			return fmt.Errorf("client %d: %w", i+1, err)
+		}
+		out.Clients = append(out.Clients, client)
+
+		for j := 0; j < creditsPerClient; j++ {
+			n := i*creditsPerClient + j
+			minPayment := float64(100 + 50*(n%10))
+			credit, err := a.credits.CreateSync(ctx, domain.CreateCreditInput{
+				ClientID:   client.ID,
+				BankID:     out.Banks[n%len(out.Banks)].ID,
+				MinPayment: minPayment,
+				MaxPayment: minPayment * float64(2+n%4),
+				TermMonths: 12 * (1 + n%5),
+				CreditType: demoTypes[n%len(demoTypes)],
+			})
+			if err != nil {
+				return fmt.Errorf("credit for client %s: %w", client.ID, err)
+			}
+			out.Credits = append(out.Credits, credit)
+		}
+	}
+
+	t := &table{header: []string{"KIND", "ID", "DETAIL"}}
+	for _, b := range out.Banks {
+		t.add("bank", b.ID, fmt.Sprintf("%s (%s)", b.Name, b.Type))
+	}
+	for _, c := range out.Clients {
+		t.add("client", c.ID, fmt.Sprintf("%s <%s>", c.FullName, c.Email))
+	}
+	for _, c := range out.Credits {
+		t.add("credit", c.ID, fmt.Sprintf("%s %s at bank %s", c.CreditType, c.Status, c.BankID))
+	}
+	return a.out.print(out, t)
+}
@@ -0,0 +1,101 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+)
+
+/*
+	Audit metadata carried in the request context and the before/after diff stored with each entry
+	Repositories read the actor, request ID and client IP from the context when writing the audit log
+*/
+
+// Actor recorded when no caller identity is attached (CLI, background jobs)
+const SystemActor = "system"
+
+type actorKey struct{}
+type requestIDKey struct{}
+type clientIPKey struct{}
+
+// Attaches the acting principal to ctx
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Returns the acting principal, or SystemActor when none is attached
+func Actor(ctx context.Context) string {
+	if v, ok := ctx.Value(actorKey{}).(string); ok && v != "" {
+		return v
+	}
+	return SystemActor
+}
+
+// Attaches the request ID to ctx
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// Returns the request ID attached to ctx, if any
+func RequestID(ctx context.Context) string {
+	v, _ := ctx.Value(requestIDKey{}).(string)
+	return v
+}
+
+// Attaches the caller's IP address to ctx
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// Returns the caller's IP address attached to ctx, if any
+func ClientIP(ctx context.Context) string {
+	v, _ := ctx.Value(clientIPKey{}).(string)
+	return v
+}
+
+// A single field change
+type Change struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Returns the JSON fields that differ between before and after (either may be nil, e.g. on create)
+func Diff(before, after interface{}) (json.RawMessage, error) {
+	b, err := toMap(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := toMap(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]Change)
+	for k, av := range a {
+		if bv, ok := b[k]; !ok || !reflect.DeepEqual(bv, av) {
+			changes[k] = Change{Before: b[k], After: av}
+		}
+	}
+	for k, bv := range b {
+		if _, ok := a[k]; !ok {
+			changes[k] = Change{Before: bv}
+		}
+	}
+	return json.Marshal(changes)
+}
+
+// Round-trips v through JSON so fields are compared by their wire names and values
+func toMap(v interface{}) (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+	if v == nil || reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil() {
+		return m, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
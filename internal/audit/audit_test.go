@@ -0,0 +1,49 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/domain"
+)
+
+func TestDiff_Update(t *testing.T) {
+	before := &domain.Credit{ID: "cr1", Status: domain.CreditStatusPending, MinPayment: 100, IsActive: true}
+	after := &domain.Credit{ID: "cr1", Status: domain.CreditStatusApproved, MinPayment: 100, IsActive: true}
+
+	raw, err := Diff(before, after)
+	require.NoError(t, err)
+
+	var changes map[string]Change
+	require.NoError(t, json.Unmarshal(raw, &changes))
+	require.Len(t, changes, 1)
+	assert.Equal(t, "PENDING", changes["status"].Before)
+	assert.Equal(t, "APPROVED", changes["status"].After)
+}
+
+func TestDiff_Create(t *testing.T) {
+	var before *domain.Bank
+	after := &domain.Bank{ID: "b1", Name: "Bank", Type: domain.BankTypePrivate, IsActive: true, Version: 1}
+
+	raw, err := Diff(before, after)
+	require.NoError(t, err)
+
+	var changes map[string]Change
+	require.NoError(t, json.Unmarshal(raw, &changes))
+	assert.Len(t, changes, 5)
+	assert.Nil(t, changes["name"].Before)
+	assert.Equal(t, "Bank", changes["name"].After)
+}
+
+func TestContextMetadata(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, SystemActor, Actor(ctx))
+	assert.Empty(t, RequestID(ctx))
+
+	ctx = WithRequestID(WithActor(ctx, "alice"), "req-1")
+	assert.Equal(t, "alice", Actor(ctx))
+	assert.Equal(t, "req-1", RequestID(ctx))
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/tucredito/backend-api/internal/tenant"
+)
+
+/*
+	APIKeys authenticates service accounts by static key
+	Only SHA-256 digests of the keys are kept in memory
+*/
+
+type APIKeys struct {
+	byDigest map[[sha256.Size]byte]*Principal
+}
+
+// Parses "name[@tenant]:role:key" entries separated by commas (the API_KEYS format)
+// tenant is the bank ID the key is bound to; keys without one act for the platform
+func ParseAPIKeys(spec string) (*APIKeys, error) {
+	keys := &APIKeys{byDigest: make(map[[sha256.Size]byte]*Principal)}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("api key entry must be name[@tenant]:role:key")
+		}
+		name, tenantID, _ := strings.Cut(parts[0], "@")
+		if tenantID == "" {
+			tenantID = tenant.Platform
+		}
+		role := Role(parts[1])
+		if !role.Valid() {
+			return nil, fmt.Errorf("api key %q: unknown role %q", name, parts[1])
+		}
+		keys.byDigest[sha256.Sum256([]byte(parts[2]))] = &Principal{Subject: name, Role: role, Tenant: tenantID, Method: MethodAPIKey}
+	}
+	return keys, nil
+}
+
+// Returns the principal owning key, or nil when the key is unknown
+func (k *APIKeys) Lookup(key string) *Principal {
+	if k == nil || key == "" {
+		return nil
+	}
+	p, ok := k.byDigest[sha256.Sum256([]byte(key))]
+	if !ok {
+		return nil
+	}
+	cp := *p
+	return &cp
+}
+
+// Number of configured keys
+func (k *APIKeys) Len() int {
+	if k == nil {
+		return 0
+	}
+	return len(k.byDigest)
+}
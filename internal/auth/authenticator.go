@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrMissingCredentials = errors.New("missing credentials")
+	ErrInvalidAPIKey      = errors.New("invalid api key")
+)
+
+// Header carrying static API keys for service accounts
+const APIKeyHeader = "X-API-Key"
+
+// Authenticator resolves the principal of a request from a bearer JWT or an API key
+type Authenticator struct {
+	jwt     *JWTVerifier
+	apiKeys *APIKeys
+}
+
+func NewAuthenticator(jwt *JWTVerifier, apiKeys *APIKeys) *Authenticator {
+	return &Authenticator{
+		jwt:     jwt,
+		apiKeys: apiKeys,
+	}
+}
+
+// Authenticates r; returns ErrMissingCredentials when no credentials were sent
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		if p := a.apiKeys.Lookup(key); p != nil {
+			return p, nil
+		}
+		return nil, ErrInvalidAPIKey
+	}
+
+	authz := r.Header.Get("Authorization")
+	if authz == "" {
+		return nil, ErrMissingCredentials
+	}
+	scheme, token, ok := strings.Cut(authz, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return nil, ErrInvalidToken
+	}
+	if a.jwt == nil {
+		return nil, ErrUnknownKey
+	}
+	return a.jwt.Verify(strings.TrimSpace(token))
+}
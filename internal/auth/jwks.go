@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+/*
+	KeySet holds the verification keys for JWT bearer tokens, indexed by kid
+	HMAC secrets verify HS256 tokens and RSA public keys verify RS256 tokens
+*/
+
+type KeySet struct {
+	hmac map[string][]byte
+	rsa  map[string]*rsa.PublicKey
+}
+
+func NewKeySet() *KeySet {
+	return &KeySet{
+		hmac: make(map[string][]byte),
+		rsa:  make(map[string]*rsa.PublicKey),
+	}
+}
+
+// Registers an HS256 secret under kid ("" for tokens without a kid header)
+func (ks *KeySet) AddHMAC(kid string, secret []byte) {
+	ks.hmac[kid] = secret
+}
+
+// Registers an RS256 public key under kid ("" for tokens without a kid header)
+func (ks *KeySet) AddRSA(kid string, key *rsa.PublicKey) {
+	ks.rsa[kid] = key
+}
+
+// Reports whether no key has been registered
+func (ks *KeySet) Empty() bool {
+	return len(ks.hmac) == 0 && len(ks.rsa) == 0
+}
+
+// Looks up the HS256 secret for kid; an empty kid matches when exactly one secret is registered
+func (ks *KeySet) hmacKey(kid string) ([]byte, bool) {
+	return lookup(ks.hmac, kid)
+}
+
+// Looks up the RS256 key for kid; an empty kid matches when exactly one key is registered
+func (ks *KeySet) rsaKey(kid string) (*rsa.PublicKey, bool) {
+	return lookup(ks.rsa, kid)
+}
+
+func lookup[T any](keys map[string]T, kid string) (T, bool) {
+	if k, ok := keys[kid]; ok {
+		return k, true
+	}
+	var zero T
+	if kid == "" && len(keys) == 1 {
+		for _, k := range keys {
+			return k, true
+		}
+	}
+	return zero, false
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	K   string `json:"k"`
+}
+
+// Reads a JWKS document ({"keys": [...]}) with RSA and oct keys from path into ks
+func (ks *KeySet) LoadJWKSFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return ks.LoadJWKS(data)
+}
+
+// Parses a JWKS document into ks; keys meant for encryption are skipped
+func (ks *KeySet) LoadJWKS(data []byte) error {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("parse jwks: %w", err)
+	}
+	if len(doc.Keys) == 0 {
+		return errors.New("jwks has no keys")
+	}
+	for i, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		switch k.Kty {
+		case "RSA":
+			pub, err := parseRSAKey(k)
+			if err != nil {
+				return fmt.Errorf("jwks key %d: %w", i, err)
+			}
+			ks.AddRSA(k.Kid, pub)
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(k.K)
+			if err != nil || len(secret) == 0 {
+				return fmt.Errorf("jwks key %d: invalid oct key", i)
+			}
+			ks.AddHMAC(k.Kid, secret)
+		default:
+			return fmt.Errorf("jwks key %d: unsupported kty %q", i, k.Kty)
+		}
+	}
+	return nil
+}
+
+func parseRSAKey(k jwk) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil || len(n) == 0 {
+		return nil, errors.New("invalid RSA modulus")
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil || len(e) == 0 || len(e) > 4 {
+		return nil, errors.New("invalid RSA exponent")
+	}
+	exp := 0
+	for _, b := range e {
+		exp = exp<<8 | int(b)
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exp}, nil
+}
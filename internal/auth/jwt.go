@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/tucredito/backend-api/internal/tenant"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+	ErrUnknownKey   = errors.New("unknown signing key")
+)
+
+// Tolerated clock skew when checking exp and nbf
+const clockSkew = 30 * time.Second
+
+// Options for JWT validation; empty Issuer/Audience are not checked
+type JWTConfig struct {
+	Keys     *KeySet
+	Issuer   string
+	Audience string
+}
+
+/*
+	JWTVerifier validates compact JWS bearer tokens signed with HS256 or RS256
+	Tokens must carry sub, role and exp claims; tenant (a bank ID) is optional and defaults to the platform
+*/
+
+type JWTVerifier struct {
+	cfg JWTConfig
+	now func() time.Time
+}
+
+func NewJWTVerifier(cfg JWTConfig) *JWTVerifier {
+	if cfg.Keys == nil {
+		cfg.Keys = NewKeySet()
+	}
+	return &JWTVerifier{cfg: cfg, now: time.Now}
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Role      Role     `json:"role"`
+	Tenant    string   `json:"tenant"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+}
+
+// aud may be a single string or an array of strings
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*a = audience{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+func (a audience) contains(s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Verifies the token signature and claims and returns the principal it identifies
+func (v *JWTVerifier) Verify(token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrInvalidToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if err := v.verifySignature(header, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, err
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if err := v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+	tenantID := claims.Tenant
+	if tenantID == "" {
+		tenantID = tenant.Platform
+	}
+	return &Principal{Subject: claims.Subject, Role: claims.Role, Tenant: tenantID, Method: MethodJWT}, nil
+}
+
+func (v *JWTVerifier) verifySignature(header jwtHeader, signingInput string, sig []byte) error {
+	digest := sha256.Sum256([]byte(signingInput))
+	switch header.Alg {
+	case "HS256":
+		secret, ok := v.cfg.Keys.hmacKey(header.Kid)
+		if !ok {
+			return ErrUnknownKey
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(mac.Sum(nil), sig) {
+			return ErrInvalidToken
+		}
+	case "RS256":
+		key, ok := v.cfg.Keys.rsaKey(header.Kid)
+		if !ok {
+			return ErrUnknownKey
+		}
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig); err != nil {
+			return ErrInvalidToken
+		}
+	default:
+		// Rejects "none" and any algorithm we do not hold keys for
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+func (v *JWTVerifier) validateClaims(c jwtClaims) error {
+	now := v.now()
+	if c.ExpiresAt == 0 || now.After(time.Unix(c.ExpiresAt, 0).Add(clockSkew)) {
+		return ErrTokenExpired
+	}
+	if c.NotBefore != 0 && now.Add(clockSkew).Before(time.Unix(c.NotBefore, 0)) {
+		return ErrInvalidToken
+	}
+	if v.cfg.Issuer != "" && c.Issuer != v.cfg.Issuer {
+		return ErrInvalidToken
+	}
+	if v.cfg.Audience != "" && !c.Audience.contains(v.cfg.Audience) {
+		return ErrInvalidToken
+	}
+	if c.Subject == "" || !c.Role.Valid() {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/tenant"
+)
+
+func signHS256(t *testing.T, secret []byte, header, claims map[string]interface{}) string {
+	t.Helper()
+	input := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, header, claims map[string]interface{}) string {
+	t.Helper()
+	input := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(input))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":  "alice",
+		"role": "underwriter",
+		"iss":  "tucredito-idp",
+		"aud":  []string{"tucredito-api"},
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestJWTVerifier_HS256(t *testing.T) {
+	secret := []byte("s3cret")
+	keys := NewKeySet()
+	keys.AddHMAC("", secret)
+	v := NewJWTVerifier(JWTConfig{Keys: keys, Issuer: "tucredito-idp", Audience: "tucredito-api"})
+
+	p, err := v.Verify(signHS256(t, secret, map[string]interface{}{"alg": "HS256", "typ": "JWT"}, validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", p.Subject)
+	assert.Equal(t, RoleUnderwriter, p.Role)
+	assert.Equal(t, tenant.Platform, p.Tenant)
+	assert.Equal(t, MethodJWT, p.Method)
+
+	claims := validClaims()
+	claims["tenant"] = "bank-1"
+	p, err = v.Verify(signHS256(t, secret, map[string]interface{}{"alg": "HS256"}, claims))
+	require.NoError(t, err)
+	assert.Equal(t, "bank-1", p.Tenant)
+
+	_, err = v.Verify(signHS256(t, []byte("other"), map[string]interface{}{"alg": "HS256"}, validClaims()))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestJWTVerifier_RS256FromJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	jwks := fmt.Sprintf(`{"keys":[{"kty":"RSA","kid":"k1","use":"sig","alg":"RS256","n":%q,"e":%q}]}`,
+		base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()))
+	keys := NewKeySet()
+	require.NoError(t, keys.LoadJWKS([]byte(jwks)))
+	v := NewJWTVerifier(JWTConfig{Keys: keys})
+
+	p, err := v.Verify(signRS256(t, key, map[string]interface{}{"alg": "RS256", "kid": "k1"}, validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", p.Subject)
+
+	_, err = v.Verify(signRS256(t, key, map[string]interface{}{"alg": "RS256", "kid": "k2"}, validClaims()))
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestJWTVerifier_RejectsBadClaims(t *testing.T) {
+	secret := []byte("s3cret")
+	keys := NewKeySet()
+	keys.AddHMAC("", secret)
+	v := NewJWTVerifier(JWTConfig{Keys: keys, Audience: "tucredito-api"})
+	header := map[string]interface{}{"alg": "HS256"}
+
+	expired := validClaims()
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	_, err := v.Verify(signHS256(t, secret, header, expired))
+	assert.ErrorIs(t, err, ErrTokenExpired)
+
+	wrongAud := validClaims()
+	wrongAud["aud"] = "someone-else"
+	_, err = v.Verify(signHS256(t, secret, header, wrongAud))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	badRole := validClaims()
+	badRole["role"] = "root"
+	_, err = v.Verify(signHS256(t, secret, header, badRole))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	none := encodeSegment(t, map[string]interface{}{"alg": "none"}) + "." + encodeSegment(t, validClaims()) + "."
+	_, err = v.Verify(none)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestParseAPIKeys(t *testing.T) {
+	keys, err := ParseAPIKeys("importer:underwriter:key-1, reporting:analyst:key-2")
+	require.NoError(t, err)
+	assert.Equal(t, 2, keys.Len())
+
+	p := keys.Lookup("key-2")
+	require.NotNil(t, p)
+	assert.Equal(t, "reporting", p.Subject)
+	assert.Equal(t, RoleAnalyst, p.Role)
+	assert.Equal(t, tenant.Platform, p.Tenant)
+	assert.Equal(t, MethodAPIKey, p.Method)
+	assert.Nil(t, keys.Lookup("key-3"))
+
+	scoped, err := ParseAPIKeys("partner@bank-1:viewer:key-4")
+	require.NoError(t, err)
+	p = scoped.Lookup("key-4")
+	require.NotNil(t, p)
+	assert.Equal(t, "partner", p.Subject)
+	assert.Equal(t, "bank-1", p.Tenant)
+
+	_, err = ParseAPIKeys("importer:root:key-1")
+	assert.Error(t, err)
+	_, err = ParseAPIKeys("importer")
+	assert.Error(t, err)
+}
+
+func TestRole_Includes(t *testing.T) {
+	assert.True(t, RoleAdmin.Includes(RoleViewer))
+	assert.True(t, RoleUnderwriter.Includes(RoleAnalyst))
+	assert.False(t, RoleAnalyst.Includes(RoleUnderwriter))
+	assert.False(t, Role("root").Includes(RoleViewer))
+}
@@ -0,0 +1,66 @@
+package auth
+
+import "context"
+
+/*
+	Role grants access to a set of routes
+	Roles are ordered: each role includes everything the previous ones can do
+	viewer < analyst < underwriter < admin
+*/
+
+type Role string
+
+const (
+	RoleViewer      Role = "viewer"
+	RoleAnalyst     Role = "analyst"
+	RoleUnderwriter Role = "underwriter"
+	RoleAdmin       Role = "admin"
+)
+
+var roleRank = map[Role]int{
+	RoleViewer:      1,
+	RoleAnalyst:     2,
+	RoleUnderwriter: 3,
+	RoleAdmin:       4,
+}
+
+// Reports whether r is one of the known roles
+func (r Role) Valid() bool {
+	_, ok := roleRank[r]
+	return ok
+}
+
+// Reports whether r grants at least the permissions of required
+func (r Role) Includes(required Role) bool {
+	return r.Valid() && roleRank[r] >= roleRank[required]
+}
+
+// How a principal proved its identity
+type Method string
+
+const (
+	MethodJWT    Method = "jwt"
+	MethodAPIKey Method = "api_key"
+)
+
+// Principal is the authenticated caller of a request
+// Tenant is the partner bank ID the principal is bound to, or tenant.Platform
+type Principal struct {
+	Subject string `json:"subject"`
+	Role    Role   `json:"role"`
+	Tenant  string `json:"tenant"`
+	Method  Method `json:"method"`
+}
+
+type principalKey struct{}
+
+// Returns a copy of ctx carrying the authenticated principal
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// Returns the principal attached to ctx, or nil for unauthenticated requests
+func PrincipalFrom(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
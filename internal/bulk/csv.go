@@ -0,0 +1,116 @@
+package bulk
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/tucredito/backend-api/internal/domain"
+)
+
+// Columns accepted in the CSV header (order does not matter)
+var csvColumns = []string{"client_id", "bank_id", "min_payment", "max_payment", "term_months", "credit_type"}
+
+var ErrMissingColumn = errors.New("missing required column")
+
+type csvReader struct {
+	r       *csv.Reader
+	columns map[string]int
+	row     int
+}
+
+// Creates a CSV reader; the first record must be a header naming every column
+func NewCSVReader(r io.Reader) (Reader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("%w: empty file", ErrMissingColumn)
+		}
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range csvColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrMissingColumn, name)
+		}
+	}
+
+	return &csvReader{r: cr, columns: columns}, nil
+}
+
+// Reads the next CSV record
+func (c *csvReader) Next() (*Row, error) {
+	record, err := c.r.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+
+	c.row++
+	row := &Row{Number: c.row}
+
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			row.Err = parseErr.Err
+			return row, nil
+		}
+		return nil, err
+	}
+
+	row.Input, row.Err = c.decode(record)
+	return row, nil
+}
+
+// Maps a record to the credit input using the header positions
+func (c *csvReader) decode(record []string) (domain.CreateCreditInput, error) {
+	var input domain.CreateCreditInput
+	field := func(name string) string {
+		i := c.columns[name]
+		if i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	input.ClientID = field("client_id")
+	input.BankID = field("bank_id")
+	input.CreditType = domain.CreditType(strings.ToUpper(field("credit_type")))
+
+	var err error
+	if input.MinPayment, err = parseFloat("min_payment", field("min_payment")); err != nil {
+		return input, err
+	}
+	if input.MaxPayment, err = parseFloat("max_payment", field("max_payment")); err != nil {
+		return input, err
+	}
+	if v := field("term_months"); v != "" {
+		if input.TermMonths, err = strconv.Atoi(v); err != nil {
+			return input, fmt.Errorf("term_months: invalid integer %q", v)
+		}
+	}
+
+	return input, nil
+}
+
+func parseFloat(name, v string) (float64, error) {
+	if v == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: invalid number %q", name, v)
+	}
+	return f, nil
+}
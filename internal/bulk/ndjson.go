@@ -0,0 +1,59 @@
+package bulk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/tucredito/backend-api/internal/domain"
+)
+
+// Maximum size of a single NDJSON line
+const maxLineBytes = 64 * 1024
+
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	row     int
+}
+
+// Creates an NDJSON reader (one CreateCreditInput object per line, blank lines skipped)
+func NewNDJSONReader(r io.Reader) Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineBytes)
+	return &ndjsonReader{scanner: scanner}
+}
+
+// Reads the next non-blank line
+func (n *ndjsonReader) Next() (*Row, error) {
+	for n.scanner.Scan() {
+		line := bytes.TrimSpace(n.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		n.row++
+		row := &Row{Number: n.row}
+		row.Err = decodeLine(line, &row.Input)
+		return row, nil
+	}
+
+	if err := n.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// Decodes one line strictly, like API request bodies: unknown fields and trailing data are errors
+func decodeLine(line []byte, input *domain.CreateCreditInput) error {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(input); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("line must contain a single JSON object")
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package bulk
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/tucredito/backend-api/internal/domain"
+)
+
+/*
+	Streaming readers for bulk credit import files
+	Rows are decoded one at a time so large batches are never fully materialized
+	A malformed row is reported through Row.Err and does not stop the reader
+*/
+
+var ErrUnsupportedFormat = errors.New("unsupported import format")
+
+// A single decoded row (Number is 1-based and counts data rows only)
+type Row struct {
+	Number int
+	Input  domain.CreateCreditInput
+	Err    error
+}
+
+// Reads rows until io.EOF
+type Reader interface {
+	Next() (*Row, error)
+}
+
+// Creates a reader for the given format
+func NewReader(format domain.ImportFormat, r io.Reader) (Reader, error) {
+	switch format {
+	case domain.ImportFormatCSV:
+		return NewCSVReader(r)
+	case domain.ImportFormatNDJSON:
+		return NewNDJSONReader(r), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
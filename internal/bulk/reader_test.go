@@ -0,0 +1,90 @@
+package bulk
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/domain"
+)
+
+func readAll(t *testing.T, r Reader) []*Row {
+	t.Helper()
+	var rows []*Row
+	for {
+		row, err := r.Next()
+		if err == io.EOF {
+			return rows
+		}
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	body := "bank_id,client_id,min_payment,max_payment,term_months,credit_type\n" +
+		"b1,c1,100,500,12,auto\n" +
+		"b1,c2,abc,500,12,AUTO\n" +
+		"b2,c3,200,800,24,MORTGAGE\n"
+	r, err := NewReader(domain.ImportFormatCSV, strings.NewReader(body))
+	require.NoError(t, err)
+
+	rows := readAll(t, r)
+	require.Len(t, rows, 3)
+
+	assert.NoError(t, rows[0].Err)
+	assert.Equal(t, 1, rows[0].Number)
+	assert.Equal(t, domain.CreateCreditInput{
+		ClientID: "c1", BankID: "b1", MinPayment: 100, MaxPayment: 500, TermMonths: 12, CreditType: domain.CreditTypeAuto,
+	}, rows[0].Input)
+
+	assert.Error(t, rows[1].Err)
+	assert.Equal(t, 2, rows[1].Number)
+
+	assert.NoError(t, rows[2].Err)
+	assert.Equal(t, "c3", rows[2].Input.ClientID)
+	assert.Equal(t, domain.CreditTypeMortgage, rows[2].Input.CreditType)
+}
+
+func TestCSVReader_MissingColumn(t *testing.T) {
+	_, err := NewCSVReader(strings.NewReader("client_id,bank_id\nc1,b1\n"))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrMissingColumn))
+}
+
+func TestNDJSONReader(t *testing.T) {
+	body := `{"client_id":"c1","bank_id":"b1","min_payment":100,"max_payment":500,"term_months":12,"credit_type":"AUTO"}
+
+{not json}
+{"client_id":"c2","bank_id":"b1","min_payment":100,"max_payment":500,"term_months":6,"credit_type":"COMMERCIAL"}
+`
+	r, err := NewReader(domain.ImportFormatNDJSON, strings.NewReader(body))
+	require.NoError(t, err)
+
+	rows := readAll(t, r)
+	require.Len(t, rows, 3)
+	assert.NoError(t, rows[0].Err)
+	assert.Equal(t, "c1", rows[0].Input.ClientID)
+	assert.Error(t, rows[1].Err)
+	assert.Equal(t, 2, rows[1].Number)
+	assert.NoError(t, rows[2].Err)
+	assert.Equal(t, domain.CreditTypeCommercial, rows[2].Input.CreditType)
+}
+
+func TestNDJSONReader_Strict(t *testing.T) {
+	body := `{"client_id":"c1","bank_id":"b1","min_payment":100,"max_payment":500,"term_months":12,"credit_type":"AUTO","rate":3}
+{"client_id":"c1"} {"client_id":"c2"}
+`
+	rows := readAll(t, NewNDJSONReader(strings.NewReader(body)))
+	require.Len(t, rows, 2)
+	assert.ErrorContains(t, rows[0].Err, `unknown field "rate"`)
+	assert.Error(t, rows[1].Err, "one object per line")
+}
+
+func TestNewReader_UnsupportedFormat(t *testing.T) {
+	_, err := NewReader("XML", strings.NewReader(""))
+	assert.True(t, errors.Is(err, ErrUnsupportedFormat))
+}
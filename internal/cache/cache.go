@@ -2,7 +2,8 @@ package cache
 
 import "context"
 
-// Cache provides caching, rate limiting, and distributed locking primitives.
+// Cache provides caching and rate limiting primitives; distributed locks are provided by
+// implementations that also satisfy Locker (RedisCache, MemoryCache, TieredCache).
 type Cache interface {
 	Get(ctx context.Context, key string) (string, error)
 	Set(ctx context.Context, key string, value string, ttlSeconds int) error
@@ -10,3 +11,20 @@ type Cache interface {
 	Expire(ctx context.Context, key string, ttlSeconds int) error
 	Delete(ctx context.Context, key string) error
 }
+
+// Implemented by caches that report how long a key has left, so copies of it can expire no later
+type TTLReader interface {
+	// Gets the value for key and its remaining TTL in whole seconds, rounded up (0 when it has none)
+	GetWithTTL(ctx context.Context, key string) (string, int, error)
+}
+
+var (
+	_ TTLReader = (*RedisCache)(nil)
+	_ TTLReader = (*MemoryCache)(nil)
+)
+
+var (
+	_ Locker = (*RedisCache)(nil)
+	_ Locker = (*MemoryCache)(nil)
+	_ Locker = (*TieredCache)(nil)
+)
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+	"strings"
+)
+
+// Redis channel carrying keys deleted by any instance
+const InvalidationChannel = "cache:invalidate"
+
+// Implemented by shared caches that can broadcast deleted keys to every instance (Redis pub/sub)
+type InvalidationBus interface {
+	PublishInvalidation(ctx context.Context, key string) error
+	// Calls onKey for every published key until ctx is done
+	SubscribeInvalidations(ctx context.Context, onKey func(key string)) error
+}
+
+// Publishes key on InvalidationChannel
+func (r *RedisCache) PublishInvalidation(ctx context.Context, key string) error {
+	return r.client.Publish(ctx, InvalidationChannel, key).Err()
+}
+
+// Subscribes to InvalidationChannel; go-redis reconnects the subscription on its own
+func (r *RedisCache) SubscribeInvalidations(ctx context.Context, onKey func(key string)) error {
+	sub := r.client.Subscribe(ctx, InvalidationChannel)
+	defer func() { _ = sub.Close() }()
+	if _, err := sub.Receive(ctx); err != nil {
+		return err
+	}
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			onKey(msg.Payload)
+		}
+	}
+}
+
+/*
+	Drops local copies of keys deleted by other instances until ctx is done
+	Without it a replica keeps serving its local copy of a changed entity for up to LocalTTL
+*/
+
+func (t *TieredCache) ListenInvalidations(ctx context.Context) error {
+	bus, ok := t.remote.(InvalidationBus)
+	if !ok {
+		return nil
+	}
+	return bus.SubscribeInvalidations(ctx, func(key string) {
+		if prefix, ok := strings.CutSuffix(key, invalidationWildcard); ok {
+			_, _ = t.local.DeletePrefix(ctx, prefix)
+			return
+		}
+		_ = t.local.Delete(ctx, key)
+	})
+}
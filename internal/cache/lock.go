@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// Returned by Renew and Release when the lock expired or is held by someone else
+	ErrLockNotHeld = errors.New("lock not held")
+	// Returned by WithLock when the lock could not be acquired within the wait time
+	ErrLockBusy = errors.New("lock busy")
+)
+
+const lockRetryInterval = 25 * time.Millisecond
+
+// A held lock; Token identifies the owner and Fence increases on every acquisition of Key
+type Lock struct {
+	Key   string
+	Token string
+	// Fencing token: pass it along with writes so a store can reject a holder whose lock already expired
+	Fence int64
+
+	local bool // acquired from the local tier of a TieredCache
+}
+
+/*
+	Locker provides distributed mutual exclusion with expiring locks
+	Acquire returns (nil, nil) when another owner holds the key; Renew and Release only act
+	when the caller still owns the lock and return ErrLockNotHeld otherwise
+	Acquire keeps a fencing counter per key for good, so it suits a bounded set of keys (one per credit under
+	a status change); AcquireUnfenced keeps nothing once the lock is gone and returns Fence 0, for locks on
+	keys anyone can make up, such as cache fills of requested IDs
+*/
+
+type Locker interface {
+	Acquire(ctx context.Context, key string, ttl time.Duration) (*Lock, error)
+	AcquireUnfenced(ctx context.Context, key string, ttl time.Duration) (*Lock, error)
+	Renew(ctx context.Context, lock *Lock, ttl time.Duration) error
+	Release(ctx context.Context, lock *Lock) error
+}
+
+/*
+	Runs fn while holding key, waiting up to wait for it to be free; returns ErrLockBusy on timeout
+	The lock is renewed every ttl/3 while fn runs, so fn may outlast ttl. Once a renewal finds the lock taken
+	over, or ttl passes without a successful one, fn's context is cancelled and WithLock returns
+	ErrLockNotHeld: fn must do its writes with that context, so a holder that lost the lock cannot commit
+*/
+
+func WithLock(ctx context.Context, l Locker, key string, ttl, wait time.Duration, fn func(ctx context.Context, lock *Lock) error) error {
+	deadline := time.Now().Add(wait)
+	for {
+		lock, err := l.Acquire(ctx, key, ttl)
+		if err != nil {
+			return err
+		}
+		if lock != nil {
+			defer func() { _ = l.Release(context.WithoutCancel(ctx), lock) }()
+			held, stop := keepAlive(ctx, l, lock, ttl)
+			err := fn(held, lock)
+			stop()
+			if err != nil && errors.Is(context.Cause(held), ErrLockNotHeld) {
+				return ErrLockNotHeld
+			}
+			return err
+		}
+		if time.Now().After(deadline) {
+			return ErrLockBusy
+		}
+		select {
+		case <-time.After(lockRetryInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Renews lock until stop is called; the returned context is cancelled with ErrLockNotHeld when the lock is lost
+func keepAlive(ctx context.Context, l Locker, lock *Lock, ttl time.Duration) (context.Context, func()) {
+	held, cancel := context.WithCancelCause(ctx)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		expiry := time.NewTimer(ttl)
+		defer expiry.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-held.Done():
+				return
+			case <-expiry.C:
+				cancel(ErrLockNotHeld)
+				return
+			case <-ticker.C:
+			}
+			renewedAt := time.Now()
+			err := l.Renew(held, lock, ttl)
+			if errors.Is(err, ErrLockNotHeld) {
+				cancel(ErrLockNotHeld)
+				return
+			}
+			if err == nil {
+				// A failed renewal (e.g. a Redis blip) is retried on the next tick while the lock may still be held
+				expiry.Reset(time.Until(renewedAt.Add(ttl)))
+			}
+		}
+	}()
+	return held, func() {
+		close(done)
+		<-exited
+		cancel(nil)
+	}
+}
+
+func newLockToken() string {
+	return uuid.New().String()
+}
@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCache_Lock(t *testing.T) {
+	ctx := context.Background()
+	m, advance := newTestMemoryCache(10)
+
+	first, err := m.Acquire(ctx, "lock:a", 10*time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, first)
+	assert.Equal(t, int64(1), first.Fence)
+
+	other, err := m.Acquire(ctx, "lock:a", 10*time.Second)
+	require.NoError(t, err)
+	assert.Nil(t, other, "held by first")
+
+	// Only the owner can release
+	assert.ErrorIs(t, m.Release(ctx, &Lock{Key: "lock:a", Token: "someone-else"}), ErrLockNotHeld)
+
+	// Renewal pushes the expiry forward
+	advance(8 * time.Second)
+	require.NoError(t, m.Renew(ctx, first, 10*time.Second))
+	advance(8 * time.Second)
+	other, _ = m.Acquire(ctx, "lock:a", 10*time.Second)
+	assert.Nil(t, other)
+
+	// Once expired the key can be taken again with a higher fence, and the old owner is fenced out
+	advance(3 * time.Second)
+	second, err := m.Acquire(ctx, "lock:a", 10*time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, second)
+	assert.Greater(t, second.Fence, first.Fence)
+	assert.ErrorIs(t, m.Renew(ctx, first, time.Second), ErrLockNotHeld)
+	assert.ErrorIs(t, m.Release(ctx, first), ErrLockNotHeld)
+	require.NoError(t, m.Release(ctx, second))
+}
+
+func TestMemoryCache_AcquireUnfenced(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryCache(10)
+
+	lock, err := m.AcquireUnfenced(ctx, "lock:credit:missing", time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, lock)
+	assert.Zero(t, lock.Fence)
+	other, err := m.Acquire(ctx, "lock:credit:missing", time.Second)
+	require.NoError(t, err)
+	assert.Nil(t, other, "fenced and unfenced locks exclude each other")
+
+	require.NoError(t, m.Release(ctx, lock))
+	assert.Empty(t, m.locks)
+	assert.Empty(t, m.fences, "nothing is left behind for the key")
+}
+
+func TestWithLock_Serializes(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryCache(0)
+
+	var wg sync.WaitGroup
+	inside, maxInside := 0, 0
+	var mu sync.Mutex
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := WithLock(ctx, m, "lock:credit", time.Second, 5*time.Second, func(context.Context, *Lock) error {
+				mu.Lock()
+				inside++
+				if inside > maxInside {
+					maxInside = inside
+				}
+				mu.Unlock()
+				time.Sleep(time.Millisecond)
+				mu.Lock()
+				inside--
+				mu.Unlock()
+				return nil
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, maxInside)
+}
+
+func TestWithLock_Busy(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryCache(0)
+	held, err := m.Acquire(ctx, "lock:credit", time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, held)
+
+	called := false
+	err = WithLock(ctx, m, "lock:credit", time.Second, 50*time.Millisecond, func(context.Context, *Lock) error {
+		called = true
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrLockBusy)
+	assert.False(t, called)
+}
+
+func TestWithLock_RenewsWhileRunning(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryCache(0)
+	ttl := 60 * time.Millisecond
+
+	err := WithLock(ctx, m, "lock:credit", ttl, 0, func(ctx context.Context, _ *Lock) error {
+		for i := 0; i < 4; i++ {
+			time.Sleep(ttl / 2)
+			other, err := m.Acquire(context.Background(), "lock:credit", ttl)
+			require.NoError(t, err)
+			assert.Nil(t, other, "the lock expired while fn was running")
+		}
+		return ctx.Err()
+	})
+	assert.NoError(t, err)
+}
+
+// lostLocker is a MemoryCache whose locks are taken over by another owner after the first acquisition
+type lostLocker struct {
+	*MemoryCache
+}
+
+func (l lostLocker) Renew(context.Context, *Lock, time.Duration) error {
+	return ErrLockNotHeld
+}
+
+func TestWithLock_CancelsWhenLost(t *testing.T) {
+	ctx := context.Background()
+	ttl := 30 * time.Millisecond
+
+	err := WithLock(ctx, lostLocker{NewMemoryCache(0)}, "lock:credit", ttl, 0, func(ctx context.Context, _ *Lock) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err() // e.g. the transaction fails to commit
+		case <-time.After(time.Second):
+			return nil
+		}
+	})
+	assert.ErrorIs(t, err, ErrLockNotHeld)
+}
@@ -0,0 +1,203 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Default number of keys kept by NewMemoryCache when capacity <= 0
+const defaultMemoryCapacity = 10000
+
+// Returned by Incr when the stored value is not an integer (same as Redis)
+var ErrNotInteger = errors.New("value is not an integer")
+
+/*
+	MemoryCache is an in-process cache.Cache: a bounded LRU with per-key TTL
+	Get/Set/Incr/Expire/Delete follow Redis semantics (missing keys read as "",
+	Incr starts at 0 and keeps the TTL, a non-positive Expire deletes the key)
+	Expired keys are dropped lazily on access or when they reach the LRU tail
+*/
+
+type MemoryCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+	now      func() time.Time
+
+	// Locks live outside the LRU so memory pressure never evicts a held lock
+	locks  map[string]memoryLock
+	fences map[string]int64
+}
+
+type memoryEntry struct {
+	key       string
+	value     string
+	expiresAt time.Time // zero means no expiry
+}
+
+func NewMemoryCache(capacity int) *MemoryCache {
+	if capacity <= 0 {
+		capacity = defaultMemoryCapacity
+	}
+	return &MemoryCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+		now:      time.Now,
+		locks:    make(map[string]memoryLock),
+		fences:   make(map[string]int64),
+	}
+}
+
+// Gets the value for key
+func (m *MemoryCache) Get(_ context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.lookup(key)
+	if e == nil {
+		return "", nil
+	}
+	return e.value, nil
+}
+
+// Gets the value for key and its remaining TTL
+func (m *MemoryCache) GetWithTTL(_ context.Context, key string) (string, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.lookup(key)
+	if e == nil {
+		return "", 0, nil
+	}
+	if e.expiresAt.IsZero() {
+		return e.value, 0, nil
+	}
+	return e.value, ceilSeconds(e.expiresAt.Sub(m.now())), nil
+}
+
+// Sets the value for key with TTL in seconds (<= 0 keeps it until evicted)
+func (m *MemoryCache) Set(_ context.Context, key string, value string, ttlSeconds int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.store(key, value, m.expiry(ttlSeconds))
+	return nil
+}
+
+// Increments the key and returns the new value
+func (m *MemoryCache) Incr(_ context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	var expiresAt time.Time
+	if e := m.lookup(key); e != nil {
+		v, err := strconv.ParseInt(e.value, 10, 64)
+		if err != nil {
+			return 0, ErrNotInteger
+		}
+		n, expiresAt = v, e.expiresAt
+	}
+	n++
+	m.store(key, strconv.FormatInt(n, 10), expiresAt)
+	return n, nil
+}
+
+// Expire TTL on key; missing keys are ignored and a non-positive TTL deletes the key
+func (m *MemoryCache) Expire(_ context.Context, key string, ttlSeconds int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.items[key]
+	if !ok || m.expired(el.Value.(*memoryEntry)) {
+		return nil
+	}
+	if ttlSeconds <= 0 {
+		m.remove(el)
+		return nil
+	}
+	el.Value.(*memoryEntry).expiresAt = m.expiry(ttlSeconds)
+	return nil
+}
+
+// Deletes the key
+func (m *MemoryCache) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.items[key]; ok {
+		m.remove(el)
+	}
+	return nil
+}
+
+// Deserializes key into v
+func (m *MemoryCache) GetJSON(ctx context.Context, key string, v interface{}) error {
+	s, err := m.Get(ctx, key)
+	if err != nil || s == "" {
+		return err
+	}
+	return json.Unmarshal([]byte(s), v)
+}
+
+// Serializes v and stores with TTL
+func (m *MemoryCache) SetJSON(ctx context.Context, key string, v interface{}, ttlSeconds int) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return m.Set(ctx, key, string(b), ttlSeconds)
+}
+
+// Number of keys currently held, including expired ones not yet dropped
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ll.Len()
+}
+
+// Returns the live entry for key and marks it as recently used; callers hold mu
+func (m *MemoryCache) lookup(key string) *memoryEntry {
+	el, ok := m.items[key]
+	if !ok {
+		return nil
+	}
+	e := el.Value.(*memoryEntry)
+	if m.expired(e) {
+		m.remove(el)
+		return nil
+	}
+	m.ll.MoveToFront(el)
+	return e
+}
+
+// Inserts or replaces key, evicting the least recently used keys over capacity; callers hold mu
+func (m *MemoryCache) store(key, value string, expiresAt time.Time) {
+	if el, ok := m.items[key]; ok {
+		e := el.Value.(*memoryEntry)
+		e.value, e.expiresAt = value, expiresAt
+		m.ll.MoveToFront(el)
+		return
+	}
+	m.items[key] = m.ll.PushFront(&memoryEntry{key: key, value: value, expiresAt: expiresAt})
+	for m.ll.Len() > m.capacity {
+		m.remove(m.ll.Back())
+	}
+}
+
+func (m *MemoryCache) remove(el *list.Element) {
+	m.ll.Remove(el)
+	delete(m.items, el.Value.(*memoryEntry).key)
+}
+
+func (m *MemoryCache) expired(e *memoryEntry) bool {
+	return !e.expiresAt.IsZero() && !m.now().Before(e.expiresAt)
+}
+
+func (m *MemoryCache) expiry(ttlSeconds int) time.Time {
+	if ttlSeconds <= 0 {
+		return time.Time{}
+	}
+	return m.now().Add(time.Duration(ttlSeconds) * time.Second)
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+type memoryLock struct {
+	token     string
+	expiresAt time.Time
+}
+
+// Acquires key for ttl; returns nil if another owner holds it
+func (m *MemoryCache) Acquire(_ context.Context, key string, ttl time.Duration) (*Lock, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if l, ok := m.locks[key]; ok && m.now().Before(l.expiresAt) {
+		return nil, nil
+	}
+	token := newLockToken()
+	m.locks[key] = memoryLock{token: token, expiresAt: m.now().Add(ttl)}
+	m.fences[key]++
+	return &Lock{Key: key, Token: token, Fence: m.fences[key]}, nil
+}
+
+// Acquires key for ttl without bumping its fencing counter; returns nil if another owner holds it
+func (m *MemoryCache) AcquireUnfenced(_ context.Context, key string, ttl time.Duration) (*Lock, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if l, ok := m.locks[key]; ok && m.now().Before(l.expiresAt) {
+		return nil, nil
+	}
+	token := newLockToken()
+	m.locks[key] = memoryLock{token: token, expiresAt: m.now().Add(ttl)}
+	return &Lock{Key: key, Token: token}, nil
+}
+
+// Extends a held lock to ttl from now
+func (m *MemoryCache) Renew(_ context.Context, lock *Lock, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.holds(lock) {
+		return ErrLockNotHeld
+	}
+	m.locks[lock.Key] = memoryLock{token: lock.Token, expiresAt: m.now().Add(ttl)}
+	return nil
+}
+
+// Releases a held lock
+func (m *MemoryCache) Release(_ context.Context, lock *Lock) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.holds(lock) {
+		return ErrLockNotHeld
+	}
+	delete(m.locks, lock.Key)
+	return nil
+}
+
+// Reports whether lock is still owned and unexpired; callers hold mu
+func (m *MemoryCache) holds(lock *Lock) bool {
+	l, ok := m.locks[lock.Key]
+	return ok && l.token == lock.Token && m.now().Before(l.expiresAt)
+}
@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Returns a cache whose clock only moves when the returned func is called
+func newTestMemoryCache(capacity int) (*MemoryCache, func(time.Duration)) {
+	m := NewMemoryCache(capacity)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	return m, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestMemoryCache_GetSet(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestMemoryCache(10)
+
+	v, err := m.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.Empty(t, v)
+
+	require.NoError(t, m.Set(ctx, "k", "v", 0))
+	v, _ = m.Get(ctx, "k")
+	assert.Equal(t, "v", v)
+
+	require.NoError(t, m.Delete(ctx, "k"))
+	v, _ = m.Get(ctx, "k")
+	assert.Empty(t, v)
+}
+
+func TestMemoryCache_TTL(t *testing.T) {
+	ctx := context.Background()
+	m, advance := newTestMemoryCache(10)
+
+	require.NoError(t, m.Set(ctx, "k", "v", 2))
+	advance(time.Second)
+	v, _ := m.Get(ctx, "k")
+	assert.Equal(t, "v", v)
+
+	advance(time.Second)
+	v, _ = m.Get(ctx, "k")
+	assert.Empty(t, v)
+	assert.Equal(t, 0, m.Len())
+}
+
+func TestMemoryCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestMemoryCache(2)
+
+	require.NoError(t, m.Set(ctx, "a", "1", 0))
+	require.NoError(t, m.Set(ctx, "b", "2", 0))
+	_, _ = m.Get(ctx, "a") // b is now the least recently used
+	require.NoError(t, m.Set(ctx, "c", "3", 0))
+
+	assert.Equal(t, 2, m.Len())
+	a, _ := m.Get(ctx, "a")
+	b, _ := m.Get(ctx, "b")
+	c, _ := m.Get(ctx, "c")
+	assert.Equal(t, "1", a)
+	assert.Empty(t, b)
+	assert.Equal(t, "3", c)
+}
+
+func TestMemoryCache_IncrExpire(t *testing.T) {
+	ctx := context.Background()
+	m, advance := newTestMemoryCache(10)
+
+	n, err := m.Incr(ctx, "counter")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	require.NoError(t, m.Expire(ctx, "counter", 60))
+
+	// Incr keeps the TTL set after the first increment
+	n, _ = m.Incr(ctx, "counter")
+	assert.Equal(t, int64(2), n)
+	advance(time.Minute)
+	n, _ = m.Incr(ctx, "counter")
+	assert.Equal(t, int64(1), n)
+
+	require.NoError(t, m.Set(ctx, "text", "abc", 0))
+	_, err = m.Incr(ctx, "text")
+	assert.ErrorIs(t, err, ErrNotInteger)
+
+	// Expire on a missing key is a no-op, a non-positive TTL deletes
+	require.NoError(t, m.Expire(ctx, "missing", 10))
+	require.NoError(t, m.Expire(ctx, "text", 0))
+	v, _ := m.Get(ctx, "text")
+	assert.Empty(t, v)
+}
+
+func TestMemoryCache_JSON(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestMemoryCache(10)
+
+	type payload struct {
+		ID string `json:"id"`
+	}
+	require.NoError(t, m.SetJSON(ctx, "p", payload{ID: "x"}, 0))
+	var got payload
+	require.NoError(t, m.GetJSON(ctx, "p", &got))
+	assert.Equal(t, "x", got.ID)
+
+	var missing payload
+	require.NoError(t, m.GetJSON(ctx, "nope", &missing))
+	assert.Empty(t, missing.ID)
+}
+
+func TestMemoryCache_DeletePrefix(t *testing.T) {
+	ctx := context.Background()
+	m, advance := newTestMemoryCache(10)
+	require.NoError(t, m.Set(ctx, "credit:1", "a", 0))
+	require.NoError(t, m.Set(ctx, "credit:2", "b", 10))
+	require.NoError(t, m.Set(ctx, "credit:3", "c", 1))
+	require.NoError(t, m.Set(ctx, "bank:1", "d", 0))
+	lock, err := m.Acquire(ctx, "credit:lock", time.Minute)
+	require.NoError(t, err)
+	advance(2 * time.Second)
+
+	n, err := m.DeletePrefix(ctx, "credit:")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n, "expired keys are dropped but not counted")
+	assert.Equal(t, 1, m.Len())
+	v, _ := m.Get(ctx, "bank:1")
+	assert.Equal(t, "d", v)
+	assert.NoError(t, m.Release(ctx, lock), "locks are not cache entries")
+}
+
+func TestEscapeGlob(t *testing.T) {
+	assert.Equal(t, `credit:\*\?\[x\]\\`, escapeGlob(`credit:*?[x]\`))
+	assert.Equal(t, "credit:", escapeGlob("credit:"))
+}
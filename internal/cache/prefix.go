@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Number of keys scanned and deleted per Redis round trip by DeletePrefix
+const prefixScanCount = 500
+
+/*
+	Invalidation messages ending in invalidationWildcard drop every local key with the preceding prefix
+	Cache keys never end in "*", so a single-key invalidation cannot be mistaken for a prefix
+*/
+
+const invalidationWildcard = "*"
+
+// Implemented by caches that can delete every key starting with a prefix (operator purges)
+type PrefixDeleter interface {
+	// Deletes the keys starting with prefix and returns how many were removed
+	DeletePrefix(ctx context.Context, prefix string) (int, error)
+}
+
+var (
+	_ PrefixDeleter = (*RedisCache)(nil)
+	_ PrefixDeleter = (*MemoryCache)(nil)
+	_ PrefixDeleter = (*TieredCache)(nil)
+)
+
+// Deletes the live keys starting with prefix; locks live outside the LRU and are kept
+func (m *MemoryCache) DeletePrefix(_ context.Context, prefix string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for key, el := range m.items {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if !m.expired(el.Value.(*memoryEntry)) {
+			n++
+		}
+		m.remove(el)
+	}
+	return n, nil
+}
+
+/*
+	Deletes the keys starting with prefix, walking the keyspace with SCAN so Redis is never blocked
+	Keys written while the scan runs may survive it
+*/
+
+func (r *RedisCache) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	iter := r.client.Scan(ctx, 0, escapeGlob(prefix)+"*", prefixScanCount).Iterator()
+	n := 0
+	batch := make([]string, 0, prefixScanCount)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		deleted, err := r.client.Unlink(ctx, batch...).Result()
+		n += int(deleted)
+		batch = batch[:0]
+		return err
+	}
+	for iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) == prefixScanCount {
+			if err := flush(); err != nil {
+				return n, err
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return n, err
+	}
+	return n, flush()
+}
+
+/*
+	Deletes the keys starting with prefix from both tiers and tells the other instances to drop their
+	local copies; returns the remote count, which covers every key shared across instances
+*/
+
+func (t *TieredCache) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	n, _ := t.local.DeletePrefix(ctx, prefix)
+	rd, ok := t.remote.(PrefixDeleter)
+	if !ok {
+		return n, nil
+	}
+	// Published even when the remote delete fails, as in Delete
+	n, err := rd.DeletePrefix(ctx, prefix)
+	if bus, ok := t.remote.(InvalidationBus); ok {
+		err = errors.Join(err, bus.PublishInvalidation(ctx, prefix+invalidationWildcard))
+	}
+	return n, err
+}
+
+// Escapes the Redis glob metacharacters in s so it matches literally
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -56,6 +56,24 @@ func (r *RedisCache) Get(ctx context.Context, key string) (string, error) {
 	return val, err
 }
 
+// Gets the value for key and its remaining TTL (GET and PTTL in one round trip)
+func (r *RedisCache) GetWithTTL(ctx context.Context, key string) (string, int, error) {
+	var get *redis.StringCmd
+	var pttl *redis.DurationCmd
+	_, err := r.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		get = p.Get(ctx, key)
+		pttl = p.PTTL(ctx, key)
+		return nil
+	})
+	if err == redis.Nil {
+		return "", 0, nil
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	return get.Val(), ceilSeconds(pttl.Val()), nil
+}
+
 // Sets the value for key with TTL in seconds
 func (r *RedisCache) Set(ctx context.Context, key string, value string, ttlSeconds int) error {
 	return r.client.Set(ctx, key, value, time.Duration(ttlSeconds)*time.Second).Err()
@@ -76,6 +94,14 @@ func (r *RedisCache) Delete(ctx context.Context, key string) error {
 	return r.client.Del(ctx, key).Err()
 }
 
+// Whole seconds in d, rounded up; 0 for no expiry (PTTL answers negative values then)
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int((d + time.Second - 1) / time.Second)
+}
+
 // Deserializes key into v
 func (r *RedisCache) GetJSON(ctx context.Context, key string, v interface{}) error {
 	s, err := r.Get(ctx, key)
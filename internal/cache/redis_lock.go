@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Sets the lock if free and bumps the key's fencing counter; returns the fence or 0 when held
+var acquireScript = redis.NewScript(`
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return redis.call('INCR', KEYS[2])
+end
+return 0
+`)
+
+// Sets the lock if free, without a fencing counter
+var acquireUnfencedScript = redis.NewScript(`
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return 1
+end
+return 0
+`)
+
+// Extends the lock only if ARGV[1] still owns it
+var renewScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// Deletes the lock only if ARGV[1] still owns it
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// Acquires key for ttl; returns nil if another owner holds it
+func (r *RedisCache) Acquire(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	token := newLockToken()
+	fence, err := acquireScript.Run(ctx, r.client, []string{key, key + ":fence"}, token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return nil, err
+	}
+	if fence == 0 {
+		return nil, nil
+	}
+	return &Lock{Key: key, Token: token, Fence: fence}, nil
+}
+
+// Acquires key for ttl without bumping its fencing counter; returns nil if another owner holds it
+func (r *RedisCache) AcquireUnfenced(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	token := newLockToken()
+	n, err := acquireUnfencedScript.Run(ctx, r.client, []string{key}, token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, nil
+	}
+	return &Lock{Key: key, Token: token}, nil
+}
+
+// Extends a held lock to ttl from now
+func (r *RedisCache) Renew(ctx context.Context, lock *Lock, ttl time.Duration) error {
+	n, err := renewScript.Run(ctx, r.client, []string{lock.Key}, lock.Token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Releases a held lock
+func (r *RedisCache) Release(ctx context.Context, lock *Lock) error {
+	n, err := releaseScript.Run(ctx, r.client, []string{lock.Key}, lock.Token).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// TTL in seconds of a local copy refilled from the remote tier when neither LocalTTL nor the remote key bound it
+const defaultRefillTTL = 60
+
+// Per-tier TTL caps in seconds; 0 keeps the TTL requested by the caller
+type TieredConfig struct {
+	LocalTTL  int
+	RemoteTTL int
+}
+
+/*
+	TieredCache serves hot keys from an in-process MemoryCache and falls back to a shared remote cache (Redis)
+	Values are written to both tiers, each with its own TTL cap; deletes are broadcast through the
+	remote tier (see ListenInvalidations) and LocalTTL bounds staleness when a broadcast is missed
+	Counters (Incr/Expire) live in the remote tier so rate limits are shared across instances,
+	and fall back to the local tier while the remote one is failing
+	Remote read errors are treated as misses so a Redis blip degrades to the local tier instead of failing requests
+*/
+
+type TieredCache struct {
+	local  *MemoryCache
+	remote Cache
+	cfg    TieredConfig
+}
+
+func NewTieredCache(local *MemoryCache, remote Cache, cfg TieredConfig) *TieredCache {
+	return &TieredCache{
+		local:  local,
+		remote: remote,
+		cfg:    cfg,
+	}
+}
+
+/*
+	Gets the value for key from memory, then from the remote tier (refilling memory on a hit)
+	The local copy expires with the remote key, capped by LocalTTL, and always expires: an entry invalidated
+	while this instance missed the broadcast is served at most defaultRefillTTL when nothing else bounds it
+*/
+
+func (t *TieredCache) Get(ctx context.Context, key string) (string, error) {
+	if v, _ := t.local.Get(ctx, key); v != "" {
+		return v, nil
+	}
+	v, remaining, err := t.remoteGet(ctx, key)
+	if err != nil || v == "" {
+		return "", nil
+	}
+	ttl := capTTL(remaining, t.cfg.LocalTTL)
+	if ttl <= 0 {
+		ttl = defaultRefillTTL
+	}
+	_ = t.local.Set(ctx, key, v, ttl)
+	return v, nil
+}
+
+// Reads key from the remote tier with its remaining TTL when the tier reports it (0 otherwise)
+func (t *TieredCache) remoteGet(ctx context.Context, key string) (string, int, error) {
+	if r, ok := t.remote.(TTLReader); ok {
+		return r.GetWithTTL(ctx, key)
+	}
+	v, err := t.remote.Get(ctx, key)
+	return v, 0, err
+}
+
+// Sets the value in both tiers; the local write always succeeds, the remote error is returned
+func (t *TieredCache) Set(ctx context.Context, key string, value string, ttlSeconds int) error {
+	_ = t.local.Set(ctx, key, value, capTTL(ttlSeconds, t.cfg.LocalTTL))
+	return t.remote.Set(ctx, key, value, capTTL(ttlSeconds, t.cfg.RemoteTTL))
+}
+
+// Increments the shared counter, or the local one while the remote tier is failing
+func (t *TieredCache) Incr(ctx context.Context, key string) (int64, error) {
+	n, err := t.remote.Incr(ctx, key)
+	if err != nil {
+		return t.local.Incr(ctx, key)
+	}
+	return n, nil
+}
+
+// Sets the TTL in both tiers so a local fallback counter also expires
+func (t *TieredCache) Expire(ctx context.Context, key string, ttlSeconds int) error {
+	_ = t.local.Expire(ctx, key, ttlSeconds)
+	return t.remote.Expire(ctx, key, ttlSeconds)
+}
+
+// Acquires the lock in the remote tier so it is shared by all instances; while the remote tier is
+// failing the lock is taken locally (per instance), keeping writes available behind their database locks
+func (t *TieredCache) Acquire(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	if rl, ok := t.remote.(Locker); ok {
+		if lock, err := rl.Acquire(ctx, key, ttl); err == nil {
+			return lock, nil
+		}
+	}
+	lock, err := t.local.Acquire(ctx, key, ttl)
+	if lock != nil {
+		lock.local = true
+	}
+	return lock, err
+}
+
+// Acquires an unfenced lock, in the remote tier when it is available and locally otherwise (see Acquire)
+func (t *TieredCache) AcquireUnfenced(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	if rl, ok := t.remote.(Locker); ok {
+		if lock, err := rl.AcquireUnfenced(ctx, key, ttl); err == nil {
+			return lock, nil
+		}
+	}
+	lock, err := t.local.AcquireUnfenced(ctx, key, ttl)
+	if lock != nil {
+		lock.local = true
+	}
+	return lock, err
+}
+
+// Renews the lock in the tier it was acquired from
+func (t *TieredCache) Renew(ctx context.Context, lock *Lock, ttl time.Duration) error {
+	return t.lockerFor(lock).Renew(ctx, lock, ttl)
+}
+
+// Releases the lock in the tier it was acquired from
+func (t *TieredCache) Release(ctx context.Context, lock *Lock) error {
+	return t.lockerFor(lock).Release(ctx, lock)
+}
+
+func (t *TieredCache) lockerFor(lock *Lock) Locker {
+	if rl, ok := t.remote.(Locker); ok && !lock.local {
+		return rl
+	}
+	return t.local
+}
+
+/*
+	Deletes the key from both tiers and tells the other instances to drop their local copy
+	The invalidation is published even when the remote delete fails: the other instances must not keep
+	serving a copy this one could not remove
+*/
+
+func (t *TieredCache) Delete(ctx context.Context, key string) error {
+	_ = t.local.Delete(ctx, key)
+	err := t.remote.Delete(ctx, key)
+	if bus, ok := t.remote.(InvalidationBus); ok {
+		err = errors.Join(err, bus.PublishInvalidation(ctx, key))
+	}
+	return err
+}
+
+// Deserializes key into v
+func (t *TieredCache) GetJSON(ctx context.Context, key string, v interface{}) error {
+	s, err := t.Get(ctx, key)
+	if err != nil || s == "" {
+		return err
+	}
+	return json.Unmarshal([]byte(s), v)
+}
+
+// Serializes v and stores with TTL
+func (t *TieredCache) SetJSON(ctx context.Context, key string, v interface{}, ttlSeconds int) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return t.Set(ctx, key, string(b), ttlSeconds)
+}
+
+// Caps ttl at limit; ttl <= 0 (no expiry) becomes limit
+func capTTL(ttl, limit int) int {
+	if limit <= 0 {
+		return ttl
+	}
+	if ttl <= 0 || ttl > limit {
+		return limit
+	}
+	return ttl
+}
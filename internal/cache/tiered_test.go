@@ -0,0 +1,257 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// remoteCache is a MemoryCache that records TTLs, can be switched to failing and fans out invalidations
+type remoteCache struct {
+	*MemoryCache
+	down bool
+	ttls map[string]int
+
+	mu   sync.Mutex
+	subs []func(string)
+}
+
+var errRemoteDown = errors.New("remote down")
+
+func newRemoteCache() *remoteCache {
+	return &remoteCache{MemoryCache: NewMemoryCache(0), ttls: make(map[string]int)}
+}
+
+func (r *remoteCache) Get(ctx context.Context, key string) (string, error) {
+	if r.down {
+		return "", errRemoteDown
+	}
+	return r.MemoryCache.Get(ctx, key)
+}
+
+func (r *remoteCache) GetWithTTL(ctx context.Context, key string) (string, int, error) {
+	if r.down {
+		return "", 0, errRemoteDown
+	}
+	return r.MemoryCache.GetWithTTL(ctx, key)
+}
+
+func (r *remoteCache) Set(ctx context.Context, key, value string, ttl int) error {
+	if r.down {
+		return errRemoteDown
+	}
+	r.ttls[key] = ttl
+	return r.MemoryCache.Set(ctx, key, value, ttl)
+}
+
+func (r *remoteCache) Delete(ctx context.Context, key string) error {
+	if r.down {
+		return errRemoteDown
+	}
+	return r.MemoryCache.Delete(ctx, key)
+}
+
+func (r *remoteCache) Incr(ctx context.Context, key string) (int64, error) {
+	if r.down {
+		return 0, errRemoteDown
+	}
+	return r.MemoryCache.Incr(ctx, key)
+}
+
+func TestTieredCache_ReadThrough(t *testing.T) {
+	ctx := context.Background()
+	local, advance := newTestMemoryCache(10)
+	remote := newRemoteCache()
+	c := NewTieredCache(local, remote, TieredConfig{LocalTTL: 5})
+
+	// A remote hit fills the local tier
+	require.NoError(t, remote.Set(ctx, "k", "v", 0))
+	v, err := c.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v", v)
+	lv, _ := local.Get(ctx, "k")
+	assert.Equal(t, "v", lv)
+
+	// The local copy expires after LocalTTL even though the caller asked for longer
+	require.NoError(t, c.Set(ctx, "long", "x", 60))
+	assert.Equal(t, 60, remote.ttls["long"])
+	advance(5 * time.Second)
+	lv, _ = local.Get(ctx, "long")
+	assert.Empty(t, lv)
+	v, _ = c.Get(ctx, "long")
+	assert.Equal(t, "x", v)
+}
+
+func TestTieredCache_RefillExpiresWithoutLocalTTL(t *testing.T) {
+	ctx := context.Background()
+	local, advance := newTestMemoryCache(10)
+	remote := newRemoteCache()
+	c := NewTieredCache(local, remote, TieredConfig{LocalTTL: 0})
+
+	// The local copy of a remote hit expires with the remote key...
+	require.NoError(t, remote.Set(ctx, "credit:missing:1", "1", 30))
+	v, err := c.Get(ctx, "credit:missing:1")
+	require.NoError(t, err)
+	assert.Equal(t, "1", v)
+	// ...and with defaultRefillTTL when the remote key has none
+	require.NoError(t, remote.Set(ctx, "forever", "x", 0))
+	_, err = c.Get(ctx, "forever")
+	require.NoError(t, err)
+
+	// Both remote keys are dropped without this instance hearing about it
+	require.NoError(t, remote.MemoryCache.Delete(ctx, "credit:missing:1"))
+	require.NoError(t, remote.MemoryCache.Delete(ctx, "forever"))
+	advance(29 * time.Second)
+	lv, _ := local.Get(ctx, "credit:missing:1")
+	assert.Equal(t, "1", lv)
+	advance(time.Second)
+	lv, _ = local.Get(ctx, "credit:missing:1")
+	assert.Empty(t, lv, "the local copy outlived the remote key")
+	advance(defaultRefillTTL * time.Second)
+	lv, _ = local.Get(ctx, "forever")
+	assert.Empty(t, lv, "LocalTTL <= 0 kept a refilled copy forever")
+}
+
+func TestTieredCache_RemoteTTLCap(t *testing.T) {
+	ctx := context.Background()
+	local, _ := newTestMemoryCache(10)
+	remote := newRemoteCache()
+	c := NewTieredCache(local, remote, TieredConfig{RemoteTTL: 30})
+
+	require.NoError(t, c.Set(ctx, "a", "1", 300))
+	require.NoError(t, c.Set(ctx, "b", "2", 0))
+	require.NoError(t, c.Set(ctx, "c", "3", 10))
+	assert.Equal(t, 30, remote.ttls["a"])
+	assert.Equal(t, 30, remote.ttls["b"])
+	assert.Equal(t, 10, remote.ttls["c"])
+}
+
+func TestTieredCache_RemoteDown(t *testing.T) {
+	ctx := context.Background()
+	local, _ := newTestMemoryCache(10)
+	remote := newRemoteCache()
+	c := NewTieredCache(local, remote, TieredConfig{LocalTTL: 5})
+
+	require.NoError(t, c.Set(ctx, "k", "v", 60))
+	remote.down = true
+
+	// Reads keep working from memory and misses are not errors
+	v, err := c.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v", v)
+	v, err = c.Get(ctx, "other")
+	require.NoError(t, err)
+	assert.Empty(t, v)
+
+	// Writes still land locally while reporting the remote failure
+	assert.ErrorIs(t, c.Set(ctx, "new", "n", 60), errRemoteDown)
+	v, _ = c.Get(ctx, "new")
+	assert.Equal(t, "n", v)
+
+	// Counters fall back to the local tier
+	n, err := c.Incr(ctx, "counter")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	n, _ = c.Incr(ctx, "counter")
+	assert.Equal(t, int64(2), n)
+}
+
+func (r *remoteCache) PublishInvalidation(_ context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, onKey := range r.subs {
+		onKey(key)
+	}
+	return nil
+}
+
+func (r *remoteCache) SubscribeInvalidations(ctx context.Context, onKey func(string)) error {
+	r.mu.Lock()
+	r.subs = append(r.subs, onKey)
+	r.mu.Unlock()
+	<-ctx.Done()
+	return nil
+}
+
+func TestTieredCache_DeleteInvalidatesOtherInstances(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	remote := newRemoteCache()
+	localA, localB := NewMemoryCache(10), NewMemoryCache(10)
+	a := NewTieredCache(localA, remote, TieredConfig{LocalTTL: 60})
+	b := NewTieredCache(localB, remote, TieredConfig{LocalTTL: 60})
+	go func() { _ = b.ListenInvalidations(ctx) }()
+	require.Eventually(t, func() bool {
+		remote.mu.Lock()
+		defer remote.mu.Unlock()
+		return len(remote.subs) == 1
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, a.Set(ctx, "bank:1", "active", 300))
+	v, _ := b.Get(ctx, "bank:1")
+	require.Equal(t, "active", v)
+
+	require.NoError(t, a.Delete(ctx, "bank:1"))
+	lv, _ := localB.Get(ctx, "bank:1")
+	assert.Empty(t, lv, "instance B dropped its local copy")
+	v, _ = b.Get(ctx, "bank:1")
+	assert.Empty(t, v)
+}
+
+func TestTieredCache_DeleteInvalidatesWhenRemoteFails(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	remote := newRemoteCache()
+	localA, localB := NewMemoryCache(10), NewMemoryCache(10)
+	a := NewTieredCache(localA, remote, TieredConfig{LocalTTL: 60})
+	b := NewTieredCache(localB, remote, TieredConfig{LocalTTL: 60})
+	go func() { _ = b.ListenInvalidations(ctx) }()
+	require.Eventually(t, func() bool {
+		remote.mu.Lock()
+		defer remote.mu.Unlock()
+		return len(remote.subs) == 1
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, a.Set(ctx, "bank:1", "active", 300))
+	_, _ = b.Get(ctx, "bank:1")
+
+	remote.down = true
+	assert.ErrorIs(t, a.Delete(ctx, "bank:1"), errRemoteDown)
+	lv, _ := localB.Get(ctx, "bank:1")
+	assert.Empty(t, lv, "instance B dropped its local copy although the remote delete failed")
+}
+
+func TestTieredCache_DeletePrefixInvalidatesOtherInstances(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	remote := newRemoteCache()
+	localA, localB := NewMemoryCache(10), NewMemoryCache(10)
+	a := NewTieredCache(localA, remote, TieredConfig{LocalTTL: 60})
+	b := NewTieredCache(localB, remote, TieredConfig{LocalTTL: 60})
+	go func() { _ = b.ListenInvalidations(ctx) }()
+	require.Eventually(t, func() bool {
+		remote.mu.Lock()
+		defer remote.mu.Unlock()
+		return len(remote.subs) == 1
+	}, time.Second, time.Millisecond)
+
+	for _, key := range []string{"credit:1", "credit:2", "bank:1"} {
+		require.NoError(t, a.Set(ctx, key, "v", 300))
+		_, _ = b.Get(ctx, key)
+	}
+
+	n, err := a.DeletePrefix(ctx, "credit:")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	for _, key := range []string{"credit:1", "credit:2"} {
+		lv, _ := localB.Get(ctx, key)
+		assert.Empty(t, lv, "instance B dropped its local copy of %s", key)
+	}
+	v, _ := b.Get(ctx, "bank:1")
+	assert.Equal(t, "v", v, "keys outside the prefix survive")
+}
@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/tucredito/backend-api/internal/tracing"
+)
+
+/*
+	TracingHook gives every Redis command a client span ("redis GET", "redis EVALSHA", ...)
+	and every pipeline one span; keys and values are not recorded
+	redis.Nil (a miss) is not an error
+*/
+
+type TracingHook struct{}
+
+var _ redis.Hook = TracingHook{}
+
+func (TracingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (TracingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		op := strings.ToUpper(cmd.Name())
+		ctx, span := tracing.Start(ctx, "redis "+op, tracing.WithKind(tracing.KindClient), tracing.WithAttributes(
+			tracing.String("db.system", "redis"),
+			tracing.String("db.operation", op),
+		))
+		defer span.End()
+		err := next(ctx, cmd)
+		if !errors.Is(err, redis.Nil) {
+			span.RecordError(err)
+		}
+		return err
+	}
+}
+
+func (TracingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		ctx, span := tracing.Start(ctx, "redis pipeline", tracing.WithKind(tracing.KindClient), tracing.WithAttributes(
+			tracing.String("db.system", "redis"),
+			tracing.Int("db.redis.commands", len(cmds)),
+		))
+		defer span.End()
+		err := next(ctx, cmds)
+		if !errors.Is(err, redis.Nil) {
+			span.RecordError(err)
+		}
+		return err
+	}
+}
@@ -0,0 +1,182 @@
+package clientip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+/*
+	Client IP resolution behind reverse proxies
+	The forwarding header is only believed when the connection comes from a trusted proxy, and the chain is
+	walked from the right (the hop closest to us) skipping trusted proxies: the first untrusted address is
+	the client. Entries left of it were written by the client and are ignored, so rotating a spoofed header
+	cannot change the resolved IP
+	Only the one header the trusted proxies write is read (X-Forwarded-For or Forwarded): a proxy that
+	appends to one passes the other through from the client untouched
+*/
+
+// Forwarding headers a Resolver can read
+const (
+	HeaderXForwardedFor = "X-Forwarded-For"
+	HeaderForwarded     = "Forwarded"
+)
+
+// Prefixes is a list of networks, e.g. trusted proxies or an allow/deny list
+type Prefixes []netip.Prefix
+
+// Parses comma-separated IPs and CIDRs ("10.0.0.0/8, 192.168.1.10")
+func ParsePrefixes(spec string) (Prefixes, error) {
+	var out Prefixes
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			p, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR %q: %w", entry, err)
+			}
+			out = append(out, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP %q: %w", entry, err)
+		}
+		addr = addr.Unmap()
+		out = append(out, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return out, nil
+}
+
+// Reports whether addr is in any of the networks
+func (p Prefixes) Contains(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range p {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the canonical name of a supported forwarding header; "" means X-Forwarded-For
+func ParseHeader(name string) (string, error) {
+	switch {
+	case name == "" || strings.EqualFold(name, HeaderXForwardedFor):
+		return HeaderXForwardedFor, nil
+	case strings.EqualFold(name, HeaderForwarded):
+		return HeaderForwarded, nil
+	}
+	return "", fmt.Errorf("unsupported forwarding header %q (want %s or %s)", name, HeaderXForwardedFor, HeaderForwarded)
+}
+
+// Resolver finds the client address of a request given the trusted proxies in front of the API
+type Resolver struct {
+	trusted Prefixes
+	header  string
+}
+
+// header is the forwarding header the trusted proxies set (see ParseHeader); the other one is ignored
+func NewResolver(trusted Prefixes, header string) *Resolver {
+	if header != HeaderForwarded {
+		header = HeaderXForwardedFor
+	}
+	return &Resolver{trusted: trusted, header: header}
+}
+
+// Returns the client address of r; invalid when RemoteAddr cannot be parsed
+func (res *Resolver) Resolve(r *http.Request) netip.Addr {
+	remote := parseHost(r.RemoteAddr)
+	if !remote.IsValid() || !res.trusted.Contains(remote) {
+		return remote
+	}
+
+	var chain []string
+	if res.header == HeaderForwarded {
+		chain = forwardedFor(r.Header.Values(HeaderForwarded))
+	} else {
+		chain = xForwardedFor(r.Header.Values(HeaderXForwardedFor))
+	}
+
+	client := remote
+	for i := len(chain) - 1; i >= 0; i-- {
+		addr := parseHost(chain[i])
+		if !addr.IsValid() {
+			// A garbled hop: stop at the last address we could verify
+			break
+		}
+		client = addr
+		if !res.trusted.Contains(addr) {
+			break
+		}
+	}
+	return client
+}
+
+// Splits X-Forwarded-For values into hops, leftmost first
+func xForwardedFor(values []string) []string {
+	var hops []string
+	for _, v := range values {
+		for _, hop := range strings.Split(v, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+	return hops
+}
+
+// Extracts the for= parameters of Forwarded values, leftmost first
+func forwardedFor(values []string) []string {
+	var hops []string
+	for _, v := range values {
+		for _, element := range strings.Split(v, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if ok && strings.EqualFold(key, "for") {
+					hops = append(hops, strings.Trim(val, `"`))
+				}
+			}
+		}
+	}
+	return hops
+}
+
+// Parses "ip", "ip:port", "[ipv6]" or "[ipv6]:port"
+func parseHost(s string) netip.Addr {
+	s = strings.TrimSpace(s)
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return addr.Unmap()
+}
+
+type ipKey struct{}
+
+// Attaches the resolved client address to ctx
+func WithIP(ctx context.Context, addr netip.Addr) context.Context {
+	return context.WithValue(ctx, ipKey{}, addr)
+}
+
+// Returns the client address attached to ctx, if any
+func FromContext(ctx context.Context) (netip.Addr, bool) {
+	addr, ok := ctx.Value(ipKey{}).(netip.Addr)
+	return addr, ok && addr.IsValid()
+}
+
+// Returns the client address attached to ctx as a string, or "" when none
+func String(ctx context.Context) string {
+	if addr, ok := FromContext(ctx); ok {
+		return addr.String()
+	}
+	return ""
+}
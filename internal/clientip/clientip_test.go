@@ -0,0 +1,79 @@
+package clientip
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolver_Resolve(t *testing.T) {
+	trusted, err := ParsePrefixes("10.0.0.0/8, 192.168.1.1, fd00::/8")
+	require.NoError(t, err)
+	res := NewResolver(trusted, HeaderXForwardedFor)
+	viaForwarded := NewResolver(trusted, HeaderForwarded)
+
+	cases := []struct {
+		name      string
+		remote    string
+		xff       []string
+		forwarded string
+		// Resolve through the Forwarded header instead of X-Forwarded-For
+		rfc7239 bool
+		want    string
+	}{
+		{name: "direct client", remote: "203.0.113.7:5000", want: "203.0.113.7"},
+		{name: "untrusted peer cannot spoof", remote: "203.0.113.7:5000", xff: []string{"1.2.3.4"}, want: "203.0.113.7"},
+		{name: "one trusted proxy", remote: "10.0.0.5:80", xff: []string{"198.51.100.2"}, want: "198.51.100.2"},
+		{name: "client-supplied hops are ignored", remote: "10.0.0.5:80", xff: []string{"6.6.6.6, 198.51.100.2"}, want: "198.51.100.2"},
+		{name: "chain of trusted proxies", remote: "10.0.0.5:80", xff: []string{"198.51.100.2, 192.168.1.1", "10.1.1.1"}, want: "198.51.100.2"},
+		{name: "all hops trusted", remote: "10.0.0.5:80", xff: []string{"10.9.9.9"}, want: "10.9.9.9"},
+		{name: "garbage hop stops the walk", remote: "10.0.0.5:80", xff: []string{"198.51.100.2, not-an-ip"}, want: "10.0.0.5"},
+		{name: "forwarded header", remote: "10.0.0.5:80", forwarded: `for=6.6.6.6, for="[2001:db8::1]:4711";proto=https`, rfc7239: true, want: "2001:db8::1"},
+		{name: "client forwarded ignored behind an xff proxy", remote: "10.0.0.5:80", xff: []string{"198.51.100.9"}, forwarded: "for=6.6.6.6", want: "198.51.100.9"},
+		{name: "client xff ignored behind a forwarded proxy", remote: "10.0.0.5:80", xff: []string{"6.6.6.6"}, forwarded: "for=198.51.100.2", rfc7239: true, want: "198.51.100.2"},
+		{name: "missing configured header", remote: "10.0.0.5:80", xff: []string{"6.6.6.6"}, rfc7239: true, want: "10.0.0.5"},
+		{name: "ipv6 proxy", remote: "[fd00::1]:80", xff: []string{"2001:db8::2"}, want: "2001:db8::2"},
+		{name: "ipv4-mapped remote", remote: "[::ffff:10.0.0.5]:80", xff: []string{"198.51.100.2"}, want: "198.51.100.2"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tc.remote
+			for _, v := range tc.xff {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			if tc.forwarded != "" {
+				r.Header.Set("Forwarded", tc.forwarded)
+			}
+			if tc.rfc7239 {
+				assert.Equal(t, tc.want, viaForwarded.Resolve(r).String())
+				return
+			}
+			assert.Equal(t, tc.want, res.Resolve(r).String())
+		})
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	for in, want := range map[string]string{"": HeaderXForwardedFor, "x-forwarded-for": HeaderXForwardedFor, "forwarded": HeaderForwarded} {
+		got, err := ParseHeader(in)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	_, err := ParseHeader("X-Real-IP")
+	assert.Error(t, err)
+}
+
+func TestParsePrefixes(t *testing.T) {
+	p, err := ParsePrefixes("")
+	require.NoError(t, err)
+	assert.Empty(t, p)
+
+	_, err = ParsePrefixes("10.0.0.0/33")
+	assert.Error(t, err)
+	_, err = ParsePrefixes("proxy.internal")
+	assert.Error(t, err)
+}
@@ -3,8 +3,11 @@ package decision
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/metrics"
+	"github.com/tucredito/backend-api/internal/tracing"
 )
 
 /*
@@ -36,13 +39,23 @@ func (e *RuleEngine) Evaluate(ctx context.Context, input *EligibilityInput) (*El
 	copy(rules, e.rules)
 	e.mu.RUnlock()
 
+	ctx, span := tracing.Start(ctx, "decision.evaluate", tracing.WithAttributes(tracing.Int("decision.rules", len(rules))))
+	defer span.End()
+
 	if len(rules) == 0 {
+		metrics.ObserveDecision(false)
+		span.SetAttributes(tracing.Bool("decision.approved", false))
 		return &EligibilityResult{Approved: false, Priority: 0, Score: 0, RuleName: "none"}, nil
 	}
 
 	var last *EligibilityResult
 	for _, rule := range rules {
-		approved, priority, score := rule.Evaluate(ctx, input)
+		ruleCtx, ruleSpan := tracing.Start(ctx, "decision.rule "+rule.Name())
+		start := time.Now()
+		approved, priority, score := rule.Evaluate(ruleCtx, input)
+		metrics.ObserveRuleEvaluation(rule.Name(), approved, time.Since(start))
+		ruleSpan.SetAttributes(tracing.Bool("decision.approved", approved), tracing.Float("decision.score", score))
+		ruleSpan.End()
 		last = &EligibilityResult{
 			Approved: approved,
 			Priority: priority,
@@ -50,10 +63,12 @@ func (e *RuleEngine) Evaluate(ctx context.Context, input *EligibilityInput) (*El
 			RuleName: rule.Name(),
 		}
 		if approved {
-			return last, nil
+			break
 		}
 	}
 
+	metrics.ObserveDecision(last.Approved)
+	span.SetAttributes(tracing.Bool("decision.approved", last.Approved), tracing.String("decision.rule", last.RuleName))
 	return last, nil
 }
 
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Entity affected by an audited mutation (CLIENT, BANK, CREDIT)
+type AuditEntity string
+
+const (
+	AuditEntityClient AuditEntity = "CLIENT"
+	AuditEntityBank   AuditEntity = "BANK"
+	AuditEntityCredit AuditEntity = "CREDIT"
+)
+
+// Kind of audited mutation
+type AuditAction string
+
+const (
+	AuditActionCreate       AuditAction = "CREATE"
+	AuditActionUpdate       AuditAction = "UPDATE"
+	AuditActionUpdateStatus AuditAction = "UPDATE_STATUS"
+	AuditActionDeactivate   AuditAction = "DEACTIVATE"
+	AuditActionActivate     AuditAction = "ACTIVATE"
+)
+
+/*
+	AuditEntry is an append-only record of a mutation
+	Changes maps each modified field to {"before": ..., "after": ...}
+*/
+
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	Entity     AuditEntity     `json:"entity"`
+	EntityID   string          `json:"entity_id"`
+	Action     AuditAction     `json:"action"`
+	Actor      string          `json:"actor"`
+	RequestID  string          `json:"request_id,omitempty"`
+	ClientIP   string          `json:"client_ip,omitempty"`
+	Changes    json.RawMessage `json:"changes"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
@@ -14,6 +14,7 @@ type Bank struct {
 	Name     string   `json:"name"`
 	Type     BankType `json:"type"`
 	IsActive bool     `json:"is_active"`
+	Version  int      `json:"version"`
 }
 
 // Structure for creating a bank
@@ -27,3 +28,22 @@ type UpdateBankInput struct {
 	Name string   `json:"name"`
 	Type BankType `json:"type"`
 }
+
+// Merge patch for a bank (RFC 7396); nil fields are left unchanged
+type BankPatch struct {
+	Name *string   `json:"name,omitempty"`
+	Type *BankType `json:"type,omitempty"`
+}
+
+// Returns the update that results from applying p to b
+func (p BankPatch) Apply(b *Bank) UpdateBankInput {
+	return UpdateBankInput{
+		Name: valueOr(p.Name, b.Name),
+		Type: valueOr(p.Type, b.Type),
+	}
+}
+
+// Reports whether p changes nothing
+func (p BankPatch) IsEmpty() bool {
+	return p == BankPatch{}
+}
@@ -11,6 +11,7 @@ type Client struct {
 	Country   string    `json:"country"`
 	CreatedAt time.Time `json:"created_at"`
 	IsActive  bool      `json:"is_active"`
+	Version   int       `json:"version"`
 }
 
 // Structure for creating a client
@@ -28,3 +29,26 @@ type UpdateClientInput struct {
 	BirthDate time.Time `json:"birth_date"`
 	Country   string    `json:"country"`
 }
+
+// Merge patch for a client (RFC 7396); nil fields are left unchanged
+type ClientPatch struct {
+	FullName  *string    `json:"full_name,omitempty"`
+	Email     *string    `json:"email,omitempty"`
+	BirthDate *time.Time `json:"birth_date,omitempty"`
+	Country   *string    `json:"country,omitempty"`
+}
+
+// Returns the update that results from applying p to c
+func (p ClientPatch) Apply(c *Client) UpdateClientInput {
+	return UpdateClientInput{
+		FullName:  valueOr(p.FullName, c.FullName),
+		Email:     valueOr(p.Email, c.Email),
+		BirthDate: valueOr(p.BirthDate, c.BirthDate),
+		Country:   valueOr(p.Country, c.Country),
+	}
+}
+
+// Reports whether p changes nothing
+func (p ClientPatch) IsEmpty() bool {
+	return p == ClientPatch{}
+}
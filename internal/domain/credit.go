@@ -32,6 +32,7 @@ type Credit struct {
 	CreatedAt  time.Time    `json:"created_at"`
 	Status     CreditStatus `json:"status"`
 	IsActive   bool         `json:"is_active"`
+	Version    int          `json:"version"`
 }
 
 // Structure for creating a credit
@@ -56,3 +57,26 @@ type UpdateCreditInput struct {
 	TermMonths int          `json:"term_months"`
 	Status     CreditStatus `json:"status"`
 }
+
+// Merge patch for a credit (RFC 7396); nil fields are left unchanged
+type CreditPatch struct {
+	MinPayment *float64      `json:"min_payment,omitempty"`
+	MaxPayment *float64      `json:"max_payment,omitempty"`
+	TermMonths *int          `json:"term_months,omitempty"`
+	Status     *CreditStatus `json:"status,omitempty"`
+}
+
+// Returns the update that results from applying p to c
+func (p CreditPatch) Apply(c *Credit) UpdateCreditInput {
+	return UpdateCreditInput{
+		MinPayment: valueOr(p.MinPayment, c.MinPayment),
+		MaxPayment: valueOr(p.MaxPayment, c.MaxPayment),
+		TermMonths: valueOr(p.TermMonths, c.TermMonths),
+		Status:     valueOr(p.Status, c.Status),
+	}
+}
+
+// Reports whether p changes nothing
+func (p CreditPatch) IsEmpty() bool {
+	return p == CreditPatch{}
+}
@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// Format of a credit portfolio export (CSV, NDJSON)
+type ExportFormat string
+
+const (
+	ExportFormatCSV    ExportFormat = "CSV"
+	ExportFormatNDJSON ExportFormat = "NDJSON"
+)
+
+// Filters for a credit portfolio export (empty fields are ignored; only active credits are exported)
+type CreditExportFilter struct {
+	ClientID    string
+	BankID      string
+	Status      CreditStatus
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+}
+
+// A credit flattened with its client and bank attributes
+type CreditExportRow struct {
+	CreditID       string       `json:"credit_id"`
+	ClientID       string       `json:"client_id"`
+	BankID         string       `json:"bank_id"`
+	MinPayment     float64      `json:"min_payment"`
+	MaxPayment     float64      `json:"max_payment"`
+	TermMonths     int          `json:"term_months"`
+	CreditType     CreditType   `json:"credit_type"`
+	Status         CreditStatus `json:"status"`
+	CreatedAt      time.Time    `json:"created_at"`
+	ClientFullName string       `json:"client_full_name"`
+	ClientEmail    string       `json:"client_email"`
+	ClientCountry  string       `json:"client_country"`
+	BankName       string       `json:"bank_name"`
+	BankType       BankType     `json:"bank_type"`
+}
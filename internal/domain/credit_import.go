@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+// Format of a bulk credit import file (CSV, NDJSON)
+type ImportFormat string
+
+const (
+	ImportFormatCSV    ImportFormat = "CSV"
+	ImportFormatNDJSON ImportFormat = "NDJSON"
+)
+
+// Status of a bulk credit import (PROCESSING, COMPLETED)
+type ImportStatus string
+
+const (
+	ImportStatusProcessing ImportStatus = "PROCESSING"
+	ImportStatusCompleted  ImportStatus = "COMPLETED"
+)
+
+// CreditImport structure (a batch of credit applications and its per-row report)
+type CreditImport struct {
+	ID          string               `json:"id"`
+	Format      ImportFormat         `json:"format"`
+	Status      ImportStatus         `json:"status"`
+	Total       int                  `json:"total"`
+	Succeeded   int                  `json:"succeeded"`
+	Failed      int                  `json:"failed"`
+	CreatedAt   time.Time            `json:"created_at"`
+	CompletedAt *time.Time           `json:"completed_at,omitempty"`
+	Results     []CreditImportResult `json:"results,omitempty"`
+}
+
+// Outcome of a single row of a bulk credit import
+type CreditImportResult struct {
+	Row      int          `json:"row"`
+	CreditID string       `json:"credit_id,omitempty"`
+	Status   CreditStatus `json:"status,omitempty"`
+	Code     string       `json:"code,omitempty"`
+	Error    string       `json:"error,omitempty"`
+}
+
+// Structure for completing a credit import with its report
+type CompleteCreditImportInput struct {
+	Total     int
+	Succeeded int
+	Failed    int
+	Results   []CreditImportResult
+}
@@ -20,6 +20,11 @@ type DomainEvent struct {
 	Type       EventType `json:"type"`
 	Payload    []byte    `json:"payload"`
 	OccurredAt time.Time `json:"occurred_at"`
+	// The request (or first event) that started this chain of events, and the request or event that directly caused this one
+	CorrelationID string `json:"correlation_id,omitempty"`
+	CausationID   string `json:"causation_id,omitempty"`
+	// Transport metadata such as the W3C traceparent of the emitting request
+	Headers map[string]string `json:"headers,omitempty"`
 }
 
 // Payload for the CreditCreated event
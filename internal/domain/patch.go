@@ -0,0 +1,9 @@
+package domain
+
+// Returns *v, or current when the patch leaves the field out
+func valueOr[T any](v *T, current T) T {
+	if v != nil {
+		return *v
+	}
+	return current
+}
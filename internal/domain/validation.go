@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tucredito/backend-api/internal/validate"
+)
+
+/*
+	Validation rules for every input type, shared by handlers, bulk imports and services
+	Limits follow the schema (VARCHAR(255) names and emails, DECIMAL(15, 2) payments) so valid input never
+	fails on a database constraint
+*/
+
+const (
+	maxNameLength    = 255
+	maxPaymentAmount = 9_999_999_999_999.99
+	maxTermMonths    = 480
+	minClientAge     = 18
+	maxClientAge     = 120
+	maxURLLength     = 2048
+	minSecretLength  = 16
+)
+
+var (
+	bankTypes      = []string{string(BankTypePrivate), string(BankTypeGovernment)}
+	creditTypes    = []string{string(CreditTypeAuto), string(CreditTypeMortgage), string(CreditTypeCommercial)}
+	creditStatuses = []string{string(CreditStatusPending), string(CreditStatusApproved), string(CreditStatusRejected)}
+	eventTypes     = []string{string(EventCreditCreated), string(EventCreditApproved), string(EventCreditRejected)}
+)
+
+func validateClient(v *validate.Validator, fullName, email string, birthDate time.Time, country string) {
+	v.String("full_name", fullName).Required().MaxLen(maxNameLength)
+	v.String("email", email).Required().MaxLen(maxNameLength).Email()
+	v.Time("birth_date", birthDate).Required().Age(minClientAge, maxClientAge)
+	v.String("country", country).Required().Country()
+}
+
+func (in CreateClientInput) Validate() error {
+	v := validate.New()
+	validateClient(v, in.FullName, in.Email, in.BirthDate, in.Country)
+	return v.Err()
+}
+
+func (in UpdateClientInput) Validate() error {
+	v := validate.New()
+	validateClient(v, in.FullName, in.Email, in.BirthDate, in.Country)
+	return v.Err()
+}
+
+func validateBank(v *validate.Validator, name string, t BankType) {
+	v.String("name", name).Required().MaxLen(maxNameLength)
+	v.String("type", string(t)).Required().OneOf(bankTypes...)
+}
+
+func (in CreateBankInput) Validate() error {
+	v := validate.New()
+	validateBank(v, in.Name, in.Type)
+	return v.Err()
+}
+
+func (in UpdateBankInput) Validate() error {
+	v := validate.New()
+	validateBank(v, in.Name, in.Type)
+	return v.Err()
+}
+
+func validateTerms(v *validate.Validator, minPayment, maxPayment float64, termMonths int) {
+	v.Number("min_payment", minPayment).Positive().Max(maxPaymentAmount)
+	v.Number("max_payment", maxPayment).Positive().Max(maxPaymentAmount)
+	v.Check(maxPayment >= minPayment, "max_payment", validate.CodeOutOfRange, "must be greater than or equal to min_payment")
+	v.Int("term_months", termMonths).Min(1).Max(maxTermMonths)
+}
+
+func (in CreateCreditInput) Validate() error {
+	v := validate.New()
+	v.String("client_id", in.ClientID).Required()
+	v.String("bank_id", in.BankID).Required()
+	validateTerms(v, in.MinPayment, in.MaxPayment, in.TermMonths)
+	v.String("credit_type", string(in.CreditType)).Required().OneOf(creditTypes...)
+	return v.Err()
+}
+
+func (in UpdateCreditInput) Validate() error {
+	v := validate.New()
+	validateTerms(v, in.MinPayment, in.MaxPayment, in.TermMonths)
+	v.String("status", string(in.Status)).Required().OneOf(creditStatuses...)
+	return v.Err()
+}
+
+func (in UpdateCreditStatusInput) Validate() error {
+	v := validate.New()
+	v.String("status", string(in.Status)).Required().OneOf(creditStatuses...)
+	return v.Err()
+}
+
+func validateWebhookSubscription(v *validate.Validator, url string, types []EventType, secret string) {
+	v.String("url", url).Required().MaxLen(maxURLLength).URL().PublicHost()
+	v.Check(len(types) > 0, "event_types", validate.CodeRequired, "is required")
+	for _, t := range types {
+		v.String("event_types", string(t)).Required().OneOf(eventTypes...)
+	}
+	if secret != "" {
+		v.Check(len(secret) >= minSecretLength, "secret", validate.CodeOutOfRange, fmt.Sprintf("must be at least %d characters", minSecretLength))
+		v.String("secret", secret).MaxLen(maxNameLength)
+	}
+}
+
+func (in CreateWebhookSubscriptionInput) Validate() error {
+	v := validate.New()
+	validateWebhookSubscription(v, in.URL, in.EventTypes, in.Secret)
+	return v.Err()
+}
+
+func (in UpdateWebhookSubscriptionInput) Validate() error {
+	v := validate.New()
+	validateWebhookSubscription(v, in.URL, in.EventTypes, in.Secret)
+	return v.Err()
+}
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Status of a webhook delivery (PENDING, SUCCEEDED, DEAD)
+type WebhookDeliveryStatus string
+
+const (
+	// Waiting for its first attempt or a retry
+	WebhookDeliveryPending WebhookDeliveryStatus = "PENDING"
+	// The endpoint answered 2xx
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "SUCCEEDED"
+	// Dead-lettered: every attempt failed or the subscription is disabled; only a manual redelivery retries it
+	WebhookDeliveryDead WebhookDeliveryStatus = "DEAD"
+)
+
+// WebhookSubscription structure (a partner endpoint notified of credit lifecycle events)
+type WebhookSubscription struct {
+	ID string `json:"id"`
+	// Bank whose credits are notified; empty for platform subscriptions, which receive every bank's events
+	BankID     string      `json:"bank_id,omitempty"`
+	URL        string      `json:"url"`
+	EventTypes []EventType `json:"event_types"`
+	// Signing secret; only returned when the subscription is created or the secret is changed
+	Secret    string    `json:"secret,omitempty"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Reports whether the subscription wants events of type t
+func (s *WebhookSubscription) Wants(t EventType) bool {
+	for _, et := range s.EventTypes {
+		if et == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Structure for creating a webhook subscription; an empty secret is generated
+type CreateWebhookSubscriptionInput struct {
+	URL        string      `json:"url"`
+	EventTypes []EventType `json:"event_types"`
+	Secret     string      `json:"secret"`
+}
+
+// Structure for updating a webhook subscription; an empty secret keeps the current one, a nil is_active keeps the state
+type UpdateWebhookSubscriptionInput struct {
+	URL        string      `json:"url"`
+	EventTypes []EventType `json:"event_types"`
+	Secret     string      `json:"secret"`
+	IsActive   *bool       `json:"is_active"`
+}
+
+// WebhookDelivery structure (one event sent to one subscription, retried until it succeeds or is dead-lettered)
+type WebhookDelivery struct {
+	ID             string    `json:"id"`
+	SubscriptionID string    `json:"subscription_id"`
+	EventID        string    `json:"event_id"`
+	EventType      EventType `json:"event_type"`
+	// The exact body that is signed and sent
+	Payload json.RawMessage       `json:"payload"`
+	Status  WebhookDeliveryStatus `json:"status"`
+	// Attempts since the delivery was created or last redelivered
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	LastStatusCode *int       `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	// Delivery log, oldest first; only filled when a single delivery is read
+	AttemptLog []WebhookAttempt `json:"attempt_log,omitempty"`
+}
+
+// One HTTP attempt of a webhook delivery
+type WebhookAttempt struct {
+	Attempt int `json:"attempt"`
+	// Response status; nil when no response was received
+	StatusCode  *int      `json:"status_code,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	DurationMS  int64     `json:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+// Structure for queueing an event for one subscription
+type CreateWebhookDeliveryInput struct {
+	SubscriptionID string
+	EventID        string
+	EventType      EventType
+	Payload        json.RawMessage
+}
+
+// Structure for recording an attempt and the delivery state it leads to
+type RecordWebhookAttemptInput struct {
+	StatusCode *int
+	Error      string
+	Duration   time.Duration
+	Status     WebhookDeliveryStatus
+	// When to retry; only for PENDING
+	NextAttemptAt *time.Time
+}
+
+// Body sent to webhook endpoints
+type WebhookEnvelope struct {
+	ID            string          `json:"id"`
+	Type          EventType       `json:"type"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	CorrelationID string          `json:"correlation_id,omitempty"`
+	Replay        bool            `json:"replay,omitempty"`
+	Data          json.RawMessage `json:"data"`
+}
@@ -0,0 +1,62 @@
+package event
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tucredito/backend-api/internal/audit"
+	"github.com/tucredito/backend-api/internal/domain"
+)
+
+/*
+	Correlation of domain events
+	- CorrelationID is shared by everything one request set off: the request ID, or for work started by
+	  a consumed event, that event's correlation ID
+	- CausationID is the request or event that directly produced the event
+	Events raised outside a request and without CausedBy start their own chain (correlation = own ID)
+*/
+
+type correlationKey struct{}
+
+type correlation struct {
+	id      string
+	causeID string
+}
+
+// Returns a context whose events continue evt's chain, with evt as their cause (for event consumers)
+func CausedBy(ctx context.Context, evt *domain.DomainEvent) context.Context {
+	id := evt.CorrelationID
+	if id == "" {
+		id = evt.ID
+	}
+	return context.WithValue(ctx, correlationKey{}, correlation{id: id, causeID: evt.ID})
+}
+
+// Returns the correlation and causation IDs for an event raised under ctx
+func CorrelationFrom(ctx context.Context) (correlationID, causationID string) {
+	if c, ok := ctx.Value(correlationKey{}).(correlation); ok {
+		return c.id, c.causeID
+	}
+	requestID := audit.RequestID(ctx)
+	return requestID, requestID
+}
+
+// Builds an event of type t with a new ID, the marshalled payload and the correlation from ctx
+func New(ctx context.Context, t domain.EventType, payload interface{}) (*domain.DomainEvent, error) {
+	data, err := domain.MarshalPayload(payload)
+	if err != nil {
+		return nil, err
+	}
+	evt := &domain.DomainEvent{
+		ID:         uuid.New().String(),
+		Type:       t,
+		Payload:    data,
+		OccurredAt: time.Now().UTC(),
+	}
+	evt.CorrelationID, evt.CausationID = CorrelationFrom(ctx)
+	if evt.CorrelationID == "" {
+		evt.CorrelationID = evt.ID
+	}
+	return evt, nil
+}
@@ -0,0 +1,34 @@
+package event
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/audit"
+	"github.com/tucredito/backend-api/internal/domain"
+)
+
+func TestNew_Correlation(t *testing.T) {
+	// Raised by a request: correlated with and caused by the request
+	reqCtx := audit.WithRequestID(context.Background(), "req-1")
+	created, err := New(reqCtx, domain.EventCreditCreated, domain.CreditCreatedPayload{CreditID: "cr1"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, "req-1", created.CorrelationID)
+	assert.Equal(t, "req-1", created.CausationID)
+	assert.JSONEq(t, `{"credit_id":"cr1","client_id":"","bank_id":"","credit_type":"","status":"","created_at":"0001-01-01T00:00:00Z"}`, string(created.Payload))
+
+	// Raised while handling that event: same correlation, caused by the event
+	approved, err := New(CausedBy(context.Background(), created), domain.EventCreditApproved, domain.CreditApprovedPayload{})
+	require.NoError(t, err)
+	assert.Equal(t, "req-1", approved.CorrelationID)
+	assert.Equal(t, created.ID, approved.CausationID)
+
+	// Raised outside any request: starts its own chain
+	background, err := New(context.Background(), domain.EventCreditRejected, domain.CreditRejectedPayload{})
+	require.NoError(t, err)
+	assert.Equal(t, background.ID, background.CorrelationID)
+	assert.Empty(t, background.CausationID)
+}
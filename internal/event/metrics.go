@@ -0,0 +1,28 @@
+package event
+
+import (
+	"context"
+
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/metrics"
+)
+
+// Wraps a Publisher to count publish successes and failures per event type
+type instrumentedPublisher struct {
+	Publisher
+}
+
+// Returns p with publish outcomes exported as events_published_total
+func WithMetrics(p Publisher) Publisher {
+	return instrumentedPublisher{Publisher: p}
+}
+
+// Publishes the event and counts the outcome
+func (p instrumentedPublisher) Publish(ctx context.Context, event *domain.DomainEvent) error {
+	err := p.Publisher.Publish(ctx, event)
+	metrics.IncEventPublished(string(event.Type), err)
+	return err
+}
+
+// Forwards to the wrapped publisher's Ping
+func (p instrumentedPublisher) Ping(ctx context.Context) error { return Ping(ctx, p.Publisher) }
@@ -41,3 +41,6 @@ func (p *MockPublisher) Events() []*domain.DomainEvent {
 	copy(out, p.events)
 	return out
 }
+
+// Reports the in-memory broker as reachable
+func (p *MockPublisher) Ping(ctx context.Context) error { return nil }
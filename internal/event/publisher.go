@@ -11,3 +11,29 @@ type Publisher interface {
 	Publish(ctx context.Context, event *domain.DomainEvent) error
 	Close() error
 }
+
+/*
+	Outbox stores events inside the caller's unit of work (repository.TxManager), so they are kept exactly
+	when the change that produced them commits and survive a crash before publishing; an error rolls the
+	change back. The webhook service is one: it stores a delivery per matching subscription
+*/
+
+type Outbox interface {
+	Enqueue(ctx context.Context, event *domain.DomainEvent) error
+}
+
+// Optional Publisher capability: checks the broker is reachable (readiness checks)
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Pings p when it supports it; publishers without a connection are always reachable
+func Ping(ctx context.Context, p Publisher) error {
+	if pinger, ok := p.(Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// Header set on events re-published from the audit trail, so consumers can tell replays from live events
+const HeaderReplay = "replay"
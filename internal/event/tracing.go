@@ -0,0 +1,42 @@
+package event
+
+import (
+	"context"
+
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/tracing"
+)
+
+// Wraps a Publisher to trace each publish and propagate the trace context in the event headers
+type tracedPublisher struct {
+	Publisher
+}
+
+// Returns p with a producer span per publish and traceparent injected into DomainEvent.Headers
+func WithTracing(p Publisher) Publisher {
+	return tracedPublisher{Publisher: p}
+}
+
+/*
+	Publishes the event inside a producer span
+	The injected traceparent names the producer span, so consumers continue the trace from the publish
+*/
+
+func (p tracedPublisher) Publish(ctx context.Context, event *domain.DomainEvent) error {
+	ctx, span := tracing.Start(ctx, "event.publish "+string(event.Type), tracing.WithKind(tracing.KindProducer), tracing.WithAttributes(
+		tracing.String("messaging.message.id", event.ID),
+	))
+	defer span.End()
+
+	if event.Headers == nil {
+		event.Headers = make(map[string]string)
+	}
+	tracing.Inject(ctx, tracing.MapCarrier(event.Headers))
+
+	err := p.Publisher.Publish(ctx, event)
+	span.RecordError(err)
+	return err
+}
+
+// Forwards to the wrapped publisher's Ping
+func (p tracedPublisher) Ping(ctx context.Context) error { return Ping(ctx, p.Publisher) }
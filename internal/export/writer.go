@@ -0,0 +1,103 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/tucredito/backend-api/internal/domain"
+)
+
+/*
+	Row encoders for credit portfolio exports
+	Column names and order are fixed so downstream loaders (spreadsheets, Parquet converters) get a stable typed schema:
+	amounts with two decimals, timestamps in RFC 3339 UTC
+*/
+
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+// Columns written by the CSV encoder (same names as the NDJSON keys)
+var Columns = []string{
+	"credit_id", "client_id", "bank_id", "min_payment", "max_payment", "term_months", "credit_type", "status", "created_at",
+	"client_full_name", "client_email", "client_country", "bank_name", "bank_type",
+}
+
+// Encodes export rows one at a time
+type Writer interface {
+	Write(row *domain.CreditExportRow) error
+	Flush() error
+}
+
+// Creates a writer for the given format
+func NewWriter(format domain.ExportFormat, w io.Writer) (Writer, error) {
+	switch format {
+	case domain.ExportFormatCSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case domain.ExportFormatNDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
+
+// Content type served for each format
+func ContentType(format domain.ExportFormat) string {
+	if format == domain.ExportFormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/x-ndjson"
+}
+
+type csvWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (c *csvWriter) Write(row *domain.CreditExportRow) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	return c.w.Write([]string{
+		row.CreditID, row.ClientID, row.BankID,
+		strconv.FormatFloat(row.MinPayment, 'f', 2, 64),
+		strconv.FormatFloat(row.MaxPayment, 'f', 2, 64),
+		strconv.Itoa(row.TermMonths),
+		string(row.CreditType), string(row.Status),
+		row.CreatedAt.UTC().Format(time.RFC3339),
+		row.ClientFullName, row.ClientEmail, row.ClientCountry,
+		row.BankName, string(row.BankType),
+	})
+}
+
+// Writes the header even when the export is empty
+func (c *csvWriter) Flush() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) writeHeader() error {
+	if c.headerWritten {
+		return nil
+	}
+	c.headerWritten = true
+	return c.w.Write(Columns)
+}
+
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonWriter) Write(row *domain.CreditExportRow) error {
+	out := *row
+	out.CreatedAt = row.CreatedAt.UTC()
+	return n.enc.Encode(&out)
+}
+
+func (n *ndjsonWriter) Flush() error { return nil }
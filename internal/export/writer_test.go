@@ -0,0 +1,68 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/domain"
+)
+
+func sampleRow() *domain.CreditExportRow {
+	return &domain.CreditExportRow{
+		CreditID: "cr1", ClientID: "c1", BankID: "b1",
+		MinPayment: 100, MaxPayment: 500.5, TermMonths: 12,
+		CreditType: domain.CreditTypeAuto, Status: domain.CreditStatusApproved,
+		CreatedAt:      time.Date(2026, 9, 30, 18, 0, 0, 0, time.FixedZone("CST", -6*3600)),
+		ClientFullName: "Doe, Jane", ClientEmail: "jane@example.com", ClientCountry: "MX",
+		BankName: "Bank", BankType: domain.BankTypePrivate,
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(domain.ExportFormatCSV, &buf)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(sampleRow()))
+	require.NoError(t, w.Flush())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, strings.Join(Columns, ","), lines[0])
+	assert.Equal(t, `cr1,c1,b1,100.00,500.50,12,AUTO,APPROVED,2026-10-01T00:00:00Z,"Doe, Jane",jane@example.com,MX,Bank,PRIVATE`, lines[1])
+}
+
+func TestCSVWriter_EmptyExportHasHeader(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(domain.ExportFormatCSV, &buf)
+	require.NoError(t, err)
+	require.NoError(t, w.Flush())
+	assert.Equal(t, strings.Join(Columns, ",")+"\n", buf.String())
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(domain.ExportFormatNDJSON, &buf)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(sampleRow()))
+	require.NoError(t, w.Write(sampleRow()))
+	require.NoError(t, w.Flush())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &got))
+	assert.Equal(t, "cr1", got["credit_id"])
+	assert.Equal(t, "2026-10-01T00:00:00Z", got["created_at"])
+	assert.Len(t, got, len(Columns))
+}
+
+func TestNewWriter_UnsupportedFormat(t *testing.T) {
+	_, err := NewWriter("PARQUET", &bytes.Buffer{})
+	assert.True(t, errors.Is(err, ErrUnsupportedFormat))
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/service"
+	"github.com/tucredito/backend-api/pkg/apperr"
+	"github.com/tucredito/backend-api/pkg/httputil"
+	"go.uber.org/zap"
+)
+
+type AuditHandler struct {
+	service service.AuditService
+	log     *zap.Logger
+}
+
+func NewAuditHandler(service service.AuditService, log *zap.Logger) *AuditHandler {
+	return &AuditHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// Lists the change history of an entity (GET /{clients|banks|credits}/{id}/history)
+func (h *AuditHandler) History(entity domain.AuditEntity) http.HandlerFunc {
+	name := strings.ToLower(string(entity))
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			httputil.Error(w, http.StatusMethodNotAllowed, "method not allowed", "METHOD_NOT_ALLOWED", "")
+			return
+		}
+		id := r.PathValue("id")
+		if id == "" {
+			httputil.WriteError(w, r, apperr.Validation("id required"))
+			return
+		}
+
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		if limit <= 0 {
+			limit = 20
+		}
+
+		entries, err := h.service.History(r.Context(), entity, id, limit, offset)
+		if err != nil {
+			writeError(w, r, h.log, "get "+name+" history", err, zap.String("id", id))
+			return
+		}
+
+		httputil.JSON(w, http.StatusOK, entries)
+	}
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/domain"
+	handlermocks "github.com/tucredito/backend-api/internal/handler/mocks"
+	"go.uber.org/zap"
+)
+
+func TestAuditHandler_History(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	mockSvc := &handlermocks.MockAuditService{}
+	mockSvc.HistoryFunc = func(_ context.Context, entity domain.AuditEntity, entityID string, limit, offset int) ([]*domain.AuditEntry, error) {
+		assert.Equal(t, domain.AuditEntityCredit, entity)
+		assert.Equal(t, 5, limit)
+		assert.Equal(t, 10, offset)
+		return []*domain.AuditEntry{
+			{ID: 7, Entity: entity, EntityID: entityID, Action: domain.AuditActionUpdateStatus, Actor: "system", Changes: json.RawMessage(`{"status":{"before":"PENDING","after":"APPROVED"}}`)},
+		}, nil
+	}
+	h := NewAuditHandler(mockSvc, log)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+apiVersion+"/credits/{id}/history", h.History(domain.AuditEntityCredit))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/credits/cr1/history?limit=5&offset=10", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var got []domain.AuditEntry
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	require.Len(t, got, 1)
+	assert.Equal(t, "cr1", got[0].EntityID)
+	assert.JSONEq(t, `{"status":{"before":"PENDING","after":"APPROVED"}}`, string(got[0].Changes))
+}
+
+func TestAuditHandler_History_Error(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	mockSvc := &handlermocks.MockAuditService{}
+	mockSvc.HistoryFunc = func(_ context.Context, _ domain.AuditEntity, _ string, _, _ int) ([]*domain.AuditEntry, error) {
+		return nil, errors.New("db down")
+	}
+	h := NewAuditHandler(mockSvc, log)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+apiVersion+"/banks/{id}/history", h.History(domain.AuditEntityBank))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/banks/b1/history", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
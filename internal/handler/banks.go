@@ -1,12 +1,12 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
 	"strconv"
 
 	"github.com/tucredito/backend-api/internal/domain"
 	"github.com/tucredito/backend-api/internal/service"
+	"github.com/tucredito/backend-api/pkg/apperr"
 	"github.com/tucredito/backend-api/pkg/httputil"
 	"go.uber.org/zap"
 )
@@ -31,24 +31,18 @@ func (h *BankHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var input domain.CreateBankInput
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		httputil.Error(w, http.StatusBadRequest, "invalid JSON", "INVALID_JSON", err.Error())
-		return
-	}
-
-	if input.Name == "" || (input.Type != domain.BankTypePrivate && input.Type != domain.BankTypeGovernment) {
-		httputil.Error(w, http.StatusBadRequest, "name and type (PRIVATE|GOVERNMENT) required", "VALIDATION", "")
+	if err := decodeInput(w, r, &input); err != nil {
+		httputil.WriteError(w, r, err)
 		return
 	}
 
 	bank, err := h.service.Create(r.Context(), input)
 	if err != nil {
-		h.log.Error("create bank", zap.Error(err))
-		httputil.Error(w, http.StatusInternalServerError, "failed to create bank", "INTERNAL", err.Error())
+		writeError(w, r, h.log, "create bank", err)
 		return
 	}
 
-	httputil.JSON(w, http.StatusCreated, bank)
+	httputil.JSONWithETag(w, r, http.StatusCreated, bank.Version, bank)
 }
 
 // Updates a bank (PUT /banks/{id}).
@@ -59,29 +53,52 @@ func (h *BankHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 	id := r.PathValue("id")
 	if id == "" {
-		httputil.Error(w, http.StatusBadRequest, "id required", "VALIDATION", "")
+		httputil.WriteError(w, r, apperr.Validation("id required"))
 		return
 	}
 	var input domain.UpdateBankInput
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		httputil.Error(w, http.StatusBadRequest, "invalid JSON", "INVALID_JSON", err.Error())
+	if err := decodeInput(w, r, &input); err != nil {
+		httputil.WriteError(w, r, err)
+		return
+	}
+	bank, err := h.service.Update(conditional(r), id, input)
+	if err != nil {
+		writeError(w, r, h.log, "update bank", err, zap.String("id", id))
+		return
+	}
+	if bank == nil {
+		httputil.WriteError(w, r, apperr.NotFound("bank not found"))
+		return
+	}
+	httputil.JSONWithETag(w, r, http.StatusOK, bank.Version, bank)
+}
+
+// Applies a JSON Merge Patch to a bank (PATCH /banks/{id}); omitted fields keep their values.
+func (h *BankHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		httputil.Error(w, http.StatusMethodNotAllowed, "method not allowed", "METHOD_NOT_ALLOWED", "")
+		return
+	}
+	id := r.PathValue("id")
+	if id == "" {
+		httputil.WriteError(w, r, apperr.Validation("id required"))
 		return
 	}
-	if input.Name == "" || (input.Type != domain.BankTypePrivate && input.Type != domain.BankTypeGovernment) {
-		httputil.Error(w, http.StatusBadRequest, "name and type (PRIVATE|GOVERNMENT) required", "VALIDATION", "")
+	var patch domain.BankPatch
+	if err := httputil.DecodeMergePatch(w, r, &patch, httputil.MaxJSONBodyBytes); err != nil {
+		httputil.WriteError(w, r, err)
 		return
 	}
-	bank, err := h.service.Update(r.Context(), id, input)
+	bank, err := h.service.Patch(conditional(r), id, patch)
 	if err != nil {
-		h.log.Error("update bank", zap.Error(err), zap.String("id", id))
-		httputil.Error(w, http.StatusInternalServerError, "failed to update bank", "INTERNAL", err.Error())
+		writeError(w, r, h.log, "patch bank", err, zap.String("id", id))
 		return
 	}
 	if bank == nil {
-		httputil.Error(w, http.StatusNotFound, "bank not found", "NOT_FOUND", "")
+		httputil.WriteError(w, r, apperr.NotFound("bank not found"))
 		return
 	}
-	httputil.JSON(w, http.StatusOK, bank)
+	httputil.JSONWithETag(w, r, http.StatusOK, bank.Version, bank)
 }
 
 // Soft-deletes a bank (DELETE /banks/{id}).
@@ -92,20 +109,19 @@ func (h *BankHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 	id := r.PathValue("id")
 	if id == "" {
-		httputil.Error(w, http.StatusBadRequest, "id required", "VALIDATION", "")
+		httputil.WriteError(w, r, apperr.Validation("id required"))
 		return
 	}
-	bank, err := h.service.Delete(r.Context(), id)
+	bank, err := h.service.Delete(conditional(r), id)
 	if err != nil {
-		h.log.Error("delete bank", zap.Error(err), zap.String("id", id))
-		httputil.Error(w, http.StatusInternalServerError, "failed to delete bank", "INTERNAL", err.Error())
+		writeError(w, r, h.log, "delete bank", err, zap.String("id", id))
 		return
 	}
 	if bank == nil {
-		httputil.Error(w, http.StatusNotFound, "bank not found", "NOT_FOUND", "")
+		httputil.WriteError(w, r, apperr.NotFound("bank not found"))
 		return
 	}
-	httputil.JSON(w, http.StatusOK, bank)
+	httputil.JSONWithETag(w, r, http.StatusOK, bank.Version, bank)
 }
 
 // Re-enables a bank (POST /banks/{id}/reenable).
@@ -116,20 +132,19 @@ func (h *BankHandler) Reenable(w http.ResponseWriter, r *http.Request) {
 	}
 	id := r.PathValue("id")
 	if id == "" {
-		httputil.Error(w, http.StatusBadRequest, "id required", "VALIDATION", "")
+		httputil.WriteError(w, r, apperr.Validation("id required"))
 		return
 	}
-	bank, err := h.service.Reenable(r.Context(), id)
+	bank, err := h.service.Reenable(conditional(r), id)
 	if err != nil {
-		h.log.Error("reenable bank", zap.Error(err), zap.String("id", id))
-		httputil.Error(w, http.StatusInternalServerError, "failed to reenable bank", "INTERNAL", err.Error())
+		writeError(w, r, h.log, "reenable bank", err, zap.String("id", id))
 		return
 	}
 	if bank == nil {
-		httputil.Error(w, http.StatusNotFound, "bank not found", "NOT_FOUND", "")
+		httputil.WriteError(w, r, apperr.NotFound("bank not found"))
 		return
 	}
-	httputil.JSON(w, http.StatusOK, bank)
+	httputil.JSONWithETag(w, r, http.StatusOK, bank.Version, bank)
 }
 
 // Gets a bank by ID (GET /banks/{id}).
@@ -141,23 +156,22 @@ func (h *BankHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 
 	id := r.PathValue("id")
 	if id == "" {
-		httputil.Error(w, http.StatusBadRequest, "id required", "VALIDATION", "")
+		httputil.WriteError(w, r, apperr.Validation("id required"))
 		return
 	}
 
 	bank, err := h.service.GetByID(r.Context(), id)
 	if err != nil {
-		h.log.Error("get bank", zap.Error(err), zap.String("id", id))
-		httputil.Error(w, http.StatusInternalServerError, "failed to get bank", "INTERNAL", err.Error())
+		writeError(w, r, h.log, "get bank", err, zap.String("id", id))
 		return
 	}
 
 	if bank == nil {
-		httputil.Error(w, http.StatusNotFound, "bank not found", "NOT_FOUND", "")
+		httputil.WriteError(w, r, apperr.NotFound("bank not found"))
 		return
 	}
 
-	httputil.JSON(w, http.StatusOK, bank)
+	httputil.JSONWithETag(w, r, http.StatusOK, bank.Version, bank)
 }
 
 // Lists banks with pagination (GET /banks).
@@ -175,8 +189,7 @@ func (h *BankHandler) List(w http.ResponseWriter, r *http.Request) {
 
 	list, err := h.service.List(r.Context(), limit, offset)
 	if err != nil {
-		h.log.Error("list banks", zap.Error(err))
-		httputil.Error(w, http.StatusInternalServerError, "failed to list banks", "INTERNAL", err.Error())
+		writeError(w, r, h.log, "list banks", err)
 		return
 	}
 
@@ -1,12 +1,12 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
 	"strconv"
 
 	"github.com/tucredito/backend-api/internal/domain"
 	"github.com/tucredito/backend-api/internal/service"
+	"github.com/tucredito/backend-api/pkg/apperr"
 	"github.com/tucredito/backend-api/pkg/httputil"
 	"go.uber.org/zap"
 )
@@ -31,24 +31,18 @@ func (h *ClientHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var input domain.CreateClientInput
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		httputil.Error(w, http.StatusBadRequest, "invalid JSON", "INVALID_JSON", err.Error())
-		return
-	}
-
-	if input.FullName == "" || input.Email == "" || input.Country == "" {
-		httputil.Error(w, http.StatusBadRequest, "full_name, email, country required", "VALIDATION", "")
+	if err := decodeInput(w, r, &input); err != nil {
+		httputil.WriteError(w, r, err)
 		return
 	}
 
 	client, err := h.service.Create(r.Context(), input)
 	if err != nil {
-		h.log.Error("create client", zap.Error(err))
-		httputil.Error(w, http.StatusInternalServerError, "failed to create client", "INTERNAL", err.Error())
+		writeError(w, r, h.log, "create client", err)
 		return
 	}
 
-	httputil.JSON(w, http.StatusCreated, client)
+	httputil.JSONWithETag(w, r, http.StatusCreated, client.Version, client)
 }
 
 // Updates a client (PUT /clients/{id}).
@@ -59,29 +53,52 @@ func (h *ClientHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 	id := r.PathValue("id")
 	if id == "" {
-		httputil.Error(w, http.StatusBadRequest, "id required", "VALIDATION", "")
+		httputil.WriteError(w, r, apperr.Validation("id required"))
 		return
 	}
 	var input domain.UpdateClientInput
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		httputil.Error(w, http.StatusBadRequest, "invalid JSON", "INVALID_JSON", err.Error())
+	if err := decodeInput(w, r, &input); err != nil {
+		httputil.WriteError(w, r, err)
+		return
+	}
+	client, err := h.service.Update(conditional(r), id, input)
+	if err != nil {
+		writeError(w, r, h.log, "update client", err, zap.String("id", id))
+		return
+	}
+	if client == nil {
+		httputil.WriteError(w, r, apperr.NotFound("client not found"))
+		return
+	}
+	httputil.JSONWithETag(w, r, http.StatusOK, client.Version, client)
+}
+
+// Applies a JSON Merge Patch to a client (PATCH /clients/{id}); omitted fields keep their values.
+func (h *ClientHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		httputil.Error(w, http.StatusMethodNotAllowed, "method not allowed", "METHOD_NOT_ALLOWED", "")
+		return
+	}
+	id := r.PathValue("id")
+	if id == "" {
+		httputil.WriteError(w, r, apperr.Validation("id required"))
 		return
 	}
-	if input.FullName == "" || input.Email == "" || input.Country == "" {
-		httputil.Error(w, http.StatusBadRequest, "full_name, email, country required", "VALIDATION", "")
+	var patch domain.ClientPatch
+	if err := httputil.DecodeMergePatch(w, r, &patch, httputil.MaxJSONBodyBytes); err != nil {
+		httputil.WriteError(w, r, err)
 		return
 	}
-	client, err := h.service.Update(r.Context(), id, input)
+	client, err := h.service.Patch(conditional(r), id, patch)
 	if err != nil {
-		h.log.Error("update client", zap.Error(err), zap.String("id", id))
-		httputil.Error(w, http.StatusInternalServerError, "failed to update client", "INTERNAL", err.Error())
+		writeError(w, r, h.log, "patch client", err, zap.String("id", id))
 		return
 	}
 	if client == nil {
-		httputil.Error(w, http.StatusNotFound, "client not found", "NOT_FOUND", "")
+		httputil.WriteError(w, r, apperr.NotFound("client not found"))
 		return
 	}
-	httputil.JSON(w, http.StatusOK, client)
+	httputil.JSONWithETag(w, r, http.StatusOK, client.Version, client)
 }
 
 // Soft-deletes a client (DELETE /clients/{id}).
@@ -92,20 +109,19 @@ func (h *ClientHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 	id := r.PathValue("id")
 	if id == "" {
-		httputil.Error(w, http.StatusBadRequest, "id required", "VALIDATION", "")
+		httputil.WriteError(w, r, apperr.Validation("id required"))
 		return
 	}
-	client, err := h.service.Delete(r.Context(), id)
+	client, err := h.service.Delete(conditional(r), id)
 	if err != nil {
-		h.log.Error("delete client", zap.Error(err), zap.String("id", id))
-		httputil.Error(w, http.StatusInternalServerError, "failed to delete client", "INTERNAL", err.Error())
+		writeError(w, r, h.log, "delete client", err, zap.String("id", id))
 		return
 	}
 	if client == nil {
-		httputil.Error(w, http.StatusNotFound, "client not found", "NOT_FOUND", "")
+		httputil.WriteError(w, r, apperr.NotFound("client not found"))
 		return
 	}
-	httputil.JSON(w, http.StatusOK, client)
+	httputil.JSONWithETag(w, r, http.StatusOK, client.Version, client)
 }
 
 // Re-enables a client (POST /clients/{id}/reenable).
@@ -116,20 +132,19 @@ func (h *ClientHandler) Reenable(w http.ResponseWriter, r *http.Request) {
 	}
 	id := r.PathValue("id")
 	if id == "" {
-		httputil.Error(w, http.StatusBadRequest, "id required", "VALIDATION", "")
+		httputil.WriteError(w, r, apperr.Validation("id required"))
 		return
 	}
-	client, err := h.service.Reenable(r.Context(), id)
+	client, err := h.service.Reenable(conditional(r), id)
 	if err != nil {
-		h.log.Error("reenable client", zap.Error(err), zap.String("id", id))
-		httputil.Error(w, http.StatusInternalServerError, "failed to reenable client", "INTERNAL", err.Error())
+		writeError(w, r, h.log, "reenable client", err, zap.String("id", id))
 		return
 	}
 	if client == nil {
-		httputil.Error(w, http.StatusNotFound, "client not found", "NOT_FOUND", "")
+		httputil.WriteError(w, r, apperr.NotFound("client not found"))
 		return
 	}
-	httputil.JSON(w, http.StatusOK, client)
+	httputil.JSONWithETag(w, r, http.StatusOK, client.Version, client)
 }
 
 // Gets a client by ID (GET /clients/{id}).
@@ -141,23 +156,22 @@ func (h *ClientHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 
 	id := r.PathValue("id")
 	if id == "" {
-		httputil.Error(w, http.StatusBadRequest, "id required", "VALIDATION", "")
+		httputil.WriteError(w, r, apperr.Validation("id required"))
 		return
 	}
 
 	client, err := h.service.GetByID(r.Context(), id)
 	if err != nil {
-		h.log.Error("get client", zap.Error(err), zap.String("id", id))
-		httputil.Error(w, http.StatusInternalServerError, "failed to get client", "INTERNAL", err.Error())
+		writeError(w, r, h.log, "get client", err, zap.String("id", id))
 		return
 	}
 
 	if client == nil {
-		httputil.Error(w, http.StatusNotFound, "client not found", "NOT_FOUND", "")
+		httputil.WriteError(w, r, apperr.NotFound("client not found"))
 		return
 	}
 
-	httputil.JSON(w, http.StatusOK, client)
+	httputil.JSONWithETag(w, r, http.StatusOK, client.Version, client)
 }
 
 // Lists clients with pagination (GET /clients).
@@ -175,8 +189,7 @@ func (h *ClientHandler) List(w http.ResponseWriter, r *http.Request) {
 
 	list, err := h.service.List(r.Context(), limit, offset)
 	if err != nil {
-		h.log.Error("list clients", zap.Error(err))
-		httputil.Error(w, http.StatusInternalServerError, "failed to list clients", "INTERNAL", err.Error())
+		writeError(w, r, h.log, "list clients", err)
 		return
 	}
 
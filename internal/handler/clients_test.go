@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,6 +15,11 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/tucredito/backend-api/internal/domain"
 	handlermocks "github.com/tucredito/backend-api/internal/handler/mocks"
+	"github.com/tucredito/backend-api/internal/repository"
+	"github.com/tucredito/backend-api/internal/repository/memory"
+	"github.com/tucredito/backend-api/internal/service"
+	"github.com/tucredito/backend-api/pkg/apperr"
+	"github.com/tucredito/backend-api/pkg/httputil"
 	"go.uber.org/zap"
 )
 
@@ -36,7 +43,7 @@ func TestClientHandler_Create(t *testing.T) {
 	mux := http.NewServeMux()
 	mux.HandleFunc("POST "+apiVersion+"/clients", h.Create)
 
-	body := []byte(`{"full_name":"Jane Doe","email":"jane@example.com","country":"US"}`)
+	body := []byte(`{"full_name":"Jane Doe","email":"jane@example.com","birth_date":"1990-05-15T00:00:00Z","country":"US"}`)
 	req := httptest.NewRequest(http.MethodPost, "/v1/clients", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	rec := httptest.NewRecorder()
@@ -57,7 +64,7 @@ func TestClientHandler_Create_ValidationError(t *testing.T) {
 	mux := http.NewServeMux()
 	mux.HandleFunc("POST "+apiVersion+"/clients", h.Create)
 
-	body := []byte(`{"full_name":"","email":"jane@example.com","country":"US"}`)
+	body := []byte(`{"full_name":"","email":"jane@example.com","birth_date":"1990-05-15T00:00:00Z","country":"US"}`)
 	req := httptest.NewRequest(http.MethodPost, "/v1/clients", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	rec := httptest.NewRecorder()
@@ -127,6 +134,39 @@ func TestClientHandler_GetByID_NotFound(t *testing.T) {
 	require.Equal(t, http.StatusNotFound, rec.Code)
 }
 
+func TestClientHandler_GetByID_ETag(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	mockSvc := &handlermocks.MockClientService{}
+	mockSvc.GetByIDFunc = func(_ context.Context, _ string) (*domain.Client, error) {
+		return &domain.Client{ID: "c1", FullName: "Jane", IsActive: true, Version: 3}, nil
+	}
+	h := NewClientHandler(mockSvc, log)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+apiVersion+"/clients/{id}", h.GetByID)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/clients/c1", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `"3"`, rec.Header().Get("ETag"))
+
+	// A matching If-None-Match (weak comparison) gets 304 without a body
+	req = httptest.NewRequest(http.MethodGet, "/v1/clients/c1", nil)
+	req.Header.Set("If-None-Match", `"2", W/"3"`)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Equal(t, `"3"`, rec.Header().Get("ETag"))
+	assert.Empty(t, rec.Body.String())
+
+	// A stale one gets the representation
+	req = httptest.NewRequest(http.MethodGet, "/v1/clients/c1", nil)
+	req.Header.Set("If-None-Match", `"2"`)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
 func TestClientHandler_List(t *testing.T) {
 	log, _ := zap.NewDevelopment()
 	list := []*domain.Client{
@@ -164,7 +204,7 @@ func TestClientHandler_Update(t *testing.T) {
 	mux := http.NewServeMux()
 	mux.HandleFunc("PUT "+apiVersion+"/clients/{id}", h.Update)
 
-	body := []byte(`{"full_name":"Jane Updated","email":"j2@x.com","country":"US"}`)
+	body := []byte(`{"full_name":"Jane Updated","email":"j2@x.com","birth_date":"1990-05-15T00:00:00Z","country":"US"}`)
 	req := httptest.NewRequest(http.MethodPut, "/v1/clients/c1", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	rec := httptest.NewRecorder()
@@ -176,6 +216,140 @@ func TestClientHandler_Update(t *testing.T) {
 	assert.Equal(t, "Jane Updated", got.FullName)
 }
 
+func TestClientHandler_Update_IfMatch(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	mockSvc := &handlermocks.MockClientService{}
+	var expected []int
+	mockSvc.UpdateFunc = func(ctx context.Context, id string, _ domain.UpdateClientInput) (*domain.Client, error) {
+		var ok bool
+		expected, ok = repository.ExpectedVersions(ctx)
+		require.True(t, ok)
+		if err := repository.CheckVersion(ctx, 4); err != nil {
+			return nil, err
+		}
+		return &domain.Client{ID: id, IsActive: true, Version: 5}, nil
+	}
+	h := NewClientHandler(mockSvc, log)
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT "+apiVersion+"/clients/{id}", h.Update)
+	body := `{"full_name":"Jane","email":"j@x.com","birth_date":"1990-05-15T00:00:00Z","country":"US"}`
+
+	req := httptest.NewRequest(http.MethodPut, "/v1/clients/c1", strings.NewReader(body))
+	req.Header.Set("If-Match", `"4"`)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []int{4}, expected)
+	assert.Equal(t, `"5"`, rec.Header().Get("ETag"))
+
+	// Stale versions and weak tags never match
+	for _, tag := range []string{`"3"`, `W/"4"`} {
+		req = httptest.NewRequest(http.MethodPut, "/v1/clients/c1", strings.NewReader(body))
+		req.Header.Set("If-Match", tag)
+		rec = httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusPreconditionFailed, rec.Code, tag)
+		var problem httputil.Problem
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&problem))
+		assert.Equal(t, "PRECONDITION_FAILED", problem.Code)
+	}
+}
+
+func TestClientHandler_Patch(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	mockSvc := &handlermocks.MockClientService{}
+	var got domain.ClientPatch
+	mockSvc.PatchFunc = func(_ context.Context, id string, patch domain.ClientPatch) (*domain.Client, error) {
+		got = patch
+		return &domain.Client{ID: id, FullName: "Jane", Country: *patch.Country, IsActive: true, Version: 2}, nil
+	}
+	h := NewClientHandler(mockSvc, log)
+	mux := http.NewServeMux()
+	mux.HandleFunc("PATCH "+apiVersion+"/clients/{id}", h.Patch)
+
+	req := httptest.NewRequest(http.MethodPatch, "/v1/clients/c1", strings.NewReader(`{"country":"MX"}`))
+	req.Header.Set("Content-Type", httputil.MergePatchContentType)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `"2"`, rec.Header().Get("ETag"))
+	require.NotNil(t, got.Country)
+	assert.Equal(t, "MX", *got.Country)
+	assert.Nil(t, got.FullName)
+	assert.Nil(t, got.BirthDate, "omitted fields are left alone")
+
+	cases := map[string]string{
+		`{"birth_date":null}`: "birth_date",
+		`{"nickname":"J"}`:    "nickname",
+		`{"country":7}`:       "country",
+	}
+	for body, field := range cases {
+		req := httptest.NewRequest(http.MethodPatch, "/v1/clients/c1", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusBadRequest, rec.Code, body)
+		var problem httputil.Problem
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&problem))
+		require.Len(t, problem.Errors, 1, body)
+		assert.Equal(t, field, problem.Errors[0].Field, body)
+	}
+
+	req = httptest.NewRequest(http.MethodPatch, "/v1/clients/c1", strings.NewReader(`["country"]`))
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestClientHandler_PatchAndPut_InactiveClient(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	ctx := context.Background()
+	repo := memory.NewClientRepository(memory.NewStore())
+	created, err := repo.Create(ctx, domain.CreateClientInput{
+		FullName: "Jane", Email: "j@x.com", BirthDate: time.Date(1990, 5, 15, 0, 0, 0, 0, time.UTC), Country: "US",
+	})
+	require.NoError(t, err)
+	_, err = repo.SetInactive(ctx, created.ID)
+	require.NoError(t, err)
+
+	h := NewClientHandler(service.NewClientService(repo, nil), log)
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT "+apiVersion+"/clients/{id}", h.Update)
+	mux.HandleFunc("PATCH "+apiVersion+"/clients/{id}", h.Patch)
+
+	// Both methods reach the soft-deleted row, which stays inactive
+	req := httptest.NewRequest(http.MethodPatch, "/v1/clients/"+created.ID, strings.NewReader(`{"country":"MX"}`))
+	req.Header.Set("Content-Type", httputil.MergePatchContentType)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var got domain.Client
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	assert.Equal(t, "MX", got.Country)
+	assert.False(t, got.IsActive)
+
+	body := `{"full_name":"Jane Updated","email":"j@x.com","birth_date":"1990-05-15T00:00:00Z","country":"MX"}`
+	req = httptest.NewRequest(http.MethodPut, "/v1/clients/"+created.ID, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	assert.Equal(t, "Jane Updated", got.FullName)
+	assert.False(t, got.IsActive)
+
+	// Both agree on a missing one
+	for _, method := range []string{http.MethodPatch, http.MethodPut} {
+		req = httptest.NewRequest(method, "/v1/clients/missing", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if method == http.MethodPatch {
+			req.Header.Set("Content-Type", httputil.MergePatchContentType)
+		}
+		rec = httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code, method)
+	}
+}
+
 func TestClientHandler_Update_NotFound(t *testing.T) {
 	log, _ := zap.NewDevelopment()
 	mockSvc := &handlermocks.MockClientService{}
@@ -184,7 +358,7 @@ func TestClientHandler_Update_NotFound(t *testing.T) {
 	mux := http.NewServeMux()
 	mux.HandleFunc("PUT "+apiVersion+"/clients/{id}", h.Update)
 
-	body := []byte(`{"full_name":"X","email":"x@x.com","country":"US"}`)
+	body := []byte(`{"full_name":"X","email":"x@x.com","birth_date":"1990-05-15T00:00:00Z","country":"US"}`)
 	req := httptest.NewRequest(http.MethodPut, "/v1/clients/none", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	rec := httptest.NewRecorder()
@@ -282,3 +456,99 @@ func TestClientHandler_MethodNotAllowed(t *testing.T) {
 
 	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
 }
+
+func TestClientHandler_Create_DuplicateEmailIsProblem(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	mockSvc := &handlermocks.MockClientService{}
+	mockSvc.CreateFunc = func(_ context.Context, _ domain.CreateClientInput) (*domain.Client, error) {
+		e := apperr.Conflict("email already exists", apperr.Field("email", "duplicate", "already in use"))
+		e.Err = errors.New(`ERROR: duplicate key value violates unique constraint "clients_email_key" (SQLSTATE 23505)`)
+		return nil, e
+	}
+	h := NewClientHandler(mockSvc, log)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+apiVersion+"/clients", h.Create)
+
+	body := []byte(`{"full_name":"Jane Doe","email":"jane@example.com","birth_date":"1990-05-15T00:00:00Z","country":"US"}`)
+	req := httptest.NewRequest(http.MethodPost, "/v1/clients", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	assert.NotContains(t, rec.Body.String(), "SQLSTATE")
+	var problem httputil.Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(t, "CONFLICT", problem.Code)
+	assert.Equal(t, http.StatusConflict, problem.Status)
+	assert.Equal(t, "/v1/clients", problem.Instance)
+	assert.Equal(t, []apperr.FieldError{{Field: "email", Code: "duplicate", Message: "already in use"}}, problem.Errors)
+}
+
+func TestClientHandler_GetByID_InternalErrorHidesCause(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	mockSvc := &handlermocks.MockClientService{}
+	mockSvc.GetByIDFunc = func(_ context.Context, _ string) (*domain.Client, error) {
+		return nil, errors.New("dial tcp 10.0.0.5:5432: connection refused")
+	}
+	h := NewClientHandler(mockSvc, log)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+apiVersion+"/clients/{id}", h.GetByID)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/clients/c1", nil))
+
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "10.0.0.5")
+	var problem httputil.Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(t, "INTERNAL", problem.Code)
+	assert.Equal(t, "about:blank", problem.Type)
+	assert.Equal(t, "Internal Server Error", problem.Title)
+}
+
+func TestClientHandler_Create_StrictJSON(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	mockSvc := &handlermocks.MockClientService{}
+	mockSvc.CreateFunc = func(_ context.Context, _ domain.CreateClientInput) (*domain.Client, error) {
+		t.Fatal("invalid bodies must not reach the service")
+		return nil, nil
+	}
+	h := NewClientHandler(mockSvc, log)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+apiVersion+"/clients", h.Create)
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		code   string
+		fields []apperr.FieldError
+	}{
+		{"unknown field", `{"full_name":"Jane Doe","email":"jane@example.com","birth_date":"1990-05-15T00:00:00Z","country":"US","nickname":"JD"}`,
+			http.StatusBadRequest, "VALIDATION", []apperr.FieldError{{Field: "nickname", Code: "unknown_field", Message: "is not a known field"}}},
+		{"wrong type", `{"full_name":42}`,
+			http.StatusBadRequest, "VALIDATION", []apperr.FieldError{{Field: "full_name", Code: "invalid_type", Message: "must be a string"}}},
+		{"trailing data", `{"full_name":"Jane Doe"} {}`, http.StatusBadRequest, "INVALID_JSON", nil},
+		{"empty body", ``, http.StatusBadRequest, "INVALID_JSON", nil},
+		{"oversized", `{"full_name":"` + strings.Repeat("a", httputil.MaxJSONBodyBytes) + `"}`, http.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE", nil},
+		{"all field errors at once", `{"email":"not-an-email","country":"Mexico"}`, http.StatusBadRequest, "VALIDATION", []apperr.FieldError{
+			{Field: "full_name", Code: "required", Message: "is required"},
+			{Field: "email", Code: "invalid_format", Message: "must be a valid email address"},
+			{Field: "birth_date", Code: "required", Message: "is required"},
+			{Field: "country", Code: "invalid_format", Message: "must be an ISO 3166-1 alpha-2 country code"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/clients", strings.NewReader(tt.body)))
+
+			require.Equal(t, tt.status, rec.Code)
+			var problem httputil.Problem
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+			assert.Equal(t, tt.code, problem.Code)
+			assert.Equal(t, tt.fields, problem.Errors)
+		})
+	}
+}
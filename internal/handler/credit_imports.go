@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tucredito/backend-api/internal/bulk"
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/service"
+	"github.com/tucredito/backend-api/pkg/apperr"
+	"github.com/tucredito/backend-api/pkg/httputil"
+	"go.uber.org/zap"
+)
+
+const (
+	// Upper bound for an uploaded batch file
+	maxImportBytes = 50 << 20
+	// Batches outlive the server's default 15s read/write timeouts
+	importTimeout = 10 * time.Minute
+)
+
+type CreditImportHandler struct {
+	service service.CreditImportService
+	log     *zap.Logger
+}
+
+func NewCreditImportHandler(service service.CreditImportService, log *zap.Logger) *CreditImportHandler {
+	return &CreditImportHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// Imports a batch of credits from CSV or NDJSON (POST /credits/bulk)
+func (h *CreditImportHandler) Import(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httputil.Error(w, http.StatusMethodNotAllowed, "method not allowed", "METHOD_NOT_ALLOWED", "")
+		return
+	}
+
+	format, ok := importFormat(r)
+	if !ok {
+		httputil.Error(w, http.StatusUnsupportedMediaType, "body must be text/csv or application/x-ndjson", "UNSUPPORTED_MEDIA_TYPE", "")
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Now().Add(importTimeout))
+	_ = rc.SetWriteDeadline(time.Now().Add(importTimeout))
+	body := http.MaxBytesReader(w, r.Body, maxImportBytes)
+
+	imp, err := h.service.Import(r.Context(), format, body)
+	if err != nil {
+		switch {
+		case errors.Is(err, bulk.ErrMissingColumn), errors.Is(err, bulk.ErrUnsupportedFormat):
+			httputil.WriteError(w, r, apperr.Validation("invalid import file: "+err.Error()))
+		default:
+			writeError(w, r, h.log, "import credits", err)
+		}
+		return
+	}
+
+	// The full report is downloaded separately; the response carries the summary only
+	summary := *imp
+	summary.Results = nil
+	w.Header().Set("Location", r.URL.Path+"/"+imp.ID+"/report")
+	httputil.JSON(w, http.StatusCreated, summary)
+}
+
+// Gets a credit import and its per-row report (GET /credits/bulk/{id}/report); ?format=csv downloads the report
+func (h *CreditImportHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httputil.Error(w, http.StatusMethodNotAllowed, "method not allowed", "METHOD_NOT_ALLOWED", "")
+		return
+	}
+
+	id := r.PathValue("id")
+	if id == "" {
+		httputil.WriteError(w, r, apperr.Validation("id required"))
+		return
+	}
+
+	imp, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, h.log, "get credit import", err, zap.String("id", id))
+		return
+	}
+
+	if imp == nil {
+		httputil.WriteError(w, r, apperr.NotFound("credit import not found"))
+		return
+	}
+
+	if strings.EqualFold(r.URL.Query().Get("format"), "csv") {
+		writeImportReportCSV(w, imp)
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, imp)
+}
+
+// Resolves the upload format from ?format= or the Content-Type header
+func importFormat(r *http.Request) (domain.ImportFormat, bool) {
+	switch strings.ToLower(r.URL.Query().Get("format")) {
+	case "csv":
+		return domain.ImportFormatCSV, true
+	case "ndjson", "jsonl":
+		return domain.ImportFormatNDJSON, true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return "", false
+	}
+	switch mediaType {
+	case "text/csv":
+		return domain.ImportFormatCSV, true
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return domain.ImportFormatNDJSON, true
+	}
+	return "", false
+}
+
+// Writes the per-row report as a CSV attachment
+func writeImportReportCSV(w http.ResponseWriter, imp *domain.CreditImport) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="credit-import-`+imp.ID+`.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"row", "credit_id", "status", "code", "error"})
+	for _, res := range imp.Results {
+		_ = cw.Write([]string{strconv.Itoa(res.Row), res.CreditID, string(res.Status), res.Code, res.Error})
+	}
+	cw.Flush()
+}
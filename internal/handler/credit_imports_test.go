@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/bulk"
+	"github.com/tucredito/backend-api/internal/domain"
+	handlermocks "github.com/tucredito/backend-api/internal/handler/mocks"
+	"go.uber.org/zap"
+)
+
+func TestCreditImportHandler_Import(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	mockSvc := &handlermocks.MockCreditImportService{}
+	var gotFormat domain.ImportFormat
+	var gotBody string
+	mockSvc.ImportFunc = func(_ context.Context, format domain.ImportFormat, r io.Reader) (*domain.CreditImport, error) {
+		gotFormat = format
+		b, _ := io.ReadAll(r)
+		gotBody = string(b)
+		return &domain.CreditImport{
+			ID: "imp1", Format: format, Status: domain.ImportStatusCompleted,
+			Total: 2, Succeeded: 1, Failed: 1, CreatedAt: time.Now(),
+			Results: []domain.CreditImportResult{{Row: 1, CreditID: "cr1"}, {Row: 2, Code: "NOT_FOUND", Error: "client not found"}},
+		}, nil
+	}
+	h := NewCreditImportHandler(mockSvc, log)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+apiVersion+"/credits/bulk", h.Import)
+
+	body := "client_id,bank_id,min_payment,max_payment,term_months,credit_type\nc1,b1,100,500,12,AUTO\n"
+	req := httptest.NewRequest(http.MethodPost, "/v1/credits/bulk", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/csv")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "/v1/credits/bulk/imp1/report", rec.Header().Get("Location"))
+	assert.Equal(t, domain.ImportFormatCSV, gotFormat)
+	assert.Equal(t, body, gotBody)
+	var got domain.CreditImport
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	assert.Equal(t, 1, got.Succeeded)
+	assert.Equal(t, 1, got.Failed)
+	assert.Empty(t, got.Results)
+}
+
+func TestCreditImportHandler_Import_UnsupportedMediaType(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	h := NewCreditImportHandler(&handlermocks.MockCreditImportService{}, log)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+apiVersion+"/credits/bulk", h.Import)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/credits/bulk", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/xml")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+}
+
+func TestCreditImportHandler_Import_InvalidHeader(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	mockSvc := &handlermocks.MockCreditImportService{}
+	mockSvc.ImportFunc = func(_ context.Context, _ domain.ImportFormat, _ io.Reader) (*domain.CreditImport, error) {
+		return nil, bulk.ErrMissingColumn
+	}
+	h := NewCreditImportHandler(mockSvc, log)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+apiVersion+"/credits/bulk", h.Import)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/credits/bulk?format=csv", strings.NewReader("client_id\n"))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestCreditImportHandler_GetByID_CSV(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	mockSvc := &handlermocks.MockCreditImportService{}
+	mockSvc.GetByIDFunc = func(_ context.Context, id string) (*domain.CreditImport, error) {
+		if id != "imp1" {
+			return nil, nil
+		}
+		return &domain.CreditImport{
+			ID: "imp1", Status: domain.ImportStatusCompleted, Total: 2,
+			Results: []domain.CreditImportResult{
+				{Row: 1, CreditID: "cr1", Status: domain.CreditStatusApproved},
+				{Row: 2, Code: "VALIDATION", Error: "bad row"},
+			},
+		}, nil
+	}
+	h := NewCreditImportHandler(mockSvc, log)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+apiVersion+"/credits/bulk/{id}/report", h.GetByID)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/credits/bulk/imp1/report?format=csv", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/csv")
+	assert.Equal(t, "row,credit_id,status,code,error\n1,cr1,APPROVED,,\n2,,,VALIDATION,bad row\n", rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/credits/bulk/none/report", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -1,12 +1,12 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
 	"strconv"
 
 	"github.com/tucredito/backend-api/internal/domain"
 	"github.com/tucredito/backend-api/internal/service"
+	"github.com/tucredito/backend-api/pkg/apperr"
 	"github.com/tucredito/backend-api/pkg/httputil"
 	"go.uber.org/zap"
 )
@@ -31,38 +31,18 @@ func (h *CreditHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var input domain.CreateCreditInput
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		httputil.Error(w, http.StatusBadRequest, "invalid JSON", "INVALID_JSON", err.Error())
-		return
-	}
-
-	if input.ClientID == "" || input.BankID == "" || input.MaxPayment < input.MinPayment || input.TermMonths <= 0 {
-		httputil.Error(w, http.StatusBadRequest, "client_id, bank_id, min_payment, max_payment, term_months required and valid", "VALIDATION", "")
-		return
-	}
-
-	if input.CreditType != domain.CreditTypeAuto && input.CreditType != domain.CreditTypeMortgage && input.CreditType != domain.CreditTypeCommercial {
-		httputil.Error(w, http.StatusBadRequest, "credit_type must be AUTO, MORTGAGE, or COMMERCIAL", "VALIDATION", "")
+	if err := decodeInput(w, r, &input); err != nil {
+		httputil.WriteError(w, r, err)
 		return
 	}
 
 	credit, err := h.service.Create(r.Context(), input)
 	if err != nil {
-		switch err {
-		case service.ErrClientNotFound:
-			httputil.Error(w, http.StatusNotFound, "client not found", "NOT_FOUND", "")
-		case service.ErrBankNotFound:
-			httputil.Error(w, http.StatusNotFound, "bank not found", "NOT_FOUND", "")
-		case service.ErrInvalidInput:
-			httputil.Error(w, http.StatusBadRequest, "invalid input", "VALIDATION", err.Error())
-		default:
-			h.log.Error("create credit", zap.Error(err))
-			httputil.Error(w, http.StatusInternalServerError, "failed to create credit", "INTERNAL", err.Error())
-		}
+		writeError(w, r, h.log, "create credit", err)
 		return
 	}
 
-	httputil.JSON(w, http.StatusCreated, credit)
+	httputil.JSONWithETag(w, r, http.StatusCreated, credit.Version, credit)
 }
 
 // Gets a credit by ID (GET /credits/{id}).
@@ -74,23 +54,22 @@ func (h *CreditHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 
 	id := r.PathValue("id")
 	if id == "" {
-		httputil.Error(w, http.StatusBadRequest, "id required", "VALIDATION", "")
+		httputil.WriteError(w, r, apperr.Validation("id required"))
 		return
 	}
 
 	credit, err := h.service.GetByID(r.Context(), id)
 	if err != nil {
-		h.log.Error("get credit", zap.Error(err), zap.String("id", id))
-		httputil.Error(w, http.StatusInternalServerError, "failed to get credit", "INTERNAL", err.Error())
+		writeError(w, r, h.log, "get credit", err, zap.String("id", id))
 		return
 	}
 
 	if credit == nil {
-		httputil.Error(w, http.StatusNotFound, "credit not found", "NOT_FOUND", "")
+		httputil.WriteError(w, r, apperr.NotFound("credit not found"))
 		return
 	}
 
-	httputil.JSON(w, http.StatusOK, credit)
+	httputil.JSONWithETag(w, r, http.StatusOK, credit.Version, credit)
 }
 
 // Updates a credit (PUT /credits/{id}).
@@ -101,33 +80,52 @@ func (h *CreditHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 	id := r.PathValue("id")
 	if id == "" {
-		httputil.Error(w, http.StatusBadRequest, "id required", "VALIDATION", "")
+		httputil.WriteError(w, r, apperr.Validation("id required"))
 		return
 	}
 	var input domain.UpdateCreditInput
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		httputil.Error(w, http.StatusBadRequest, "invalid JSON", "INVALID_JSON", err.Error())
+	if err := decodeInput(w, r, &input); err != nil {
+		httputil.WriteError(w, r, err)
 		return
 	}
-	if input.MaxPayment < input.MinPayment || input.TermMonths <= 0 {
-		httputil.Error(w, http.StatusBadRequest, "min_payment, max_payment, term_months required and valid", "VALIDATION", "")
+	credit, err := h.service.Update(conditional(r), id, input)
+	if err != nil {
+		writeError(w, r, h.log, "update credit", err, zap.String("id", id))
+		return
+	}
+	if credit == nil {
+		httputil.WriteError(w, r, apperr.NotFound("credit not found"))
+		return
+	}
+	httputil.JSONWithETag(w, r, http.StatusOK, credit.Version, credit)
+}
+
+// Applies a JSON Merge Patch to a credit (PATCH /credits/{id}); omitted fields keep their values.
+func (h *CreditHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		httputil.Error(w, http.StatusMethodNotAllowed, "method not allowed", "METHOD_NOT_ALLOWED", "")
+		return
+	}
+	id := r.PathValue("id")
+	if id == "" {
+		httputil.WriteError(w, r, apperr.Validation("id required"))
 		return
 	}
-	if input.Status != domain.CreditStatusPending && input.Status != domain.CreditStatusApproved && input.Status != domain.CreditStatusRejected {
-		httputil.Error(w, http.StatusBadRequest, "status must be PENDING, APPROVED, or REJECTED", "VALIDATION", "")
+	var patch domain.CreditPatch
+	if err := httputil.DecodeMergePatch(w, r, &patch, httputil.MaxJSONBodyBytes); err != nil {
+		httputil.WriteError(w, r, err)
 		return
 	}
-	credit, err := h.service.Update(r.Context(), id, input)
+	credit, err := h.service.Patch(conditional(r), id, patch)
 	if err != nil {
-		h.log.Error("update credit", zap.Error(err), zap.String("id", id))
-		httputil.Error(w, http.StatusInternalServerError, "failed to update credit", "INTERNAL", err.Error())
+		writeError(w, r, h.log, "patch credit", err, zap.String("id", id))
 		return
 	}
 	if credit == nil {
-		httputil.Error(w, http.StatusNotFound, "credit not found", "NOT_FOUND", "")
+		httputil.WriteError(w, r, apperr.NotFound("credit not found"))
 		return
 	}
-	httputil.JSON(w, http.StatusOK, credit)
+	httputil.JSONWithETag(w, r, http.StatusOK, credit.Version, credit)
 }
 
 // Soft-deletes a credit (DELETE /credits/{id}).
@@ -138,20 +136,19 @@ func (h *CreditHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 	id := r.PathValue("id")
 	if id == "" {
-		httputil.Error(w, http.StatusBadRequest, "id required", "VALIDATION", "")
+		httputil.WriteError(w, r, apperr.Validation("id required"))
 		return
 	}
-	credit, err := h.service.Delete(r.Context(), id)
+	credit, err := h.service.Delete(conditional(r), id)
 	if err != nil {
-		h.log.Error("delete credit", zap.Error(err), zap.String("id", id))
-		httputil.Error(w, http.StatusInternalServerError, "failed to delete credit", "INTERNAL", err.Error())
+		writeError(w, r, h.log, "delete credit", err, zap.String("id", id))
 		return
 	}
 	if credit == nil {
-		httputil.Error(w, http.StatusNotFound, "credit not found", "NOT_FOUND", "")
+		httputil.WriteError(w, r, apperr.NotFound("credit not found"))
 		return
 	}
-	httputil.JSON(w, http.StatusOK, credit)
+	httputil.JSONWithETag(w, r, http.StatusOK, credit.Version, credit)
 }
 
 // Re-enables a credit (POST /credits/{id}/reenable).
@@ -162,20 +159,19 @@ func (h *CreditHandler) Reenable(w http.ResponseWriter, r *http.Request) {
 	}
 	id := r.PathValue("id")
 	if id == "" {
-		httputil.Error(w, http.StatusBadRequest, "id required", "VALIDATION", "")
+		httputil.WriteError(w, r, apperr.Validation("id required"))
 		return
 	}
-	credit, err := h.service.Reenable(r.Context(), id)
+	credit, err := h.service.Reenable(conditional(r), id)
 	if err != nil {
-		h.log.Error("reenable credit", zap.Error(err), zap.String("id", id))
-		httputil.Error(w, http.StatusInternalServerError, "failed to reenable credit", "INTERNAL", err.Error())
+		writeError(w, r, h.log, "reenable credit", err, zap.String("id", id))
 		return
 	}
 	if credit == nil {
-		httputil.Error(w, http.StatusNotFound, "credit not found", "NOT_FOUND", "")
+		httputil.WriteError(w, r, apperr.NotFound("credit not found"))
 		return
 	}
-	httputil.JSON(w, http.StatusOK, credit)
+	httputil.JSONWithETag(w, r, http.StatusOK, credit.Version, credit)
 }
 
 // Lists credits with pagination (GET /credits).
@@ -193,8 +189,7 @@ func (h *CreditHandler) List(w http.ResponseWriter, r *http.Request) {
 
 	list, err := h.service.List(r.Context(), limit, offset)
 	if err != nil {
-		h.log.Error("list credits", zap.Error(err))
-		httputil.Error(w, http.StatusInternalServerError, "failed to list credits", "INTERNAL", err.Error())
+		writeError(w, r, h.log, "list credits", err)
 		return
 	}
 
@@ -210,7 +205,7 @@ func (h *CreditHandler) ListByClientID(w http.ResponseWriter, r *http.Request) {
 
 	clientID := r.PathValue("id")
 	if clientID == "" {
-		httputil.Error(w, http.StatusBadRequest, "client id required", "VALIDATION", "")
+		httputil.WriteError(w, r, apperr.Validation("client id required"))
 		return
 	}
 
@@ -222,8 +217,7 @@ func (h *CreditHandler) ListByClientID(w http.ResponseWriter, r *http.Request) {
 
 	list, err := h.service.ListByClientID(r.Context(), clientID, limit, offset)
 	if err != nil {
-		h.log.Error("list credits by client", zap.Error(err), zap.String("client_id", clientID))
-		httputil.Error(w, http.StatusInternalServerError, "failed to list credits", "INTERNAL", err.Error())
+		writeError(w, r, h.log, "list credits by client", err, zap.String("client_id", clientID))
 		return
 	}
 
@@ -221,6 +221,25 @@ func TestCreditHandler_Update(t *testing.T) {
 	assert.Equal(t, domain.CreditStatusApproved, got.Status)
 }
 
+func TestCreditHandler_Update_Busy(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	mockSvc := &handlermocks.MockCreditService{}
+	mockSvc.UpdateFunc = func(_ context.Context, id string, input domain.UpdateCreditInput) (*domain.Credit, error) {
+		return nil, service.ErrCreditBusy
+	}
+	h := NewCreditHandler(mockSvc, log)
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT "+apiVersion+"/credits/{id}", h.Update)
+
+	body := []byte(`{"min_payment":200,"max_payment":600,"term_months":24,"status":"REJECTED"}`)
+	req := httptest.NewRequest(http.MethodPut, "/v1/credits/cr1", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+}
+
 func TestCreditHandler_Delete(t *testing.T) {
 	log, _ := zap.NewDevelopment()
 	softDeleted := &domain.Credit{ID: "cr1", ClientID: "c1", BankID: "b1", IsActive: false}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/tucredito/backend-api/pkg/httputil"
+	"github.com/tucredito/backend-api/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Writes err as a problem document; server-side failures (5xx) are logged with op first, since their cause never reaches the client
+func writeError(w http.ResponseWriter, r *http.Request, log *zap.Logger, op string, err error, fields ...zap.Field) {
+	if httputil.StatusOf(err) >= http.StatusInternalServerError {
+		logger.FromContext(r.Context(), log).Error(op, append(fields, zap.Error(err))...)
+	}
+	httputil.WriteError(w, r, err)
+}
+
+// Implemented by the domain input types (see domain/validation.go)
+type validatable interface {
+	Validate() error
+}
+
+// Strictly decodes the JSON body into input and validates it; the error is ready for httputil.WriteError
+func decodeInput(w http.ResponseWriter, r *http.Request, input validatable) error {
+	if err := httputil.DecodeJSON(w, r, input, httputil.MaxJSONBodyBytes); err != nil {
+		return err
+	}
+	return input.Validate()
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/export"
+	"github.com/tucredito/backend-api/internal/service"
+	"github.com/tucredito/backend-api/pkg/apperr"
+	"github.com/tucredito/backend-api/pkg/httputil"
+	"github.com/tucredito/backend-api/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Large portfolios outlive the server's default 15s write timeout
+const exportTimeout = 30 * time.Minute
+
+type ExportHandler struct {
+	service service.ExportService
+	log     *zap.Logger
+}
+
+func NewExportHandler(service service.ExportService, log *zap.Logger) *ExportHandler {
+	return &ExportHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// Streams the credit portfolio (GET /exports/credits?format=csv|ndjson&client_id=&bank_id=&status=&from=&to=)
+func (h *ExportHandler) Credits(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httputil.Error(w, http.StatusMethodNotAllowed, "method not allowed", "METHOD_NOT_ALLOWED", "")
+		return
+	}
+
+	q := r.URL.Query()
+	var format domain.ExportFormat
+	switch strings.ToLower(q.Get("format")) {
+	case "", "csv":
+		format = domain.ExportFormatCSV
+	case "ndjson":
+		format = domain.ExportFormatNDJSON
+	default:
+		httputil.WriteError(w, r, apperr.Validation("format must be csv or ndjson"))
+		return
+	}
+
+	filter, err := ParseCreditExportFilter(q)
+	if err != nil {
+		httputil.WriteError(w, r, err)
+		return
+	}
+
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(exportTimeout))
+	sw := &streamWriter{w: w, onFirstWrite: func() {
+		ext := ".csv"
+		if format == domain.ExportFormatNDJSON {
+			ext = ".ndjson"
+		}
+		w.Header().Set("Content-Type", export.ContentType(format))
+		w.Header().Set("Content-Disposition", `attachment; filename="credits-`+time.Now().UTC().Format("20060102")+ext+`"`)
+		w.WriteHeader(http.StatusOK)
+	}}
+
+	err = h.service.ExportCredits(r.Context(), format, filter, sw)
+	if err == nil {
+		return
+	}
+	if sw.started {
+		// Headers are gone; the client sees a truncated body
+		logger.FromContext(r.Context(), h.log).Error("export credits aborted mid-stream", zap.Error(err))
+		return
+	}
+	writeError(w, r, h.log, "export credits", err)
+}
+
+// Parses client_id, bank_id, status, from and to (YYYY-MM-DD or RFC 3339; to is exclusive)
+func ParseCreditExportFilter(q url.Values) (domain.CreditExportFilter, error) {
+	filter := domain.CreditExportFilter{
+		ClientID: q.Get("client_id"),
+		BankID:   q.Get("bank_id"),
+		Status:   domain.CreditStatus(strings.ToUpper(q.Get("status"))),
+	}
+
+	var err error
+	if filter.CreatedFrom, err = parseExportTime("from", q.Get("from")); err != nil {
+		return filter, err
+	}
+	if filter.CreatedTo, err = parseExportTime("to", q.Get("to")); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+func parseExportTime(name, v string) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.DateOnly, time.RFC3339} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, apperr.Validation("invalid export filter", apperr.Field(name, "invalid_format", "must be YYYY-MM-DD or RFC 3339"))
+}
+
+// Delays the response headers until the first byte so early errors can still become JSON errors
+type streamWriter struct {
+	w            http.ResponseWriter
+	onFirstWrite func()
+	started      bool
+}
+
+func (s *streamWriter) Write(p []byte) (int, error) {
+	if !s.started {
+		s.started = true
+		s.onFirstWrite()
+	}
+	return s.w.Write(p)
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/domain"
+	handlermocks "github.com/tucredito/backend-api/internal/handler/mocks"
+	"github.com/tucredito/backend-api/internal/service"
+	"go.uber.org/zap"
+)
+
+func TestExportHandler_Credits(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	var gotFormat domain.ExportFormat
+	var gotFilter domain.CreditExportFilter
+	mockSvc := &handlermocks.MockExportService{}
+	mockSvc.ExportCreditsFunc = func(_ context.Context, format domain.ExportFormat, filter domain.CreditExportFilter, w io.Writer) error {
+		gotFormat, gotFilter = format, filter
+		_, err := io.WriteString(w, "{\"credit_id\":\"cr1\"}\n")
+		return err
+	}
+	h := NewExportHandler(mockSvc, log)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+apiVersion+"/exports/credits", h.Credits)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/exports/credits?format=ndjson&client_id=c1&status=approved&from=2026-09-01&to=2026-10-01", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), ".ndjson")
+	assert.Equal(t, "{\"credit_id\":\"cr1\"}\n", rec.Body.String())
+	assert.Equal(t, domain.ExportFormatNDJSON, gotFormat)
+	assert.Equal(t, "c1", gotFilter.ClientID)
+	assert.Equal(t, domain.CreditStatusApproved, gotFilter.Status)
+	require.NotNil(t, gotFilter.CreatedFrom)
+	require.NotNil(t, gotFilter.CreatedTo)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), *gotFilter.CreatedTo)
+}
+
+func TestExportHandler_Credits_InvalidFilter(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	mockSvc := &handlermocks.MockExportService{}
+	mockSvc.ExportCreditsFunc = func(_ context.Context, _ domain.ExportFormat, _ domain.CreditExportFilter, _ io.Writer) error {
+		return service.ErrInvalidInput
+	}
+	h := NewExportHandler(mockSvc, log)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+apiVersion+"/exports/credits", h.Credits)
+
+	for _, target := range []string{
+		"/v1/exports/credits?format=xml",
+		"/v1/exports/credits?from=yesterday",
+		"/v1/exports/credits?status=DONE",
+	} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+	}
+}
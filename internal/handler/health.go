@@ -3,66 +3,201 @@ package handler
 import (
 	"context"
 	"net/http"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 	"github.com/tucredito/backend-api/pkg/httputil"
 )
 
+/*
+	Health endpoints
+	- /health (liveness) only checks that the process serves requests
+	- /ready runs the registered checks in parallel, each with its own timeout, and caches the results
+	  for the refresh interval so frequent probes do not hammer dependencies; a failed critical check
+	  answers 503, failed non-critical checks answer 200 with status "degraded"
+	- /startup answers 503 until every critical check has passed once, then 200 for the life of the process
+	Checks run detached from the probe request, so a probe that gives up does not poison the cached result
+*/
+
+const (
+	defaultCheckTimeout    = 2 * time.Second
+	defaultRefreshInterval = 5 * time.Second
+)
+
+// A dependency check; Critical failures take the instance out of rotation, others only report degraded
+type HealthCheck struct {
+	Name     string
+	Check    func(ctx context.Context) error
+	Timeout  time.Duration // defaultCheckTimeout when zero
+	Critical bool
+}
+
+// Outcome of one check as reported by /ready and /startup
+type CheckResult struct {
+	Status     string `json:"status"` // "ok" or "fail"
+	Error      string `json:"error,omitempty"`
+	Critical   bool   `json:"critical"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
 type HealthHandler struct {
-	pool   *pgxpool.Pool
-	redis  *redis.Client
-	checks map[string]func(context.Context) error
-	mu     sync.RWMutex
+	mu              sync.RWMutex
+	checks          map[string]HealthCheck
+	refreshInterval time.Duration
+
+	// Cached results; refreshMu lets one caller refresh while the others wait for its results
+	refreshMu sync.Mutex
+	results   map[string]CheckResult
+	checkedAt time.Time
+
+	started atomic.Bool
+	now     func() time.Time
 }
 
-// Creates a new HealthHandler
+// Creates a new HealthHandler with the Postgres (critical) and Redis (degraded: the cache falls back to memory) checks
 func NewHealthHandler(pool *pgxpool.Pool, redisClient *redis.Client) *HealthHandler {
-	h := &HealthHandler{pool: pool, redis: redisClient, checks: make(map[string]func(context.Context) error)}
+	h := &HealthHandler{checks: make(map[string]HealthCheck), refreshInterval: defaultRefreshInterval, now: time.Now}
 
 	if pool != nil {
-		h.checks["postgres"] = func(ctx context.Context) error { return pool.Ping(ctx) }
+		h.RegisterCheck(HealthCheck{Name: "postgres", Check: pool.Ping, Critical: true})
 	}
 
 	if redisClient != nil {
-		h.checks["redis"] = func(ctx context.Context) error { return redisClient.Ping(ctx).Err() }
+		h.RegisterCheck(HealthCheck{Name: "redis", Check: func(ctx context.Context) error { return redisClient.Ping(ctx).Err() }})
 	}
 
 	return h
 }
 
+// Adds or replaces a check; it runs from the next refresh on
+func (h *HealthHandler) RegisterCheck(c HealthCheck) {
+	if c.Timeout <= 0 {
+		c.Timeout = defaultCheckTimeout
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks[c.Name] = c
+	h.refreshMu.Lock()
+	h.checkedAt = time.Time{}
+	h.refreshMu.Unlock()
+}
+
+// Sets how long check results are reused (0 runs the checks on every probe)
+func (h *HealthHandler) SetRefreshInterval(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.refreshInterval = d
+}
+
 // Function to check if server responds
 func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
 	httputil.JSON(w, http.StatusOK, map[string]string{"status": "ok"})
 }
 
-// Function to check if all dependencies respond
+// Function to check if all dependencies respond (GET /ready)
 func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
-	h.mu.RLock()
-	checks := make(map[string]func(context.Context) error, len(h.checks))
+	results := h.Results()
+	status, code := summarize(results)
+	httputil.JSON(w, code, map[string]interface{}{"status": status, "checks": results})
+}
 
-	for k, v := range h.checks {
-		checks[k] = v
+// Function to check if the instance has finished starting (GET /startup)
+func (h *HealthHandler) Startup(w http.ResponseWriter, r *http.Request) {
+	if h.started.Load() {
+		httputil.JSON(w, http.StatusOK, map[string]string{"status": "started"})
+		return
+	}
+	results := h.Results()
+	if _, code := summarize(results); code != http.StatusOK {
+		httputil.JSON(w, http.StatusServiceUnavailable, map[string]interface{}{"status": "starting", "checks": results})
+		return
 	}
+	httputil.JSON(w, http.StatusOK, map[string]string{"status": "started"})
+}
 
+// Returns the check results, running the checks when the cached ones are older than the refresh interval
+func (h *HealthHandler) Results() map[string]CheckResult {
+	h.mu.RLock()
+	checks := make([]HealthCheck, 0, len(h.checks))
+	for _, c := range h.checks {
+		checks = append(checks, c)
+	}
+	interval := h.refreshInterval
 	h.mu.RUnlock()
-	ctx := r.Context()
-	results := make(map[string]string)
-	allOk := true
-
-	for name, fn := range checks {
-		if err := fn(ctx); err != nil {
-			results[name] = err.Error()
-			allOk = false
-		} else {
-			results[name] = "ok"
-		}
+
+	h.refreshMu.Lock()
+	defer h.refreshMu.Unlock()
+	if h.results != nil && !h.checkedAt.IsZero() && h.now().Sub(h.checkedAt) < interval {
+		return h.results
+	}
+
+	h.results = runChecks(checks)
+	h.checkedAt = h.now()
+	if _, code := summarize(h.results); code == http.StatusOK {
+		h.started.Store(true)
+	}
+	return h.results
+}
+
+// Runs checks in parallel, each bounded by its own timeout
+func runChecks(checks []HealthCheck) map[string]CheckResult {
+	sort.Slice(checks, func(i, j int) bool { return checks[i].Name < checks[j].Name })
+	out := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c HealthCheck) {
+			defer wg.Done()
+			out[i] = runCheck(c)
+		}(i, c)
 	}
+	wg.Wait()
+
+	results := make(map[string]CheckResult, len(checks))
+	for i, c := range checks {
+		results[c.Name] = out[i]
+	}
+	return results
+}
 
-	if allOk {
-		httputil.JSON(w, http.StatusOK, map[string]interface{}{"status": "ok", "checks": results})
-	} else {
-		httputil.JSON(w, http.StatusServiceUnavailable, map[string]interface{}{"status": "degraded", "checks": results})
+// Runs one check; a check that ignores its context still fails at the timeout
+func runCheck(c HealthCheck) CheckResult {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- c.Check(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	res := CheckResult{Status: "ok", Critical: c.Critical, DurationMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		res.Status, res.Error = "fail", err.Error()
+	}
+	return res
+}
+
+// Returns the overall status and HTTP code for results
+func summarize(results map[string]CheckResult) (string, int) {
+	status := "ok"
+	for _, res := range results {
+		if res.Status == "ok" {
+			continue
+		}
+		if res.Critical {
+			return "unavailable", http.StatusServiceUnavailable
+		}
+		status = "degraded"
 	}
+	return status, http.StatusOK
 }
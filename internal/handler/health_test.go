@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type readyBody struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+func serveHealth(t *testing.T, hf http.HandlerFunc) (int, readyBody) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	hf(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	var body readyBody
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return rec.Code, body
+}
+
+func TestHealthHandler_Ready_Criticality(t *testing.T) {
+	fail := errors.New("down")
+	tests := []struct {
+		name       string
+		dbErr      error
+		cacheErr   error
+		wantCode   int
+		wantStatus string
+	}{
+		{"all ok", nil, nil, http.StatusOK, "ok"},
+		{"non-critical failure", nil, fail, http.StatusOK, "degraded"},
+		{"critical failure", fail, nil, http.StatusServiceUnavailable, "unavailable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHealthHandler(nil, nil)
+			h.RegisterCheck(HealthCheck{Name: "db", Critical: true, Check: func(context.Context) error { return tt.dbErr }})
+			h.RegisterCheck(HealthCheck{Name: "cache", Check: func(context.Context) error { return tt.cacheErr }})
+
+			code, body := serveHealth(t, h.Ready)
+			assert.Equal(t, tt.wantCode, code)
+			assert.Equal(t, tt.wantStatus, body.Status)
+			assert.True(t, body.Checks["db"].Critical)
+			assert.False(t, body.Checks["cache"].Critical)
+		})
+	}
+}
+
+func TestHealthHandler_Ready_ParallelWithTimeout(t *testing.T) {
+	h := NewHealthHandler(nil, nil)
+	for _, name := range []string{"a", "b", "c"} {
+		h.RegisterCheck(HealthCheck{Name: name, Timeout: 50 * time.Millisecond, Check: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}})
+	}
+	// Ignores its context; the runner still gives up at the timeout
+	h.RegisterCheck(HealthCheck{Name: "stuck", Timeout: 50 * time.Millisecond, Critical: true, Check: func(context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}})
+
+	start := time.Now()
+	code, body := serveHealth(t, h.Ready)
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "checks should run in parallel and honour their timeout")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "fail", body.Checks["stuck"].Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), body.Checks["a"].Error)
+}
+
+func TestHealthHandler_Ready_CachesResults(t *testing.T) {
+	now := time.Unix(0, 0)
+	var calls atomic.Int32
+	h := NewHealthHandler(nil, nil)
+	h.now = func() time.Time { return now }
+	h.SetRefreshInterval(5 * time.Second)
+	h.RegisterCheck(HealthCheck{Name: "db", Check: func(context.Context) error {
+		calls.Add(1)
+		return nil
+	}})
+
+	serveHealth(t, h.Ready)
+	now = now.Add(4 * time.Second)
+	serveHealth(t, h.Ready)
+	assert.Equal(t, int32(1), calls.Load(), "results within the refresh interval are reused")
+
+	now = now.Add(2 * time.Second)
+	serveHealth(t, h.Ready)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestHealthHandler_Startup_LatchesOnceStarted(t *testing.T) {
+	var up atomic.Bool
+	h := NewHealthHandler(nil, nil)
+	h.SetRefreshInterval(0)
+	h.RegisterCheck(HealthCheck{Name: "db", Critical: true, Check: func(context.Context) error {
+		if !up.Load() {
+			return errors.New("down")
+		}
+		return nil
+	}})
+
+	code, body := serveHealth(t, h.Startup)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "starting", body.Status)
+
+	up.Store(true)
+	code, _ = serveHealth(t, h.Startup)
+	assert.Equal(t, http.StatusOK, code)
+
+	// Later failures show on /ready but do not restart the startup probe
+	up.Store(false)
+	code, _ = serveHealth(t, h.Startup)
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = serveHealth(t, h.Ready)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+}
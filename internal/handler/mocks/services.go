@@ -2,7 +2,9 @@ package mocks
 
 import (
 	"context"
+	"io"
 
+	"github.com/tucredito/backend-api/internal/decision"
 	"github.com/tucredito/backend-api/internal/domain"
 	"github.com/tucredito/backend-api/internal/service"
 )
@@ -11,6 +13,7 @@ type MockClientService struct {
 	CreateFunc    func(ctx context.Context, input domain.CreateClientInput) (*domain.Client, error)
 	GetByIDFunc   func(ctx context.Context, id string) (*domain.Client, error)
 	UpdateFunc    func(ctx context.Context, id string, input domain.UpdateClientInput) (*domain.Client, error)
+	PatchFunc     func(ctx context.Context, id string, patch domain.ClientPatch) (*domain.Client, error)
 	DeleteFunc    func(ctx context.Context, id string) (*domain.Client, error)
 	ReenableFunc   func(ctx context.Context, id string) (*domain.Client, error)
 	ListFunc      func(ctx context.Context, limit, offset int) ([]*domain.Client, error)
@@ -37,6 +40,13 @@ func (m *MockClientService) Update(ctx context.Context, id string, input domain.
 	return nil, nil
 }
 
+func (m *MockClientService) Patch(ctx context.Context, id string, patch domain.ClientPatch) (*domain.Client, error) {
+	if m.PatchFunc != nil {
+		return m.PatchFunc(ctx, id, patch)
+	}
+	return nil, nil
+}
+
 func (m *MockClientService) Delete(ctx context.Context, id string) (*domain.Client, error) {
 	if m.DeleteFunc != nil {
 		return m.DeleteFunc(ctx, id)
@@ -64,6 +74,7 @@ type MockBankService struct {
 	CreateFunc   func(ctx context.Context, input domain.CreateBankInput) (*domain.Bank, error)
 	GetByIDFunc  func(ctx context.Context, id string) (*domain.Bank, error)
 	UpdateFunc   func(ctx context.Context, id string, input domain.UpdateBankInput) (*domain.Bank, error)
+	PatchFunc    func(ctx context.Context, id string, patch domain.BankPatch) (*domain.Bank, error)
 	DeleteFunc   func(ctx context.Context, id string) (*domain.Bank, error)
 	ReenableFunc func(ctx context.Context, id string) (*domain.Bank, error)
 	ListFunc     func(ctx context.Context, limit, offset int) ([]*domain.Bank, error)
@@ -90,6 +101,13 @@ func (m *MockBankService) Update(ctx context.Context, id string, input domain.Up
 	return nil, nil
 }
 
+func (m *MockBankService) Patch(ctx context.Context, id string, patch domain.BankPatch) (*domain.Bank, error) {
+	if m.PatchFunc != nil {
+		return m.PatchFunc(ctx, id, patch)
+	}
+	return nil, nil
+}
+
 func (m *MockBankService) Delete(ctx context.Context, id string) (*domain.Bank, error) {
 	if m.DeleteFunc != nil {
 		return m.DeleteFunc(ctx, id)
@@ -118,11 +136,14 @@ type MockCreditService struct {
 	CreateSyncFunc     func(ctx context.Context, input domain.CreateCreditInput) (*domain.Credit, error)
 	GetByIDFunc        func(ctx context.Context, id string) (*domain.Credit, error)
 	UpdateFunc         func(ctx context.Context, id string, input domain.UpdateCreditInput) (*domain.Credit, error)
+	PatchFunc          func(ctx context.Context, id string, patch domain.CreditPatch) (*domain.Credit, error)
 	UpdateStatusFunc   func(ctx context.Context, id string, status domain.CreditStatus) (*domain.Credit, error)
+	RedecideFunc       func(ctx context.Context, id string) (*domain.Credit, *decision.EligibilityResult, error)
 	DeleteFunc         func(ctx context.Context, id string) (*domain.Credit, error)
 	ReenableFunc       func(ctx context.Context, id string) (*domain.Credit, error)
 	ListFunc           func(ctx context.Context, limit, offset int) ([]*domain.Credit, error)
 	ListByClientIDFunc func(ctx context.Context, clientID string, limit, offset int) ([]*domain.Credit, error)
+	PingWorkersFunc    func(ctx context.Context) error
 }
 
 func (m *MockCreditService) Create(ctx context.Context, input domain.CreateCreditInput) (*domain.Credit, error) {
@@ -153,6 +174,13 @@ func (m *MockCreditService) Update(ctx context.Context, id string, input domain.
 	return nil, nil
 }
 
+func (m *MockCreditService) Patch(ctx context.Context, id string, patch domain.CreditPatch) (*domain.Credit, error) {
+	if m.PatchFunc != nil {
+		return m.PatchFunc(ctx, id, patch)
+	}
+	return nil, nil
+}
+
 func (m *MockCreditService) UpdateStatus(ctx context.Context, id string, status domain.CreditStatus) (*domain.Credit, error) {
 	if m.UpdateStatusFunc != nil {
 		return m.UpdateStatusFunc(ctx, id, status)
@@ -160,6 +188,13 @@ func (m *MockCreditService) UpdateStatus(ctx context.Context, id string, status
 	return nil, nil
 }
 
+func (m *MockCreditService) Redecide(ctx context.Context, id string) (*domain.Credit, *decision.EligibilityResult, error) {
+	if m.RedecideFunc != nil {
+		return m.RedecideFunc(ctx, id)
+	}
+	return nil, nil, nil
+}
+
 func (m *MockCreditService) Delete(ctx context.Context, id string) (*domain.Credit, error) {
 	if m.DeleteFunc != nil {
 		return m.DeleteFunc(ctx, id)
@@ -188,6 +223,139 @@ func (m *MockCreditService) ListByClientID(ctx context.Context, clientID string,
 	return nil, nil
 }
 
+func (m *MockCreditService) PingWorkers(ctx context.Context) error {
+	if m.PingWorkersFunc != nil {
+		return m.PingWorkersFunc(ctx)
+	}
+	return nil
+}
+
 func (m *MockCreditService) Shutdown() {}
 
 var _ service.CreditService = (*MockCreditService)(nil)
+
+type MockCreditImportService struct {
+	ImportFunc  func(ctx context.Context, format domain.ImportFormat, r io.Reader) (*domain.CreditImport, error)
+	GetByIDFunc func(ctx context.Context, id string) (*domain.CreditImport, error)
+}
+
+func (m *MockCreditImportService) Import(ctx context.Context, format domain.ImportFormat, r io.Reader) (*domain.CreditImport, error) {
+	if m.ImportFunc != nil {
+		return m.ImportFunc(ctx, format, r)
+	}
+	return nil, nil
+}
+
+func (m *MockCreditImportService) GetByID(ctx context.Context, id string) (*domain.CreditImport, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+var _ service.CreditImportService = (*MockCreditImportService)(nil)
+
+type MockExportService struct {
+	ExportCreditsFunc func(ctx context.Context, format domain.ExportFormat, filter domain.CreditExportFilter, w io.Writer) error
+}
+
+func (m *MockExportService) ExportCredits(ctx context.Context, format domain.ExportFormat, filter domain.CreditExportFilter, w io.Writer) error {
+	if m.ExportCreditsFunc != nil {
+		return m.ExportCreditsFunc(ctx, format, filter, w)
+	}
+	return nil
+}
+
+var _ service.ExportService = (*MockExportService)(nil)
+
+type MockAuditService struct {
+	HistoryFunc func(ctx context.Context, entity domain.AuditEntity, entityID string, limit, offset int) ([]*domain.AuditEntry, error)
+}
+
+func (m *MockAuditService) History(ctx context.Context, entity domain.AuditEntity, entityID string, limit, offset int) ([]*domain.AuditEntry, error) {
+	if m.HistoryFunc != nil {
+		return m.HistoryFunc(ctx, entity, entityID, limit, offset)
+	}
+	return nil, nil
+}
+
+var _ service.AuditService = (*MockAuditService)(nil)
+
+type MockWebhookService struct {
+	CreateFunc         func(ctx context.Context, input domain.CreateWebhookSubscriptionInput) (*domain.WebhookSubscription, error)
+	GetByIDFunc        func(ctx context.Context, id string) (*domain.WebhookSubscription, error)
+	UpdateFunc         func(ctx context.Context, id string, input domain.UpdateWebhookSubscriptionInput) (*domain.WebhookSubscription, error)
+	DeleteFunc         func(ctx context.Context, id string) (*domain.WebhookSubscription, error)
+	ListFunc           func(ctx context.Context, limit, offset int) ([]*domain.WebhookSubscription, error)
+	ListDeliveriesFunc func(ctx context.Context, subscriptionID string, status domain.WebhookDeliveryStatus, limit, offset int) ([]*domain.WebhookDelivery, error)
+	GetDeliveryFunc    func(ctx context.Context, subscriptionID, id string) (*domain.WebhookDelivery, error)
+	RedeliverFunc      func(ctx context.Context, subscriptionID, id string) (*domain.WebhookDelivery, error)
+	EnqueueFunc        func(ctx context.Context, evt *domain.DomainEvent) error
+}
+
+func (m *MockWebhookService) Create(ctx context.Context, input domain.CreateWebhookSubscriptionInput) (*domain.WebhookSubscription, error) {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, input)
+	}
+	return nil, nil
+}
+
+func (m *MockWebhookService) GetByID(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockWebhookService) Update(ctx context.Context, id string, input domain.UpdateWebhookSubscriptionInput) (*domain.WebhookSubscription, error) {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, id, input)
+	}
+	return nil, nil
+}
+
+func (m *MockWebhookService) Delete(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockWebhookService) List(ctx context.Context, limit, offset int) ([]*domain.WebhookSubscription, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, limit, offset)
+	}
+	return nil, nil
+}
+
+func (m *MockWebhookService) ListDeliveries(ctx context.Context, subscriptionID string, status domain.WebhookDeliveryStatus, limit, offset int) ([]*domain.WebhookDelivery, error) {
+	if m.ListDeliveriesFunc != nil {
+		return m.ListDeliveriesFunc(ctx, subscriptionID, status, limit, offset)
+	}
+	return nil, nil
+}
+
+func (m *MockWebhookService) GetDelivery(ctx context.Context, subscriptionID, id string) (*domain.WebhookDelivery, error) {
+	if m.GetDeliveryFunc != nil {
+		return m.GetDeliveryFunc(ctx, subscriptionID, id)
+	}
+	return nil, nil
+}
+
+func (m *MockWebhookService) Redeliver(ctx context.Context, subscriptionID, id string) (*domain.WebhookDelivery, error) {
+	if m.RedeliverFunc != nil {
+		return m.RedeliverFunc(ctx, subscriptionID, id)
+	}
+	return nil, nil
+}
+
+func (m *MockWebhookService) Enqueue(ctx context.Context, evt *domain.DomainEvent) error {
+	if m.EnqueueFunc != nil {
+		return m.EnqueueFunc(ctx, evt)
+	}
+	return nil
+}
+
+func (m *MockWebhookService) Run(ctx context.Context) {}
+
+var _ service.WebhookService = (*MockWebhookService)(nil)
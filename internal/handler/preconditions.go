@@ -0,0 +1,17 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/tucredito/backend-api/internal/repository"
+	"github.com/tucredito/backend-api/pkg/httputil"
+)
+
+// Returns r's context carrying the If-Match versions the repositories must find (see repository.CheckVersion)
+func conditional(r *http.Request) context.Context {
+	if versions, ok := httputil.IfMatch(r); ok {
+		return repository.WithExpectedVersion(r.Context(), versions...)
+	}
+	return r.Context()
+}
@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/service"
+	"github.com/tucredito/backend-api/pkg/apperr"
+	"github.com/tucredito/backend-api/pkg/httputil"
+	"go.uber.org/zap"
+)
+
+type WebhookHandler struct {
+	service service.WebhookService
+	log     *zap.Logger
+}
+
+func NewWebhookHandler(service service.WebhookService, log *zap.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// Creates a webhook subscription (POST /webhooks); the response is the only one carrying a generated secret.
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httputil.Error(w, http.StatusMethodNotAllowed, "method not allowed", "METHOD_NOT_ALLOWED", "")
+		return
+	}
+
+	var input domain.CreateWebhookSubscriptionInput
+	if err := decodeInput(w, r, &input); err != nil {
+		httputil.WriteError(w, r, err)
+		return
+	}
+
+	sub, err := h.service.Create(r.Context(), input)
+	if err != nil {
+		writeError(w, r, h.log, "create webhook", err)
+		return
+	}
+
+	w.Header().Set("Location", r.URL.Path+"/"+sub.ID)
+	httputil.JSON(w, http.StatusCreated, sub)
+}
+
+// Updates a webhook subscription (PUT /webhooks/{id}); omit secret to keep it and is_active to keep the state.
+func (h *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		httputil.Error(w, http.StatusMethodNotAllowed, "method not allowed", "METHOD_NOT_ALLOWED", "")
+		return
+	}
+	id := r.PathValue("id")
+	if id == "" {
+		httputil.WriteError(w, r, apperr.Validation("id required"))
+		return
+	}
+	var input domain.UpdateWebhookSubscriptionInput
+	if err := decodeInput(w, r, &input); err != nil {
+		httputil.WriteError(w, r, err)
+		return
+	}
+	sub, err := h.service.Update(r.Context(), id, input)
+	if err != nil {
+		writeError(w, r, h.log, "update webhook", err, zap.String("id", id))
+		return
+	}
+	if sub == nil {
+		httputil.WriteError(w, r, apperr.NotFound("webhook subscription not found"))
+		return
+	}
+	httputil.JSON(w, http.StatusOK, sub)
+}
+
+// Deletes a webhook subscription with its delivery log (DELETE /webhooks/{id}).
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		httputil.Error(w, http.StatusMethodNotAllowed, "method not allowed", "METHOD_NOT_ALLOWED", "")
+		return
+	}
+	id := r.PathValue("id")
+	if id == "" {
+		httputil.WriteError(w, r, apperr.Validation("id required"))
+		return
+	}
+	sub, err := h.service.Delete(r.Context(), id)
+	if err != nil {
+		writeError(w, r, h.log, "delete webhook", err, zap.String("id", id))
+		return
+	}
+	if sub == nil {
+		httputil.WriteError(w, r, apperr.NotFound("webhook subscription not found"))
+		return
+	}
+	httputil.JSON(w, http.StatusOK, sub)
+}
+
+// Gets a webhook subscription by ID (GET /webhooks/{id}).
+func (h *WebhookHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httputil.Error(w, http.StatusMethodNotAllowed, "method not allowed", "METHOD_NOT_ALLOWED", "")
+		return
+	}
+	id := r.PathValue("id")
+	if id == "" {
+		httputil.WriteError(w, r, apperr.Validation("id required"))
+		return
+	}
+	sub, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, h.log, "get webhook", err, zap.String("id", id))
+		return
+	}
+	if sub == nil {
+		httputil.WriteError(w, r, apperr.NotFound("webhook subscription not found"))
+		return
+	}
+	httputil.JSON(w, http.StatusOK, sub)
+}
+
+// Lists webhook subscriptions with pagination (GET /webhooks).
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httputil.Error(w, http.StatusMethodNotAllowed, "method not allowed", "METHOD_NOT_ALLOWED", "")
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if limit <= 0 {
+		limit = 20
+	}
+
+	list, err := h.service.List(r.Context(), limit, offset)
+	if err != nil {
+		writeError(w, r, h.log, "list webhooks", err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, list)
+}
+
+// Lists a subscription's deliveries, newest first (GET /webhooks/{id}/deliveries); ?status= filters by status.
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httputil.Error(w, http.StatusMethodNotAllowed, "method not allowed", "METHOD_NOT_ALLOWED", "")
+		return
+	}
+	id := r.PathValue("id")
+	if id == "" {
+		httputil.WriteError(w, r, apperr.Validation("id required"))
+		return
+	}
+
+	status := domain.WebhookDeliveryStatus(strings.ToUpper(r.URL.Query().Get("status")))
+	switch status {
+	case "", domain.WebhookDeliveryPending, domain.WebhookDeliverySucceeded, domain.WebhookDeliveryDead:
+	default:
+		httputil.WriteError(w, r, apperr.Validation("invalid status",
+			apperr.Field("status", "not_allowed", "must be one of PENDING, SUCCEEDED, DEAD")))
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if limit <= 0 {
+		limit = 20
+	}
+
+	list, err := h.service.ListDeliveries(r.Context(), id, status, limit, offset)
+	if err != nil {
+		writeError(w, r, h.log, "list webhook deliveries", err, zap.String("id", id))
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, list)
+}
+
+// Gets a delivery with its attempt log (GET /webhooks/{id}/deliveries/{deliveryID}).
+func (h *WebhookHandler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httputil.Error(w, http.StatusMethodNotAllowed, "method not allowed", "METHOD_NOT_ALLOWED", "")
+		return
+	}
+	id, deliveryID := r.PathValue("id"), r.PathValue("deliveryID")
+	if id == "" || deliveryID == "" {
+		httputil.WriteError(w, r, apperr.Validation("id required"))
+		return
+	}
+	delivery, err := h.service.GetDelivery(r.Context(), id, deliveryID)
+	if err != nil {
+		writeError(w, r, h.log, "get webhook delivery", err, zap.String("id", id), zap.String("delivery_id", deliveryID))
+		return
+	}
+	if delivery == nil {
+		httputil.WriteError(w, r, apperr.NotFound("webhook delivery not found"))
+		return
+	}
+	httputil.JSON(w, http.StatusOK, delivery)
+}
+
+// Queues a delivery to be sent again (POST /webhooks/{id}/deliveries/{deliveryID}/redeliver); answers 202.
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httputil.Error(w, http.StatusMethodNotAllowed, "method not allowed", "METHOD_NOT_ALLOWED", "")
+		return
+	}
+	id, deliveryID := r.PathValue("id"), r.PathValue("deliveryID")
+	if id == "" || deliveryID == "" {
+		httputil.WriteError(w, r, apperr.Validation("id required"))
+		return
+	}
+	delivery, err := h.service.Redeliver(r.Context(), id, deliveryID)
+	if err != nil {
+		writeError(w, r, h.log, "redeliver webhook", err, zap.String("id", id), zap.String("delivery_id", deliveryID))
+		return
+	}
+	if delivery == nil {
+		httputil.WriteError(w, r, apperr.NotFound("webhook delivery not found"))
+		return
+	}
+	httputil.JSON(w, http.StatusAccepted, delivery)
+}
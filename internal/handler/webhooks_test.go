@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/domain"
+	handlermocks "github.com/tucredito/backend-api/internal/handler/mocks"
+	"github.com/tucredito/backend-api/internal/service"
+	"go.uber.org/zap"
+)
+
+func TestWebhookHandler_Create(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	mockSvc := &handlermocks.MockWebhookService{}
+	mockSvc.CreateFunc = func(_ context.Context, input domain.CreateWebhookSubscriptionInput) (*domain.WebhookSubscription, error) {
+		return &domain.WebhookSubscription{ID: "wh-1", URL: input.URL, EventTypes: input.EventTypes, Secret: "whsec_generated", IsActive: true}, nil
+	}
+	h := NewWebhookHandler(mockSvc, log)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+apiVersion+"/webhooks", h.Create)
+
+	body := []byte(`{"url":"https://partner.example.com/hooks","event_types":["CreditApproved","CreditRejected"]}`)
+	req := httptest.NewRequest(http.MethodPost, "/v1/webhooks", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "/v1/webhooks/wh-1", rec.Header().Get("Location"))
+	var got domain.WebhookSubscription
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	assert.Equal(t, "whsec_generated", got.Secret)
+	assert.Equal(t, []domain.EventType{domain.EventCreditApproved, domain.EventCreditRejected}, got.EventTypes)
+}
+
+func TestWebhookHandler_Create_ValidationError(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	h := NewWebhookHandler(&handlermocks.MockWebhookService{}, log)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+apiVersion+"/webhooks", h.Create)
+
+	for _, body := range []string{
+		`{"url":"ftp://partner.example.com","event_types":["CreditApproved"]}`,
+		`{"url":"https://partner.example.com","event_types":[]}`,
+		`{"url":"https://partner.example.com","event_types":["CreditDeleted"]}`,
+		`{"url":"https://partner.example.com","event_types":["CreditApproved"],"secret":"short"}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/v1/webhooks", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+	}
+}
+
+func TestWebhookHandler_GetByID_NotFound(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	h := NewWebhookHandler(&handlermocks.MockWebhookService{}, log)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+apiVersion+"/webhooks/{id}", h.GetByID)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/webhooks/missing", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestWebhookHandler_ListDeliveries(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	mockSvc := &handlermocks.MockWebhookService{}
+	var gotStatus domain.WebhookDeliveryStatus
+	var gotLimit int
+	mockSvc.ListDeliveriesFunc = func(_ context.Context, subscriptionID string, status domain.WebhookDeliveryStatus, limit, offset int) ([]*domain.WebhookDelivery, error) {
+		if subscriptionID != "wh-1" {
+			return nil, service.ErrWebhookNotFound
+		}
+		gotStatus, gotLimit = status, limit
+		return []*domain.WebhookDelivery{{ID: "dlv-1", SubscriptionID: subscriptionID, Status: status, Attempts: 8}}, nil
+	}
+	h := NewWebhookHandler(mockSvc, log)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+apiVersion+"/webhooks/{id}/deliveries", h.ListDeliveries)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/webhooks/wh-1/deliveries?status=dead", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, domain.WebhookDeliveryDead, gotStatus)
+	assert.Equal(t, 20, gotLimit)
+	var got []domain.WebhookDelivery
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	require.Len(t, got, 1)
+	assert.Equal(t, "dlv-1", got[0].ID)
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/webhooks/wh-1/deliveries?status=LOST", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/webhooks/missing/deliveries", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestWebhookHandler_Redeliver(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	mockSvc := &handlermocks.MockWebhookService{}
+	mockSvc.RedeliverFunc = func(_ context.Context, subscriptionID, id string) (*domain.WebhookDelivery, error) {
+		if subscriptionID == "wh-1" && id == "dlv-1" {
+			return &domain.WebhookDelivery{ID: id, SubscriptionID: subscriptionID, Status: domain.WebhookDeliveryPending}, nil
+		}
+		return nil, nil
+	}
+	h := NewWebhookHandler(mockSvc, log)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+apiVersion+"/webhooks/{id}/deliveries/{deliveryID}/redeliver", h.Redeliver)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/webhooks/wh-1/deliveries/dlv-1/redeliver", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	var got domain.WebhookDelivery
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	assert.Equal(t, domain.WebhookDeliveryPending, got.Status)
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/webhooks/wh-1/deliveries/dlv-2/redeliver", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
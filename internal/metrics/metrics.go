@@ -3,133 +3,212 @@ package metrics
 import (
 	"net/http"
 	"strconv"
-	"sync"
+	"strings"
 	"time"
 )
 
 /*
    Prometheus-style in-memory metrics (no external dependency)
-   Export via /metrics endpoint
-   Counters for HTTP requests, errors, and credits created, approved, and rejected.
-   Latency histogram for HTTP requests.
+   Export via /metrics endpoint, in the Prometheus text format or OpenMetrics when the scraper asks for it
+   HTTP requests are labelled by method, matched route pattern, status code and tenant
+   (the partner bank ID, or "platform"); latency is both a fixed-bucket histogram and a p50/p95/p99 summary.
+   Counters for credits created, approved, and rejected, labelled by tenant.
+   Cache hits, misses and coalesced loads per cache (e.g. "credit").
+   Credit worker-pool queue depth, queue wait and job latency; decision engine per-rule evaluations,
+   latency and approval ratio; event publish outcomes by event type; webhook delivery attempts by event type and outcome.
+   Go runtime and connection pool stats are refreshed at scrape time (runtime.go, pools.go).
 */
 
+const (
+	textContentType        = "text/plain; version=0.0.4; charset=utf-8"
+	openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+	// Route label for requests that matched no registered pattern
+	UnmatchedRoute = "unmatched"
+)
+
 var (
-	mu sync.RWMutex
-
-	// Counters
-	httpRequestsTotal    map[string]int64
-	httpRequestsErrors   map[string]int64
-	creditsCreatedTotal  int64
-	creditsApprovedTotal int64
-	creditsRejectedTotal int64
-
-	// Latency histogram
-	httpRequestDuration map[string][]time.Duration
-	maxSamples          = 1000
+	httpRequests = register(newCounter("http_requests",
+		"HTTP requests by method, route pattern, status code and tenant.",
+		"method", "route", "status", "tenant"))
+	httpRequestDuration = register(newHistogram("http_request_duration_seconds",
+		"HTTP request latency in seconds by method, route pattern and status code.",
+		DefaultBuckets, "method", "route", "status"))
+	httpRequestDurationSummary = register(newSummary("http_request_duration_summary_seconds",
+		"HTTP request latency quantiles in seconds over the last 1000 requests per series.",
+		"method", "route", "status"))
+
+	creditsCreated  = register(newCounter("credits_created", "Credits created by owning tenant.", "tenant"))
+	creditsApproved = register(newCounter("credits_approved", "Credits approved by owning tenant.", "tenant"))
+	creditsRejected = register(newCounter("credits_rejected", "Credits rejected by owning tenant.", "tenant"))
+
+	cacheHits      = register(newCounter("cache_hits", "Cache hits, including negative entries, by cache.", "cache"))
+	cacheMisses    = register(newCounter("cache_misses", "Cache misses by cache.", "cache"))
+	cacheCoalesced = register(newCounter("cache_coalesced", "Lookups that waited on another caller's load, by cache.", "cache"))
+
+	creditJobsQueued  = register(newGauge("credit_jobs_queued", "Credit creation jobs waiting for a worker."))
+	creditWorkersBusy = register(newGauge("credit_workers_busy", "Credit workers currently running a job."))
+	creditJobWait     = register(newHistogram("credit_job_wait_seconds",
+		"Time credit creation jobs spent queued before a worker picked them up, in seconds.", DefaultBuckets))
+	creditJobDuration = register(newHistogram("credit_job_duration_seconds",
+		"Credit creation job latency in seconds by outcome (ok, error).", DefaultBuckets, "outcome"))
+
+	decisionRuleEvaluations = register(newCounter("decision_rule_evaluations",
+		"Decision rule evaluations by rule and outcome (approved, declined).", "rule", "outcome"))
+	decisionRuleDuration = register(newHistogram("decision_rule_duration_seconds",
+		"Decision rule evaluation latency in seconds by rule.", ruleBuckets, "rule"))
+	decisionEvaluations = register(newCounter("decision_evaluations",
+		"Decision engine evaluations by outcome (approved, declined).", "outcome"))
+	decisionApprovalRatio = register(newGauge("decision_approval_ratio",
+		"Share of decision engine evaluations that approved, since start."))
+
+	eventsPublished = register(newCounter("events_published",
+		"Domain events handed to the publisher by event type and outcome (success, failure).", "type", "outcome"))
+
+	webhookAttempts = register(newCounter("webhook_attempts",
+		"Webhook delivery attempts by event type and outcome (succeeded, retrying, dead).", "type", "outcome"))
 )
 
-func init() {
-	httpRequestsTotal = make(map[string]int64)
-	httpRequestsErrors = make(map[string]int64)
-	httpRequestDuration = make(map[string][]time.Duration)
+// Rule latency buckets in seconds, from 1µs to 100ms
+var ruleBuckets = []float64{0.000001, 0.00001, 0.0001, 0.001, 0.01, 0.1}
+
+// Standard request methods; anything else is reported as OTHER to keep the label bounded
+var knownMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true, http.MethodPut: true,
+	http.MethodPatch: true, http.MethodDelete: true, http.MethodOptions: true,
 }
 
-// Increments request count for method_path
-func IncHTTPRequest(methodPath string) {
-	mu.Lock()
-	defer mu.Unlock()
-	httpRequestsTotal[methodPath]++
+/*
+	Records a finished HTTP request
+	pattern is the ServeMux pattern that matched ("GET /v1/credits/{id}"), empty when none did;
+	the route label is its path part so IDs never reach the series key
+*/
+
+func ObserveHTTPRequest(method, pattern string, status int, tenant string, d time.Duration) {
+	if !knownMethods[method] {
+		method = "OTHER"
+	}
+	route := UnmatchedRoute
+	if pattern != "" {
+		route = pattern
+		if _, path, ok := strings.Cut(pattern, " "); ok {
+			route = strings.TrimSpace(path)
+		}
+	}
+	code := strconv.Itoa(status)
+	seconds := d.Seconds()
+
+	httpRequests.Inc(method, route, code, tenant)
+	httpRequestDuration.Observe(seconds, method, route, code)
+	httpRequestDurationSummary.Observe(seconds, method, route, code)
 }
 
-// Increments error count
-func IncHTTPRequestError(methodPath string) {
-	mu.Lock()
-	defer mu.Unlock()
-	httpRequestsErrors[methodPath]++
+// Increments credits created for the owning tenant
+func IncCreditsCreated(tenant string) {
+	creditsCreated.Inc(tenant)
 }
 
-// Records request duration
-func ObserveHTTPDuration(methodPath string, d time.Duration) {
-	mu.Lock()
-	defer mu.Unlock()
-	list := httpRequestDuration[methodPath]
+// Increments credits approved for the owning tenant
+func IncCreditsApproved(tenant string) {
+	creditsApproved.Inc(tenant)
+}
 
-	if len(list) >= maxSamples {
-		list = list[1:]
-	}
+// Increments credits rejected for the owning tenant
+func IncCreditsRejected(tenant string) {
+	creditsRejected.Inc(tenant)
+}
 
-	httpRequestDuration[methodPath] = append(list, d)
+// Increments cache hits (including negative entries) for the named cache
+func IncCacheHit(cache string) {
+	cacheHits.Inc(cache)
 }
 
-// Increments credits created
-func IncCreditsCreated() {
-	mu.Lock()
-	defer mu.Unlock()
-	creditsCreatedTotal++
+// Increments cache misses for the named cache
+func IncCacheMiss(cache string) {
+	cacheMisses.Inc(cache)
 }
 
-// Increments credits approved
-func IncCreditsApproved() {
-	mu.Lock()
-	defer mu.Unlock()
-	creditsApprovedTotal++
+// Increments lookups that waited on another caller's load instead of querying the database
+func IncCacheCoalesced(cache string) {
+	cacheCoalesced.Inc(cache)
 }
 
-// Increments credits rejected
-func IncCreditsRejected() {
-	mu.Lock()
-	defer mu.Unlock()
-	creditsRejectedTotal++
+// Counts a credit creation job entering the worker queue
+func IncCreditJobQueued() {
+	creditJobsQueued.Add(1)
 }
 
-// Returns a copy of all metrics for exposition
-func Snapshot() (total map[string]int64, errors map[string]int64, creditsCreated, creditsApproved, creditsRejected int64, durations map[string][]time.Duration) {
-	mu.RLock()
-	defer mu.RUnlock()
-	total = make(map[string]int64)
-	errors = make(map[string]int64)
-	durations = make(map[string][]time.Duration)
+// Records a worker picking up a job that waited wait in the queue
+func ObserveCreditJobStarted(wait time.Duration) {
+	creditJobsQueued.Add(-1)
+	creditWorkersBusy.Add(1)
+	creditJobWait.Observe(wait.Seconds())
+}
 
-	for k, v := range httpRequestsTotal {
-		total[k] = v
-	}
+// Records a finished credit creation job
+func ObserveCreditJobDone(d time.Duration, err error) {
+	creditWorkersBusy.Add(-1)
+	creditJobDuration.Observe(d.Seconds(), outcome(err, "ok", "error"))
+}
 
-	for k, v := range httpRequestsErrors {
-		errors[k] = v
+// Records one rule evaluation
+func ObserveRuleEvaluation(rule string, approved bool, d time.Duration) {
+	decisionRuleEvaluations.Inc(rule, approval(approved))
+	decisionRuleDuration.Observe(d.Seconds(), rule)
+}
+
+// Records the engine's final decision and refreshes the approval ratio
+func ObserveDecision(approved bool) {
+	decisionEvaluations.Inc(approval(approved))
+	yes := decisionEvaluations.Value("approved")
+	if total := yes + decisionEvaluations.Value("declined"); total > 0 {
+		decisionApprovalRatio.Set(yes / total)
 	}
+}
+
+// Counts a publish attempt for eventType
+func IncEventPublished(eventType string, err error) {
+	eventsPublished.Inc(eventType, outcome(err, "success", "failure"))
+}
+
+// Counts a webhook delivery attempt; outcome is the delivery's resulting state (succeeded, retrying, dead)
+func IncWebhookAttempt(eventType, outcome string) {
+	webhookAttempts.Inc(eventType, outcome)
+}
 
-	for k, v := range httpRequestDuration {
-		durations[k] = append([]time.Duration(nil), v...)
+func outcome(err error, ok, failed string) string {
+	if err != nil {
+		return failed
 	}
+	return ok
+}
 
-	return total, errors, creditsCreatedTotal, creditsApprovedTotal, creditsRejectedTotal, durations
+func approval(approved bool) string {
+	if approved {
+		return "approved"
+	}
+	return "declined"
 }
 
-// Writes Prometheus-style text format for /metrics
+// Writes all registered families for /metrics, as OpenMetrics when the Accept header asks for it
 func Handler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
 
-	total, errors, created, approved, rejected, _ := Snapshot()
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-	w.WriteHeader(http.StatusOK)
-
-	for k, v := range total {
-		_, _ = w.Write([]byte("http_requests_total{path=\"" + k + "\"} " + formatInt64(v) + "\n"))
+	openMetrics := strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text")
+	var b strings.Builder
+	for _, c := range collect() {
+		c.write(&b, openMetrics)
 	}
 
-	for k, v := range errors {
-		_, _ = w.Write([]byte("http_requests_errors_total{path=\"" + k + "\"} " + formatInt64(v) + "\n"))
+	if openMetrics {
+		b.WriteString("# EOF\n")
+		w.Header().Set("Content-Type", openMetricsContentType)
+	} else {
+		w.Header().Set("Content-Type", textContentType)
 	}
-
-	_, _ = w.Write([]byte("credits_created_total " + formatInt64(created) + "\n"))
-	_, _ = w.Write([]byte("credits_approved_total " + formatInt64(approved) + "\n"))
-	_, _ = w.Write([]byte("credits_rejected_total " + formatInt64(rejected) + "\n"))
-}
-
-func formatInt64(n int64) string {
-	return strconv.FormatInt(n, 10)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(b.String()))
 }
@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func render(c collector, openMetrics bool) string {
+	var b strings.Builder
+	c.write(&b, openMetrics)
+	return b.String()
+}
+
+func TestHistogram_CumulativeBuckets(t *testing.T) {
+	h := newHistogram("latency_seconds", "Latency.", []float64{0.1, 1}, "route")
+	h.Observe(0.05, "/a")
+	h.Observe(0.1, "/a") // bounds are inclusive
+	h.Observe(0.5, "/a")
+	h.Observe(3, "/a")
+
+	out := render(h, false)
+	assert.Contains(t, out, "# HELP latency_seconds Latency.\n# TYPE latency_seconds histogram\n")
+	assert.Contains(t, out, `latency_seconds_bucket{route="/a",le="0.1"} 2`)
+	assert.Contains(t, out, `latency_seconds_bucket{route="/a",le="1"} 3`)
+	assert.Contains(t, out, `latency_seconds_bucket{route="/a",le="+Inf"} 4`)
+	assert.Contains(t, out, `latency_seconds_sum{route="/a"} 3.65`)
+	assert.Contains(t, out, `latency_seconds_count{route="/a"} 4`)
+}
+
+func TestSummary_QuantilesOverRecentSamples(t *testing.T) {
+	s := newSummary("latency_seconds", "Latency.", "route")
+	for i := 1; i <= 100; i++ {
+		s.Observe(float64(i), "/a")
+	}
+
+	out := render(s, false)
+	assert.Contains(t, out, `latency_seconds{route="/a",quantile="0.5"} 50`)
+	assert.Contains(t, out, `latency_seconds{route="/a",quantile="0.95"} 95`)
+	assert.Contains(t, out, `latency_seconds{route="/a",quantile="0.99"} 99`)
+	assert.Contains(t, out, `latency_seconds_count{route="/a"} 100`)
+
+	// Only the last maxSamples observations feed the quantiles
+	for i := 0; i < maxSamples; i++ {
+		s.Observe(1000, "/a")
+	}
+	assert.Contains(t, render(s, false), `latency_seconds{route="/a",quantile="0.5"} 1000`)
+}
+
+func TestCounter_ExpositionFormats(t *testing.T) {
+	c := newCounter("jobs", "Jobs run.", "queue")
+	c.Add(2, `say "hi"`+"\n")
+
+	text := render(c, false)
+	assert.Contains(t, text, "# TYPE jobs_total counter\n")
+	assert.Contains(t, text, `jobs_total{queue="say \"hi\"\n"} 2`)
+
+	// OpenMetrics names the family without the suffix
+	om := render(c, true)
+	assert.Contains(t, om, "# TYPE jobs counter\n")
+	assert.Contains(t, om, `jobs_total{queue=`)
+}
+
+func TestObserveHTTPRequest_BoundedLabels(t *testing.T) {
+	ObserveHTTPRequest(http.MethodGet, "GET /v1/credits/{id}", http.StatusOK, "bank-a", 20*time.Millisecond)
+	ObserveHTTPRequest("BREW", "", http.StatusNotFound, "platform", time.Millisecond)
+
+	assert.Equal(t, 1.0, httpRequests.Value("GET", "/v1/credits/{id}", "200", "bank-a"))
+	assert.Equal(t, 1.0, httpRequests.Value("OTHER", UnmatchedRoute, "404", "platform"))
+}
+
+func TestHandler_ContentNegotiation(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Handler(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, textContentType, rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "# TYPE http_requests_total counter")
+	assert.NotContains(t, rec.Body.String(), "# EOF")
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text;version=1.0.0,text/plain;q=0.5")
+	rec = httptest.NewRecorder()
+	Handler(rec, req)
+	assert.Equal(t, openMetricsContentType, rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "# TYPE http_requests counter")
+	assert.True(t, strings.HasSuffix(rec.Body.String(), "# EOF\n"))
+}
+
+func TestCollect_RuntimeAndPoolSources(t *testing.T) {
+	SetDBPoolSource(func() DBPoolStats {
+		return DBPoolStats{Acquired: 3, Idle: 2, Total: 5, Max: 10, WaitCount: 7, WaitDuration: 1500 * time.Millisecond}
+	})
+	defer SetDBPoolSource(nil)
+
+	rec := httptest.NewRecorder()
+	Handler(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	assert.Contains(t, body, `db_pool_connections{state="acquired"} 3`)
+	assert.Contains(t, body, `db_pool_connections{state="max"} 10`)
+	assert.Contains(t, body, "db_pool_wait_total 7\n")
+	assert.Contains(t, body, "db_pool_acquire_seconds_total 1.5\n")
+	assert.Contains(t, body, "# TYPE go_goroutines gauge")
+	assert.Greater(t, goGoroutines.Value(), 0.0)
+	assert.Greater(t, goHeapAlloc.Value(), 0.0)
+}
+
+func TestObserveDecision_ApprovalRatio(t *testing.T) {
+	before := decisionEvaluations.Value("approved") + decisionEvaluations.Value("declined")
+	require.Zero(t, before, "no other test observes decisions")
+
+	ObserveDecision(true)
+	ObserveDecision(true)
+	ObserveDecision(true)
+	ObserveDecision(false)
+	assert.Equal(t, 0.75, decisionApprovalRatio.Value())
+}
+
+func TestCreditJobGauges(t *testing.T) {
+	IncCreditJobQueued()
+	IncCreditJobQueued()
+	ObserveCreditJobStarted(10 * time.Millisecond)
+	assert.Equal(t, 1.0, creditJobsQueued.Value())
+	assert.Equal(t, 1.0, creditWorkersBusy.Value())
+
+	ObserveCreditJobDone(20*time.Millisecond, nil)
+	assert.Equal(t, 0.0, creditWorkersBusy.Value())
+}
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+/*
+	Connection pool stats are read from the pools at scrape time through sources set by the server
+	The sources keep this package free of database and Redis client imports
+*/
+
+// Database pool stats (pgxpool.Stat)
+type DBPoolStats struct {
+	Acquired     int32
+	Idle         int32
+	Total        int32
+	Max          int32
+	WaitCount    int64         // acquires that had to wait for a connection
+	WaitDuration time.Duration // cumulative time spent acquiring connections
+}
+
+// Redis client pool stats (redis.PoolStats)
+type RedisPoolStats struct {
+	Hits     uint32
+	Misses   uint32
+	Timeouts uint32
+	Total    uint32
+	Idle     uint32
+	Stale    uint32
+}
+
+var (
+	dbPoolSource    atomic.Pointer[func() DBPoolStats]
+	redisPoolSource atomic.Pointer[func() RedisPoolStats]
+)
+
+var (
+	dbPoolConns = register(newGauge("db_pool_connections",
+		"Database pool connections by state (acquired, idle, total, max).", "state"))
+	dbPoolWaits = register(newCounter("db_pool_wait",
+		"Database connection acquires that had to wait because the pool was empty."))
+	dbPoolWaitSeconds = register(newCounter("db_pool_acquire_seconds",
+		"Cumulative time spent acquiring database connections, in seconds."))
+
+	redisPoolConns = register(newGauge("redis_pool_connections",
+		"Redis client pool connections by state (idle, total).", "state"))
+	redisPoolEvents = register(newCounter("redis_pool_events",
+		"Redis client pool lookups by result (hit, miss, timeout) and stale connections removed (stale).", "event"))
+)
+
+func init() {
+	onScrape(collectPools)
+}
+
+// Sets the database pool read at scrape time (nil stops reporting it)
+func SetDBPoolSource(fn func() DBPoolStats) {
+	if fn == nil {
+		dbPoolSource.Store(nil)
+		return
+	}
+	dbPoolSource.Store(&fn)
+}
+
+// Sets the Redis client pool read at scrape time (nil stops reporting it)
+func SetRedisPoolSource(fn func() RedisPoolStats) {
+	if fn == nil {
+		redisPoolSource.Store(nil)
+		return
+	}
+	redisPoolSource.Store(&fn)
+}
+
+func collectPools() {
+	if fn := dbPoolSource.Load(); fn != nil {
+		st := (*fn)()
+		dbPoolConns.Set(float64(st.Acquired), "acquired")
+		dbPoolConns.Set(float64(st.Idle), "idle")
+		dbPoolConns.Set(float64(st.Total), "total")
+		dbPoolConns.Set(float64(st.Max), "max")
+		dbPoolWaits.set(float64(st.WaitCount))
+		dbPoolWaitSeconds.set(st.WaitDuration.Seconds())
+	}
+	if fn := redisPoolSource.Load(); fn != nil {
+		st := (*fn)()
+		redisPoolConns.Set(float64(st.Idle), "idle")
+		redisPoolConns.Set(float64(st.Total), "total")
+		redisPoolEvents.set(float64(st.Hits), "hit")
+		redisPoolEvents.set(float64(st.Misses), "miss")
+		redisPoolEvents.set(float64(st.Timeouts), "timeout")
+		redisPoolEvents.set(float64(st.Stale), "stale")
+	}
+}
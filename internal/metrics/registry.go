@@ -0,0 +1,364 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*
+	Minimal metric families for the Prometheus text and OpenMetrics expositions
+	A family has a fixed set of label names; each distinct set of label values is one series
+	Callers must keep label values bounded (route patterns, not raw paths)
+*/
+
+// Metric types as written in # TYPE lines
+const (
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+	typeSummary   = "summary"
+)
+
+// Latency buckets in seconds, from 5ms to 10s
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Quantiles reported by summaries
+var summaryQuantiles = []float64{0.5, 0.95, 0.99}
+
+// Observations kept per summary series for quantile estimation
+const maxSamples = 1000
+
+// A metric family that can write itself in either exposition format
+type collector interface {
+	write(b *strings.Builder, openMetrics bool)
+}
+
+var (
+	registryMu  sync.RWMutex
+	registry    []collector
+	scrapeHooks []func()
+)
+
+// Adds c to the families served by Handler, in registration order
+func register[C collector](c C) C {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, c)
+	return c
+}
+
+// Runs fn before every scrape, to refresh values read from elsewhere (runtime, connection pools)
+func onScrape(fn func()) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	scrapeHooks = append(scrapeHooks, fn)
+}
+
+// Runs the scrape hooks and returns the registered families
+func collect() []collector {
+	registryMu.RLock()
+	hooks := append([]func(){}, scrapeHooks...)
+	families := append([]collector(nil), registry...)
+	registryMu.RUnlock()
+
+	for _, fn := range hooks {
+		fn()
+	}
+	return families
+}
+
+type family struct {
+	name   string
+	help   string
+	typ    string
+	labels []string
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	values []string
+
+	// Counter and gauge value
+	value float64
+
+	// Histogram bucket counts (non-cumulative, one per bound) and summary sample ring
+	counts  []uint64
+	samples []float64
+	next    int
+
+	sum   float64
+	count uint64
+}
+
+func newFamily(name, help, typ string, labels []string) *family {
+	return &family{name: name, help: help, typ: typ, labels: labels, series: make(map[string]*series)}
+}
+
+// Returns the series for values, creating it; must be called with f.mu held
+func (f *family) get(values []string) *series {
+	if len(values) != len(f.labels) {
+		panic("metrics: " + f.name + " expects " + strconv.Itoa(len(f.labels)) + " label values")
+	}
+	key := strings.Join(values, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{values: append([]string(nil), values...)}
+		f.series[key] = s
+	}
+	return s
+}
+
+// Returns the series sorted by label values, for stable output
+func (f *family) sorted() []*series {
+	out := make([]*series, 0, len(f.series))
+	for _, s := range f.series {
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i].values, out[j].values
+		for k := range a {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return false
+	})
+	return out
+}
+
+// Writes the # HELP and # TYPE lines
+func (f *family) writeHeader(b *strings.Builder, name string) {
+	b.WriteString("# HELP " + name + " " + escapeHelp(f.help) + "\n")
+	b.WriteString("# TYPE " + name + " " + f.typ + "\n")
+}
+
+// Writes one sample line; extra is an additional label pair such as le or quantile
+func (f *family) writeSample(b *strings.Builder, name string, values []string, extraName, extraValue, value string) {
+	b.WriteString(name)
+	if len(values) > 0 || extraName != "" {
+		b.WriteByte('{')
+		for i, l := range f.labels {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(l + `="` + escapeLabel(values[i]) + `"`)
+		}
+		if extraName != "" {
+			if len(values) > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(extraName + `="` + extraValue + `"`)
+		}
+		b.WriteByte('}')
+	}
+	b.WriteString(" " + value + "\n")
+}
+
+// A monotonically increasing count; name is given without the _total suffix
+type Counter struct{ *family }
+
+func newCounter(name, help string, labels ...string) *Counter {
+	return &Counter{newFamily(name, help, typeCounter, labels)}
+}
+
+// Adds one to the series for values
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Adds v (which must not be negative) to the series for values
+func (c *Counter) Add(v float64, values ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.get(values).value += v
+}
+
+// Sets the series for values to a cumulative count kept elsewhere (e.g. a pool's own counters)
+func (c *Counter) set(v float64, values ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.get(values).value = v
+}
+
+// Returns the current value of the series for values
+func (c *Counter) Value(values ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.get(values).value
+}
+
+/*
+	The text format names the family after its sample (foo_total)
+	OpenMetrics names the family foo and only the sample carries the suffix
+*/
+
+func (c *Counter) write(b *strings.Builder, openMetrics bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sample := c.name + "_total"
+	if openMetrics {
+		c.writeHeader(b, c.name)
+	} else {
+		c.writeHeader(b, sample)
+	}
+	for _, s := range c.sorted() {
+		c.writeSample(b, sample, s.values, "", "", formatFloat(s.value))
+	}
+}
+
+// A value that can go up and down
+type Gauge struct{ *family }
+
+func newGauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{newFamily(name, help, typeGauge, labels)}
+}
+
+// Sets the series for values to v
+func (g *Gauge) Set(v float64, values ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.get(values).value = v
+}
+
+// Adds v (possibly negative) to the series for values
+func (g *Gauge) Add(v float64, values ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.get(values).value += v
+}
+
+// Returns the current value of the series for values
+func (g *Gauge) Value(values ...string) float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.get(values).value
+}
+
+func (g *Gauge) write(b *strings.Builder, _ bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.writeHeader(b, g.name)
+	for _, s := range g.sorted() {
+		g.writeSample(b, g.name, s.values, "", "", formatFloat(s.value))
+	}
+}
+
+// Observations counted into fixed upper bounds
+type Histogram struct {
+	*family
+	buckets []float64
+}
+
+func newHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return &Histogram{family: newFamily(name, help, typeHistogram, labels), buckets: buckets}
+}
+
+// Records v in the series for values
+func (h *Histogram) Observe(v float64, values ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := h.get(values)
+	if s.counts == nil {
+		s.counts = make([]uint64, len(h.buckets))
+	}
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.sum += v
+	s.count++
+}
+
+func (h *Histogram) write(b *strings.Builder, _ bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.writeHeader(b, h.name)
+	for _, s := range h.sorted() {
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			h.writeSample(b, h.name+"_bucket", s.values, "le", formatFloat(bound), formatUint(cumulative))
+		}
+		h.writeSample(b, h.name+"_bucket", s.values, "le", "+Inf", formatUint(s.count))
+		h.writeSample(b, h.name+"_sum", s.values, "", "", formatFloat(s.sum))
+		h.writeSample(b, h.name+"_count", s.values, "", "", formatUint(s.count))
+	}
+}
+
+// Quantiles over the most recent maxSamples observations; sum and count cover all of them
+type Summary struct{ *family }
+
+func newSummary(name, help string, labels ...string) *Summary {
+	return &Summary{newFamily(name, help, typeSummary, labels)}
+}
+
+// Records v in the series for values
+func (m *Summary) Observe(v float64, values ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.get(values)
+	if len(s.samples) < maxSamples {
+		s.samples = append(s.samples, v)
+	} else {
+		s.samples[s.next] = v
+		s.next = (s.next + 1) % maxSamples
+	}
+	s.sum += v
+	s.count++
+}
+
+func (m *Summary) write(b *strings.Builder, _ bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.writeHeader(b, m.name)
+	for _, s := range m.sorted() {
+		sorted := append([]float64(nil), s.samples...)
+		sort.Float64s(sorted)
+		for _, q := range summaryQuantiles {
+			m.writeSample(b, m.name, s.values, "quantile", formatFloat(q), formatFloat(quantile(sorted, q)))
+		}
+		m.writeSample(b, m.name+"_sum", s.values, "", "", formatFloat(s.sum))
+		m.writeSample(b, m.name+"_count", s.values, "", "", formatUint(s.count))
+	}
+}
+
+// Returns the nearest-rank q-quantile of sorted, NaN when empty
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	i := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func formatUint(n uint64) string {
+	return strconv.FormatUint(n, 10)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+
+func escapeHelp(s string) string { return helpEscaper.Replace(s) }
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// GC pause buckets in seconds, from 10µs to 100ms
+var gcPauseBuckets = []float64{0.00001, 0.00005, 0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1}
+
+var (
+	goGoroutines = register(newGauge("go_goroutines", "Number of goroutines that currently exist."))
+	goHeapAlloc  = register(newGauge("go_memstats_heap_alloc_bytes", "Heap bytes allocated and still in use."))
+	goHeapInuse  = register(newGauge("go_memstats_heap_inuse_bytes", "Heap bytes in in-use spans."))
+	goHeapObjs   = register(newGauge("go_memstats_heap_objects", "Number of allocated heap objects."))
+	goNextGC     = register(newGauge("go_memstats_next_gc_bytes", "Heap size target of the next GC cycle."))
+	goGCCycles   = register(newCounter("go_gc_cycles", "Completed GC cycles."))
+	goGCPauses   = register(newHistogram("go_gc_pause_seconds",
+		"Stop-the-world GC pause durations in seconds, recorded at scrape time.", gcPauseBuckets))
+)
+
+// The GC cycle count at the previous scrape; pauses after it are new
+var (
+	gcMu     sync.Mutex
+	gcLastNo uint32
+)
+
+func init() {
+	onScrape(collectRuntime)
+}
+
+/*
+	Refreshes the runtime gauges and records the GC pauses since the previous scrape
+	MemStats keeps the last 256 pauses, so a scrape interval spanning more cycles undercounts the histogram
+	(go_gc_cycles_total stays exact)
+*/
+
+func collectRuntime() {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	goGoroutines.Set(float64(runtime.NumGoroutine()))
+	goHeapAlloc.Set(float64(ms.HeapAlloc))
+	goHeapInuse.Set(float64(ms.HeapInuse))
+	goHeapObjs.Set(float64(ms.HeapObjects))
+	goNextGC.Set(float64(ms.NextGC))
+	goGCCycles.set(float64(ms.NumGC))
+
+	gcMu.Lock()
+	defer gcMu.Unlock()
+	first := gcLastNo + 1
+	if ms.NumGC-gcLastNo > 256 {
+		first = ms.NumGC - 255
+	}
+	for n := first; n <= ms.NumGC; n++ {
+		pause := time.Duration(ms.PauseNs[(n+255)%256])
+		goGCPauses.Observe(pause.Seconds())
+	}
+	gcLastNo = ms.NumGC
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/tucredito/backend-api/internal/audit"
+	"github.com/tucredito/backend-api/internal/auth"
+	"github.com/tucredito/backend-api/internal/clientip"
+)
+
+// Actor recorded for requests without an authenticated principal
+const anonymousActor = "anonymous"
+
+// Attaches the actor (the authenticated principal) and client IP to the context so audited mutations can record them
+// The request ID is attached earlier by RequestID
+func Audit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor := anonymousActor
+		if p := auth.PrincipalFrom(r.Context()); p != nil {
+			actor = p.Subject
+		}
+		ctx := audit.WithActor(r.Context(), actor)
+		ctx = audit.WithClientIP(ctx, clientip.String(ctx))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
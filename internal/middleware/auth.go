@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/tucredito/backend-api/internal/auth"
+	"github.com/tucredito/backend-api/internal/tenant"
+	"github.com/tucredito/backend-api/pkg/httputil"
+)
+
+type authErrorKey struct{}
+
+/*
+	Authenticate resolves the caller from the Authorization or X-API-Key header
+	It never rejects a request itself: the principal (or the authentication error) is
+	stored in the context and RequireRole decides per route, so public routes stay open
+	and rejected requests are still logged
+	Principals bound to a bank also scope the context to that tenant (see internal/tenant)
+	A nil authenticator disables authentication: every caller is treated as a platform admin
+*/
+
+func Authenticate(a *auth.Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			if a == nil {
+				ctx = auth.WithPrincipal(ctx, &auth.Principal{Subject: "anonymous", Role: auth.RoleAdmin, Tenant: tenant.Platform})
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+			p, err := a.Authenticate(r)
+			if err != nil {
+				ctx = context.WithValue(ctx, authErrorKey{}, err)
+			} else {
+				ctx = tenant.WithBank(auth.WithPrincipal(ctx, p), p.Tenant)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Rejects requests from principals bound to a bank (403); used for platform-wide administration
+func RequirePlatform(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, scoped := tenant.BankID(r.Context()); scoped {
+			httputil.Error(w, http.StatusForbidden, "platform principal required", "FORBIDDEN", "bank-bound principals cannot perform this operation")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Rejects requests without a principal (401) or whose role does not include role (403)
+func RequireRole(role auth.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p := auth.PrincipalFrom(r.Context())
+			if p == nil {
+				details := ""
+				if err, _ := r.Context().Value(authErrorKey{}).(error); err != nil && !errors.Is(err, auth.ErrMissingCredentials) {
+					details = err.Error()
+				}
+				w.Header().Set("WWW-Authenticate", `Bearer realm="tucredito"`)
+				httputil.Error(w, http.StatusUnauthorized, "authentication required", "UNAUTHORIZED", details)
+				return
+			}
+			if !p.Role.Includes(role) {
+				httputil.Error(w, http.StatusForbidden, "insufficient role", "FORBIDDEN", "requires "+string(role))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
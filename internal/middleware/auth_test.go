@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/audit"
+	"github.com/tucredito/backend-api/internal/auth"
+	"github.com/tucredito/backend-api/internal/tenant"
+	"github.com/tucredito/backend-api/pkg/httputil"
+)
+
+func TestAuthenticateAndRequireRole(t *testing.T) {
+	keys, err := auth.ParseAPIKeys("reporting:analyst:analyst-key,ops:admin:admin-key")
+	require.NoError(t, err)
+	authenticator := auth.NewAuthenticator(auth.NewJWTVerifier(auth.JWTConfig{}), keys)
+
+	var actor string
+	protected := RequireRole(auth.RoleUnderwriter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor = audit.Actor(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	h := Authenticate(authenticator)(Audit(protected))
+
+	cases := []struct {
+		name   string
+		header string
+		value  string
+		status int
+		code   string
+	}{
+		{"missing", "", "", http.StatusUnauthorized, "UNAUTHORIZED"},
+		{"bad key", auth.APIKeyHeader, "nope", http.StatusUnauthorized, "UNAUTHORIZED"},
+		{"bad bearer", "Authorization", "Bearer not.a.jwt", http.StatusUnauthorized, "UNAUTHORIZED"},
+		{"insufficient role", auth.APIKeyHeader, "analyst-key", http.StatusForbidden, "FORBIDDEN"},
+		{"allowed", auth.APIKeyHeader, "admin-key", http.StatusNoContent, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/v1/credits/cr1", nil)
+			if tc.header != "" {
+				req.Header.Set(tc.header, tc.value)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			require.Equal(t, tc.status, rec.Code)
+			if tc.code != "" {
+				var body httputil.Problem
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				assert.Equal(t, tc.code, body.Code)
+				assert.Equal(t, http.StatusText(tc.status), body.Title, "the title is the status text")
+				assert.NotEmpty(t, body.Detail)
+			}
+		})
+	}
+	assert.Equal(t, "ops", actor)
+}
+
+func TestAuthenticate_Disabled(t *testing.T) {
+	h := Authenticate(nil)(RequireRole(auth.RoleAdmin)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/v1/banks/b1", nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestAuthenticate_ScopesBankPrincipals(t *testing.T) {
+	keys, err := auth.ParseAPIKeys("bank-a-ops@bank-a:admin:bank-key,ops:admin:platform-key")
+	require.NoError(t, err)
+	authenticator := auth.NewAuthenticator(nil, keys)
+
+	var scope string
+	h := Authenticate(authenticator)(RequireRole(auth.RoleAdmin)(RequirePlatform(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope = tenant.Label(r.Context())
+		w.WriteHeader(http.StatusCreated)
+	}))))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/banks", nil)
+	req.Header.Set(auth.APIKeyHeader, "bank-key")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/banks", nil)
+	req.Header.Set(auth.APIKeyHeader, "platform-key")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, tenant.Platform, scope)
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/tucredito/backend-api/internal/clientip"
+	"github.com/tucredito/backend-api/pkg/httputil"
+	"go.uber.org/zap"
+)
+
+/*
+	ClientIP resolves the caller's address once (see clientip.Resolver) and stores it in the context for
+	rate limiting, logging and the audit trail
+	Addresses in deny are rejected; when allow is not empty only addresses in it are accepted
+*/
+
+func ClientIP(res *clientip.Resolver, allow, deny clientip.Prefixes) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			addr := res.Resolve(r)
+			r = withLogFields(r, zap.String("client_ip", addr.String()))
+			if deny.Contains(addr) || len(allow) > 0 && !allow.Contains(addr) {
+				httputil.Error(w, http.StatusForbidden, "client address not allowed", "IP_FORBIDDEN", "")
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(clientip.WithIP(r.Context(), addr)))
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/clientip"
+	"github.com/tucredito/backend-api/internal/ratelimit"
+)
+
+func TestClientIP_AllowDeny(t *testing.T) {
+	trusted, err := clientip.ParsePrefixes("10.0.0.0/8")
+	require.NoError(t, err)
+	allow, err := clientip.ParsePrefixes("198.51.100.0/24")
+	require.NoError(t, err)
+	deny, err := clientip.ParsePrefixes("198.51.100.66")
+	require.NoError(t, err)
+
+	var seen string
+	h := ClientIP(clientip.NewResolver(trusted, clientip.HeaderXForwardedFor), allow, deny)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = clientip.String(r.Context())
+	}))
+	do := func(xff string) int {
+		req := httptest.NewRequest(http.MethodGet, "/v1/credits", nil)
+		req.RemoteAddr = "10.0.0.1:443"
+		req.Header.Set("X-Forwarded-For", xff)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, do("198.51.100.7"))
+	assert.Equal(t, "198.51.100.7", seen)
+	assert.Equal(t, http.StatusForbidden, do("198.51.100.66"))
+	assert.Equal(t, http.StatusForbidden, do("203.0.113.1"))
+}
+
+func TestRateLimit_RotatingForwardedForDoesNotBypass(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	limited := RateLimit(ratelimit.NewMemoryLimiter(), ratelimit.Policies{Default: ratelimit.Policy{Limit: 2, Window: time.Minute}})(ok)
+	h := ClientIP(clientip.NewResolver(nil, clientip.HeaderXForwardedFor), nil, nil)(limited)
+
+	codes := make([]int, 0, 3)
+	for _, spoofed := range []string{"1.1.1.1", "2.2.2.2", "3.3.3.3"} {
+		req := httptest.NewRequest(http.MethodGet, "/v1/credits", nil)
+		req.RemoteAddr = "203.0.113.9:5555"
+		req.Header.Set("X-Forwarded-For", spoofed)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		codes = append(codes, rec.Code)
+	}
+	assert.Equal(t, []int{http.StatusNoContent, http.StatusNoContent, http.StatusTooManyRequests}, codes)
+}
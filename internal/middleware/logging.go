@@ -1,9 +1,14 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"time"
 
+	"github.com/tucredito/backend-api/internal/audit"
+	"github.com/tucredito/backend-api/internal/auth"
+	"github.com/tucredito/backend-api/internal/tracing"
+	"github.com/tucredito/backend-api/pkg/logger"
 	"go.uber.org/zap"
 )
 
@@ -25,20 +30,76 @@ func (w *responseWriter) Write(b []byte) (int, error) {
 	return n, err
 }
 
-// Logging logs each request with method, path, status, duration, and size.
-func Logging(log *zap.Logger) func(http.Handler) http.Handler {
+// Exposes the underlying writer to http.ResponseController (deadlines, flushing)
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+/*
+	Logging attaches a request-scoped logger (logger.FromContext) carrying the request ID and route pattern,
+	then logs the request with method, path, status, duration and size
+	It is mounted outside the middleware that can reject a request (client IP lists, rate limits), so those
+	responses are logged too; fields those layers learn later (client IP, trace ID, principal) are added to
+	both the scoped logger and the request line through withLogFields (see LogContext)
+*/
+
+func Logging(log *zap.Logger, routes *http.ServeMux) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
+			ctx := r.Context()
+			_, pattern := routes.Handler(r)
+			reqLog := log.With(zap.String("request_id", audit.RequestID(ctx)), zap.String("route", pattern))
+			entry := &accessLog{}
+			ctx = context.WithValue(logger.WithContext(ctx, reqLog), accessLogKey{}, entry)
+
 			wrapped := &responseWriter{ResponseWriter: w, status: http.StatusOK}
-			next.ServeHTTP(wrapped, r)
-			log.Info("request",
+			next.ServeHTTP(wrapped, r.WithContext(ctx))
+			reqLog.Info("request", append(entry.fields,
 				zap.String("method", r.Method),
 				zap.String("path", r.URL.Path),
 				zap.Int("status", wrapped.status),
 				zap.Duration("duration", time.Since(start)),
 				zap.Int("size", wrapped.size),
-			)
+			)...)
 		})
 	}
 }
+
+// LogContext adds the trace ID and authenticated principal to the request's logs; mount it inside
+// Tracing and Authenticate
+func LogContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		var fields []zap.Field
+		if sc := tracing.SpanContextFrom(ctx); sc.IsValid() {
+			fields = append(fields, zap.String("trace_id", sc.TraceID.String()))
+		}
+		if p := auth.PrincipalFrom(ctx); p != nil {
+			fields = append(fields, zap.String("principal", p.Subject), zap.String("role", string(p.Role)))
+		}
+		next.ServeHTTP(w, withLogFields(r, fields...))
+	})
+}
+
+// Fields an inner middleware adds to the request line written by Logging
+type accessLog struct {
+	fields []zap.Field
+}
+
+type accessLogKey struct{}
+
+// Adds fields to r's scoped logger and to its request line; without Logging in front only the logger changes
+func withLogFields(r *http.Request, fields ...zap.Field) *http.Request {
+	if len(fields) == 0 {
+		return r
+	}
+	ctx := r.Context()
+	if entry, ok := ctx.Value(accessLogKey{}).(*accessLog); ok {
+		entry.fields = append(entry.fields, fields...)
+	}
+	if l := logger.FromContext(ctx, nil); l != nil {
+		ctx = logger.WithContext(ctx, l.With(fields...))
+	}
+	return r.WithContext(ctx)
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/tucredito/backend-api/internal/metrics"
+	"github.com/tucredito/backend-api/internal/tenant"
+)
+
+/*
+	Metrics records each request's count and latency labelled by method, status and the route pattern it matched
+	The pattern is looked up on routes (the application mux) before serving, because the router runs further
+	down the chain on a copy of the request; raw paths are never used as labels
+*/
+
+func Metrics(routes *http.ServeMux) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			_, pattern := routes.Handler(r)
+			wrapped := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(wrapped, r)
+			metrics.ObserveHTTPRequest(r.Method, pattern, wrapped.status, tenant.Label(r.Context()), time.Since(start))
+		})
+	}
+}
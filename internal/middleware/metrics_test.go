@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tucredito/backend-api/internal/metrics"
+)
+
+func TestMetrics_LabelsByRoutePattern(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/things/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	h := Metrics(mux)(mux)
+
+	for _, path := range []string{"/v1/things/3f2c1a", "/v1/things/9b7e4d", "/nowhere"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.Handler(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/v1/things/{id}",status="418",tenant="platform"} 2`)
+	assert.Contains(t, body, `http_requests_total{method="GET",route="unmatched",status="404",tenant="platform"} 1`)
+	assert.NotContains(t, body, "3f2c1a")
+}
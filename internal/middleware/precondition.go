@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/tucredito/backend-api/pkg/apperr"
+	"github.com/tucredito/backend-api/pkg/httputil"
+)
+
+// Rejects writes without If-Match (428) so clients cannot overwrite changes they have not seen
+func RequireIfMatch(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Match") == "" {
+			httputil.WriteError(w, r, apperr.PreconditionRequired("If-Match is required; send the ETag from a previous GET"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
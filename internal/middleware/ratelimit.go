@@ -1,55 +1,75 @@
 package middleware
 
 import (
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
-	"github.com/tucredito/backend-api/internal/cache"
+	"github.com/tucredito/backend-api/internal/auth"
+	"github.com/tucredito/backend-api/internal/clientip"
+	"github.com/tucredito/backend-api/internal/ratelimit"
+	"github.com/tucredito/backend-api/internal/tenant"
 	"github.com/tucredito/backend-api/pkg/httputil"
 )
 
 const (
-	rateLimitWindowSec = 60
+	rateLimitWindow    = 60 * time.Second
 	rateLimitMaxReq    = 100
 	rateLimitKeyPrefix = "ratelimit:"
 )
 
-// RateLimit limits requests per client
-func RateLimit(c cache.Cache, maxReq int, windowSec int) func(http.Handler) http.Handler {
-	// Validate the max requests and window seconds
-	if maxReq <= 0 {
-		maxReq = rateLimitMaxReq
+/*
+	RateLimit applies token-bucket limits and reports them in RateLimit-Limit/Remaining/Reset headers
+	(Retry-After on 429)
+	Each caller has one budget: bank-bound principals share one per tenant (all of the bank's keys and
+	tokens), other authenticated principals have one per subject and anonymous callers one per client IP
+	(resolved by ClientIP).
+	Tenant and principal overrides replace the default limit. A route with its own policy also draws
+	from a separate per-caller bucket for that route, and the tighter of the two is reported
+*/
+
+func RateLimit(l ratelimit.Limiter, p ratelimit.Policies) func(http.Handler) http.Handler {
+	if p.Default.Limit <= 0 {
+		p.Default.Limit = rateLimitMaxReq
+	}
+	if p.Default.Window <= 0 {
+		p.Default.Window = rateLimitWindow
 	}
 
-	if windowSec <= 0 {
-		windowSec = rateLimitWindowSec
+	// Patterns are matched with a private mux because the router has not run yet
+	routes := http.NewServeMux()
+	for pattern := range p.Routes {
+		routes.Handle(pattern, http.NotFoundHandler())
 	}
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if c == nil {
+			if l == nil {
 				next.ServeHTTP(w, r)
 				return
 			}
 
-			clientID := r.RemoteAddr
-			if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-				clientID = xff
-			}
-
-			key := rateLimitKeyPrefix + clientID
 			ctx := r.Context()
-			n, err := c.Incr(ctx, key)
-
+			caller, policy := rateLimitCaller(r, p)
+			res, err := l.Allow(ctx, rateLimitKeyPrefix+caller, policy)
 			if err != nil {
 				next.ServeHTTP(w, r)
 				return
 			}
-
-			if n == 1 {
-				_ = c.Expire(ctx, key, windowSec)
+			if _, pattern := routes.Handler(r); pattern != "" && res.Allowed {
+				routeRes, err := l.Allow(ctx, rateLimitKeyPrefix+"route:"+pattern+":"+caller, p.Routes[pattern])
+				if err == nil && (!routeRes.Allowed || routeRes.Remaining < res.Remaining) {
+					res = routeRes
+				}
 			}
 
-			if n > int64(maxReq) {
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("RateLimit-Reset", ceilSeconds(res.Reset))
+			if !res.Allowed {
+				h.Set("Retry-After", ceilSeconds(res.RetryAfter))
 				httputil.Error(w, http.StatusTooManyRequests, "rate limit exceeded", "RATE_LIMIT", "")
 				return
 			}
@@ -57,3 +77,23 @@ func RateLimit(c cache.Cache, maxReq int, windowSec int) func(http.Handler) http
 		})
 	}
 }
+
+// Returns the bucket key suffix and budget of the caller
+func rateLimitCaller(r *http.Request, p ratelimit.Policies) (string, ratelimit.Policy) {
+	ctx := r.Context()
+	if bankID, ok := tenant.BankID(ctx); ok {
+		return "tenant:" + bankID, p.Override(p.Tenants, bankID)
+	}
+	if pr := auth.PrincipalFrom(ctx); pr != nil && pr.Method != "" {
+		return "principal:" + pr.Subject, p.Override(p.Principals, pr.Subject)
+	}
+	ip := clientip.String(ctx)
+	if ip == "" {
+		ip = r.RemoteAddr
+	}
+	return "ip:" + ip, p.Default
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/auth"
+	"github.com/tucredito/backend-api/internal/ratelimit"
+	"github.com/tucredito/backend-api/internal/tenant"
+)
+
+func TestRateLimit_PerTenant(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	h := RateLimit(ratelimit.NewMemoryLimiter(), ratelimit.Policies{
+		Default: ratelimit.Policy{Limit: 2, Window: time.Minute},
+		Tenants: map[string]int{"bank-big": 3},
+	})(ok)
+
+	do := func(bankID, remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodGet, "/v1/credits", nil)
+		req.RemoteAddr = remoteAddr
+		req = req.WithContext(tenant.WithBank(req.Context(), bankID))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Every credential of a bank draws from the same budget, whatever the client IP
+	assert.Equal(t, http.StatusNoContent, do("bank-a", "10.0.0.1:1"))
+	assert.Equal(t, http.StatusNoContent, do("bank-a", "10.0.0.2:1"))
+	assert.Equal(t, http.StatusTooManyRequests, do("bank-a", "10.0.0.3:1"))
+
+	// Another tenant is unaffected, and overrides apply per bank
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusNoContent, do("bank-big", "10.0.0.1:1"))
+	}
+	assert.Equal(t, http.StatusTooManyRequests, do("bank-big", "10.0.0.1:1"))
+
+	// Platform requests are limited per IP
+	assert.Equal(t, http.StatusNoContent, do(tenant.Platform, "10.0.0.9:1"))
+}
+
+func TestRateLimit_HeadersAndRoutePolicy(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	h := RateLimit(ratelimit.NewMemoryLimiter(), ratelimit.Policies{
+		Default:    ratelimit.Policy{Limit: 10, Window: time.Minute},
+		Principals: map[string]int{"batch-job": 50},
+		Routes:     map[string]ratelimit.Policy{"POST /v1/credits": {Limit: 1, Window: time.Minute}},
+	})(ok)
+
+	do := func(method, subject string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/v1/credits", nil)
+		req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Subject: subject, Role: auth.RoleUnderwriter, Method: auth.MethodAPIKey}))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodGet, "alice")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "10", rec.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "9", rec.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "6", rec.Header().Get("RateLimit-Reset"))
+
+	// The stricter route budget applies to POST only and is reported instead of the caller budget
+	rec = do(http.MethodPost, "alice")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+	rec = do(http.MethodPost, "alice")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusNoContent, do(http.MethodGet, "alice").Code)
+
+	// Budgets are per principal, with overrides
+	assert.Equal(t, http.StatusNoContent, do(http.MethodPost, "bob").Code)
+	assert.Equal(t, "50", do(http.MethodGet, "batch-job").Header().Get("RateLimit-Limit"))
+}
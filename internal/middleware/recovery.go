@@ -3,18 +3,20 @@ package middleware
 import (
 	"net/http"
 
+	"github.com/tucredito/backend-api/internal/audit"
+	"github.com/tucredito/backend-api/pkg/httputil"
 	"go.uber.org/zap"
 )
 
-// Recovers from panics and returns 500
+// Recovers from panics and returns 500 with the request ID
 func Recovery(log *zap.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
 				if err := recover(); err != nil {
-					log.Error("panic recovered", zap.Any("panic", err))
-					w.WriteHeader(http.StatusInternalServerError)
-					_, _ = w.Write([]byte(`{"error":"internal server error"}`))
+					log.Error("panic recovered", zap.Any("panic", err),
+						zap.String("request_id", audit.RequestID(r.Context())), zap.String("path", r.URL.Path))
+					httputil.Error(w, http.StatusInternalServerError, "internal server error", "INTERNAL", "")
 				}
 			}()
 			next.ServeHTTP(w, r)
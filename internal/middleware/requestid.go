@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/tucredito/backend-api/internal/audit"
+	"github.com/tucredito/backend-api/pkg/httputil"
+)
+
+// Longest accepted client-supplied request ID
+const maxRequestIDLen = 128
+
+/*
+	RequestID accepts the caller's X-Request-ID or generates a UUID, echoes it on the response and attaches it
+	to the context (audit.RequestID) for logs, the audit trail and event correlation
+	It runs first so every response, including auth and rate-limit rejections, carries the ID
+	IDs with characters outside printable ASCII (or spaces) are replaced, so they cannot forge log lines
+*/
+
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(httputil.RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.New().String()
+		}
+		w.Header().Set(httputil.RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(audit.WithRequestID(r.Context(), id)))
+	})
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/audit"
+	"github.com/tucredito/backend-api/internal/auth"
+	"github.com/tucredito/backend-api/internal/clientip"
+	"github.com/tucredito/backend-api/pkg/httputil"
+	"github.com/tucredito/backend-api/pkg/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRequestID_AcceptOrGenerate(t *testing.T) {
+	var seen string
+	h := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = audit.RequestID(r.Context())
+		httputil.Error(w, http.StatusNotFound, "not found", "NOT_FOUND", "")
+	}))
+
+	do := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if id != "" {
+			req.Header.Set("X-Request-ID", id)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do("client-req-42")
+	assert.Equal(t, "client-req-42", seen)
+	assert.Equal(t, "client-req-42", rec.Header().Get("X-Request-ID"))
+	var body httputil.Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "client-req-42", body.RequestID)
+
+	// Missing or unsafe IDs are replaced with a generated one
+	for _, id := range []string{"", "forged\nlog line", string(make([]byte, 200))} {
+		rec := do(id)
+		assert.NotEqual(t, id, seen)
+		assert.Len(t, seen, 36)
+		assert.Equal(t, seen, rec.Header().Get("X-Request-ID"))
+	}
+}
+
+func TestLogging_ContextLoggerCarriesRequestFields(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/things/{id}", func(w http.ResponseWriter, r *http.Request) {
+		logger.FromContext(r.Context(), zap.NewNop()).Info("handler log")
+	})
+	h := RequestID(Logging(zap.New(core), mux)(mux))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/things/42", nil)
+	req.Header.Set("X-Request-ID", "req-7")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := logs.All()
+	require.Len(t, entries, 2)
+	for _, e := range entries {
+		fields := e.ContextMap()
+		assert.Equal(t, "req-7", fields["request_id"], e.Message)
+		assert.Equal(t, "GET /v1/things/{id}", fields["route"], e.Message)
+	}
+	assert.Equal(t, "handler log", entries[0].Message)
+	assert.Equal(t, "request", entries[1].Message)
+}
+
+func TestLogging_LogsRejectionsWithLateFields(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/things", func(w http.ResponseWriter, r *http.Request) {
+		logger.FromContext(r.Context(), zap.NewNop()).Info("handler log")
+	})
+	deny, err := clientip.ParsePrefixes("203.0.113.66")
+	require.NoError(t, err)
+	authenticate := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), &auth.Principal{Subject: "svc", Role: auth.RoleViewer})))
+		})
+	}
+	h := RequestID(Logging(zap.New(core), mux)(ClientIP(clientip.NewResolver(nil, clientip.HeaderXForwardedFor), nil, deny)(authenticate(LogContext(mux)))))
+
+	// A request turned away by the IP denylist still gets its request line
+	req := httptest.NewRequest(http.MethodGet, "/v1/things", nil)
+	req.RemoteAddr = "203.0.113.66:4000"
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	entries := logs.TakeAll()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "request", entries[0].Message)
+	assert.Equal(t, int64(http.StatusForbidden), entries[0].ContextMap()["status"])
+	assert.Equal(t, "203.0.113.66", entries[0].ContextMap()["client_ip"])
+
+	// Fields added by inner middleware reach both the handler's logger and the request line
+	req = httptest.NewRequest(http.MethodGet, "/v1/things", nil)
+	req.RemoteAddr = "198.51.100.7:4000"
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	entries = logs.TakeAll()
+	require.Len(t, entries, 2)
+	for _, e := range entries {
+		fields := e.ContextMap()
+		assert.Equal(t, "198.51.100.7", fields["client_ip"], e.Message)
+		assert.Equal(t, "svc", fields["principal"], e.Message)
+	}
+	assert.Equal(t, "handler log", entries[0].Message)
+	assert.Equal(t, "request", entries[1].Message)
+}
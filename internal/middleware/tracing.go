@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/tucredito/backend-api/internal/tracing"
+)
+
+/*
+	Tracing continues the caller's trace from its traceparent header (or starts one) with a server span
+	named after the matched route pattern, so spans group by route like the metrics do
+	The response carries the traceparent of the server span so callers can look the trace up
+*/
+
+func Tracing(routes *http.ServeMux) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := tracing.Extract(r.Context(), r.Header)
+			_, pattern := routes.Handler(r)
+			name := pattern
+			if name == "" {
+				name = r.Method + " unmatched"
+			}
+			ctx, span := tracing.Start(ctx, name, tracing.WithKind(tracing.KindServer), tracing.WithAttributes(
+				tracing.String("http.request.method", r.Method),
+				tracing.String("http.route", pattern),
+				tracing.String("url.path", r.URL.Path),
+			))
+			defer span.End()
+			tracing.Inject(ctx, w.Header())
+
+			wrapped := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(wrapped, r.WithContext(ctx))
+
+			span.SetAttributes(tracing.Int("http.response.status_code", wrapped.status))
+			if wrapped.status >= http.StatusInternalServerError {
+				span.SetStatus(tracing.StatusError, http.StatusText(wrapped.status))
+			}
+		})
+	}
+}
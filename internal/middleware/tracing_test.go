@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/tracing"
+)
+
+func TestTracing_ContinuesIncomingTrace(t *testing.T) {
+	rec := &tracing.Recorder{}
+	tracer := tracing.NewTracer(tracing.Config{Exporter: rec})
+	tracing.SetGlobal(tracer)
+	defer tracing.SetGlobal(nil)
+
+	var inner tracing.SpanContext
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/things/{id}", func(w http.ResponseWriter, r *http.Request) {
+		inner = tracing.SpanContextFrom(r.Context())
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	h := Tracing(mux)(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/things/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	require.NoError(t, tracer.Flush(context.Background()))
+
+	span := rec.Find("GET /v1/things/{id}")
+	require.NotNil(t, span)
+	assert.Equal(t, tracing.KindServer, span.Kind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent().String())
+	assert.Equal(t, span.SpanContext(), inner)
+	status, _ := span.Attribute("http.response.status_code")
+	assert.Equal(t, int64(http.StatusBadGateway), status)
+	code, _ := span.Status()
+	assert.Equal(t, tracing.StatusError, code)
+	assert.Equal(t, span.SpanContext().Traceparent(), w.Header().Get("traceparent"))
+}
@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+/*
+	Token-bucket rate limiting
+	A bucket holds up to Limit tokens and refills continuously at Limit per Window, so a client can burst
+	up to Limit requests and then sustain Limit per Window; there is no fixed window edge to game
+	Buckets expire once they would be full again, so an idle key costs nothing
+*/
+
+// Budget of a bucket: Limit requests per Window
+type Policy struct {
+	Limit  int
+	Window time.Duration
+}
+
+// Outcome of taking a token
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Time until the bucket is full again
+	Reset time.Duration
+	// Time until the next token is available; zero when allowed
+	RetryAfter time.Duration
+}
+
+// Limiter takes one token from the bucket identified by key
+type Limiter interface {
+	Allow(ctx context.Context, key string, p Policy) (Result, error)
+}
+
+type fallbackLimiter struct {
+	primary  Limiter
+	fallback Limiter
+	onError  func(error)
+}
+
+/*
+	Returns a Limiter that uses fallback whenever primary fails, so a Redis outage degrades
+	to per-instance limits instead of failing open; onError (optional) observes primary failures
+*/
+
+func WithFallback(primary, fallback Limiter, onError func(error)) Limiter {
+	return &fallbackLimiter{primary: primary, fallback: fallback, onError: onError}
+}
+
+func (l *fallbackLimiter) Allow(ctx context.Context, key string, p Policy) (Result, error) {
+	res, err := l.primary.Allow(ctx, key, p)
+	if err == nil {
+		return res, nil
+	}
+	if l.onError != nil {
+		l.onError(err)
+	}
+	return l.fallback.Allow(ctx, key, p)
+}
+
+// Tokens refilled per second
+func (p Policy) rate() float64 {
+	return float64(p.Limit) / p.Window.Seconds()
+}
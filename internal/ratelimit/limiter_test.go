@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLimiter_TokenBucket(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryLimiter()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	p := Policy{Limit: 3, Window: 3 * time.Second}
+
+	// Bursts up to the limit
+	for i := 2; i >= 0; i-- {
+		res, err := m.Allow(ctx, "k", p)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, i, res.Remaining)
+	}
+	res, _ := m.Allow(ctx, "k", p)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Second, res.RetryAfter)
+	assert.Equal(t, 3*time.Second, res.Reset)
+
+	// Refills continuously: one token per second
+	now = now.Add(time.Second)
+	res, _ = m.Allow(ctx, "k", p)
+	assert.True(t, res.Allowed)
+	res, _ = m.Allow(ctx, "k", p)
+	assert.False(t, res.Allowed)
+
+	// Other keys have their own bucket
+	res, _ = m.Allow(ctx, "other", p)
+	assert.True(t, res.Allowed)
+}
+
+func TestMemoryLimiter_RefillsBetweenCloseCalls(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryLimiter()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	p := Policy{Limit: 1000, Window: time.Second}
+
+	for i := 0; i < 1000; i++ {
+		res, _ := m.Allow(ctx, "hot", p)
+		require.True(t, res.Allowed)
+	}
+	// One token per millisecond, requested every 0.5ms: every other call gets one
+	allowed := 0
+	for i := 0; i < 2000; i++ {
+		now = now.Add(500 * time.Microsecond)
+		if res, _ := m.Allow(ctx, "hot", p); res.Allowed {
+			allowed++
+		}
+	}
+	assert.Equal(t, 1000, allowed)
+}
+
+func TestMemoryLimiter_SweepsIdleBuckets(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryLimiter()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	p := Policy{Limit: 1, Window: time.Hour}
+
+	for i := 0; i < memoryMaxBuckets+10; i++ {
+		_, _ = m.Allow(ctx, fmt.Sprintf("ip-%d", i), p)
+		now = now.Add(time.Millisecond)
+	}
+	assert.Less(t, len(m.buckets), memoryMaxBuckets, "churning keys cannot grow the map past the cap")
+	res, _ := m.Allow(ctx, fmt.Sprintf("ip-%d", memoryMaxBuckets+9), p)
+	assert.False(t, res.Allowed, "recently used buckets are kept")
+
+	// Buckets idle for a whole window go at the next periodic sweep
+	now = now.Add(2 * time.Hour)
+	_, _ = m.Allow(ctx, "new", p)
+	assert.Len(t, m.buckets, 1)
+}
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(context.Context, string, Policy) (Result, error) {
+	return Result{}, errors.New("redis down")
+}
+
+func TestWithFallback(t *testing.T) {
+	var seen error
+	l := WithFallback(failingLimiter{}, NewMemoryLimiter(), func(err error) { seen = err })
+	p := Policy{Limit: 1, Window: time.Minute}
+
+	res, err := l.Allow(context.Background(), "k", p)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Error(t, seen)
+
+	// The fallback enforces the limit instead of failing open
+	res, _ = l.Allow(context.Background(), "k", p)
+	assert.False(t, res.Allowed)
+}
+
+func TestParseRoutes(t *testing.T) {
+	routes, err := ParseRoutes("POST /v1/credits=20/30, POST /v1/credits/bulk=2", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, Policy{Limit: 20, Window: 30 * time.Second}, routes["POST /v1/credits"])
+	assert.Equal(t, Policy{Limit: 2, Window: time.Minute}, routes["POST /v1/credits/bulk"])
+
+	empty, err := ParseRoutes("", time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	for _, bad := range []string{"POST /v1/credits", "POST /v1/credits=x", "POST /v1/credits=5/0", "BAD PATTERN HERE=5"} {
+		_, err := ParseRoutes(bad, time.Minute)
+		assert.Error(t, err, bad)
+	}
+}
@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// How often buckets idle for a whole window (and so full again) are dropped
+	memorySweepInterval = time.Minute
+	// Bucket count that forces a sweep; past it the least recently used buckets are evicted too
+	memoryMaxBuckets = 10000
+)
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	window time.Duration
+}
+
+// MemoryLimiter is an in-process token-bucket Limiter, used without Redis and as its fallback
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+	now     func() time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Takes a token from key's bucket
+func (m *MemoryLimiter) Allow(_ context.Context, key string, p Policy) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	b, ok := m.buckets[key]
+	if !ok {
+		if len(m.buckets) >= memoryMaxBuckets || now.Sub(m.swept) >= memorySweepInterval {
+			m.sweep(now)
+		}
+		b = &bucket{tokens: float64(p.Limit), last: now}
+		m.buckets[key] = b
+	}
+	b.window = p.Window
+	rate := p.rate()
+	// Fractional seconds, so calls less than a millisecond apart still refill
+	b.tokens = math.Min(float64(p.Limit), b.tokens+math.Max(0, now.Sub(b.last).Seconds())*rate)
+	b.last = now
+
+	res := Result{Limit: p.Limit}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = ceilMillis((1 - b.tokens) / rate)
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = ceilMillis((float64(p.Limit) - b.tokens) / rate)
+	return res, nil
+}
+
+/*
+	Drops buckets idle for their whole window, which have refilled completely and would be recreated
+	identical; callers hold mu
+	If that leaves memoryMaxBuckets or more, the least recently used quarter is evicted as well: those keys
+	get a full bucket on their next request, which bounds memory when keys churn faster than windows expire
+*/
+
+func (m *MemoryLimiter) sweep(now time.Time) {
+	m.swept = now
+	for key, b := range m.buckets {
+		if now.Sub(b.last) >= b.window {
+			delete(m.buckets, key)
+		}
+	}
+	if len(m.buckets) < memoryMaxBuckets {
+		return
+	}
+	keys := make([]string, 0, len(m.buckets))
+	for key := range m.buckets {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return m.buckets[keys[i]].last.Before(m.buckets[keys[j]].last) })
+	for _, key := range keys[:len(keys)-memoryMaxBuckets*3/4] {
+		delete(m.buckets, key)
+	}
+}
+
+// Rounds seconds up to whole milliseconds
+func ceilMillis(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds*1000)) * time.Millisecond
+}
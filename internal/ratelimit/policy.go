@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rate-limit configuration: a default budget per caller, overrides per tenant and principal, and per-route budgets
+type Policies struct {
+	Default Policy
+	// Limits per bank ID and per principal subject, over Default.Window
+	Tenants    map[string]int
+	Principals map[string]int
+	// Extra budgets keyed by ServeMux pattern (e.g. "POST /v1/credits"), drawn in addition to the caller's budget
+	Routes map[string]Policy
+}
+
+// Returns the caller budget for a tenant or principal override, or Default
+func (p Policies) Override(limits map[string]int, key string) Policy {
+	if l, ok := limits[key]; ok && l > 0 {
+		return Policy{Limit: l, Window: p.Default.Window}
+	}
+	return p.Default
+}
+
+/*
+	Parses route policies from "PATTERN=LIMIT[/WINDOW_SECONDS],..." where PATTERN is a ServeMux pattern,
+	e.g. "POST /v1/credits=20/60,POST /v1/credits/bulk=2"; a missing window uses defaultWindow
+*/
+
+func ParseRoutes(spec string, defaultWindow time.Duration) (map[string]Policy, error) {
+	routes := make(map[string]Policy)
+	mux := http.NewServeMux()
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		i := strings.LastIndex(entry, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("rate limit route %q: want PATTERN=LIMIT[/WINDOW_SECONDS]", entry)
+		}
+		pattern := strings.TrimSpace(entry[:i])
+		p, err := parsePolicy(entry[i+1:], defaultWindow)
+		if err != nil {
+			return nil, fmt.Errorf("rate limit route %q: %w", entry, err)
+		}
+		if err := checkPattern(mux, pattern); err != nil {
+			return nil, fmt.Errorf("rate limit route %q: %w", entry, err)
+		}
+		routes[pattern] = p
+	}
+	return routes, nil
+}
+
+func parsePolicy(s string, defaultWindow time.Duration) (Policy, error) {
+	limitStr, windowStr, hasWindow := strings.Cut(strings.TrimSpace(s), "/")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		return Policy{}, fmt.Errorf("invalid limit %q", limitStr)
+	}
+	window := defaultWindow
+	if hasWindow {
+		secs, err := strconv.Atoi(windowStr)
+		if err != nil || secs <= 0 {
+			return Policy{}, fmt.Errorf("invalid window %q", windowStr)
+		}
+		window = time.Duration(secs) * time.Second
+	}
+	return Policy{Limit: limit, Window: window}, nil
+}
+
+// Registers pattern on mux, turning ServeMux's panics on invalid or conflicting patterns into errors
+func checkPattern(mux *http.ServeMux, pattern string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	mux.Handle(pattern, http.NotFoundHandler())
+	return nil
+}
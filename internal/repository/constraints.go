@@ -0,0 +1,24 @@
+package repository
+
+import "github.com/tucredito/backend-api/pkg/apperr"
+
+/*
+	Constraint violations
+	Every backend reports a broken schema constraint with the same typed error, so callers and the
+	conformance suite cannot tell whether Postgres or the in-memory store refused the write
+*/
+
+// A unique column already holds the value
+func DuplicateError(field string) *apperr.Error {
+	return apperr.Conflict(field+" already exists", apperr.Field(field, "duplicate", "already in use"))
+}
+
+// A foreign key names a row that does not exist
+func MissingReferenceError(field string) *apperr.Error {
+	return apperr.Validation(field+" references a record that does not exist", apperr.Field(field, "not_found", "does not exist"))
+}
+
+// A value is outside the set or range a check constraint allows
+func OutOfRangeError(field string) *apperr.Error {
+	return apperr.Validation(field+" is out of range", apperr.Field(field, "out_of_range", "is out of range"))
+}
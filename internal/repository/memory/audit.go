@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/tenant"
+)
+
+type AuditRepository struct {
+	store *Store
+}
+
+func NewAuditRepository(store *Store) *AuditRepository {
+	return &AuditRepository{store: store}
+}
+
+// Lists the audit entries of an entity, oldest first
+// Bank-scoped requests only see the history of entities visible to that bank
+func (r *AuditRepository) ListByEntity(ctx context.Context, entity domain.AuditEntity, entityID string, limit, offset int) ([]*domain.AuditEntry, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.auditVisible(ctx, entity, entityID) {
+		return nil, nil
+	}
+	var entries []domain.AuditEntry
+	for _, e := range s.audit {
+		if e.Entity == entity && e.EntityID == entityID {
+			entries = append(entries, e)
+		}
+	}
+	return auditPage(entries, limit, offset), nil
+}
+
+// Lists the audit entries of an entity type recorded in [from, to), oldest first, with the same scoping
+func (r *AuditRepository) ListByRange(ctx context.Context, entity domain.AuditEntity, from, to time.Time, limit, offset int) ([]*domain.AuditEntry, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var entries []domain.AuditEntry
+	for _, e := range s.audit {
+		if e.Entity == entity && !e.OccurredAt.Before(from) && e.OccurredAt.Before(to) && s.auditVisible(ctx, entity, e.EntityID) {
+			entries = append(entries, e)
+		}
+	}
+	return auditPage(entries, limit, offset), nil
+}
+
+func auditPage(entries []domain.AuditEntry, limit, offset int) []*domain.AuditEntry {
+	var list []*domain.AuditEntry
+	for _, e := range page(entries, limit, offset) {
+		e := e
+		list = append(list, &e)
+	}
+	return list
+}
+
+// Visibility of the audited entity for a bank, mirroring the scoping of its repository; s.mu must be held
+func (s *Store) auditVisible(ctx context.Context, entity domain.AuditEntity, entityID string) bool {
+	bankID, ok := tenant.BankID(ctx)
+	if !ok {
+		return true
+	}
+	switch entity {
+	case domain.AuditEntityCredit:
+		c, ok := s.credits[entityID]
+		return ok && c.BankID == bankID
+	case domain.AuditEntityClient:
+		c, ok := s.clients[entityID]
+		return ok && s.clientVisible(ctx, c)
+	case domain.AuditEntityBank:
+		return entityID == bankID
+	default:
+		return false
+	}
+}
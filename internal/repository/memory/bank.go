@@ -0,0 +1,143 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/repository"
+	"github.com/tucredito/backend-api/internal/tenant"
+)
+
+type BankRepository struct {
+	store *Store
+}
+
+func NewBankRepository(store *Store) *BankRepository {
+	return &BankRepository{store: store}
+}
+
+// Creates a new bank; onboarding banks is a platform operation
+func (r *BankRepository) Create(ctx context.Context, input domain.CreateBankInput) (*domain.Bank, error) {
+	if _, scoped := tenant.BankID(ctx); scoped {
+		return nil, tenant.ErrCrossTenant
+	}
+	s := r.store
+	return mutation(ctx, s, domain.AuditEntityBank, domain.AuditActionCreate, nil,
+		func(_ *domain.Bank) (*domain.Bank, error) {
+			b := domain.Bank{ID: uuid.New().String(), IsActive: true, Version: 1}
+			return s.writeBank(ctx, b, domain.UpdateBankInput(input))
+		}, bankID)
+}
+
+// Gets a bank by ID
+func (r *BankRepository) GetByID(ctx context.Context, id string) (*domain.Bank, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.banks[id]
+	if !ok || !b.IsActive || !tenant.CanAccessBank(ctx, id) {
+		return nil, nil
+	}
+	return &b, nil
+}
+
+// Gets a bank the way Update finds it (see lock)
+func (r *BankRepository) GetForUpdate(ctx context.Context, id string) (*domain.Bank, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return r.lock(ctx, id)()
+}
+
+// Updates a bank
+func (r *BankRepository) Update(ctx context.Context, id string, input domain.UpdateBankInput) (*domain.Bank, error) {
+	s := r.store
+	return mutation(ctx, s, domain.AuditEntityBank, domain.AuditActionUpdate, r.lock(ctx, id),
+		func(before *domain.Bank) (*domain.Bank, error) {
+			b := *before
+			b.Version++
+			return s.writeBank(ctx, b, input)
+		}, bankID)
+}
+
+// Updates the fields a merge patch provides
+func (r *BankRepository) Patch(ctx context.Context, id string, patch domain.BankPatch) (*domain.Bank, error) {
+	s := r.store
+	return mutation(ctx, s, domain.AuditEntityBank, domain.AuditActionUpdate, r.lock(ctx, id),
+		func(before *domain.Bank) (*domain.Bank, error) {
+			b := *before
+			b.Version++
+			return s.writeBank(ctx, b, patch.Apply(before))
+		}, bankID)
+}
+
+// Soft-deletes a bank
+func (r *BankRepository) SetInactive(ctx context.Context, id string) (*domain.Bank, error) {
+	return r.setActive(ctx, id, false, domain.AuditActionDeactivate)
+}
+
+// Re-enables a bank
+func (r *BankRepository) SetActive(ctx context.Context, id string) (*domain.Bank, error) {
+	return r.setActive(ctx, id, true, domain.AuditActionActivate)
+}
+
+func (r *BankRepository) setActive(ctx context.Context, id string, active bool, action domain.AuditAction) (*domain.Bank, error) {
+	s := r.store
+	return mutation(ctx, s, domain.AuditEntityBank, action, r.lock(ctx, id),
+		func(before *domain.Bank) (*domain.Bank, error) {
+			b := *before
+			b.IsActive = active
+			b.Version++
+			put(ctx, s.banks, id, b)
+			return &b, nil
+		}, bankID)
+}
+
+// Lists banks with pagination, ordered by name
+func (r *BankRepository) List(ctx context.Context, limit, offset int) ([]*domain.Bank, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var rows []domain.Bank
+	for id, b := range s.banks {
+		if b.IsActive && tenant.CanAccessBank(ctx, id) {
+			rows = append(rows, b)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Name != rows[j].Name {
+			return rows[i].Name < rows[j].Name
+		}
+		return rows[i].ID < rows[j].ID
+	})
+	var list []*domain.Bank
+	for _, b := range page(rows, limit, offset) {
+		b := b
+		list = append(list, &b)
+	}
+	return list, nil
+}
+
+// Returns the "before" state of a bank mutation; a scoped request only sees its own bank
+func (r *BankRepository) lock(ctx context.Context, id string) func() (*domain.Bank, error) {
+	return func() (*domain.Bank, error) {
+		b, ok := r.store.banks[id]
+		if !ok || !tenant.CanAccessBank(ctx, id) {
+			return nil, nil
+		}
+		return &b, repository.CheckVersion(ctx, b.Version)
+	}
+}
+
+// Writes the editable fields of b after enforcing the type check; s.mu must be held
+func (s *Store) writeBank(ctx context.Context, b domain.Bank, input domain.UpdateBankInput) (*domain.Bank, error) {
+	if !oneOf(input.Type, domain.BankTypePrivate, domain.BankTypeGovernment) {
+		return nil, repository.OutOfRangeError("type")
+	}
+	b.Name, b.Type = input.Name, input.Type
+	put(ctx, s.banks, b.ID, b)
+	return &b, nil
+}
+
+func bankID(b *domain.Bank) string { return b.ID }
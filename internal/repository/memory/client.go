@@ -0,0 +1,152 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/repository"
+	"github.com/tucredito/backend-api/internal/tenant"
+)
+
+type ClientRepository struct {
+	store *Store
+}
+
+func NewClientRepository(store *Store) *ClientRepository {
+	return &ClientRepository{store: store}
+}
+
+// Creates a new client, owned by the requesting bank when the request is tenant-scoped
+func (r *ClientRepository) Create(ctx context.Context, client domain.CreateClientInput) (*domain.Client, error) {
+	s := r.store
+	return mutation(ctx, s, domain.AuditEntityClient, domain.AuditActionCreate, nil,
+		func(_ *domain.Client) (*domain.Client, error) {
+			owner, _ := tenant.BankID(ctx)
+			if _, ok := s.banks[owner]; owner != "" && !ok {
+				return nil, repository.MissingReferenceError("owner_bank_id")
+			}
+			row := clientRow{
+				Client: domain.Client{
+					ID: uuid.New().String(), CreatedAt: s.timestamp(), IsActive: true, Version: 1,
+				},
+				ownerBankID: owner,
+				seq:         s.nextSeq(),
+			}
+			return s.writeClient(ctx, row, domain.UpdateClientInput(client))
+		}, clientID)
+}
+
+// Gets a client by ID
+func (r *ClientRepository) GetByID(ctx context.Context, id string) (*domain.Client, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	row, ok := s.clients[id]
+	if !ok || !row.IsActive || !s.clientVisible(ctx, row) {
+		return nil, nil
+	}
+	c := row.Client
+	return &c, nil
+}
+
+// Gets a client the way Update finds it (see lock)
+func (r *ClientRepository) GetForUpdate(ctx context.Context, id string) (*domain.Client, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return r.lock(ctx, id)()
+}
+
+// Updates a client
+func (r *ClientRepository) Update(ctx context.Context, id string, input domain.UpdateClientInput) (*domain.Client, error) {
+	s := r.store
+	return mutation(ctx, s, domain.AuditEntityClient, domain.AuditActionUpdate, r.lock(ctx, id),
+		func(before *domain.Client) (*domain.Client, error) {
+			row := s.clients[id]
+			row.Version++
+			return s.writeClient(ctx, row, input)
+		}, clientID)
+}
+
+// Updates the fields a merge patch provides
+func (r *ClientRepository) Patch(ctx context.Context, id string, patch domain.ClientPatch) (*domain.Client, error) {
+	s := r.store
+	return mutation(ctx, s, domain.AuditEntityClient, domain.AuditActionUpdate, r.lock(ctx, id),
+		func(before *domain.Client) (*domain.Client, error) {
+			row := s.clients[id]
+			row.Version++
+			return s.writeClient(ctx, row, patch.Apply(before))
+		}, clientID)
+}
+
+// Soft-deletes a client
+func (r *ClientRepository) SetInactive(ctx context.Context, id string) (*domain.Client, error) {
+	return r.setActive(ctx, id, false, domain.AuditActionDeactivate)
+}
+
+// Re-enables a client
+func (r *ClientRepository) SetActive(ctx context.Context, id string) (*domain.Client, error) {
+	return r.setActive(ctx, id, true, domain.AuditActionActivate)
+}
+
+func (r *ClientRepository) setActive(ctx context.Context, id string, active bool, action domain.AuditAction) (*domain.Client, error) {
+	s := r.store
+	return mutation(ctx, s, domain.AuditEntityClient, action, r.lock(ctx, id),
+		func(_ *domain.Client) (*domain.Client, error) {
+			row := s.clients[id]
+			row.IsActive = active
+			row.Version++
+			put(ctx, s.clients, id, row)
+			c := row.Client
+			return &c, nil
+		}, clientID)
+}
+
+// Lists clients with pagination, newest first
+func (r *ClientRepository) List(ctx context.Context, limit, offset int) ([]*domain.Client, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var rows []clientRow
+	for _, row := range s.clients {
+		if row.IsActive && s.clientVisible(ctx, row) {
+			rows = append(rows, row)
+		}
+	}
+	newestFirst(rows, func(r clientRow) time.Time { return r.CreatedAt }, func(r clientRow) int64 { return r.seq })
+	var list []*domain.Client
+	for _, row := range page(rows, limit, offset) {
+		c := row.Client
+		list = append(list, &c)
+	}
+	return list, nil
+}
+
+// Returns the "before" state of a client mutation; s.mu must be held when it runs
+// Banks may read clients they hold credits with, but only change the clients they onboarded
+func (r *ClientRepository) lock(ctx context.Context, id string) func() (*domain.Client, error) {
+	return func() (*domain.Client, error) {
+		row, ok := r.store.clients[id]
+		if !ok || !tenant.CanAccessBank(ctx, row.ownerBankID) {
+			return nil, nil
+		}
+		c := row.Client
+		return &c, repository.CheckVersion(ctx, c.Version)
+	}
+}
+
+// Writes the editable fields of row after enforcing the unique email; s.mu must be held
+func (s *Store) writeClient(ctx context.Context, row clientRow, input domain.UpdateClientInput) (*domain.Client, error) {
+	for id, other := range s.clients {
+		if id != row.ID && other.Email == input.Email {
+			return nil, repository.DuplicateError("email")
+		}
+	}
+	row.FullName, row.Email, row.BirthDate, row.Country = input.FullName, input.Email, date(input.BirthDate), input.Country
+	put(ctx, s.clients, row.ID, row)
+	c := row.Client
+	return &c, nil
+}
+
+func clientID(c *domain.Client) string { return c.ID }
@@ -0,0 +1,173 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/repository"
+	"github.com/tucredito/backend-api/internal/tenant"
+)
+
+type CreditRepository struct {
+	store *Store
+}
+
+func NewCreditRepository(store *Store) *CreditRepository {
+	return &CreditRepository{store: store}
+}
+
+// Creates a new credit in its decided status; a bank-scoped request may only create credits for its own bank
+func (r *CreditRepository) Create(ctx context.Context, input domain.CreateCreditInput, status domain.CreditStatus) (*domain.Credit, error) {
+	if !tenant.CanAccessBank(ctx, input.BankID) {
+		return nil, tenant.ErrCrossTenant
+	}
+	s := r.store
+	return mutation(ctx, s, domain.AuditEntityCredit, domain.AuditActionCreate, nil,
+		func(_ *domain.Credit) (*domain.Credit, error) {
+			if !oneOf(input.CreditType, domain.CreditTypeAuto, domain.CreditTypeMortgage, domain.CreditTypeCommercial) {
+				return nil, repository.OutOfRangeError("credit_type")
+			}
+			row := creditRow{
+				Credit: domain.Credit{
+					ID: uuid.New().String(), ClientID: input.ClientID, BankID: input.BankID,
+					CreditType: input.CreditType, CreatedAt: s.timestamp(), IsActive: true, Version: 1,
+				},
+				seq: s.nextSeq(),
+			}
+			update := domain.UpdateCreditInput{MinPayment: input.MinPayment, MaxPayment: input.MaxPayment, TermMonths: input.TermMonths, Status: status}
+			return s.writeCredit(ctx, row, update)
+		}, creditID)
+}
+
+// Gets a credit by ID
+func (r *CreditRepository) GetByID(ctx context.Context, id string) (*domain.Credit, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	row, ok := s.credits[id]
+	if !ok || !tenant.CanAccessBank(ctx, row.BankID) {
+		return nil, nil
+	}
+	c := row.Credit
+	return &c, nil
+}
+
+// Updates a credit
+func (r *CreditRepository) Update(ctx context.Context, id string, input domain.UpdateCreditInput) (*domain.Credit, error) {
+	return r.update(ctx, id, domain.AuditActionUpdate, func(*domain.Credit) domain.UpdateCreditInput { return input })
+}
+
+// Updates the fields a merge patch provides
+func (r *CreditRepository) Patch(ctx context.Context, id string, patch domain.CreditPatch) (*domain.Credit, error) {
+	return r.update(ctx, id, domain.AuditActionUpdate, patch.Apply)
+}
+
+// Updates a credit status
+func (r *CreditRepository) UpdateStatus(ctx context.Context, id string, status domain.CreditStatus) (*domain.Credit, error) {
+	return r.update(ctx, id, domain.AuditActionUpdateStatus, domain.CreditPatch{Status: &status}.Apply)
+}
+
+func (r *CreditRepository) update(ctx context.Context, id string, action domain.AuditAction, change func(*domain.Credit) domain.UpdateCreditInput) (*domain.Credit, error) {
+	s := r.store
+	return mutation(ctx, s, domain.AuditEntityCredit, action, r.lock(ctx, id),
+		func(before *domain.Credit) (*domain.Credit, error) {
+			row := s.credits[id]
+			row.Version++
+			return s.writeCredit(ctx, row, change(before))
+		}, creditID)
+}
+
+// Soft-deletes a credit
+func (r *CreditRepository) SetInactive(ctx context.Context, id string) (*domain.Credit, error) {
+	return r.setActive(ctx, id, false, domain.AuditActionDeactivate)
+}
+
+// Re-enables a credit
+func (r *CreditRepository) SetActive(ctx context.Context, id string) (*domain.Credit, error) {
+	return r.setActive(ctx, id, true, domain.AuditActionActivate)
+}
+
+func (r *CreditRepository) setActive(ctx context.Context, id string, active bool, action domain.AuditAction) (*domain.Credit, error) {
+	s := r.store
+	return mutation(ctx, s, domain.AuditEntityCredit, action, r.lock(ctx, id),
+		func(_ *domain.Credit) (*domain.Credit, error) {
+			row := s.credits[id]
+			row.IsActive = active
+			row.Version++
+			put(ctx, s.credits, id, row)
+			c := row.Credit
+			return &c, nil
+		}, creditID)
+}
+
+// Lists credits with pagination, newest first
+func (r *CreditRepository) List(ctx context.Context, limit, offset int) ([]*domain.Credit, error) {
+	return r.list(ctx, func(creditRow) bool { return true }, limit, offset), nil
+}
+
+// Lists credits for a client with pagination, newest first
+func (r *CreditRepository) ListByClientID(ctx context.Context, clientID string, limit, offset int) ([]*domain.Credit, error) {
+	return r.list(ctx, func(row creditRow) bool { return row.ClientID == clientID }, limit, offset), nil
+}
+
+func (r *CreditRepository) list(ctx context.Context, match func(creditRow) bool, limit, offset int) []*domain.Credit {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var list []*domain.Credit
+	for _, row := range page(s.activeCredits(ctx, match), limit, offset) {
+		c := row.Credit
+		list = append(list, &c)
+	}
+	return list
+}
+
+// Active credits visible to the tenant in ctx that match, newest first; s.mu must be held
+func (s *Store) activeCredits(ctx context.Context, match func(creditRow) bool) []creditRow {
+	var rows []creditRow
+	for _, row := range s.credits {
+		if row.IsActive && tenant.CanAccessBank(ctx, row.BankID) && match(row) {
+			rows = append(rows, row)
+		}
+	}
+	newestFirst(rows, func(r creditRow) time.Time { return r.CreatedAt }, func(r creditRow) int64 { return r.seq })
+	return rows
+}
+
+// Returns the "before" state of a credit mutation
+// The lock is tenant-scoped, so a credit of another bank is reported as missing and never mutated
+func (r *CreditRepository) lock(ctx context.Context, id string) func() (*domain.Credit, error) {
+	return func() (*domain.Credit, error) {
+		row, ok := r.store.credits[id]
+		if !ok || !tenant.CanAccessBank(ctx, row.BankID) {
+			return nil, nil
+		}
+		c := row.Credit
+		return &c, repository.CheckVersion(ctx, c.Version)
+	}
+}
+
+// Writes the editable fields of row after enforcing the check constraints and foreign keys; s.mu must be held
+func (s *Store) writeCredit(ctx context.Context, row creditRow, input domain.UpdateCreditInput) (*domain.Credit, error) {
+	switch {
+	case input.TermMonths <= 0:
+		return nil, repository.OutOfRangeError("term_months")
+	case !oneOf(input.Status, domain.CreditStatusPending, domain.CreditStatusApproved, domain.CreditStatusRejected):
+		return nil, repository.OutOfRangeError("status")
+	}
+	if _, ok := s.clients[row.ClientID]; !ok {
+		return nil, repository.MissingReferenceError("client_id")
+	}
+	if _, ok := s.banks[row.BankID]; !ok {
+		return nil, repository.MissingReferenceError("bank_id")
+	}
+	row.MinPayment, row.MaxPayment = cents(input.MinPayment), cents(input.MaxPayment)
+	row.TermMonths, row.Status = input.TermMonths, input.Status
+	put(ctx, s.credits, row.ID, row)
+	c := row.Credit
+	return &c, nil
+}
+
+func creditID(c *domain.Credit) string { return c.ID }
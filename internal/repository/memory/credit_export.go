@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/tucredito/backend-api/internal/domain"
+)
+
+type CreditExportRepository struct {
+	store *Store
+}
+
+func NewCreditExportRepository(store *Store) *CreditExportRepository {
+	return &CreditExportRepository{store: store}
+}
+
+/*
+	StreamCredits calls fn for each active credit joined with its client and bank, newest first, with the
+	same filters and tenant scoping as the postgres export
+	The rows are copied under the lock before fn runs, so the export is a consistent snapshot and fn may
+	call back into the store; an error from fn stops the export
+*/
+
+func (r *CreditExportRepository) StreamCredits(ctx context.Context, filter domain.CreditExportFilter, fn func(row *domain.CreditExportRow) error) error {
+	s := r.store
+	s.mu.Lock()
+	var rows []domain.CreditExportRow
+	for _, c := range s.activeCredits(ctx, exportMatch(filter)) {
+		cl, b := s.clients[c.ClientID], s.banks[c.BankID]
+		rows = append(rows, domain.CreditExportRow{
+			CreditID: c.ID, ClientID: c.ClientID, BankID: c.BankID, MinPayment: c.MinPayment, MaxPayment: c.MaxPayment,
+			TermMonths: c.TermMonths, CreditType: c.CreditType, Status: c.Status, CreatedAt: c.CreatedAt,
+			ClientFullName: cl.FullName, ClientEmail: cl.Email, ClientCountry: cl.Country, BankName: b.Name, BankType: b.Type,
+		})
+	}
+	s.mu.Unlock()
+
+	for i := range rows {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(&rows[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reports whether a credit passes the export's optional filters
+func exportMatch(filter domain.CreditExportFilter) func(creditRow) bool {
+	return func(c creditRow) bool {
+		switch {
+		case filter.ClientID != "" && c.ClientID != filter.ClientID,
+			filter.BankID != "" && c.BankID != filter.BankID,
+			filter.Status != "" && c.Status != filter.Status,
+			filter.CreatedFrom != nil && c.CreatedAt.Before(*filter.CreatedFrom),
+			filter.CreatedTo != nil && !c.CreatedAt.Before(*filter.CreatedTo):
+			return false
+		}
+		return true
+	}
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/repository"
+	"github.com/tucredito/backend-api/internal/tenant"
+)
+
+type CreditImportRepository struct {
+	store *Store
+}
+
+func NewCreditImportRepository(store *Store) *CreditImportRepository {
+	return &CreditImportRepository{store: store}
+}
+
+// Creates a new credit import in PROCESSING status, owned by the requesting bank when tenant-scoped
+func (r *CreditImportRepository) Create(ctx context.Context, format domain.ImportFormat) (*domain.CreditImport, error) {
+	if !oneOf(format, domain.ImportFormatCSV, domain.ImportFormatNDJSON) {
+		return nil, repository.OutOfRangeError("format")
+	}
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	owner, _ := tenant.BankID(ctx)
+	if _, ok := s.banks[owner]; owner != "" && !ok {
+		return nil, repository.MissingReferenceError("bank_id")
+	}
+	row := importRow{
+		CreditImport: domain.CreditImport{
+			ID: uuid.New().String(), Format: format, Status: domain.ImportStatusProcessing, CreatedAt: s.timestamp(),
+		},
+		bankID: owner,
+	}
+	put(ctx, s.imports, row.ID, row)
+	imp := row.CreditImport
+	return &imp, nil
+}
+
+// Stores the report and marks the import as COMPLETED
+func (r *CreditImportRepository) Complete(ctx context.Context, id string, input domain.CompleteCreditImportInput) (*domain.CreditImport, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	row, ok := s.imports[id]
+	if !ok || !tenant.CanAccessBank(ctx, row.bankID) {
+		return nil, nil
+	}
+	results := input.Results
+	if results == nil {
+		results = []domain.CreditImportResult{}
+	}
+	completedAt := s.timestamp()
+	row.Status, row.CompletedAt = domain.ImportStatusCompleted, &completedAt
+	row.Total, row.Succeeded, row.Failed = input.Total, input.Succeeded, input.Failed
+	row.Results = append([]domain.CreditImportResult(nil), results...)
+	put(ctx, s.imports, id, row)
+	imp := row.CreditImport
+	imp.Results = results
+	return &imp, nil
+}
+
+// Gets a credit import with its report by ID
+func (r *CreditImportRepository) GetByID(ctx context.Context, id string) (*domain.CreditImport, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	row, ok := s.imports[id]
+	if !ok || !tenant.CanAccessBank(ctx, row.bankID) {
+		return nil, nil
+	}
+	imp := row.CreditImport
+	imp.Results = append([]domain.CreditImportResult(nil), row.Results...)
+	return &imp, nil
+}
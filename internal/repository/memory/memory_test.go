@@ -0,0 +1,22 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/tucredito/backend-api/internal/repository/memory"
+	"github.com/tucredito/backend-api/internal/repository/repotest"
+)
+
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Backend {
+		store := memory.NewStore()
+		return repotest.Backend{
+			Clients:  memory.NewClientRepository(store),
+			Banks:    memory.NewBankRepository(store),
+			Credits:  memory.NewCreditRepository(store),
+			Audit:    memory.NewAuditRepository(store),
+			Webhooks: memory.NewWebhookRepository(store),
+			Tx:       memory.NewTxManager(store),
+		}
+	})
+}
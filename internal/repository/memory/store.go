@@ -0,0 +1,223 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tucredito/backend-api/internal/audit"
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/tenant"
+)
+
+/*
+	In-memory storage for a zero-dependency dev mode (STORAGE=memory)
+	A Store holds the tables the postgres repositories use and enforces the same constraints: unique client
+	emails, credits referencing existing clients and banks, check constraints on enums and term_months, row
+	versions, tenant scoping and an audit entry per mutation. Data lives as long as the process
+	One mutex serializes every access, which stands in for Postgres' row locks
+*/
+
+type Store struct {
+	mu         sync.Mutex
+	clients    map[string]clientRow
+	banks      map[string]domain.Bank
+	credits    map[string]creditRow
+	imports    map[string]importRow
+	webhooks   map[string]subscriptionRow
+	deliveries map[string]deliveryRow
+	audit      []domain.AuditEntry
+	seq        int64 // insertion order, breaks created_at ties
+	auditID    int64
+	now        func() time.Time
+}
+
+func NewStore() *Store {
+	return &Store{
+		clients:    make(map[string]clientRow),
+		banks:      make(map[string]domain.Bank),
+		credits:    make(map[string]creditRow),
+		imports:    make(map[string]importRow),
+		webhooks:   make(map[string]subscriptionRow),
+		deliveries: make(map[string]deliveryRow),
+		now:        time.Now,
+	}
+}
+
+type clientRow struct {
+	domain.Client
+	ownerBankID string // empty for clients onboarded by the platform
+	seq         int64
+}
+
+type creditRow struct {
+	domain.Credit
+	seq int64
+}
+
+type importRow struct {
+	domain.CreditImport
+	bankID string
+}
+
+// Current time at the precision of a TIMESTAMPTZ column
+func (s *Store) timestamp() time.Time {
+	return s.now().UTC().Truncate(time.Microsecond)
+}
+
+func (s *Store) nextSeq() int64 {
+	s.seq++
+	return s.seq
+}
+
+// Stores row under id, journaling the previous state when ctx carries a unit of work
+func put[R any](ctx context.Context, table map[string]R, id string, row R) {
+	prev, existed := table[id]
+	table[id] = row
+	journal(ctx, func() {
+		if existed {
+			table[id] = prev
+		} else {
+			delete(table, id)
+		}
+	})
+}
+
+// Deletes the row under id, journaling it when ctx carries a unit of work
+func remove[R any](ctx context.Context, table map[string]R, id string) {
+	prev, existed := table[id]
+	if !existed {
+		return
+	}
+	delete(table, id)
+	journal(ctx, func() { table[id] = prev })
+}
+
+/*
+	mutation mirrors postgres.auditedMutation under the store lock
+	lock returns the current row (nil, nil when it is missing or not visible to the tenant) and is checked
+	against the expected versions in ctx; mutate applies the change and returns the new row, and the audit
+	entry describing it is appended. A failed mutation leaves the store untouched
+*/
+
+func mutation[T any](
+	ctx context.Context,
+	s *Store,
+	entity domain.AuditEntity,
+	action domain.AuditAction,
+	lock func() (*T, error),
+	mutate func(before *T) (*T, error),
+	idOf func(*T) string,
+) (*T, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var before *T
+	if lock != nil {
+		var err error
+		if before, err = lock(); err != nil || before == nil {
+			return nil, err
+		}
+	}
+	after, err := mutate(before)
+	if err != nil || after == nil {
+		return nil, err
+	}
+	if err := s.appendAudit(ctx, entity, idOf(after), action, before, after); err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+// Appends an audit entry with the actor, request ID and client IP taken from ctx
+func (s *Store) appendAudit(ctx context.Context, entity domain.AuditEntity, entityID string, action domain.AuditAction, before, after interface{}) error {
+	changes, err := audit.Diff(before, after)
+	if err != nil {
+		return err
+	}
+	s.auditID++
+	id := s.auditID
+	s.audit = append(s.audit, domain.AuditEntry{
+		ID: id, Entity: entity, EntityID: entityID, Action: action,
+		Actor: audit.Actor(ctx), RequestID: audit.RequestID(ctx), ClientIP: audit.ClientIP(ctx),
+		Changes: changes, OccurredAt: s.timestamp(),
+	})
+	journal(ctx, func() {
+		for i := range s.audit {
+			if s.audit[i].ID == id {
+				s.audit = append(s.audit[:i], s.audit[i+1:]...)
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// A client is visible to a bank that onboarded it or holds one of its credits; s.mu must be held
+func (s *Store) clientVisible(ctx context.Context, row clientRow) bool {
+	bankID, ok := tenant.BankID(ctx)
+	if !ok || row.ownerBankID == bankID {
+		return true
+	}
+	for _, c := range s.credits {
+		if c.ClientID == row.ID && c.BankID == bankID {
+			return true
+		}
+	}
+	return false
+}
+
+// Sorts rows newest first like ORDER BY created_at DESC, later inserts first on ties
+func newestFirst[R any](rows []R, createdAt func(R) time.Time, seq func(R) int64) {
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := createdAt(rows[i]), createdAt(rows[j])
+		if !a.Equal(b) {
+			return a.After(b)
+		}
+		return seq(rows[i]) > seq(rows[j])
+	})
+}
+
+// Applies LIMIT/OFFSET with the repositories' default limit
+func page[T any](rows []T, limit, offset int) []T {
+	if limit <= 0 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(rows) {
+		return nil
+	}
+	rows = rows[offset:]
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+	return rows
+}
+
+// Rounds an amount to the cents a DECIMAL(15, 2) column keeps
+func cents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// Truncates a birth date to the day a DATE column keeps
+func date(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Reports whether v is one of allowed
+func oneOf[V comparable](v V, allowed ...V) bool {
+	for _, a := range allowed {
+		if v == a {
+			return true
+		}
+	}
+	return false
+}
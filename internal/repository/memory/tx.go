@@ -0,0 +1,49 @@
+package memory
+
+import "context"
+
+/*
+	Unit of work over the in-memory store
+	Every mutation made with the unit's ctx journals how to undo itself; a failed unit replays the journal
+	backwards. Mutations are atomic one by one, but a unit is not isolated: other requests see its writes
+	before it commits, which is good enough for development and tests
+*/
+
+type txKey struct{}
+
+// Undo steps of a unit of work, appended under the store lock
+type undoLog struct {
+	steps []func()
+}
+
+type TxManager struct {
+	store *Store
+}
+
+func NewTxManager(store *Store) *TxManager {
+	return &TxManager{store: store}
+}
+
+// Runs fn as a unit of work; nested calls join the outer one
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*undoLog); ok {
+		return fn(ctx)
+	}
+	log := &undoLog{}
+	if err := fn(context.WithValue(ctx, txKey{}, log)); err != nil {
+		m.store.mu.Lock()
+		defer m.store.mu.Unlock()
+		for i := len(log.steps) - 1; i >= 0; i-- {
+			log.steps[i]()
+		}
+		return err
+	}
+	return nil
+}
+
+// Records undo in the unit of work carried by ctx, if any
+func journal(ctx context.Context, undo func()) {
+	if log, ok := ctx.Value(txKey{}).(*undoLog); ok {
+		log.steps = append(log.steps, undo)
+	}
+}
@@ -0,0 +1,307 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/repository"
+	"github.com/tucredito/backend-api/internal/tenant"
+)
+
+type WebhookRepository struct {
+	store *Store
+}
+
+func NewWebhookRepository(store *Store) *WebhookRepository {
+	return &WebhookRepository{store: store}
+}
+
+type subscriptionRow struct {
+	domain.WebhookSubscription
+	seq int64
+}
+
+type deliveryRow struct {
+	domain.WebhookDelivery
+	seq int64
+}
+
+// Copies the row so callers never share its slices
+func (r subscriptionRow) subscription() *domain.WebhookSubscription {
+	sub := r.WebhookSubscription
+	sub.EventTypes = append([]domain.EventType(nil), r.EventTypes...)
+	return &sub
+}
+
+// Copies the row without its delivery log
+func (r deliveryRow) delivery() *domain.WebhookDelivery {
+	d := r.WebhookDelivery
+	d.Payload = append(json.RawMessage(nil), r.Payload...)
+	d.AttemptLog = nil
+	return &d
+}
+
+// Creates a subscription owned by the requesting bank when tenant-scoped
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, input domain.CreateWebhookSubscriptionInput) (*domain.WebhookSubscription, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	owner, _ := tenant.BankID(ctx)
+	if _, ok := s.banks[owner]; owner != "" && !ok {
+		return nil, repository.MissingReferenceError("bank_id")
+	}
+	if len(input.EventTypes) == 0 {
+		return nil, repository.OutOfRangeError("event_types")
+	}
+	now := s.timestamp()
+	row := subscriptionRow{
+		WebhookSubscription: domain.WebhookSubscription{
+			ID: uuid.New().String(), BankID: owner, URL: input.URL, EventTypes: append([]domain.EventType(nil), input.EventTypes...),
+			Secret: input.Secret, IsActive: true, CreatedAt: now, UpdatedAt: now,
+		},
+		seq: s.nextSeq(),
+	}
+	put(ctx, s.webhooks, row.ID, row)
+	return row.subscription(), nil
+}
+
+// Gets a subscription, secret included, by ID
+func (r *WebhookRepository) GetSubscription(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	row, ok := s.webhooks[id]
+	if !ok || !tenant.CanAccessBank(ctx, row.BankID) {
+		return nil, nil
+	}
+	return row.subscription(), nil
+}
+
+// Updates a subscription; an empty secret and a nil IsActive keep the current values
+func (r *WebhookRepository) UpdateSubscription(ctx context.Context, id string, input domain.UpdateWebhookSubscriptionInput) (*domain.WebhookSubscription, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	row, ok := s.webhooks[id]
+	if !ok || !tenant.CanAccessBank(ctx, row.BankID) {
+		return nil, nil
+	}
+	if len(input.EventTypes) == 0 {
+		return nil, repository.OutOfRangeError("event_types")
+	}
+	row.URL = input.URL
+	row.EventTypes = append([]domain.EventType(nil), input.EventTypes...)
+	if input.Secret != "" {
+		row.Secret = input.Secret
+	}
+	if input.IsActive != nil {
+		row.IsActive = *input.IsActive
+	}
+	row.UpdatedAt = s.timestamp()
+	put(ctx, s.webhooks, id, row)
+	return row.subscription(), nil
+}
+
+// Deletes a subscription with its deliveries and their log
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	row, ok := s.webhooks[id]
+	if !ok || !tenant.CanAccessBank(ctx, row.BankID) {
+		return nil, nil
+	}
+	remove(ctx, s.webhooks, id)
+	for deliveryID, d := range s.deliveries {
+		if d.SubscriptionID == id {
+			remove(ctx, s.deliveries, deliveryID)
+		}
+	}
+	return row.subscription(), nil
+}
+
+// Lists subscriptions, newest first
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context, limit, offset int) ([]*domain.WebhookSubscription, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var rows []subscriptionRow
+	for _, row := range s.webhooks {
+		if tenant.CanAccessBank(ctx, row.BankID) {
+			rows = append(rows, row)
+		}
+	}
+	newestFirst(rows, func(r subscriptionRow) time.Time { return r.CreatedAt }, func(r subscriptionRow) int64 { return r.seq })
+	var list []*domain.WebhookSubscription
+	for _, row := range page(rows, limit, offset) {
+		list = append(list, row.subscription())
+	}
+	return list, nil
+}
+
+// Active subscriptions to eventType that receive bankID's events: the bank's own and the platform's
+func (r *WebhookRepository) Subscribers(_ context.Context, eventType domain.EventType, bankID string) ([]*domain.WebhookSubscription, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var rows []subscriptionRow
+	for _, row := range s.webhooks {
+		if row.IsActive && row.Wants(eventType) && (row.BankID == "" || row.BankID == bankID) {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].seq < rows[j].seq })
+	var list []*domain.WebhookSubscription
+	for _, row := range rows {
+		list = append(list, row.subscription())
+	}
+	return list, nil
+}
+
+// Queues an event for a subscription, due immediately
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, input domain.CreateWebhookDeliveryInput) (*domain.WebhookDelivery, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.webhooks[input.SubscriptionID]; !ok {
+		return nil, repository.MissingReferenceError("subscription_id")
+	}
+	for _, d := range s.deliveries {
+		if d.SubscriptionID == input.SubscriptionID && d.EventID == input.EventID {
+			return nil, repository.DuplicateError("subscription_id_event_id")
+		}
+	}
+	now := s.timestamp()
+	row := deliveryRow{
+		WebhookDelivery: domain.WebhookDelivery{
+			ID: uuid.New().String(), SubscriptionID: input.SubscriptionID, EventID: input.EventID, EventType: input.EventType,
+			Payload: append(json.RawMessage(nil), input.Payload...), Status: domain.WebhookDeliveryPending,
+			NextAttemptAt: &now, CreatedAt: now,
+		},
+		seq: s.nextSeq(),
+	}
+	put(ctx, s.deliveries, row.ID, row)
+	return row.delivery(), nil
+}
+
+// Claims up to limit pending deliveries whose next attempt is due, oldest first, pushing them lease into the future
+func (r *WebhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.timestamp()
+	var due []deliveryRow
+	for _, row := range s.deliveries {
+		if row.Status == domain.WebhookDeliveryPending && row.NextAttemptAt != nil && !row.NextAttemptAt.After(now) {
+			due = append(due, row)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		a, b := *due[i].NextAttemptAt, *due[j].NextAttemptAt
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return due[i].seq < due[j].seq
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	leased := now.Add(lease).Truncate(time.Microsecond)
+	var list []*domain.WebhookDelivery
+	for _, row := range due {
+		row.NextAttemptAt = &leased
+		put(ctx, s.deliveries, row.ID, row)
+		list = append(list, row.delivery())
+	}
+	return list, nil
+}
+
+// Appends an attempt to the delivery log and moves the delivery to input.Status
+func (r *WebhookRepository) RecordAttempt(ctx context.Context, id string, input domain.RecordWebhookAttemptInput) (*domain.WebhookDelivery, error) {
+	if !oneOf(input.Status, domain.WebhookDeliveryPending, domain.WebhookDeliverySucceeded, domain.WebhookDeliveryDead) {
+		return nil, repository.OutOfRangeError("status")
+	}
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	row, ok := s.deliveries[id]
+	if !ok {
+		return nil, nil
+	}
+	now := s.timestamp()
+	row.Status, row.Attempts, row.LastAttemptAt = input.Status, row.Attempts+1, &now
+	row.NextAttemptAt, row.LastStatusCode, row.LastError = truncate(input.NextAttemptAt), input.StatusCode, input.Error
+	row.AttemptLog = append(append([]domain.WebhookAttempt(nil), row.AttemptLog...), domain.WebhookAttempt{
+		Attempt: row.Attempts, StatusCode: input.StatusCode, Error: input.Error,
+		DurationMS: input.Duration.Milliseconds(), AttemptedAt: now,
+	})
+	put(ctx, s.deliveries, id, row)
+	return row.delivery(), nil
+}
+
+// Gets a delivery of the subscription with its delivery log
+func (r *WebhookRepository) GetDelivery(ctx context.Context, subscriptionID, id string) (*domain.WebhookDelivery, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	row, ok := s.deliveries[id]
+	if !ok || row.SubscriptionID != subscriptionID || !s.deliveryVisible(ctx, row) {
+		return nil, nil
+	}
+	d := row.delivery()
+	d.AttemptLog = append([]domain.WebhookAttempt{}, row.AttemptLog...)
+	return d, nil
+}
+
+// Lists the subscription's deliveries, newest first, optionally only those in status
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID string, status domain.WebhookDeliveryStatus, limit, offset int) ([]*domain.WebhookDelivery, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var rows []deliveryRow
+	for _, row := range s.deliveries {
+		if row.SubscriptionID == subscriptionID && (status == "" || row.Status == status) && s.deliveryVisible(ctx, row) {
+			rows = append(rows, row)
+		}
+	}
+	newestFirst(rows, func(r deliveryRow) time.Time { return r.CreatedAt }, func(r deliveryRow) int64 { return r.seq })
+	var list []*domain.WebhookDelivery
+	for _, row := range page(rows, limit, offset) {
+		list = append(list, row.delivery())
+	}
+	return list, nil
+}
+
+// Queues the delivery again, due immediately with its attempts reset; the delivery log is kept
+func (r *WebhookRepository) Redeliver(ctx context.Context, subscriptionID, id string) (*domain.WebhookDelivery, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	row, ok := s.deliveries[id]
+	if !ok || row.SubscriptionID != subscriptionID || !s.deliveryVisible(ctx, row) {
+		return nil, nil
+	}
+	now := s.timestamp()
+	row.Status, row.Attempts, row.NextAttemptAt = domain.WebhookDeliveryPending, 0, &now
+	put(ctx, s.deliveries, id, row)
+	return row.delivery(), nil
+}
+
+// A delivery is visible to the bank that owns its subscription; s.mu must be held
+func (s *Store) deliveryVisible(ctx context.Context, row deliveryRow) bool {
+	sub, ok := s.webhooks[row.SubscriptionID]
+	return ok && tenant.CanAccessBank(ctx, sub.BankID)
+}
+
+// Truncates an optional time to the precision of a TIMESTAMPTZ column
+func truncate(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	v := t.UTC().Truncate(time.Microsecond)
+	return &v
+}
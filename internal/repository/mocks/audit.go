@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/tucredito/backend-api/internal/domain"
+)
+
+/*
+	AuditRepository is a mock for repository.AuditRepository
+	Used for testing purposes
+*/
+
+type AuditRepository struct {
+	ListByEntityFunc func(ctx context.Context, entity domain.AuditEntity, entityID string, limit, offset int) ([]*domain.AuditEntry, error)
+	ListByRangeFunc  func(ctx context.Context, entity domain.AuditEntity, from, to time.Time, limit, offset int) ([]*domain.AuditEntry, error)
+}
+
+func (m *AuditRepository) ListByEntity(ctx context.Context, entity domain.AuditEntity, entityID string, limit, offset int) ([]*domain.AuditEntry, error) {
+	if m.ListByEntityFunc != nil {
+		return m.ListByEntityFunc(ctx, entity, entityID, limit, offset)
+	}
+	return nil, nil
+}
+
+func (m *AuditRepository) ListByRange(ctx context.Context, entity domain.AuditEntity, from, to time.Time, limit, offset int) ([]*domain.AuditEntry, error) {
+	if m.ListByRangeFunc != nil {
+		return m.ListByRangeFunc(ctx, entity, from, to, limit, offset)
+	}
+	return nil, nil
+}
@@ -12,12 +12,14 @@ import (
 */
 
 type BankRepository struct {
-	CreateFunc      func(ctx context.Context, input domain.CreateBankInput) (*domain.Bank, error)
-	GetByIDFunc     func(ctx context.Context, id string) (*domain.Bank, error)
-	UpdateFunc      func(ctx context.Context, id string, input domain.UpdateBankInput) (*domain.Bank, error)
-	SetInactiveFunc func(ctx context.Context, id string) (*domain.Bank, error)
-	SetActiveFunc   func(ctx context.Context, id string) (*domain.Bank, error)
-	ListFunc        func(ctx context.Context, limit, offset int) ([]*domain.Bank, error)
+	CreateFunc       func(ctx context.Context, input domain.CreateBankInput) (*domain.Bank, error)
+	GetByIDFunc      func(ctx context.Context, id string) (*domain.Bank, error)
+	GetForUpdateFunc func(ctx context.Context, id string) (*domain.Bank, error)
+	UpdateFunc       func(ctx context.Context, id string, input domain.UpdateBankInput) (*domain.Bank, error)
+	PatchFunc        func(ctx context.Context, id string, patch domain.BankPatch) (*domain.Bank, error)
+	SetInactiveFunc  func(ctx context.Context, id string) (*domain.Bank, error)
+	SetActiveFunc    func(ctx context.Context, id string) (*domain.Bank, error)
+	ListFunc         func(ctx context.Context, limit, offset int) ([]*domain.Bank, error)
 }
 
 func (m *BankRepository) Create(ctx context.Context, input domain.CreateBankInput) (*domain.Bank, error) {
@@ -34,6 +36,13 @@ func (m *BankRepository) GetByID(ctx context.Context, id string) (*domain.Bank,
 	return nil, nil
 }
 
+func (m *BankRepository) GetForUpdate(ctx context.Context, id string) (*domain.Bank, error) {
+	if m.GetForUpdateFunc != nil {
+		return m.GetForUpdateFunc(ctx, id)
+	}
+	return nil, nil
+}
+
 func (m *BankRepository) Update(ctx context.Context, id string, input domain.UpdateBankInput) (*domain.Bank, error) {
 	if m.UpdateFunc != nil {
 		return m.UpdateFunc(ctx, id, input)
@@ -41,6 +50,13 @@ func (m *BankRepository) Update(ctx context.Context, id string, input domain.Upd
 	return nil, nil
 }
 
+func (m *BankRepository) Patch(ctx context.Context, id string, patch domain.BankPatch) (*domain.Bank, error) {
+	if m.PatchFunc != nil {
+		return m.PatchFunc(ctx, id, patch)
+	}
+	return nil, nil
+}
+
 func (m *BankRepository) SetInactive(ctx context.Context, id string) (*domain.Bank, error) {
 	if m.SetInactiveFunc != nil {
 		return m.SetInactiveFunc(ctx, id)
@@ -12,12 +12,14 @@ import (
 */
 
 type ClientRepository struct {
-	CreateFunc      func(ctx context.Context, input domain.CreateClientInput) (*domain.Client, error)
-	GetByIDFunc     func(ctx context.Context, id string) (*domain.Client, error)
-	UpdateFunc      func(ctx context.Context, id string, input domain.UpdateClientInput) (*domain.Client, error)
-	SetInactiveFunc func(ctx context.Context, id string) (*domain.Client, error)
-	SetActiveFunc   func(ctx context.Context, id string) (*domain.Client, error)
-	ListFunc        func(ctx context.Context, limit, offset int) ([]*domain.Client, error)
+	CreateFunc       func(ctx context.Context, input domain.CreateClientInput) (*domain.Client, error)
+	GetByIDFunc      func(ctx context.Context, id string) (*domain.Client, error)
+	GetForUpdateFunc func(ctx context.Context, id string) (*domain.Client, error)
+	UpdateFunc       func(ctx context.Context, id string, input domain.UpdateClientInput) (*domain.Client, error)
+	PatchFunc        func(ctx context.Context, id string, patch domain.ClientPatch) (*domain.Client, error)
+	SetInactiveFunc  func(ctx context.Context, id string) (*domain.Client, error)
+	SetActiveFunc    func(ctx context.Context, id string) (*domain.Client, error)
+	ListFunc         func(ctx context.Context, limit, offset int) ([]*domain.Client, error)
 }
 
 func (m *ClientRepository) Create(ctx context.Context, input domain.CreateClientInput) (*domain.Client, error) {
@@ -34,6 +36,13 @@ func (m *ClientRepository) GetByID(ctx context.Context, id string) (*domain.Clie
 	return nil, nil
 }
 
+func (m *ClientRepository) GetForUpdate(ctx context.Context, id string) (*domain.Client, error) {
+	if m.GetForUpdateFunc != nil {
+		return m.GetForUpdateFunc(ctx, id)
+	}
+	return nil, nil
+}
+
 func (m *ClientRepository) Update(ctx context.Context, id string, input domain.UpdateClientInput) (*domain.Client, error) {
 	if m.UpdateFunc != nil {
 		return m.UpdateFunc(ctx, id, input)
@@ -41,6 +50,13 @@ func (m *ClientRepository) Update(ctx context.Context, id string, input domain.U
 	return nil, nil
 }
 
+func (m *ClientRepository) Patch(ctx context.Context, id string, patch domain.ClientPatch) (*domain.Client, error) {
+	if m.PatchFunc != nil {
+		return m.PatchFunc(ctx, id, patch)
+	}
+	return nil, nil
+}
+
 func (m *ClientRepository) SetInactive(ctx context.Context, id string) (*domain.Client, error) {
 	if m.SetInactiveFunc != nil {
 		return m.SetInactiveFunc(ctx, id)
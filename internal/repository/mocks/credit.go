@@ -12,9 +12,10 @@ import (
 */
 
 type CreditRepository struct {
-	CreateFunc         func(ctx context.Context, input domain.CreateCreditInput) (*domain.Credit, error)
+	CreateFunc         func(ctx context.Context, input domain.CreateCreditInput, status domain.CreditStatus) (*domain.Credit, error)
 	GetByIDFunc        func(ctx context.Context, id string) (*domain.Credit, error)
 	UpdateFunc         func(ctx context.Context, id string, input domain.UpdateCreditInput) (*domain.Credit, error)
+	PatchFunc          func(ctx context.Context, id string, patch domain.CreditPatch) (*domain.Credit, error)
 	UpdateStatusFunc   func(ctx context.Context, id string, status domain.CreditStatus) (*domain.Credit, error)
 	SetInactiveFunc    func(ctx context.Context, id string) (*domain.Credit, error)
 	SetActiveFunc      func(ctx context.Context, id string) (*domain.Credit, error)
@@ -22,9 +23,9 @@ type CreditRepository struct {
 	ListByClientIDFunc func(ctx context.Context, clientID string, limit, offset int) ([]*domain.Credit, error)
 }
 
-func (m *CreditRepository) Create(ctx context.Context, input domain.CreateCreditInput) (*domain.Credit, error) {
+func (m *CreditRepository) Create(ctx context.Context, input domain.CreateCreditInput, status domain.CreditStatus) (*domain.Credit, error) {
 	if m.CreateFunc != nil {
-		return m.CreateFunc(ctx, input)
+		return m.CreateFunc(ctx, input, status)
 	}
 	return nil, nil
 }
@@ -43,6 +44,13 @@ func (m *CreditRepository) Update(ctx context.Context, id string, input domain.U
 	return nil, nil
 }
 
+func (m *CreditRepository) Patch(ctx context.Context, id string, patch domain.CreditPatch) (*domain.Credit, error) {
+	if m.PatchFunc != nil {
+		return m.PatchFunc(ctx, id, patch)
+	}
+	return nil, nil
+}
+
 func (m *CreditRepository) UpdateStatus(ctx context.Context, id string, status domain.CreditStatus) (*domain.Credit, error) {
 	if m.UpdateStatusFunc != nil {
 		return m.UpdateStatusFunc(ctx, id, status)
@@ -0,0 +1,23 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/tucredito/backend-api/internal/domain"
+)
+
+/*
+	CreditExportRepository is a mock for repository.CreditExportRepository
+	Used for testing purposes
+*/
+
+type CreditExportRepository struct {
+	StreamCreditsFunc func(ctx context.Context, filter domain.CreditExportFilter, fn func(row *domain.CreditExportRow) error) error
+}
+
+func (m *CreditExportRepository) StreamCredits(ctx context.Context, filter domain.CreditExportFilter, fn func(row *domain.CreditExportRow) error) error {
+	if m.StreamCreditsFunc != nil {
+		return m.StreamCreditsFunc(ctx, filter, fn)
+	}
+	return nil
+}
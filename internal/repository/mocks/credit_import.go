@@ -0,0 +1,39 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/tucredito/backend-api/internal/domain"
+)
+
+/*
+	CreditImportRepository is a mock for repository.CreditImportRepository
+	Used for testing purposes
+*/
+
+type CreditImportRepository struct {
+	CreateFunc   func(ctx context.Context, format domain.ImportFormat) (*domain.CreditImport, error)
+	CompleteFunc func(ctx context.Context, id string, input domain.CompleteCreditImportInput) (*domain.CreditImport, error)
+	GetByIDFunc  func(ctx context.Context, id string) (*domain.CreditImport, error)
+}
+
+func (m *CreditImportRepository) Create(ctx context.Context, format domain.ImportFormat) (*domain.CreditImport, error) {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, format)
+	}
+	return nil, nil
+}
+
+func (m *CreditImportRepository) Complete(ctx context.Context, id string, input domain.CompleteCreditImportInput) (*domain.CreditImport, error) {
+	if m.CompleteFunc != nil {
+		return m.CompleteFunc(ctx, id, input)
+	}
+	return nil, nil
+}
+
+func (m *CreditImportRepository) GetByID(ctx context.Context, id string) (*domain.CreditImport, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
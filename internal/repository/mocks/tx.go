@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+/*
+	TxManager is a mock for repository.TxManager
+	It runs fn directly (or WithinTxFunc) and counts units of work that committed and rolled back
+*/
+
+type TxManager struct {
+	WithinTxFunc func(ctx context.Context, fn func(ctx context.Context) error) error
+	Committed    atomic.Int32
+	RolledBack   atomic.Int32
+}
+
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	var err error
+	if m.WithinTxFunc != nil {
+		err = m.WithinTxFunc(ctx, fn)
+	} else {
+		err = fn(ctx)
+	}
+	if err != nil {
+		m.RolledBack.Add(1)
+	} else {
+		m.Committed.Add(1)
+	}
+	return err
+}
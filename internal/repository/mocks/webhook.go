@@ -0,0 +1,112 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/tucredito/backend-api/internal/domain"
+)
+
+/*
+	WebhookRepository is a mock for repository.WebhookRepository
+	Used for testing purposes
+*/
+
+type WebhookRepository struct {
+	CreateSubscriptionFunc func(ctx context.Context, input domain.CreateWebhookSubscriptionInput) (*domain.WebhookSubscription, error)
+	GetSubscriptionFunc    func(ctx context.Context, id string) (*domain.WebhookSubscription, error)
+	UpdateSubscriptionFunc func(ctx context.Context, id string, input domain.UpdateWebhookSubscriptionInput) (*domain.WebhookSubscription, error)
+	DeleteSubscriptionFunc func(ctx context.Context, id string) (*domain.WebhookSubscription, error)
+	ListSubscriptionsFunc  func(ctx context.Context, limit, offset int) ([]*domain.WebhookSubscription, error)
+	SubscribersFunc        func(ctx context.Context, eventType domain.EventType, bankID string) ([]*domain.WebhookSubscription, error)
+	CreateDeliveryFunc     func(ctx context.Context, input domain.CreateWebhookDeliveryInput) (*domain.WebhookDelivery, error)
+	ClaimDueFunc           func(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error)
+	RecordAttemptFunc      func(ctx context.Context, id string, input domain.RecordWebhookAttemptInput) (*domain.WebhookDelivery, error)
+	GetDeliveryFunc        func(ctx context.Context, subscriptionID, id string) (*domain.WebhookDelivery, error)
+	ListDeliveriesFunc     func(ctx context.Context, subscriptionID string, status domain.WebhookDeliveryStatus, limit, offset int) ([]*domain.WebhookDelivery, error)
+	RedeliverFunc          func(ctx context.Context, subscriptionID, id string) (*domain.WebhookDelivery, error)
+}
+
+func (m *WebhookRepository) CreateSubscription(ctx context.Context, input domain.CreateWebhookSubscriptionInput) (*domain.WebhookSubscription, error) {
+	if m.CreateSubscriptionFunc != nil {
+		return m.CreateSubscriptionFunc(ctx, input)
+	}
+	return nil, nil
+}
+
+func (m *WebhookRepository) GetSubscription(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	if m.GetSubscriptionFunc != nil {
+		return m.GetSubscriptionFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *WebhookRepository) UpdateSubscription(ctx context.Context, id string, input domain.UpdateWebhookSubscriptionInput) (*domain.WebhookSubscription, error) {
+	if m.UpdateSubscriptionFunc != nil {
+		return m.UpdateSubscriptionFunc(ctx, id, input)
+	}
+	return nil, nil
+}
+
+func (m *WebhookRepository) DeleteSubscription(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	if m.DeleteSubscriptionFunc != nil {
+		return m.DeleteSubscriptionFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *WebhookRepository) ListSubscriptions(ctx context.Context, limit, offset int) ([]*domain.WebhookSubscription, error) {
+	if m.ListSubscriptionsFunc != nil {
+		return m.ListSubscriptionsFunc(ctx, limit, offset)
+	}
+	return nil, nil
+}
+
+func (m *WebhookRepository) Subscribers(ctx context.Context, eventType domain.EventType, bankID string) ([]*domain.WebhookSubscription, error) {
+	if m.SubscribersFunc != nil {
+		return m.SubscribersFunc(ctx, eventType, bankID)
+	}
+	return nil, nil
+}
+
+func (m *WebhookRepository) CreateDelivery(ctx context.Context, input domain.CreateWebhookDeliveryInput) (*domain.WebhookDelivery, error) {
+	if m.CreateDeliveryFunc != nil {
+		return m.CreateDeliveryFunc(ctx, input)
+	}
+	return nil, nil
+}
+
+func (m *WebhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error) {
+	if m.ClaimDueFunc != nil {
+		return m.ClaimDueFunc(ctx, limit, lease)
+	}
+	return nil, nil
+}
+
+func (m *WebhookRepository) RecordAttempt(ctx context.Context, id string, input domain.RecordWebhookAttemptInput) (*domain.WebhookDelivery, error) {
+	if m.RecordAttemptFunc != nil {
+		return m.RecordAttemptFunc(ctx, id, input)
+	}
+	return nil, nil
+}
+
+func (m *WebhookRepository) GetDelivery(ctx context.Context, subscriptionID, id string) (*domain.WebhookDelivery, error) {
+	if m.GetDeliveryFunc != nil {
+		return m.GetDeliveryFunc(ctx, subscriptionID, id)
+	}
+	return nil, nil
+}
+
+func (m *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID string, status domain.WebhookDeliveryStatus, limit, offset int) ([]*domain.WebhookDelivery, error) {
+	if m.ListDeliveriesFunc != nil {
+		return m.ListDeliveriesFunc(ctx, subscriptionID, status, limit, offset)
+	}
+	return nil, nil
+}
+
+func (m *WebhookRepository) Redeliver(ctx context.Context, subscriptionID, id string) (*domain.WebhookDelivery, error) {
+	if m.RedeliverFunc != nil {
+		return m.RedeliverFunc(ctx, subscriptionID, id)
+	}
+	return nil, nil
+}
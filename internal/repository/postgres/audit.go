@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tucredito/backend-api/internal/domain"
+)
+
+type AuditRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAuditRepository(pool *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{pool: pool}
+}
+
+// Lists the audit entries of an entity, oldest first
+// Bank-scoped requests only see the history of entities visible to that bank
+func (r *AuditRepository) ListByEntity(ctx context.Context, entity domain.AuditEntity, entityID string, limit, offset int) ([]*domain.AuditEntry, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	scope, args := tenantScope(ctx, []interface{}{entity, entityID}, auditVisibleTo(entity))
+	page, args := paginate(args, limit, offset)
+	return r.list(ctx, `
+		SELECT id, entity, entity_id, action, actor, request_id, client_ip, changes, occurred_at
+		FROM audit_log WHERE entity = $1 AND entity_id = $2`+scope+`
+		ORDER BY occurred_at ASC, id ASC`+page, args)
+}
+
+// Lists the audit entries of an entity type recorded in [from, to), oldest first, with the same scoping
+func (r *AuditRepository) ListByRange(ctx context.Context, entity domain.AuditEntity, from, to time.Time, limit, offset int) ([]*domain.AuditEntry, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	scope, args := tenantScope(ctx, []interface{}{entity, from, to}, auditVisibleTo(entity))
+	page, args := paginate(args, limit, offset)
+	return r.list(ctx, `
+		SELECT id, entity, entity_id, action, actor, request_id, client_ip, changes, occurred_at
+		FROM audit_log WHERE entity = $1 AND occurred_at >= $2 AND occurred_at < $3`+scope+`
+		ORDER BY occurred_at ASC, id ASC`+page, args)
+}
+
+func (r *AuditRepository) list(ctx context.Context, query string, args []interface{}) ([]*domain.AuditEntry, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	var list []*domain.AuditEntry
+	for rows.Next() {
+		var e domain.AuditEntry
+		if err := rows.Scan(&e.ID, &e.Entity, &e.EntityID, &e.Action, &e.Actor, &e.RequestID, &e.ClientIP, &e.Changes, &e.OccurredAt); err != nil {
+			return nil, dbError(err)
+		}
+		list = append(list, &e)
+	}
+	return list, dbError(rows.Err())
+}
+
+// Visibility of the audited entity for a bank, mirroring the scoping of its repository
+func auditVisibleTo(entity domain.AuditEntity) func(param string) string {
+	return func(param string) string {
+		switch entity {
+		case domain.AuditEntityCredit:
+			return "EXISTS (SELECT 1 FROM credits ac WHERE ac.id = audit_log.entity_id AND ac.bank_id = " + param + ")"
+		case domain.AuditEntityClient:
+			return "EXISTS (SELECT 1 FROM clients acl WHERE acl.id = audit_log.entity_id AND " + clientVisibleTo("acl")(param) + ")"
+		case domain.AuditEntityBank:
+			return "audit_log.entity_id = " + param
+		default:
+			return "FALSE"
+		}
+	}
+}
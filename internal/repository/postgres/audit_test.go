@@ -0,0 +1,80 @@
+package postgres_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/audit"
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/repository/postgres"
+)
+
+func TestAuditRepository_RecordsMutations(t *testing.T) {
+	pool := testDBPool(t)
+	defer pool.Close()
+	ctx := audit.WithClientIP(audit.WithRequestID(audit.WithActor(context.Background(), "tester"), "req-audit"), "203.0.113.7")
+	banks := postgres.NewBankRepository(pool)
+	repo := postgres.NewAuditRepository(pool)
+
+	created, err := banks.Create(ctx, domain.CreateBankInput{Name: "Audited Bank", Type: domain.BankTypePrivate})
+	require.NoError(t, err)
+	require.NotNil(t, created)
+	defer deleteBank(t, pool, created.ID)
+
+	_, err = banks.Update(ctx, created.ID, domain.UpdateBankInput{Name: "Audited Bank 2", Type: domain.BankTypePrivate})
+	require.NoError(t, err)
+	_, err = banks.SetInactive(ctx, created.ID)
+	require.NoError(t, err)
+
+	entries, err := repo.ListByEntity(context.Background(), domain.AuditEntityBank, created.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, domain.AuditActionCreate, entries[0].Action)
+	assert.Equal(t, domain.AuditActionUpdate, entries[1].Action)
+	assert.Equal(t, domain.AuditActionDeactivate, entries[2].Action)
+	assert.Equal(t, "tester", entries[1].Actor)
+	assert.Equal(t, "req-audit", entries[1].RequestID)
+	assert.Equal(t, "203.0.113.7", entries[1].ClientIP)
+
+	var changes map[string]audit.Change
+	require.NoError(t, json.Unmarshal(entries[1].Changes, &changes))
+	require.Len(t, changes, 1)
+	assert.Equal(t, "Audited Bank", changes["name"].Before)
+	assert.Equal(t, "Audited Bank 2", changes["name"].After)
+}
+
+func TestAuditRepository_AppendOnly(t *testing.T) {
+	pool := testDBPool(t)
+	defer pool.Close()
+	ctx := context.Background()
+	banks := postgres.NewBankRepository(pool)
+
+	created, err := banks.Create(ctx, domain.CreateBankInput{Name: "Append Only Bank", Type: domain.BankTypePrivate})
+	require.NoError(t, err)
+	require.NotNil(t, created)
+	defer deleteBank(t, pool, created.ID)
+
+	_, err = pool.Exec(ctx, "DELETE FROM audit_log WHERE entity_id = $1", created.ID)
+	require.Error(t, err)
+	_, err = pool.Exec(ctx, "UPDATE audit_log SET actor = 'x' WHERE entity_id = $1", created.ID)
+	require.Error(t, err)
+}
+
+func TestAuditRepository_NoMutationNoEntry(t *testing.T) {
+	pool := testDBPool(t)
+	defer pool.Close()
+	ctx := context.Background()
+	banks := postgres.NewBankRepository(pool)
+	id := "00000000-0000-0000-0000-000000000000"
+
+	got, err := banks.Update(ctx, id, domain.UpdateBankInput{Name: "Ghost", Type: domain.BankTypePrivate})
+	require.NoError(t, err)
+	require.Nil(t, got)
+
+	entries, err := postgres.NewAuditRepository(pool).ListByEntity(ctx, domain.AuditEntityBank, id, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -6,6 +6,8 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/repository"
+	"github.com/tucredito/backend-api/internal/tenant"
 )
 
 type BankRepository struct {
@@ -18,76 +20,72 @@ func NewBankRepository(pool *pgxpool.Pool) *BankRepository {
 	}
 }
 
-// Creates a new bank
+// Creates a new bank; onboarding banks is a platform operation
 func (r *BankRepository) Create(ctx context.Context, input domain.CreateBankInput) (*domain.Bank, error) {
+	if _, scoped := tenant.BankID(ctx); scoped {
+		return nil, tenant.ErrCrossTenant
+	}
 	id := uuid.New().String()
-	var b domain.Bank
 	query := `
 		INSERT INTO banks (id, name, type, created_at, is_active)
 		VALUES ($1, $2, $3, NOW(), TRUE)
-		RETURNING id, name, type, is_active
-	`
-	err := r.pool.QueryRow(ctx, query, id, input.Name, input.Type).Scan(&b.ID, &b.Name, &b.Type, &b.IsActive)
-	if err != nil {
-		return nil, err
-	}
-	return &b, nil
+		RETURNING ` + bankColumns
+	return auditedMutation(ctx, r.pool, domain.AuditEntityBank, domain.AuditActionCreate, nil,
+		func(ctx context.Context, q querier, _ *domain.Bank) (*domain.Bank, error) {
+			return scanBank(q.QueryRow(ctx, query, id, input.Name, input.Type))
+		}, bankID)
 }
 
 // Gets a bank by ID
 func (r *BankRepository) GetByID(ctx context.Context, id string) (*domain.Bank, error) {
-	query := `SELECT id, name, type, is_active FROM banks WHERE id = $1 AND is_active = TRUE`
-	var b domain.Bank
-	err := r.pool.QueryRow(ctx, query, id).Scan(&b.ID, &b.Name, &b.Type, &b.IsActive)
-	if err != nil {
-		if isNotFound(err) {
-			return nil, nil
-		}
-		return nil, err
-	}
-	return &b, nil
+	scope, args := tenantScope(ctx, []interface{}{id}, ownedBy("id"))
+	query := `SELECT ` + bankColumns + ` FROM banks WHERE id = $1 AND is_active = TRUE` + scope
+	return scanBank(conn(ctx, r.pool).QueryRow(ctx, query, args...))
+}
+
+// Gets a bank through Update's lock query (see lockBank); outside a transaction the row lock ends with it
+func (r *BankRepository) GetForUpdate(ctx context.Context, id string) (*domain.Bank, error) {
+	return lockBank(id)(ctx, conn(ctx, r.pool))
 }
 
 // Updates a bank
 func (r *BankRepository) Update(ctx context.Context, id string, input domain.UpdateBankInput) (*domain.Bank, error) {
-	query := `UPDATE banks SET name = $1, type = $2 WHERE id = $3 RETURNING id, name, type, is_active`
-	var b domain.Bank
-	err := r.pool.QueryRow(ctx, query, input.Name, input.Type, id).Scan(&b.ID, &b.Name, &b.Type, &b.IsActive)
-	if err != nil {
-		if isNotFound(err) {
-			return nil, nil
-		}
-		return nil, err
-	}
-	return &b, nil
+	query := `UPDATE banks SET name = $1, type = $2, version = version + 1 WHERE id = $3 AND version = $4 RETURNING ` + bankColumns
+	return auditedMutation(ctx, r.pool, domain.AuditEntityBank, domain.AuditActionUpdate, lockBank(id),
+		func(ctx context.Context, q querier, before *domain.Bank) (*domain.Bank, error) {
+			return scanBank(q.QueryRow(ctx, query, input.Name, input.Type, id, before.Version))
+		}, bankID)
+}
+
+// Updates the fields a merge patch provides
+func (r *BankRepository) Patch(ctx context.Context, id string, patch domain.BankPatch) (*domain.Bank, error) {
+	set := &assignments{}
+	setIf(set, "name", patch.Name)
+	setIf(set, "type", patch.Type)
+	set.expr("version = version + 1")
+	return auditedMutation(ctx, r.pool, domain.AuditEntityBank, domain.AuditActionUpdate, lockBank(id),
+		func(ctx context.Context, q querier, before *domain.Bank) (*domain.Bank, error) {
+			query := `UPDATE banks SET ` + set.sql() + ` WHERE id = ` + set.arg(id) + ` AND version = ` + set.arg(before.Version) + ` RETURNING ` + bankColumns
+			return scanBank(q.QueryRow(ctx, query, set.args...))
+		}, bankID)
 }
 
 // Soft-deletes a bank
 func (r *BankRepository) SetInactive(ctx context.Context, id string) (*domain.Bank, error) {
-	query := `UPDATE banks SET is_active = FALSE WHERE id = $1 RETURNING id, name, type, is_active`
-	var b domain.Bank
-	err := r.pool.QueryRow(ctx, query, id).Scan(&b.ID, &b.Name, &b.Type, &b.IsActive)
-	if err != nil {
-		if isNotFound(err) {
-			return nil, nil
-		}
-		return nil, err
-	}
-	return &b, nil
+	query := `UPDATE banks SET is_active = FALSE, version = version + 1 WHERE id = $1 AND version = $2 RETURNING ` + bankColumns
+	return auditedMutation(ctx, r.pool, domain.AuditEntityBank, domain.AuditActionDeactivate, lockBank(id),
+		func(ctx context.Context, q querier, before *domain.Bank) (*domain.Bank, error) {
+			return scanBank(q.QueryRow(ctx, query, id, before.Version))
+		}, bankID)
 }
 
 // Re-enables a bank
 func (r *BankRepository) SetActive(ctx context.Context, id string) (*domain.Bank, error) {
-	query := `UPDATE banks SET is_active = TRUE WHERE id = $1 RETURNING id, name, type, is_active`
-	var b domain.Bank
-	err := r.pool.QueryRow(ctx, query, id).Scan(&b.ID, &b.Name, &b.Type, &b.IsActive)
-	if err != nil {
-		if isNotFound(err) {
-			return nil, nil
-		}
-		return nil, err
-	}
-	return &b, nil
+	query := `UPDATE banks SET is_active = TRUE, version = version + 1 WHERE id = $1 AND version = $2 RETURNING ` + bankColumns
+	return auditedMutation(ctx, r.pool, domain.AuditEntityBank, domain.AuditActionActivate, lockBank(id),
+		func(ctx context.Context, q querier, before *domain.Bank) (*domain.Bank, error) {
+			return scanBank(q.QueryRow(ctx, query, id, before.Version))
+		}, bankID)
 }
 
 // Lists banks with pagination
@@ -95,19 +93,36 @@ func (r *BankRepository) List(ctx context.Context, limit, offset int) ([]*domain
 	if limit <= 0 {
 		limit = 20
 	}
-	query := `SELECT id, name, type, is_active FROM banks WHERE is_active = TRUE ORDER BY name LIMIT $1 OFFSET $2`
-	rows, err := r.pool.Query(ctx, query, limit, offset)
+	scope, args := tenantScope(ctx, nil, ownedBy("id"))
+	page, args := paginate(args, limit, offset)
+	query := `SELECT ` + bankColumns + ` FROM banks WHERE is_active = TRUE` + scope + ` ORDER BY name` + page
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, dbError(err)
 	}
 	defer rows.Close()
 	var list []*domain.Bank
 	for rows.Next() {
-		var b domain.Bank
-		if err := rows.Scan(&b.ID, &b.Name, &b.Type, &b.IsActive); err != nil {
-			return nil, err
+		b, err := scanBank(rows)
+		if err != nil {
+			return nil, dbError(err)
+		}
+		list = append(list, b)
+	}
+	return list, dbError(rows.Err())
+}
+
+// Loads a bank FOR UPDATE as the "before" state of an audited mutation; a scoped request only sees its own bank
+// A row at a version other than the expected ones in ctx fails with repository.ErrVersionMismatch
+func lockBank(id string) func(ctx context.Context, q querier) (*domain.Bank, error) {
+	return func(ctx context.Context, q querier) (*domain.Bank, error) {
+		scope, args := tenantScope(ctx, []interface{}{id}, ownedBy("id"))
+		b, err := scanBank(q.QueryRow(ctx, `SELECT `+bankColumns+` FROM banks WHERE id = $1`+scope+` FOR UPDATE`, args...))
+		if err != nil || b == nil {
+			return b, err
 		}
-		list = append(list, &b)
+		return b, repository.CheckVersion(ctx, b.Version)
 	}
-	return list, rows.Err()
 }
+
+func bankID(b *domain.Bank) string { return b.ID }
@@ -6,6 +6,8 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/repository"
+	"github.com/tucredito/backend-api/internal/tenant"
 )
 
 type ClientRepository struct {
@@ -18,98 +20,79 @@ func NewClientRepository(pool *pgxpool.Pool) *ClientRepository {
 	}
 }
 
-// Creates a new client
+// Creates a new client, owned by the requesting bank when the request is tenant-scoped
 func (r *ClientRepository) Create(ctx context.Context, client domain.CreateClientInput) (*domain.Client, error) {
 	// Generate a new UUID for the client
 	id := uuid.New().String()
-	var c domain.Client
-	query := `
-		INSERT INTO clients (id, full_name, email, birth_date, country, created_at, is_active)
-		VALUES ($1, $2, $3, $4, $5, NOW(), TRUE)
-		RETURNING id, full_name, email, birth_date, country, created_at, is_active
-	`
-	err := r.pool.QueryRow(ctx, query,
-		id, client.FullName, client.Email, client.BirthDate, client.Country,
-	).Scan(&c.ID, &c.FullName, &c.Email, &c.BirthDate, &c.Country, &c.CreatedAt, &c.IsActive)
-	if err != nil {
-		return nil, err
+	var owner *string
+	if bankID, ok := tenant.BankID(ctx); ok {
+		owner = &bankID
 	}
-
-	return &c, nil
+	query := `
+		INSERT INTO clients (id, full_name, email, birth_date, country, created_at, is_active, owner_bank_id)
+		VALUES ($1, $2, $3, $4, $5, NOW(), TRUE, $6)
+		RETURNING ` + clientColumns
+	return auditedMutation(ctx, r.pool, domain.AuditEntityClient, domain.AuditActionCreate, nil,
+		func(ctx context.Context, q querier, _ *domain.Client) (*domain.Client, error) {
+			return scanClient(q.QueryRow(ctx, query, id, client.FullName, client.Email, client.BirthDate, client.Country, owner))
+		}, clientID)
 }
 
 // Gets a client by ID
 func (r *ClientRepository) GetByID(ctx context.Context, id string) (*domain.Client, error) {
-	var c domain.Client
-	query := `SELECT id, full_name, email, birth_date, country, created_at, is_active FROM clients WHERE id = $1 AND is_active = TRUE`
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&c.ID, &c.FullName, &c.Email, &c.BirthDate, &c.Country, &c.CreatedAt, &c.IsActive,
-	)
-	if err != nil {
-		if isNotFound(err) {
-			return nil, nil
-		}
-		return nil, err
-	}
-	return &c, nil
+	scope, args := tenantScope(ctx, []interface{}{id}, clientVisibleTo("clients"))
+	query := `SELECT ` + clientColumns + ` FROM clients WHERE id = $1 AND is_active = TRUE` + scope
+	return scanClient(conn(ctx, r.pool).QueryRow(ctx, query, args...))
+}
+
+// Gets a client through Update's lock query (see lockClient); outside a transaction the row lock ends with it
+func (r *ClientRepository) GetForUpdate(ctx context.Context, id string) (*domain.Client, error) {
+	return lockClient(id)(ctx, conn(ctx, r.pool))
 }
 
 // Updates a client
 func (r *ClientRepository) Update(ctx context.Context, id string, input domain.UpdateClientInput) (*domain.Client, error) {
 	query := `
-		UPDATE clients SET full_name = $1, email = $2, birth_date = $3, country = $4
-		WHERE id = $5
-		RETURNING id, full_name, email, birth_date, country, created_at, is_active
-	`
-	var c domain.Client
-	err := r.pool.QueryRow(ctx, query, input.FullName, input.Email, input.BirthDate, input.Country, id).Scan(
-		&c.ID, &c.FullName, &c.Email, &c.BirthDate, &c.Country, &c.CreatedAt, &c.IsActive,
-	)
-	if err != nil {
-		if isNotFound(err) {
-			return nil, nil
-		}
-		return nil, err
-	}
-	return &c, nil
+		UPDATE clients SET full_name = $1, email = $2, birth_date = $3, country = $4, version = version + 1
+		WHERE id = $5 AND version = $6
+		RETURNING ` + clientColumns
+	return auditedMutation(ctx, r.pool, domain.AuditEntityClient, domain.AuditActionUpdate, lockClient(id),
+		func(ctx context.Context, q querier, before *domain.Client) (*domain.Client, error) {
+			return scanClient(q.QueryRow(ctx, query, input.FullName, input.Email, input.BirthDate, input.Country, id, before.Version))
+		}, clientID)
+}
+
+// Updates the fields a merge patch provides
+func (r *ClientRepository) Patch(ctx context.Context, id string, patch domain.ClientPatch) (*domain.Client, error) {
+	set := &assignments{}
+	setIf(set, "full_name", patch.FullName)
+	setIf(set, "email", patch.Email)
+	setIf(set, "birth_date", patch.BirthDate)
+	setIf(set, "country", patch.Country)
+	set.expr("version = version + 1")
+	return auditedMutation(ctx, r.pool, domain.AuditEntityClient, domain.AuditActionUpdate, lockClient(id),
+		func(ctx context.Context, q querier, before *domain.Client) (*domain.Client, error) {
+			query := `UPDATE clients SET ` + set.sql() + ` WHERE id = ` + set.arg(id) + ` AND version = ` + set.arg(before.Version) + ` RETURNING ` + clientColumns
+			return scanClient(q.QueryRow(ctx, query, set.args...))
+		}, clientID)
 }
 
 // Soft-deletes a client
 func (r *ClientRepository) SetInactive(ctx context.Context, id string) (*domain.Client, error) {
-	query := `
-		UPDATE clients SET is_active = FALSE WHERE id = $1
-		RETURNING id, full_name, email, birth_date, country, created_at, is_active
-	`
-	var c domain.Client
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&c.ID, &c.FullName, &c.Email, &c.BirthDate, &c.Country, &c.CreatedAt, &c.IsActive,
-	)
-	if err != nil {
-		if isNotFound(err) {
-			return nil, nil
-		}
-		return nil, err
-	}
-	return &c, nil
+	query := `UPDATE clients SET is_active = FALSE, version = version + 1 WHERE id = $1 AND version = $2 RETURNING ` + clientColumns
+	return auditedMutation(ctx, r.pool, domain.AuditEntityClient, domain.AuditActionDeactivate, lockClient(id),
+		func(ctx context.Context, q querier, before *domain.Client) (*domain.Client, error) {
+			return scanClient(q.QueryRow(ctx, query, id, before.Version))
+		}, clientID)
 }
 
 // Re-enables a client
 func (r *ClientRepository) SetActive(ctx context.Context, id string) (*domain.Client, error) {
-	query := `
-		UPDATE clients SET is_active = TRUE WHERE id = $1
-		RETURNING id, full_name, email, birth_date, country, created_at, is_active
-	`
-	var c domain.Client
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&c.ID, &c.FullName, &c.Email, &c.BirthDate, &c.Country, &c.CreatedAt, &c.IsActive,
-	)
-	if err != nil {
-		if isNotFound(err) {
-			return nil, nil
-		}
-		return nil, err
-	}
-	return &c, nil
+	query := `UPDATE clients SET is_active = TRUE, version = version + 1 WHERE id = $1 AND version = $2 RETURNING ` + clientColumns
+	return auditedMutation(ctx, r.pool, domain.AuditEntityClient, domain.AuditActionActivate, lockClient(id),
+		func(ctx context.Context, q querier, before *domain.Client) (*domain.Client, error) {
+			return scanClient(q.QueryRow(ctx, query, id, before.Version))
+		}, clientID)
 }
 
 // Lists clients with pagination
@@ -117,22 +100,39 @@ func (r *ClientRepository) List(ctx context.Context, limit, offset int) ([]*doma
 	if limit <= 0 {
 		limit = 20
 	}
+	scope, args := tenantScope(ctx, nil, clientVisibleTo("clients"))
+	page, args := paginate(args, limit, offset)
 	query := `
-		SELECT id, full_name, email, birth_date, country, created_at, is_active
-		FROM clients WHERE is_active = TRUE ORDER BY created_at DESC LIMIT $1 OFFSET $2
-	`
-	rows, err := r.pool.Query(ctx, query, limit, offset)
+		SELECT ` + clientColumns + `
+		FROM clients WHERE is_active = TRUE` + scope + ` ORDER BY created_at DESC` + page
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, dbError(err)
 	}
 	defer rows.Close()
 	var list []*domain.Client
 	for rows.Next() {
-		var c domain.Client
-		if err := rows.Scan(&c.ID, &c.FullName, &c.Email, &c.BirthDate, &c.Country, &c.CreatedAt, &c.IsActive); err != nil {
-			return nil, err
+		c, err := scanClient(rows)
+		if err != nil {
+			return nil, dbError(err)
+		}
+		list = append(list, c)
+	}
+	return list, dbError(rows.Err())
+}
+
+// Loads a client FOR UPDATE as the "before" state of an audited mutation
+// A row at a version other than the expected ones in ctx fails with repository.ErrVersionMismatch
+// Banks may read clients they hold credits with, but only change the clients they onboarded
+func lockClient(id string) func(ctx context.Context, q querier) (*domain.Client, error) {
+	return func(ctx context.Context, q querier) (*domain.Client, error) {
+		scope, args := tenantScope(ctx, []interface{}{id}, ownedBy("owner_bank_id"))
+		c, err := scanClient(q.QueryRow(ctx, `SELECT `+clientColumns+` FROM clients WHERE id = $1`+scope+` FOR UPDATE`, args...))
+		if err != nil || c == nil {
+			return c, err
 		}
-		list = append(list, &c)
+		return c, repository.CheckVersion(ctx, c.Version)
 	}
-	return list, rows.Err()
 }
+
+func clientID(c *domain.Client) string { return c.ID }
@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/repository"
 	"github.com/tucredito/backend-api/internal/repository/postgres"
 )
 
@@ -88,6 +89,66 @@ func TestClientRepository_Update(t *testing.T) {
 	assert.Equal(t, "MX", updated.Country)
 }
 
+func TestClientRepository_Update_VersionMismatch(t *testing.T) {
+	pool := testDBPool(t)
+	defer pool.Close()
+	ctx := context.Background()
+	repo := postgres.NewClientRepository(pool)
+
+	input := domain.CreateClientInput{
+		FullName:  "Versioned",
+		Email:     uniqueClientEmail(t),
+		BirthDate: time.Date(1992, 2, 2, 0, 0, 0, 0, time.UTC),
+		Country:   "US",
+	}
+	created, err := repo.Create(ctx, input)
+	require.NoError(t, err)
+	require.NotNil(t, created)
+	defer deleteClient(t, pool, created.ID)
+	assert.Equal(t, 1, created.Version)
+
+	update := domain.UpdateClientInput{FullName: "First", Email: input.Email, BirthDate: input.BirthDate, Country: "US"}
+	updated, err := repo.Update(repository.WithExpectedVersion(ctx, created.Version), created.ID, update)
+	require.NoError(t, err)
+	require.NotNil(t, updated)
+	assert.Equal(t, 2, updated.Version)
+
+	// A second writer still holding version 1 must not overwrite the change
+	update.FullName = "Second"
+	_, err = repo.Update(repository.WithExpectedVersion(ctx, created.Version), created.ID, update)
+	assert.ErrorIs(t, err, repository.ErrVersionMismatch)
+
+	got, err := repo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "First", got.FullName)
+}
+
+func TestClientRepository_Patch(t *testing.T) {
+	pool := testDBPool(t)
+	defer pool.Close()
+	ctx := context.Background()
+	repo := postgres.NewClientRepository(pool)
+
+	created, err := repo.Create(ctx, domain.CreateClientInput{
+		FullName:  "Patch Me",
+		Email:     uniqueClientEmail(t),
+		BirthDate: time.Date(1992, 2, 2, 0, 0, 0, 0, time.UTC),
+		Country:   "US",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, created)
+	defer deleteClient(t, pool, created.ID)
+
+	country := "MX"
+	patched, err := repo.Patch(ctx, created.ID, domain.ClientPatch{Country: &country})
+	require.NoError(t, err)
+	require.NotNil(t, patched)
+	assert.Equal(t, "MX", patched.Country)
+	assert.Equal(t, created.FullName, patched.FullName)
+	assert.True(t, created.BirthDate.Equal(patched.BirthDate), "omitted fields keep their values")
+	assert.Equal(t, created.Version+1, patched.Version)
+}
+
 func TestClientRepository_SetInactive(t *testing.T) {
 	pool := testDBPool(t)
 	defer pool.Close()
@@ -0,0 +1,27 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tucredito/backend-api/internal/repository/postgres"
+	"github.com/tucredito/backend-api/internal/repository/repotest"
+)
+
+func TestConformance(t *testing.T) {
+	pool := testDBPool(t)
+	defer pool.Close()
+	repotest.Run(t, func(t *testing.T) repotest.Backend {
+		return repotest.Backend{
+			Clients:  postgres.NewClientRepository(pool),
+			Banks:    postgres.NewBankRepository(pool),
+			Credits:  postgres.NewCreditRepository(pool),
+			Audit:    postgres.NewAuditRepository(pool),
+			Webhooks: postgres.NewWebhookRepository(pool),
+			Tx:       postgres.NewTxManager(pool),
+			Purge: func(table, id string) {
+				_, _ = pool.Exec(context.Background(), "DELETE FROM "+table+" WHERE id = $1", id)
+			},
+		}
+	})
+}
@@ -6,6 +6,8 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/repository"
+	"github.com/tucredito/backend-api/internal/tenant"
 )
 
 type CreditRepository struct {
@@ -16,125 +18,91 @@ func NewCreditRepository(pool *pgxpool.Pool) *CreditRepository {
 	return &CreditRepository{pool: pool}
 }
 
-// Creates a new credit
-func (r *CreditRepository) Create(ctx context.Context, input domain.CreateCreditInput) (*domain.Credit, error) {
+// Creates a new credit in its decided status; a bank-scoped request may only create credits for its own bank
+func (r *CreditRepository) Create(ctx context.Context, input domain.CreateCreditInput, status domain.CreditStatus) (*domain.Credit, error) {
+	if !tenant.CanAccessBank(ctx, input.BankID) {
+		return nil, tenant.ErrCrossTenant
+	}
 	id := uuid.New().String()
 	query := `
 		INSERT INTO credits (id, client_id, bank_id, min_payment, max_payment, term_months, credit_type, status, created_at, updated_at, is_active)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, 'PENDING', NOW(), NOW(), TRUE)
-		RETURNING id, client_id, bank_id, min_payment, max_payment, term_months, credit_type, status, created_at, is_active
-	`
-	var c domain.Credit
-	err := r.pool.QueryRow(ctx, query, id, input.ClientID, input.BankID, input.MinPayment, input.MaxPayment, input.TermMonths, input.CreditType).Scan(
-		&c.ID, &c.ClientID, &c.BankID, &c.MinPayment, &c.MaxPayment, &c.TermMonths, &c.CreditType, &c.Status, &c.CreatedAt, &c.IsActive,
-	)
-	if err != nil {
-		return nil, err
-	}
-	return &c, nil
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW(), TRUE)
+		RETURNING ` + creditColumns
+	return auditedMutation(ctx, r.pool, domain.AuditEntityCredit, domain.AuditActionCreate, nil,
+		func(ctx context.Context, q querier, _ *domain.Credit) (*domain.Credit, error) {
+			return scanCredit(q.QueryRow(ctx, query, id, input.ClientID, input.BankID, input.MinPayment, input.MaxPayment, input.TermMonths, input.CreditType, status))
+		}, creditID)
 }
 
 // Gets a credit by ID
 func (r *CreditRepository) GetByID(ctx context.Context, id string) (*domain.Credit, error) {
-	query := `
-		SELECT id, client_id, bank_id, min_payment, max_payment, term_months, credit_type, status, created_at, is_active
-		FROM credits WHERE id = $1
-	`
-	var c domain.Credit
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&c.ID, &c.ClientID, &c.BankID, &c.MinPayment, &c.MaxPayment,
-		&c.TermMonths, &c.CreditType, &c.Status, &c.CreatedAt, &c.IsActive,
-	)
-	if err != nil {
-		if isNotFound(err) {
-			return nil, nil
-		}
-		return nil, err
-	}
-	return &c, nil
+	scope, args := tenantScope(ctx, []interface{}{id}, ownedBy("bank_id"))
+	query := `SELECT ` + creditColumns + ` FROM credits WHERE id = $1` + scope
+	return scanCredit(conn(ctx, r.pool).QueryRow(ctx, query, args...))
 }
 
 // Updates a credit
 func (r *CreditRepository) Update(ctx context.Context, id string, input domain.UpdateCreditInput) (*domain.Credit, error) {
 	query := `
-		UPDATE credits SET min_payment = $1, max_payment = $2, term_months = $3, status = $4, updated_at = NOW()
-		WHERE id = $5
-		RETURNING id, client_id, bank_id, min_payment, max_payment, term_months, credit_type, status, created_at, is_active
-	`
-	var c domain.Credit
-	err := r.pool.QueryRow(ctx, query, input.MinPayment, input.MaxPayment, input.TermMonths, input.Status, id).Scan(
-		&c.ID, &c.ClientID, &c.BankID, &c.MinPayment, &c.MaxPayment, &c.TermMonths, &c.CreditType, &c.Status, &c.CreatedAt, &c.IsActive,
-	)
-	if err != nil {
-		if isNotFound(err) {
-			return nil, nil
-		}
-		return nil, err
-	}
-	return &c, nil
+		UPDATE credits SET min_payment = $1, max_payment = $2, term_months = $3, status = $4, updated_at = NOW(), version = version + 1
+		WHERE id = $5 AND version = $6
+		RETURNING ` + creditColumns
+	return auditedMutation(ctx, r.pool, domain.AuditEntityCredit, domain.AuditActionUpdate, lockCredit(id),
+		func(ctx context.Context, q querier, before *domain.Credit) (*domain.Credit, error) {
+			return scanCredit(q.QueryRow(ctx, query, input.MinPayment, input.MaxPayment, input.TermMonths, input.Status, id, before.Version))
+		}, creditID)
+}
+
+// Updates the fields a merge patch provides
+func (r *CreditRepository) Patch(ctx context.Context, id string, patch domain.CreditPatch) (*domain.Credit, error) {
+	set := &assignments{}
+	setIf(set, "min_payment", patch.MinPayment)
+	setIf(set, "max_payment", patch.MaxPayment)
+	setIf(set, "term_months", patch.TermMonths)
+	setIf(set, "status", patch.Status)
+	set.expr("updated_at = NOW()")
+	set.expr("version = version + 1")
+	return auditedMutation(ctx, r.pool, domain.AuditEntityCredit, domain.AuditActionUpdate, lockCredit(id),
+		func(ctx context.Context, q querier, before *domain.Credit) (*domain.Credit, error) {
+			query := `UPDATE credits SET ` + set.sql() + ` WHERE id = ` + set.arg(id) + ` AND version = ` + set.arg(before.Version) + ` RETURNING ` + creditColumns
+			return scanCredit(q.QueryRow(ctx, query, set.args...))
+		}, creditID)
 }
 
 // Updates a credit status
 func (r *CreditRepository) UpdateStatus(ctx context.Context, id string, status domain.CreditStatus) (*domain.Credit, error) {
 	query := `
-		UPDATE credits SET status = $1, updated_at = NOW()
-		WHERE id = $2
-		RETURNING id, client_id, bank_id, min_payment, max_payment, term_months, credit_type, status, created_at, is_active
-	`
-	var c domain.Credit
-	err := r.pool.QueryRow(ctx, query, status, id).Scan(
-		&c.ID, &c.ClientID, &c.BankID, &c.MinPayment, &c.MaxPayment,
-		&c.TermMonths, &c.CreditType, &c.Status, &c.CreatedAt, &c.IsActive,
-	)
-	if err != nil {
-		if isNotFound(err) {
-			return nil, nil
-		}
-		return nil, err
-	}
-	return &c, nil
+		UPDATE credits SET status = $1, updated_at = NOW(), version = version + 1
+		WHERE id = $2 AND version = $3
+		RETURNING ` + creditColumns
+	return auditedMutation(ctx, r.pool, domain.AuditEntityCredit, domain.AuditActionUpdateStatus, lockCredit(id),
+		func(ctx context.Context, q querier, before *domain.Credit) (*domain.Credit, error) {
+			return scanCredit(q.QueryRow(ctx, query, status, id, before.Version))
+		}, creditID)
 }
 
 // Soft-deletes a credit
 func (r *CreditRepository) SetInactive(ctx context.Context, id string) (*domain.Credit, error) {
 	query := `
-		UPDATE credits SET is_active = FALSE, updated_at = NOW()
-		WHERE id = $1
-		RETURNING id, client_id, bank_id, min_payment, max_payment, term_months, credit_type, status, created_at, is_active
-	`
-	var c domain.Credit
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&c.ID, &c.ClientID, &c.BankID, &c.MinPayment, &c.MaxPayment,
-		&c.TermMonths, &c.CreditType, &c.Status, &c.CreatedAt, &c.IsActive,
-	)
-	if err != nil {
-		if isNotFound(err) {
-			return nil, nil
-		}
-		return nil, err
-	}
-	return &c, nil
+		UPDATE credits SET is_active = FALSE, updated_at = NOW(), version = version + 1
+		WHERE id = $1 AND version = $2
+		RETURNING ` + creditColumns
+	return auditedMutation(ctx, r.pool, domain.AuditEntityCredit, domain.AuditActionDeactivate, lockCredit(id),
+		func(ctx context.Context, q querier, before *domain.Credit) (*domain.Credit, error) {
+			return scanCredit(q.QueryRow(ctx, query, id, before.Version))
+		}, creditID)
 }
 
 // Re-enables a credit
 func (r *CreditRepository) SetActive(ctx context.Context, id string) (*domain.Credit, error) {
 	query := `
-		UPDATE credits SET is_active = TRUE, updated_at = NOW()
-		WHERE id = $1
-		RETURNING id, client_id, bank_id, min_payment, max_payment, term_months, credit_type, status, created_at, is_active
-	`
-	var c domain.Credit
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&c.ID, &c.ClientID, &c.BankID, &c.MinPayment, &c.MaxPayment,
-		&c.TermMonths, &c.CreditType, &c.Status, &c.CreatedAt, &c.IsActive,
-	)
-	if err != nil {
-		if isNotFound(err) {
-			return nil, nil
-		}
-		return nil, err
-	}
-	return &c, nil
+		UPDATE credits SET is_active = TRUE, updated_at = NOW(), version = version + 1
+		WHERE id = $1 AND version = $2
+		RETURNING ` + creditColumns
+	return auditedMutation(ctx, r.pool, domain.AuditEntityCredit, domain.AuditActionActivate, lockCredit(id),
+		func(ctx context.Context, q querier, before *domain.Credit) (*domain.Credit, error) {
+			return scanCredit(q.QueryRow(ctx, query, id, before.Version))
+		}, creditID)
 }
 
 // Lists credits with pagination
@@ -142,13 +110,14 @@ func (r *CreditRepository) List(ctx context.Context, limit, offset int) ([]*doma
 	if limit <= 0 {
 		limit = 20
 	}
+	scope, args := tenantScope(ctx, nil, ownedBy("bank_id"))
+	page, args := paginate(args, limit, offset)
 	query := `
-		SELECT id, client_id, bank_id, min_payment, max_payment, term_months, credit_type, status, created_at, is_active
-		FROM credits WHERE is_active = TRUE ORDER BY created_at DESC LIMIT $1 OFFSET $2
-	`
-	rows, err := r.pool.Query(ctx, query, limit, offset)
+		SELECT ` + creditColumns + `
+		FROM credits WHERE is_active = TRUE` + scope + ` ORDER BY created_at DESC` + page
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, dbError(err)
 	}
 	defer rows.Close()
 	return scanCredits(rows)
@@ -159,14 +128,31 @@ func (r *CreditRepository) ListByClientID(ctx context.Context, clientID string,
 	if limit <= 0 {
 		limit = 20
 	}
+	scope, args := tenantScope(ctx, []interface{}{clientID}, ownedBy("bank_id"))
+	page, args := paginate(args, limit, offset)
 	query := `
-		SELECT id, client_id, bank_id, min_payment, max_payment, term_months, credit_type, status, created_at, is_active
-		FROM credits WHERE client_id = $1 AND is_active = TRUE ORDER BY created_at DESC LIMIT $2 OFFSET $3
-	`
-	rows, err := r.pool.Query(ctx, query, clientID, limit, offset)
+		SELECT ` + creditColumns + `
+		FROM credits WHERE client_id = $1 AND is_active = TRUE` + scope + ` ORDER BY created_at DESC` + page
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, dbError(err)
 	}
 	defer rows.Close()
 	return scanCredits(rows)
 }
+
+// Loads a credit FOR UPDATE as the "before" state of an audited mutation
+// A row at a version other than the expected ones in ctx fails with repository.ErrVersionMismatch
+// The lock is tenant-scoped, so a credit of another bank is reported as missing and never mutated
+func lockCredit(id string) func(ctx context.Context, q querier) (*domain.Credit, error) {
+	return func(ctx context.Context, q querier) (*domain.Credit, error) {
+		scope, args := tenantScope(ctx, []interface{}{id}, ownedBy("bank_id"))
+		c, err := scanCredit(q.QueryRow(ctx, `SELECT `+creditColumns+` FROM credits WHERE id = $1`+scope+` FOR UPDATE`, args...))
+		if err != nil || c == nil {
+			return c, err
+		}
+		return c, repository.CheckVersion(ctx, c.Version)
+	}
+}
+
+func creditID(c *domain.Credit) string { return c.ID }
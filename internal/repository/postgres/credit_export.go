@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tucredito/backend-api/internal/domain"
+)
+
+// Rows fetched from the export cursor per round trip
+const exportFetchSize = 500
+
+type CreditExportRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCreditExportRepository(pool *pgxpool.Pool) *CreditExportRepository {
+	return &CreditExportRepository{pool: pool}
+}
+
+/*
+	StreamCredits walks a server-side cursor over credits joined with clients and banks, calling fn per row
+	Runs in a read-only REPEATABLE READ transaction so the export is a consistent snapshot
+	Only exportFetchSize rows are held in memory at a time; an error from fn stops the export
+*/
+
+func (r *CreditExportRepository) StreamCredits(ctx context.Context, filter domain.CreditExportFilter, fn func(row *domain.CreditExportRow) error) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return dbError(err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	query, args := exportQuery(ctx, filter)
+	if _, err := tx.Exec(ctx, "DECLARE credit_export NO SCROLL CURSOR FOR "+query, args...); err != nil {
+		return dbError(err)
+	}
+
+	fetch := "FETCH FORWARD " + strconv.Itoa(exportFetchSize) + " FROM credit_export"
+	for {
+		rows, err := tx.Query(ctx, fetch)
+		if err != nil {
+			return dbError(err)
+		}
+
+		n := 0
+		for rows.Next() {
+			n++
+			var row domain.CreditExportRow
+			if err := rows.Scan(
+				&row.CreditID, &row.ClientID, &row.BankID, &row.MinPayment, &row.MaxPayment,
+				&row.TermMonths, &row.CreditType, &row.Status, &row.CreatedAt,
+				&row.ClientFullName, &row.ClientEmail, &row.ClientCountry, &row.BankName, &row.BankType,
+			); err != nil {
+				rows.Close()
+				return dbError(err)
+			}
+			if err := fn(&row); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return dbError(err)
+		}
+
+		if n < exportFetchSize {
+			break
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Builds the export query with the same active-only and tenant semantics as listing plus optional filters
+func exportQuery(ctx context.Context, filter domain.CreditExportFilter) (string, []interface{}) {
+	conditions := []string{"cr.is_active = TRUE"}
+	var args []interface{}
+	add := func(cond string, v interface{}) {
+		args = append(args, v)
+		conditions = append(conditions, cond+" $"+strconv.Itoa(len(args)))
+	}
+
+	if filter.ClientID != "" {
+		add("cr.client_id =", filter.ClientID)
+	}
+	if filter.BankID != "" {
+		add("cr.bank_id =", filter.BankID)
+	}
+	if filter.Status != "" {
+		add("cr.status =", filter.Status)
+	}
+	if filter.CreatedFrom != nil {
+		add("cr.created_at >=", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		add("cr.created_at <", *filter.CreatedTo)
+	}
+	scope, args := tenantScope(ctx, args, ownedBy("cr.bank_id"))
+
+	query := `
+		SELECT cr.id, cr.client_id, cr.bank_id, cr.min_payment, cr.max_payment, cr.term_months, cr.credit_type, cr.status, cr.created_at,
+			cl.full_name, cl.email, cl.country, b.name, b.type
+		FROM credits cr
+		JOIN clients cl ON cl.id = cr.client_id
+		JOIN banks b ON b.id = cr.bank_id
+		WHERE ` + strings.Join(conditions, " AND ") + scope + `
+		ORDER BY cr.created_at DESC, cr.id
+	`
+	return query, args
+}
@@ -0,0 +1,56 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/repository/postgres"
+)
+
+func TestCreditExportRepository_StreamCredits(t *testing.T) {
+	pool := testDBPool(t)
+	defer pool.Close()
+	ctx := context.Background()
+	clientRepo := postgres.NewClientRepository(pool)
+	bankRepo := postgres.NewBankRepository(pool)
+	creditRepo := postgres.NewCreditRepository(pool)
+	exportRepo := postgres.NewCreditExportRepository(pool)
+
+	client, err := clientRepo.Create(ctx, domain.CreateClientInput{FullName: "Export Client", Email: uniqueClientEmail(t), BirthDate: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), Country: "MX"})
+	require.NoError(t, err)
+	require.NotNil(t, client)
+	defer deleteClient(t, pool, client.ID)
+	bank, err := bankRepo.Create(ctx, domain.CreateBankInput{Name: "Export Bank", Type: domain.BankTypeGovernment})
+	require.NoError(t, err)
+	require.NotNil(t, bank)
+	defer deleteBank(t, pool, bank.ID)
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		credit, err := creditRepo.Create(ctx, domain.CreateCreditInput{
+			ClientID: client.ID, BankID: bank.ID, MinPayment: 100, MaxPayment: 500, TermMonths: 12, CreditType: domain.CreditTypeAuto,
+		}, domain.CreditStatusPending)
+		require.NoError(t, err)
+		require.NotNil(t, credit)
+		defer deleteCredit(t, pool, credit.ID)
+		ids = append(ids, credit.ID)
+	}
+	_, err = creditRepo.SetInactive(ctx, ids[2])
+	require.NoError(t, err)
+
+	var rows []*domain.CreditExportRow
+	err = exportRepo.StreamCredits(ctx, domain.CreditExportFilter{ClientID: client.ID}, func(row *domain.CreditExportRow) error {
+		rows = append(rows, row)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "Export Client", rows[0].ClientFullName)
+	assert.Equal(t, "MX", rows[0].ClientCountry)
+	assert.Equal(t, "Export Bank", rows[0].BankName)
+	assert.Equal(t, domain.BankTypeGovernment, rows[0].BankType)
+}
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/tenant"
+)
+
+type CreditImportRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCreditImportRepository(pool *pgxpool.Pool) *CreditImportRepository {
+	return &CreditImportRepository{pool: pool}
+}
+
+// Creates a new credit import in PROCESSING status, owned by the requesting bank when tenant-scoped
+func (r *CreditImportRepository) Create(ctx context.Context, format domain.ImportFormat) (*domain.CreditImport, error) {
+	id := uuid.New().String()
+	var owner *string
+	if bankID, ok := tenant.BankID(ctx); ok {
+		owner = &bankID
+	}
+	query := `
+		INSERT INTO credit_imports (id, format, status, created_at, bank_id)
+		VALUES ($1, $2, 'PROCESSING', NOW(), $3)
+		RETURNING id, format, status, total, succeeded, failed, created_at, completed_at
+	`
+	var imp domain.CreditImport
+	err := conn(ctx, r.pool).QueryRow(ctx, query, id, format, owner).Scan(
+		&imp.ID, &imp.Format, &imp.Status, &imp.Total, &imp.Succeeded, &imp.Failed, &imp.CreatedAt, &imp.CompletedAt,
+	)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	return &imp, nil
+}
+
+// Stores the report and marks the import as COMPLETED
+func (r *CreditImportRepository) Complete(ctx context.Context, id string, input domain.CompleteCreditImportInput) (*domain.CreditImport, error) {
+	results := input.Results
+	if results == nil {
+		results = []domain.CreditImportResult{}
+	}
+	resultsJSON, err := json.Marshal(results)
+	if err != nil {
+		return nil, dbError(err)
+	}
+
+	scope, args := tenantScope(ctx, []interface{}{input.Total, input.Succeeded, input.Failed, resultsJSON, id}, ownedBy("bank_id"))
+	query := `
+		UPDATE credit_imports SET status = 'COMPLETED', total = $1, succeeded = $2, failed = $3, results = $4, completed_at = NOW()
+		WHERE id = $5` + scope + `
+		RETURNING id, format, status, total, succeeded, failed, created_at, completed_at
+	`
+	var imp domain.CreditImport
+	err = conn(ctx, r.pool).QueryRow(ctx, query, args...).Scan(
+		&imp.ID, &imp.Format, &imp.Status, &imp.Total, &imp.Succeeded, &imp.Failed, &imp.CreatedAt, &imp.CompletedAt,
+	)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, dbError(err)
+	}
+	imp.Results = results
+	return &imp, nil
+}
+
+// Gets a credit import with its report by ID
+func (r *CreditImportRepository) GetByID(ctx context.Context, id string) (*domain.CreditImport, error) {
+	scope, args := tenantScope(ctx, []interface{}{id}, ownedBy("bank_id"))
+	query := `
+		SELECT id, format, status, total, succeeded, failed, created_at, completed_at, results
+		FROM credit_imports WHERE id = $1` + scope
+	var imp domain.CreditImport
+	var resultsJSON []byte
+	err := conn(ctx, r.pool).QueryRow(ctx, query, args...).Scan(
+		&imp.ID, &imp.Format, &imp.Status, &imp.Total, &imp.Succeeded, &imp.Failed, &imp.CreatedAt, &imp.CompletedAt, &resultsJSON,
+	)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, dbError(err)
+	}
+	if err := json.Unmarshal(resultsJSON, &imp.Results); err != nil {
+		return nil, dbError(err)
+	}
+	return &imp, nil
+}
@@ -0,0 +1,54 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/repository/postgres"
+)
+
+func TestCreditImportRepository_CreateAndComplete(t *testing.T) {
+	pool := testDBPool(t)
+	defer pool.Close()
+	ctx := context.Background()
+	repo := postgres.NewCreditImportRepository(pool)
+
+	created, err := repo.Create(ctx, domain.ImportFormatCSV)
+	require.NoError(t, err)
+	require.NotNil(t, created)
+	defer deleteCreditImport(t, pool, created.ID)
+	assert.Equal(t, domain.ImportStatusProcessing, created.Status)
+	assert.Nil(t, created.CompletedAt)
+
+	completed, err := repo.Complete(ctx, created.ID, domain.CompleteCreditImportInput{
+		Total: 2, Succeeded: 1, Failed: 1,
+		Results: []domain.CreditImportResult{
+			{Row: 1, CreditID: "00000000-0000-0000-0000-000000000001", Status: domain.CreditStatusApproved},
+			{Row: 2, Code: "NOT_FOUND", Error: "client not found"},
+		},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, completed)
+	assert.Equal(t, domain.ImportStatusCompleted, completed.Status)
+	assert.NotNil(t, completed.CompletedAt)
+
+	got, err := repo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, 2, got.Total)
+	require.Len(t, got.Results, 2)
+	assert.Equal(t, "NOT_FOUND", got.Results[1].Code)
+}
+
+func TestCreditImportRepository_GetByID_NotFound(t *testing.T) {
+	pool := testDBPool(t)
+	defer pool.Close()
+	repo := postgres.NewCreditImportRepository(pool)
+
+	got, err := repo.GetByID(context.Background(), "00000000-0000-0000-0000-000000000000")
+	require.NoError(t, err)
+	require.Nil(t, got)
+}
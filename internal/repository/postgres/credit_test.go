@@ -38,7 +38,7 @@ func TestCreditRepository_Create(t *testing.T) {
 		TermMonths: 12,
 		CreditType: domain.CreditTypeAuto,
 	}
-	credit, err := creditRepo.Create(ctx, input)
+	credit, err := creditRepo.Create(ctx, input, domain.CreditStatusPending)
 	require.NoError(t, err)
 	require.NotNil(t, credit)
 	defer deleteCredit(t, pool, credit.ID)
@@ -71,7 +71,7 @@ func TestCreditRepository_GetByID(t *testing.T) {
 	defer deleteBank(t, pool, bank.ID)
 	created, err := creditRepo.Create(ctx, domain.CreateCreditInput{
 		ClientID: client.ID, BankID: bank.ID, MinPayment: 200, MaxPayment: 600, TermMonths: 24, CreditType: domain.CreditTypeMortgage,
-	})
+	}, domain.CreditStatusPending)
 	require.NoError(t, err)
 	require.NotNil(t, created)
 	defer deleteCredit(t, pool, created.ID)
@@ -101,7 +101,7 @@ func TestCreditRepository_Update(t *testing.T) {
 	defer deleteBank(t, pool, bank.ID)
 	created, err := creditRepo.Create(ctx, domain.CreateCreditInput{
 		ClientID: client.ID, BankID: bank.ID, MinPayment: 100, MaxPayment: 500, TermMonths: 12, CreditType: domain.CreditTypeAuto,
-	})
+	}, domain.CreditStatusPending)
 	require.NoError(t, err)
 	require.NotNil(t, created)
 	defer deleteCredit(t, pool, created.ID)
@@ -135,7 +135,7 @@ func TestCreditRepository_SetInactive(t *testing.T) {
 	defer deleteBank(t, pool, bank.ID)
 	created, err := creditRepo.Create(ctx, domain.CreateCreditInput{
 		ClientID: client.ID, BankID: bank.ID, MinPayment: 100, MaxPayment: 500, TermMonths: 12, CreditType: domain.CreditTypeAuto,
-	})
+	}, domain.CreditStatusPending)
 	require.NoError(t, err)
 	require.NotNil(t, created)
 	defer deleteCredit(t, pool, created.ID)
@@ -164,7 +164,7 @@ func TestCreditRepository_SetActive(t *testing.T) {
 	defer deleteBank(t, pool, bank.ID)
 	created, err := creditRepo.Create(ctx, domain.CreateCreditInput{
 		ClientID: client.ID, BankID: bank.ID, MinPayment: 100, MaxPayment: 500, TermMonths: 12, CreditType: domain.CreditTypeAuto,
-	})
+	}, domain.CreditStatusPending)
 	require.NoError(t, err)
 	require.NotNil(t, created)
 	defer deleteCredit(t, pool, created.ID)
@@ -208,7 +208,7 @@ func TestCreditRepository_List(t *testing.T) {
 	defer deleteBank(t, pool, bank.ID)
 	credit, err := creditRepo.Create(ctx, domain.CreateCreditInput{
 		ClientID: client.ID, BankID: bank.ID, MinPayment: 100, MaxPayment: 500, TermMonths: 12, CreditType: domain.CreditTypeAuto,
-	})
+	}, domain.CreditStatusPending)
 	require.NoError(t, err)
 	require.NotNil(t, credit)
 	defer deleteCredit(t, pool, credit.ID)
@@ -237,7 +237,7 @@ func TestCreditRepository_ListByClientID(t *testing.T) {
 
 	credit, err := creditRepo.Create(ctx, domain.CreateCreditInput{
 		ClientID: client.ID, BankID: bank.ID, MinPayment: 100, MaxPayment: 500, TermMonths: 12, CreditType: domain.CreditTypeAuto,
-	})
+	}, domain.CreditStatusPending)
 	require.NoError(t, err)
 	require.NotNil(t, credit)
 	defer deleteCredit(t, pool, credit.ID)
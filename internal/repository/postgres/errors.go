@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/tucredito/backend-api/internal/repository"
+	"github.com/tucredito/backend-api/pkg/apperr"
+)
+
+// SQLSTATE codes translated to typed errors (https://www.postgresql.org/docs/current/errcodes-appendix.html)
+const (
+	pgUniqueViolation      = "23505"
+	pgForeignKeyViolation  = "23503"
+	pgCheckViolation       = "23514"
+	pgNotNullViolation     = "23502"
+	pgInvalidTextRep       = "22P02"
+	pgUndefinedTable       = "42P01"
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+	pgQueryCanceled        = "57014"
+	pgAdminShutdown        = "57P01"
+	pgCannotConnectNow     = "57P03"
+)
+
+/*
+	Translates a database error into an *apperr.Error
+	Constraint violations become Conflict/Validation errors naming the offending field, connection failures and
+	shutdowns become Unavailable; the driver error is kept as the cause for logs but never shown to clients
+	Errors that already are *apperr.Error (e.g. tenant.ErrCrossTenant) and nil pass through
+*/
+
+func dbError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := apperr.As(err); ok {
+		return err
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		if pgconn.SafeToRetry(err) || pgconn.Timeout(err) || isConnectError(err) {
+			return apperr.Unavailable("database unavailable", err)
+		}
+		return err
+	}
+
+	field := constraintField(pgErr.TableName, pgErr.ConstraintName)
+	var e *apperr.Error
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		e = repository.DuplicateError(field)
+	case pgForeignKeyViolation:
+		if strings.Contains(pgErr.Detail, "still referenced") {
+			e = apperr.Conflict("the record is still referenced by other records")
+		} else {
+			e = repository.MissingReferenceError(field)
+		}
+	case pgCheckViolation:
+		e = repository.OutOfRangeError(field)
+	case pgNotNullViolation:
+		e = apperr.Validation(pgErr.ColumnName+" is required", apperr.Field(pgErr.ColumnName, "required", "is required"))
+	case pgInvalidTextRep:
+		e = apperr.Validation("malformed value")
+	case pgSerializationFailure, pgDeadlockDetected:
+		e = apperr.Conflict("concurrent update, retry the request")
+	case pgQueryCanceled, pgAdminShutdown, pgCannotConnectNow:
+		e = apperr.Unavailable("database unavailable", nil)
+	default:
+		if strings.HasPrefix(pgErr.Code, "08") { // connection exception class
+			e = apperr.Unavailable("database unavailable", nil)
+		} else {
+			return err
+		}
+	}
+	e.Err = err
+	return e
+}
+
+// Derives the field from Postgres' default constraint names (<table>_<column>_key, _fkey, _check, _pkey)
+func constraintField(table, constraint string) string {
+	if constraint == "" {
+		return "value"
+	}
+	if constraint == table+"_pkey" {
+		return "id"
+	}
+	name := strings.TrimPrefix(constraint, table+"_")
+	for _, suffix := range []string{"_fkey", "_key", "_check"} {
+		if trimmed, ok := strings.CutSuffix(name, suffix); ok {
+			return trimmed
+		}
+	}
+	return name
+}
+
+// Dial and socket failures surface as net.Error, possibly wrapped by pgconn's connect error
+func isConnectError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
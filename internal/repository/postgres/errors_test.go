@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/tucredito/backend-api/internal/tenant"
+	"github.com/tucredito/backend-api/pkg/apperr"
+)
+
+func TestDBError_TranslatesConstraintViolations(t *testing.T) {
+	tests := []struct {
+		name  string
+		pgErr *pgconn.PgError
+		kind  apperr.Kind
+		field string
+	}{
+		{"duplicate email", &pgconn.PgError{Code: pgUniqueViolation, TableName: "clients", ConstraintName: "clients_email_key"}, apperr.KindConflict, "email"},
+		{"missing client", &pgconn.PgError{Code: pgForeignKeyViolation, TableName: "credits", ConstraintName: "credits_client_id_fkey", Detail: `Key (client_id)=(x) is not present in table "clients".`}, apperr.KindValidation, "client_id"},
+		{"check", &pgconn.PgError{Code: pgCheckViolation, TableName: "credits", ConstraintName: "credits_term_months_check"}, apperr.KindValidation, "term_months"},
+		{"not null", &pgconn.PgError{Code: pgNotNullViolation, TableName: "banks", ColumnName: "name"}, apperr.KindValidation, "name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, ok := apperr.As(dbError(tt.pgErr))
+			assert.True(t, ok)
+			assert.Equal(t, tt.kind, e.Kind)
+			if assert.Len(t, e.Fields, 1) {
+				assert.Equal(t, tt.field, e.Fields[0].Field)
+			}
+			assert.ErrorIs(t, e, tt.pgErr, "the driver error is kept as the cause")
+		})
+	}
+}
+
+func TestDBError_Other(t *testing.T) {
+	assert.NoError(t, dbError(nil))
+	assert.Equal(t, apperr.KindConflict, apperr.KindOf(dbError(&pgconn.PgError{Code: pgForeignKeyViolation, Detail: `Key (id)=(x) is still referenced from table "credits".`})))
+	assert.Equal(t, apperr.KindConflict, apperr.KindOf(dbError(&pgconn.PgError{Code: pgSerializationFailure})))
+	assert.Equal(t, apperr.KindUnavailable, apperr.KindOf(dbError(&pgconn.PgError{Code: "08006"})))
+	assert.Same(t, tenant.ErrCrossTenant, dbError(tenant.ErrCrossTenant))
+
+	plain := errors.New("boom")
+	assert.Equal(t, plain, dbError(plain), "unknown errors stay internal")
+}
@@ -60,3 +60,11 @@ func uniqueClientEmail(t *testing.T) string {
 	safe := strings.ReplaceAll(t.Name(), "/", "-")
 	return "test-" + safe + "-" + uuid.New().String()[:8] + "@test.com"
 }
+
+func deleteCreditImport(t *testing.T, pool *pgxpool.Pool, id string) {
+	t.Helper()
+	if id == "" {
+		return
+	}
+	_, _ = pool.Exec(context.Background(), "DELETE FROM credit_imports WHERE id = $1", id)
+}
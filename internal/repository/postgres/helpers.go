@@ -3,18 +3,31 @@ package postgres
 import (
 	"context"
 	"errors"
+	"strconv"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tucredito/backend-api/internal/audit"
 	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/repository"
 )
 
-// Creates a PostgreSQL connection pool
+// Common query methods of *pgxpool.Pool and pgx.Tx
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// Creates a PostgreSQL connection pool; queries are traced (see tracing.go)
 func NewPool(ctx context.Context, connString string) (*pgxpool.Pool, error) {
 	config, err := pgxpool.ParseConfig(connString)
 	if err != nil {
 		return nil, err
 	}
+	config.ConnConfig.Tracer = queryTracer{}
 	return pgxpool.NewWithConfig(ctx, config)
 }
 
@@ -23,18 +36,166 @@ func isNotFound(err error) bool {
 	return errors.Is(err, pgx.ErrNoRows)
 }
 
+const creditColumns = "id, client_id, bank_id, min_payment, max_payment, term_months, credit_type, status, created_at, is_active, version"
+
+// Scans a single credit row; "no rows" becomes (nil, nil)
+func scanCredit(row pgx.Row) (*domain.Credit, error) {
+	var c domain.Credit
+	err := row.Scan(
+		&c.ID, &c.ClientID, &c.BankID, &c.MinPayment, &c.MaxPayment,
+		&c.TermMonths, &c.CreditType, &c.Status, &c.CreatedAt, &c.IsActive, &c.Version,
+	)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, dbError(err)
+	}
+	return &c, nil
+}
+
 // scanCredits scans credit rows into a slice
 func scanCredits(rows pgx.Rows) ([]*domain.Credit, error) {
 	var list []*domain.Credit
 	for rows.Next() {
-		var c domain.Credit
-		if err := rows.Scan(
-			&c.ID, &c.ClientID, &c.BankID, &c.MinPayment, &c.MaxPayment,
-			&c.TermMonths, &c.CreditType, &c.Status, &c.CreatedAt, &c.IsActive,
-		); err != nil {
-			return nil, err
+		c, err := scanCredit(rows)
+		if err != nil {
+			return nil, dbError(err)
+		}
+		list = append(list, c)
+	}
+	return list, dbError(rows.Err())
+}
+
+const clientColumns = "id, full_name, email, birth_date, country, created_at, is_active, version"
+
+// Scans a single client row; "no rows" becomes (nil, nil)
+func scanClient(row pgx.Row) (*domain.Client, error) {
+	var c domain.Client
+	err := row.Scan(&c.ID, &c.FullName, &c.Email, &c.BirthDate, &c.Country, &c.CreatedAt, &c.IsActive, &c.Version)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
 		}
-		list = append(list, &c)
+		return nil, dbError(err)
+	}
+	return &c, nil
+}
+
+const bankColumns = "id, name, type, is_active, version"
+
+// Scans a single bank row; "no rows" becomes (nil, nil)
+func scanBank(row pgx.Row) (*domain.Bank, error) {
+	var b domain.Bank
+	err := row.Scan(&b.ID, &b.Name, &b.Type, &b.IsActive, &b.Version)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, dbError(err)
+	}
+	return &b, nil
+}
+
+// SET list of a dynamically built UPDATE; placeholders are numbered in the order values are added
+type assignments struct {
+	columns []string
+	args    []interface{}
+}
+
+// Adds "column = $n"
+func (a *assignments) set(column string, value interface{}) {
+	a.columns = append(a.columns, column+" = "+a.arg(value))
+}
+
+// Adds a literal assignment such as "version = version + 1"
+func (a *assignments) expr(assignment string) {
+	a.columns = append(a.columns, assignment)
+}
+
+// Appends value and returns its placeholder, for the WHERE clause
+func (a *assignments) arg(value interface{}) string {
+	a.args = append(a.args, value)
+	return "$" + strconv.Itoa(len(a.args))
+}
+
+func (a *assignments) sql() string {
+	return strings.Join(a.columns, ", ")
+}
+
+// Sets column only when the patch provides the field
+func setIf[V any](a *assignments, column string, v *V) {
+	if v != nil {
+		a.set(column, *v)
+	}
+}
+
+/*
+	auditedMutation runs mutate and the audit_log insert describing it in one transaction (a savepoint of the
+	unit of work in ctx, if any)
+	lock loads the current row FOR UPDATE (nil lock means the row is being created) and checks it against the
+	expected versions in ctx (see repository.CheckVersion); mutate receives it as before (nil on create)
+	A missing row (lock or mutate returning nil) rolls back and returns (nil, nil) like the plain queries; updates
+	are conditional on the locked version (WHERE version = $n), so a mutate finding no row after a successful
+	lock means the row changed underneath and is reported as repository.ErrVersionMismatch
+*/
+
+func auditedMutation[T any](
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	entity domain.AuditEntity,
+	action domain.AuditAction,
+	lock func(ctx context.Context, q querier) (*T, error),
+	mutate func(ctx context.Context, q querier, before *T) (*T, error),
+	idOf func(*T) string,
+) (*T, error) {
+	tx, err := begin(ctx, pool)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var before *T
+	if lock != nil {
+		if before, err = lock(ctx, tx); err != nil {
+			return nil, dbError(err)
+		}
+		if before == nil {
+			return nil, nil
+		}
+	}
+
+	after, err := mutate(ctx, tx, before)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	if after == nil {
+		if before != nil {
+			return nil, repository.ErrVersionMismatch
+		}
+		return nil, nil
+	}
+
+	if err := insertAuditEntry(ctx, tx, entity, idOf(after), action, before, after); err != nil {
+		return nil, dbError(err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, dbError(err)
+	}
+	return after, nil
+}
+
+// Appends an audit entry with the actor, request ID and client IP taken from ctx
+func insertAuditEntry(ctx context.Context, q querier, entity domain.AuditEntity, entityID string, action domain.AuditAction, before, after interface{}) error {
+	changes, err := audit.Diff(before, after)
+	if err != nil {
+		return err
 	}
-	return list, rows.Err()
+	query := `
+		INSERT INTO audit_log (entity, entity_id, action, actor, request_id, client_ip, changes, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+	`
+	_, err = q.Exec(ctx, query, entity, entityID, action, audit.Actor(ctx), audit.RequestID(ctx), audit.ClientIP(ctx), changes)
+	return err
 }
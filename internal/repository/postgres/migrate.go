@@ -0,0 +1,248 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+/*
+	Migration runner for the SQL files in migrations/ (embedded in the binary)
+	It keeps golang-migrate's file layout and schema_migrations table, so databases migrated with the migrate
+	CLI carry on with the runner and the other way round. Each migration marks the version dirty, runs its
+	file and marks it clean; a failure leaves the version dirty until an operator fixes the schema and
+	forces a version
+	Every change runs under a session advisory lock, so instances starting together migrate one at a time
+*/
+
+// Key of the advisory lock serializing migrations ("tucredit" in ASCII)
+const migrationLockKey int64 = 0x7475637265646974
+
+var migrationFile = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+type migration struct {
+	version  int64
+	name     string
+	up, down string
+}
+
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []migration // ascending by version
+}
+
+// Creates a migrator for the migrations in source; malformed or duplicate files are an error
+func NewMigrator(pool *pgxpool.Pool, source fs.FS) (*Migrator, error) {
+	files, err := fs.Glob(source, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int64]*migration)
+	for _, file := range files {
+		match := migrationFile.FindStringSubmatch(file)
+		if match == nil {
+			return nil, fmt.Errorf("migration %s: name must be VERSION_NAME.up.sql or VERSION_NAME.down.sql", file)
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: invalid version", file)
+		}
+		m := byVersion[version]
+		if m == nil {
+			m = &migration{version: version, name: match[2]}
+			byVersion[version] = m
+		} else if m.name != match[2] {
+			return nil, fmt.Errorf("migration %s: version %d is also used by %s", file, version, m.name)
+		}
+		sql, err := fs.ReadFile(source, file)
+		if err != nil {
+			return nil, err
+		}
+		if match[3] == "up" {
+			m.up = string(sql)
+		} else {
+			m.down = string(sql)
+		}
+	}
+
+	migrator := &Migrator{pool: pool}
+	for _, m := range byVersion {
+		if m.up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.version, m.name)
+		}
+		migrator.migrations = append(migrator.migrations, *m)
+	}
+	sort.Slice(migrator.migrations, func(i, j int) bool { return migrator.migrations[i].version < migrator.migrations[j].version })
+	return migrator, nil
+}
+
+// Latest migration version; the schema the binary expects
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].version
+}
+
+// Returns the applied version and dirty flag (0 on an empty database)
+func (m *Migrator) Version(ctx context.Context) (version int64, dirty bool, err error) {
+	return SchemaVersion(ctx, m.pool)
+}
+
+// Fails unless the schema is exactly at Latest and clean; registered as a critical readiness check
+func (m *Migrator) Check(ctx context.Context) error {
+	return CheckSchemaVersion(ctx, m.pool, m.Latest())
+}
+
+// Applies every migration after the current version and returns the versions applied
+func (m *Migrator) Up(ctx context.Context) (applied []int64, err error) {
+	err = m.locked(ctx, func(conn *pgxpool.Conn, current int64) error {
+		if current > m.Latest() {
+			return fmt.Errorf("schema version %d is newer than this binary's latest migration %d", current, m.Latest())
+		}
+		for _, mig := range m.migrations {
+			if mig.version <= current {
+				continue
+			}
+			if err := m.run(ctx, conn, mig.version, mig.up, mig.version); err != nil {
+				return fmt.Errorf("migration %d_%s up: %w", mig.version, mig.name, err)
+			}
+			applied = append(applied, mig.version)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Reverts the latest steps applied migrations and returns the versions reverted
+func (m *Migrator) Down(ctx context.Context, steps int) (reverted []int64, err error) {
+	err = m.locked(ctx, func(conn *pgxpool.Conn, current int64) error {
+		for ; steps > 0 && current > 0; steps-- {
+			i := m.index(current)
+			if i < 0 {
+				return fmt.Errorf("schema version %d has no migration in this binary", current)
+			}
+			mig := m.migrations[i]
+			if mig.down == "" {
+				return fmt.Errorf("migration %d_%s has no down file", mig.version, mig.name)
+			}
+			var previous int64
+			if i > 0 {
+				previous = m.migrations[i-1].version
+			}
+			if err := m.run(ctx, conn, mig.version, mig.down, previous); err != nil {
+				return fmt.Errorf("migration %d_%s down: %w", mig.version, mig.name, err)
+			}
+			reverted = append(reverted, mig.version)
+			current = previous
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Records version as applied and clean without running anything, to recover from a dirty schema
+// Version 0 marks an empty database
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	if version != 0 && m.index(version) < 0 {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+	conn, err := m.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer m.unlock(conn)
+	return setSchemaVersion(ctx, conn, version, false)
+}
+
+// Runs fn holding the migration lock on a clean schema; current is the applied version
+func (m *Migrator) locked(ctx context.Context, fn func(conn *pgxpool.Conn, current int64) error) error {
+	conn, err := m.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer m.unlock(conn)
+
+	current, dirty, err := SchemaVersion(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("schema version %d is dirty; fix the schema, then run: migrate force VERSION", current)
+	}
+	return fn(conn, current)
+}
+
+// Acquires a connection holding the migration lock, creating schema_migrations if needed
+func (m *Migrator) lock(ctx context.Context) (*pgxpool.Conn, error) {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+		conn.Release()
+		return nil, err
+	}
+	if _, err := conn.Exec(ctx, "CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)"); err != nil {
+		m.unlock(conn)
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Releases the migration lock; a connection that cannot unlock is closed, which releases it too
+func (m *Migrator) unlock(conn *pgxpool.Conn) {
+	if _, err := conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockKey); err != nil {
+		_ = conn.Conn().Close(context.Background())
+	}
+	conn.Release()
+}
+
+// Marks version dirty, runs sql and records next as the clean version
+func (m *Migrator) run(ctx context.Context, conn *pgxpool.Conn, version int64, sql string, next int64) error {
+	if err := setSchemaVersion(ctx, conn, version, true); err != nil {
+		return err
+	}
+	// Without arguments pgx uses the simple protocol, which runs every statement of the file
+	if _, err := conn.Exec(ctx, sql); err != nil {
+		return err
+	}
+	return setSchemaVersion(ctx, conn, next, false)
+}
+
+func (m *Migrator) index(version int64) int {
+	for i, mig := range m.migrations {
+		if mig.version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// Replaces the single schema_migrations row; version 0 leaves the table empty
+func setSchemaVersion(ctx context.Context, conn *pgxpool.Conn, version int64, dirty bool) error {
+	return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "DELETE FROM schema_migrations"); err != nil {
+			return err
+		}
+		if version == 0 {
+			return nil
+		}
+		_, err := tx.Exec(ctx, "INSERT INTO schema_migrations (version, dirty) VALUES ($1, $2)", version, dirty)
+		return err
+	})
+}
+
+// Reports whether err is Postgres' undefined_table
+func isUndefinedTable(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUndefinedTable
+}
@@ -0,0 +1,71 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/repository/postgres"
+	"github.com/tucredito/backend-api/migrations"
+)
+
+func TestNewMigrator_EmbeddedMigrations(t *testing.T) {
+	m, err := postgres.NewMigrator(nil, migrations.FS)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), m.Latest())
+}
+
+func TestNewMigrator_RejectsMalformedSources(t *testing.T) {
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+	}{
+		{"bad name", fstest.MapFS{"init.up.sql": {Data: []byte("SELECT 1")}}},
+		{"duplicate version", fstest.MapFS{
+			"000001_a.up.sql": {Data: []byte("SELECT 1")},
+			"000001_b.up.sql": {Data: []byte("SELECT 1")},
+		}},
+		{"down without up", fstest.MapFS{"000001_a.down.sql": {Data: []byte("SELECT 1")}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := postgres.NewMigrator(nil, tt.files)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMigrator_UpIsIdempotent(t *testing.T) {
+	pool := testDBPool(t)
+	defer pool.Close()
+	ctx := context.Background()
+	m, err := postgres.NewMigrator(pool, migrations.FS)
+	require.NoError(t, err)
+
+	_, err = m.Up(ctx)
+	require.NoError(t, err)
+	applied, err := m.Up(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	version, dirty, err := m.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, m.Latest(), version)
+	assert.False(t, dirty)
+	assert.NoError(t, m.Check(ctx))
+}
+
+func TestCheckSchemaVersion_Mismatch(t *testing.T) {
+	pool := testDBPool(t)
+	defer pool.Close()
+	ctx := context.Background()
+	m, err := postgres.NewMigrator(pool, migrations.FS)
+	require.NoError(t, err)
+	_, err = m.Up(ctx)
+	require.NoError(t, err)
+
+	assert.Error(t, postgres.CheckSchemaVersion(ctx, pool, m.Latest()-1), "an older binary must not serve a newer schema")
+	assert.Error(t, postgres.CheckSchemaVersion(ctx, pool, m.Latest()+1), "a newer binary must not serve an older schema")
+}
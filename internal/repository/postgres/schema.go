@@ -0,0 +1,32 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+)
+
+// Returns the applied migration version and dirty flag from golang-migrate's schema_migrations table
+// A database that was never migrated is at version 0
+func SchemaVersion(ctx context.Context, db querier) (version int64, dirty bool, err error) {
+	err = db.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if isNotFound(err) || isUndefinedTable(err) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// Fails unless the schema is clean and exactly at want; an older schema lacks columns the queries use and a
+// newer one may have dropped some, so either way the instance must not take traffic
+func CheckSchemaVersion(ctx context.Context, db querier, want int64) error {
+	version, dirty, err := SchemaVersion(ctx, db)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("schema version %d is dirty", version)
+	}
+	if version != want {
+		return fmt.Errorf("schema version %d, want %d", version, want)
+	}
+	return nil
+}
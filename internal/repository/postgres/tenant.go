@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/tucredito/backend-api/internal/tenant"
+)
+
+/*
+	Tenant scoping: every query on tenant-owned rows appends the condition returned here
+	Platform requests get an empty condition; bank-scoped requests get the bank ID as
+	the next placeholder so callers can keep building args positionally
+*/
+
+func tenantScope(ctx context.Context, args []interface{}, cond func(param string) string) (string, []interface{}) {
+	bankID, ok := tenant.BankID(ctx)
+	if !ok {
+		return "", args
+	}
+	args = append(args, bankID)
+	return " AND " + cond("$"+strconv.Itoa(len(args))), args
+}
+
+// Condition for rows whose column holds the owning bank
+func ownedBy(column string) func(param string) string {
+	return func(param string) string { return column + " = " + param }
+}
+
+// A client is visible to a bank that onboarded it or holds one of its credits
+func clientVisibleTo(alias string) func(param string) string {
+	return func(param string) string {
+		return "(" + alias + ".owner_bank_id = " + param +
+			" OR EXISTS (SELECT 1 FROM credits tc WHERE tc.client_id = " + alias + ".id AND tc.bank_id = " + param + "))"
+	}
+}
+
+// Appends limit and offset to args and returns the matching LIMIT/OFFSET clause
+func paginate(args []interface{}, limit, offset int) (string, []interface{}) {
+	args = append(args, limit, offset)
+	return " LIMIT $" + strconv.Itoa(len(args)-1) + " OFFSET $" + strconv.Itoa(len(args)), args
+}
@@ -0,0 +1,188 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/repository/postgres"
+	"github.com/tucredito/backend-api/internal/tenant"
+)
+
+// Two partner banks, each with its own client and credit, created by the platform
+type tenantFixture struct {
+	bankA, bankB     *domain.Bank
+	clientA, clientB *domain.Client
+	creditA, creditB *domain.Credit
+}
+
+func newTenantFixture(t *testing.T) (*tenantFixture, func()) {
+	t.Helper()
+	pool := testDBPool(t)
+	ctx := context.Background()
+	banks := postgres.NewBankRepository(pool)
+	clients := postgres.NewClientRepository(pool)
+	credits := postgres.NewCreditRepository(pool)
+
+	f := &tenantFixture{}
+	var err error
+	f.bankA, err = banks.Create(ctx, domain.CreateBankInput{Name: "Tenant A", Type: domain.BankTypePrivate})
+	require.NoError(t, err)
+	f.bankB, err = banks.Create(ctx, domain.CreateBankInput{Name: "Tenant B", Type: domain.BankTypePrivate})
+	require.NoError(t, err)
+
+	// Each client is onboarded by its bank
+	f.clientA, err = clients.Create(tenant.WithBank(ctx, f.bankA.ID), domain.CreateClientInput{FullName: "Client A", Email: uniqueClientEmail(t), BirthDate: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), Country: "MX"})
+	require.NoError(t, err)
+	f.clientB, err = clients.Create(tenant.WithBank(ctx, f.bankB.ID), domain.CreateClientInput{FullName: "Client B", Email: uniqueClientEmail(t), BirthDate: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), Country: "MX"})
+	require.NoError(t, err)
+
+	f.creditA, err = credits.Create(ctx, domain.CreateCreditInput{ClientID: f.clientA.ID, BankID: f.bankA.ID, MinPayment: 100, MaxPayment: 500, TermMonths: 12, CreditType: domain.CreditTypeAuto}, domain.CreditStatusPending)
+	require.NoError(t, err)
+	f.creditB, err = credits.Create(ctx, domain.CreateCreditInput{ClientID: f.clientB.ID, BankID: f.bankB.ID, MinPayment: 100, MaxPayment: 500, TermMonths: 12, CreditType: domain.CreditTypeAuto}, domain.CreditStatusPending)
+	require.NoError(t, err)
+
+	return f, func() {
+		deleteCredit(t, pool, f.creditA.ID)
+		deleteCredit(t, pool, f.creditB.ID)
+		deleteClient(t, pool, f.clientA.ID)
+		deleteClient(t, pool, f.clientB.ID)
+		deleteBank(t, pool, f.bankA.ID)
+		deleteBank(t, pool, f.bankB.ID)
+		pool.Close()
+	}
+}
+
+func TestTenantIsolation_Reads(t *testing.T) {
+	f, cleanup := newTenantFixture(t)
+	defer cleanup()
+	pool := testDBPool(t)
+	defer pool.Close()
+	ctxA := tenant.WithBank(context.Background(), f.bankA.ID)
+
+	credits := postgres.NewCreditRepository(pool)
+	got, err := credits.GetByID(ctxA, f.creditB.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got, "credit of another bank must be invisible")
+	got, err = credits.GetByID(ctxA, f.creditA.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+
+	list, err := credits.List(ctxA, 1000, 0)
+	require.NoError(t, err)
+	for _, c := range list {
+		assert.Equal(t, f.bankA.ID, c.BankID)
+	}
+	byClient, err := credits.ListByClientID(ctxA, f.clientB.ID, 100, 0)
+	require.NoError(t, err)
+	assert.Empty(t, byClient)
+
+	clients := postgres.NewClientRepository(pool)
+	client, err := clients.GetByID(ctxA, f.clientB.ID)
+	require.NoError(t, err)
+	assert.Nil(t, client)
+	clientList, err := clients.List(ctxA, 1000, 0)
+	require.NoError(t, err)
+	for _, c := range clientList {
+		assert.NotEqual(t, f.clientB.ID, c.ID)
+	}
+
+	banks := postgres.NewBankRepository(pool)
+	bank, err := banks.GetByID(ctxA, f.bankB.ID)
+	require.NoError(t, err)
+	assert.Nil(t, bank)
+	bankList, err := banks.List(ctxA, 1000, 0)
+	require.NoError(t, err)
+	require.Len(t, bankList, 1)
+	assert.Equal(t, f.bankA.ID, bankList[0].ID)
+
+	history, err := postgres.NewAuditRepository(pool).ListByEntity(ctxA, domain.AuditEntityCredit, f.creditB.ID, 100, 0)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+
+	var exported []string
+	err = postgres.NewCreditExportRepository(pool).StreamCredits(ctxA, domain.CreditExportFilter{}, func(row *domain.CreditExportRow) error {
+		exported = append(exported, row.BankID)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, exported)
+	for _, bankID := range exported {
+		assert.Equal(t, f.bankA.ID, bankID)
+	}
+}
+
+func TestTenantIsolation_Writes(t *testing.T) {
+	f, cleanup := newTenantFixture(t)
+	defer cleanup()
+	pool := testDBPool(t)
+	defer pool.Close()
+	ctxA := tenant.WithBank(context.Background(), f.bankA.ID)
+	credits := postgres.NewCreditRepository(pool)
+	clients := postgres.NewClientRepository(pool)
+	banks := postgres.NewBankRepository(pool)
+
+	updated, err := credits.UpdateStatus(ctxA, f.creditB.ID, domain.CreditStatusApproved)
+	require.NoError(t, err)
+	assert.Nil(t, updated)
+	updated, err = credits.Update(ctxA, f.creditB.ID, domain.UpdateCreditInput{MinPayment: 1, MaxPayment: 2, TermMonths: 1, Status: domain.CreditStatusRejected})
+	require.NoError(t, err)
+	assert.Nil(t, updated)
+	updated, err = credits.SetInactive(ctxA, f.creditB.ID)
+	require.NoError(t, err)
+	assert.Nil(t, updated)
+
+	_, err = credits.Create(ctxA, domain.CreateCreditInput{ClientID: f.clientA.ID, BankID: f.bankB.ID, MinPayment: 100, MaxPayment: 500, TermMonths: 12, CreditType: domain.CreditTypeAuto}, domain.CreditStatusPending)
+	assert.ErrorIs(t, err, tenant.ErrCrossTenant)
+
+	client, err := clients.Update(ctxA, f.clientB.ID, domain.UpdateClientInput{FullName: "Hijacked", Email: uniqueClientEmail(t), BirthDate: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), Country: "US"})
+	require.NoError(t, err)
+	assert.Nil(t, client)
+	client, err = clients.SetInactive(ctxA, f.clientB.ID)
+	require.NoError(t, err)
+	assert.Nil(t, client)
+
+	bank, err := banks.Update(ctxA, f.bankB.ID, domain.UpdateBankInput{Name: "Hijacked", Type: domain.BankTypeGovernment})
+	require.NoError(t, err)
+	assert.Nil(t, bank)
+	_, err = banks.Create(ctxA, domain.CreateBankInput{Name: "Rogue", Type: domain.BankTypePrivate})
+	assert.ErrorIs(t, err, tenant.ErrCrossTenant)
+
+	// Tenant B's data is untouched
+	ctx := context.Background()
+	creditB, err := credits.GetByID(ctx, f.creditB.ID)
+	require.NoError(t, err)
+	require.NotNil(t, creditB)
+	assert.Equal(t, domain.CreditStatusPending, creditB.Status)
+	assert.True(t, creditB.IsActive)
+	clientB, err := clients.GetByID(ctx, f.clientB.ID)
+	require.NoError(t, err)
+	require.NotNil(t, clientB)
+	assert.Equal(t, "Client B", clientB.FullName)
+	bankB, err := banks.GetByID(ctx, f.bankB.ID)
+	require.NoError(t, err)
+	require.NotNil(t, bankB)
+	assert.Equal(t, "Tenant B", bankB.Name)
+}
+
+func TestTenantIsolation_CreditImports(t *testing.T) {
+	f, cleanup := newTenantFixture(t)
+	defer cleanup()
+	pool := testDBPool(t)
+	defer pool.Close()
+	repo := postgres.NewCreditImportRepository(pool)
+
+	imp, err := repo.Create(tenant.WithBank(context.Background(), f.bankB.ID), domain.ImportFormatCSV)
+	require.NoError(t, err)
+	defer deleteCreditImport(t, pool, imp.ID)
+
+	got, err := repo.GetByID(tenant.WithBank(context.Background(), f.bankA.ID), imp.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+	got, err = repo.GetByID(tenant.WithBank(context.Background(), f.bankB.ID), imp.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, got)
+}
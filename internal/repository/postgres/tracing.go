@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/tucredito/backend-api/internal/tracing"
+)
+
+/*
+	queryTracer gives every Query, QueryRow and Exec a client span ("postgres SELECT", "postgres INSERT", ...)
+	The statement is recorded without its arguments; pgx runs it on the caller's ctx, so spans nest under the service
+*/
+
+type queryTracer struct{}
+
+var _ pgx.QueryTracer = queryTracer{}
+
+type querySpanKey struct{}
+
+func (queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx, span := tracing.Start(ctx, "postgres "+sqlOperation(data.SQL), tracing.WithKind(tracing.KindClient), tracing.WithAttributes(
+		tracing.String("db.system", "postgresql"),
+		tracing.String("db.statement", data.SQL),
+	))
+	if span == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, querySpanKey{}, span)
+}
+
+func (queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span, _ := ctx.Value(querySpanKey{}).(*tracing.Span)
+	if span == nil {
+		return
+	}
+	span.SetAttributes(tracing.Int("db.rows_affected", int(data.CommandTag.RowsAffected())))
+	span.RecordError(data.Err)
+	span.End()
+}
+
+// Returns the statement's leading keyword (SELECT, INSERT, BEGIN, ...)
+func sqlOperation(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "QUERY"
+	}
+	return strings.ToUpper(fields[0])
+}
@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+/*
+	Unit of work over a pgx transaction
+	The transaction travels in the context, so repositories need no per-call plumbing: conn and begin pick it
+	up when present and fall back to the pool otherwise. Audited mutations inside a unit of work run as
+	savepoints, so a failed one rolls back alone and the caller decides whether the unit fails
+*/
+
+type txKey struct{}
+
+type TxManager struct {
+	pool *pgxpool.Pool
+}
+
+func NewTxManager(pool *pgxpool.Pool) *TxManager {
+	return &TxManager{pool: pool}
+}
+
+// Runs fn in a transaction; nested calls join the outer one
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+	tx, err := m.pool.Begin(ctx)
+	if err != nil {
+		return dbError(err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	return dbError(tx.Commit(ctx))
+}
+
+// Returns the unit of work's transaction in ctx, or pool
+func conn(ctx context.Context, pool *pgxpool.Pool) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return pool
+}
+
+// Starts a transaction, or a savepoint when ctx carries a unit of work
+func begin(ctx context.Context, pool *pgxpool.Pool) (pgx.Tx, error) {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx.Begin(ctx)
+	}
+	return pool.Begin(ctx)
+}
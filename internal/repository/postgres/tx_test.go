@@ -0,0 +1,63 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/repository/postgres"
+)
+
+func TestTxManager_SpansRepositories(t *testing.T) {
+	pool := testDBPool(t)
+	defer pool.Close()
+	ctx := context.Background()
+	tx := postgres.NewTxManager(pool)
+	clientRepo := postgres.NewClientRepository(pool)
+	bankRepo := postgres.NewBankRepository(pool)
+	creditRepo := postgres.NewCreditRepository(pool)
+
+	var client *domain.Client
+	var bank *domain.Bank
+	var credit *domain.Credit
+	errAbort := errors.New("abort")
+	err := tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if client, err = clientRepo.Create(ctx, domain.CreateClientInput{
+			FullName: "Tx Client", Email: uniqueClientEmail(t), BirthDate: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), Country: "US",
+		}); err != nil {
+			return err
+		}
+		if bank, err = bankRepo.Create(ctx, domain.CreateBankInput{Name: "Tx Bank", Type: domain.BankTypePrivate}); err != nil {
+			return err
+		}
+		credit, err = creditRepo.Create(ctx, domain.CreateCreditInput{
+			ClientID: client.ID, BankID: bank.ID, MinPayment: 100, MaxPayment: 500, TermMonths: 12, CreditType: domain.CreditTypeAuto,
+		}, domain.CreditStatusApproved)
+		if err != nil {
+			return err
+		}
+		// Reads inside the unit see its uncommitted writes
+		got, err := creditRepo.GetByID(ctx, credit.ID)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, domain.CreditStatusApproved, got.Status)
+		return errAbort
+	})
+	assert.ErrorIs(t, err, errAbort)
+
+	// Nothing survives the rollback
+	gotCredit, err := creditRepo.GetByID(ctx, credit.ID)
+	require.NoError(t, err)
+	assert.Nil(t, gotCredit)
+	gotClient, err := clientRepo.GetByID(ctx, client.ID)
+	require.NoError(t, err)
+	assert.Nil(t, gotClient)
+	gotBank, err := bankRepo.GetByID(ctx, bank.ID)
+	require.NoError(t, err)
+	assert.Nil(t, gotBank)
+}
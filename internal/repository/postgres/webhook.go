@@ -0,0 +1,294 @@
+package postgres
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/tenant"
+)
+
+type WebhookRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewWebhookRepository(pool *pgxpool.Pool) *WebhookRepository {
+	return &WebhookRepository{pool: pool}
+}
+
+const subscriptionColumns = "id, bank_id, url, event_types, secret, is_active, created_at, updated_at"
+
+// Scans a single subscription row; "no rows" becomes (nil, nil)
+func scanSubscription(row pgx.Row) (*domain.WebhookSubscription, error) {
+	var s domain.WebhookSubscription
+	var bankID *string
+	var types []string
+	err := row.Scan(&s.ID, &bankID, &s.URL, &types, &s.Secret, &s.IsActive, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, dbError(err)
+	}
+	if bankID != nil {
+		s.BankID = *bankID
+	}
+	s.EventTypes = make([]domain.EventType, len(types))
+	for i, t := range types {
+		s.EventTypes[i] = domain.EventType(t)
+	}
+	return &s, nil
+}
+
+func scanSubscriptions(rows pgx.Rows) ([]*domain.WebhookSubscription, error) {
+	var list []*domain.WebhookSubscription
+	for rows.Next() {
+		s, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, dbError(rows.Err())
+}
+
+// TEXT[] parameter for event types
+func eventTypeNames(types []domain.EventType) []string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = string(t)
+	}
+	return names
+}
+
+// Creates a subscription owned by the requesting bank when tenant-scoped
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, input domain.CreateWebhookSubscriptionInput) (*domain.WebhookSubscription, error) {
+	var owner *string
+	if bankID, ok := tenant.BankID(ctx); ok {
+		owner = &bankID
+	}
+	query := `
+		INSERT INTO webhook_subscriptions (id, bank_id, url, event_types, secret)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + subscriptionColumns
+	return scanSubscription(conn(ctx, r.pool).QueryRow(ctx, query,
+		uuid.New().String(), owner, input.URL, eventTypeNames(input.EventTypes), input.Secret))
+}
+
+// Gets a subscription, secret included, by ID
+func (r *WebhookRepository) GetSubscription(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	scope, args := tenantScope(ctx, []interface{}{id}, ownedBy("bank_id"))
+	query := `SELECT ` + subscriptionColumns + ` FROM webhook_subscriptions WHERE id = $1` + scope
+	return scanSubscription(conn(ctx, r.pool).QueryRow(ctx, query, args...))
+}
+
+// Updates a subscription; an empty secret and a nil IsActive keep the current values
+func (r *WebhookRepository) UpdateSubscription(ctx context.Context, id string, input domain.UpdateWebhookSubscriptionInput) (*domain.WebhookSubscription, error) {
+	var a assignments
+	a.set("url", input.URL)
+	a.set("event_types", eventTypeNames(input.EventTypes))
+	if input.Secret != "" {
+		a.set("secret", input.Secret)
+	}
+	setIf(&a, "is_active", input.IsActive)
+	where := a.arg(id)
+	scope, args := tenantScope(ctx, a.args, ownedBy("bank_id"))
+	query := `UPDATE webhook_subscriptions SET ` + a.sql() + ` WHERE id = ` + where + scope + ` RETURNING ` + subscriptionColumns
+	return scanSubscription(conn(ctx, r.pool).QueryRow(ctx, query, args...))
+}
+
+// Deletes a subscription with its deliveries and their log
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	scope, args := tenantScope(ctx, []interface{}{id}, ownedBy("bank_id"))
+	query := `DELETE FROM webhook_subscriptions WHERE id = $1` + scope + ` RETURNING ` + subscriptionColumns
+	return scanSubscription(conn(ctx, r.pool).QueryRow(ctx, query, args...))
+}
+
+// Lists subscriptions, newest first
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context, limit, offset int) ([]*domain.WebhookSubscription, error) {
+	scope, args := tenantScope(ctx, nil, ownedBy("bank_id"))
+	pagination, args := paginate(args, limit, offset)
+	query := `SELECT ` + subscriptionColumns + ` FROM webhook_subscriptions WHERE TRUE` + scope + ` ORDER BY created_at DESC, id` + pagination
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	return scanSubscriptions(rows)
+}
+
+// Active subscriptions to eventType that receive bankID's events: the bank's own and the platform's
+func (r *WebhookRepository) Subscribers(ctx context.Context, eventType domain.EventType, bankID string) ([]*domain.WebhookSubscription, error) {
+	query := `
+		SELECT ` + subscriptionColumns + ` FROM webhook_subscriptions
+		WHERE is_active AND $1 = ANY(event_types) AND (bank_id IS NULL OR bank_id::text = $2)
+		ORDER BY created_at, id
+	`
+	rows, err := conn(ctx, r.pool).Query(ctx, query, string(eventType), bankID)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	return scanSubscriptions(rows)
+}
+
+const deliveryColumns = "d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at, d.last_attempt_at, d.last_status_code, d.last_error, d.created_at"
+
+// Scans a single delivery row; "no rows" becomes (nil, nil)
+func scanDelivery(row pgx.Row) (*domain.WebhookDelivery, error) {
+	var d domain.WebhookDelivery
+	var payload []byte
+	err := row.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastAttemptAt, &d.LastStatusCode, &d.LastError, &d.CreatedAt)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, dbError(err)
+	}
+	d.Payload = payload
+	return &d, nil
+}
+
+func scanDeliveries(rows pgx.Rows) ([]*domain.WebhookDelivery, error) {
+	var list []*domain.WebhookDelivery
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+	return list, dbError(rows.Err())
+}
+
+// Condition for deliveries of subscriptions owned by the bank
+func deliveryOwnedBy(param string) string {
+	return "EXISTS (SELECT 1 FROM webhook_subscriptions s WHERE s.id = d.subscription_id AND s.bank_id = " + param + ")"
+}
+
+// Queues an event for a subscription, due immediately
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, input domain.CreateWebhookDeliveryInput) (*domain.WebhookDelivery, error) {
+	query := `
+		INSERT INTO webhook_deliveries AS d (id, subscription_id, event_id, event_type, payload, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING ` + deliveryColumns
+	return scanDelivery(conn(ctx, r.pool).QueryRow(ctx, query,
+		uuid.New().String(), input.SubscriptionID, input.EventID, input.EventType, []byte(input.Payload)))
+}
+
+/*
+	Claims up to limit pending deliveries whose next attempt is due, oldest first
+	Claimed deliveries are pushed lease into the future, so other instances skip them while they are in flight
+	and they are retried once the lease runs out if this instance dies before recording the attempt
+*/
+
+func (r *WebhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries AS d SET next_attempt_at = NOW() + $2 * INTERVAL '1 millisecond'
+		WHERE d.id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'PENDING' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + deliveryColumns
+	rows, err := conn(ctx, r.pool).Query(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	return scanDeliveries(rows)
+}
+
+// Appends an attempt to the delivery log and moves the delivery to input.Status
+func (r *WebhookRepository) RecordAttempt(ctx context.Context, id string, input domain.RecordWebhookAttemptInput) (*domain.WebhookDelivery, error) {
+	tx, err := begin(ctx, r.pool)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	delivery, err := scanDelivery(tx.QueryRow(ctx, `
+		UPDATE webhook_deliveries AS d SET status = $2, attempts = attempts + 1, next_attempt_at = $3,
+			last_attempt_at = NOW(), last_status_code = $4, last_error = $5
+		WHERE d.id = $1
+		RETURNING `+deliveryColumns,
+		id, input.Status, input.NextAttemptAt, input.StatusCode, input.Error))
+	if err != nil || delivery == nil {
+		return nil, err
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO webhook_delivery_attempts (delivery_id, attempt, status_code, error, duration_ms, attempted_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		id, delivery.Attempts, input.StatusCode, input.Error, input.Duration.Milliseconds(), delivery.LastAttemptAt)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, dbError(err)
+	}
+	return delivery, nil
+}
+
+// Gets a delivery of the subscription with its delivery log
+func (r *WebhookRepository) GetDelivery(ctx context.Context, subscriptionID, id string) (*domain.WebhookDelivery, error) {
+	scope, args := tenantScope(ctx, []interface{}{id, subscriptionID}, deliveryOwnedBy)
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries d WHERE d.id = $1 AND d.subscription_id = $2` + scope
+	delivery, err := scanDelivery(conn(ctx, r.pool).QueryRow(ctx, query, args...))
+	if err != nil || delivery == nil {
+		return nil, err
+	}
+
+	rows, err := conn(ctx, r.pool).Query(ctx, `
+		SELECT attempt, status_code, error, duration_ms, attempted_at
+		FROM webhook_delivery_attempts WHERE delivery_id = $1 ORDER BY id`, id)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	delivery.AttemptLog = []domain.WebhookAttempt{}
+	for rows.Next() {
+		var a domain.WebhookAttempt
+		if err := rows.Scan(&a.Attempt, &a.StatusCode, &a.Error, &a.DurationMS, &a.AttemptedAt); err != nil {
+			return nil, dbError(err)
+		}
+		delivery.AttemptLog = append(delivery.AttemptLog, a)
+	}
+	return delivery, dbError(rows.Err())
+}
+
+// Lists the subscription's deliveries, newest first, optionally only those in status
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID string, status domain.WebhookDeliveryStatus, limit, offset int) ([]*domain.WebhookDelivery, error) {
+	args := []interface{}{subscriptionID}
+	filter := ""
+	if status != "" {
+		args = append(args, status)
+		filter = " AND d.status = $" + strconv.Itoa(len(args))
+	}
+	scope, args := tenantScope(ctx, args, deliveryOwnedBy)
+	pagination, args := paginate(args, limit, offset)
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries d WHERE d.subscription_id = $1` + filter + scope +
+		` ORDER BY d.created_at DESC, d.id` + pagination
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	return scanDeliveries(rows)
+}
+
+// Queues the delivery again, due immediately with its attempts reset; the delivery log is kept
+func (r *WebhookRepository) Redeliver(ctx context.Context, subscriptionID, id string) (*domain.WebhookDelivery, error) {
+	scope, args := tenantScope(ctx, []interface{}{id, subscriptionID}, deliveryOwnedBy)
+	query := `
+		UPDATE webhook_deliveries AS d SET status = 'PENDING', attempts = 0, next_attempt_at = NOW()
+		WHERE d.id = $1 AND d.subscription_id = $2` + scope + `
+		RETURNING ` + deliveryColumns
+	return scanDelivery(conn(ctx, r.pool).QueryRow(ctx, query, args...))
+}
@@ -2,15 +2,28 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/tucredito/backend-api/internal/domain"
 )
 
+/*
+	TxManager runs units of work: repository calls made with the ctx passed to fn share one transaction,
+	committed when fn returns nil and rolled back otherwise. A WithinTx inside another joins the outer unit
+*/
+
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 // ClientRepository defines the methods for client repository persistence
 type ClientRepository interface {
 	Create(ctx context.Context, client domain.CreateClientInput) (*domain.Client, error)
 	GetByID(ctx context.Context, id string) (*domain.Client, error)
+	// Gets the row Update and Patch would change: inactive included, only if the caller may change it
+	GetForUpdate(ctx context.Context, id string) (*domain.Client, error)
 	Update(ctx context.Context, id string, input domain.UpdateClientInput) (*domain.Client, error)
+	Patch(ctx context.Context, id string, patch domain.ClientPatch) (*domain.Client, error)
 	SetInactive(ctx context.Context, id string) (*domain.Client, error)
 	SetActive(ctx context.Context, id string) (*domain.Client, error)
 	List(ctx context.Context, limit, offset int) ([]*domain.Client, error)
@@ -20,7 +33,10 @@ type ClientRepository interface {
 type BankRepository interface {
 	Create(ctx context.Context, input domain.CreateBankInput) (*domain.Bank, error)
 	GetByID(ctx context.Context, id string) (*domain.Bank, error)
+	// Gets the row Update and Patch would change: inactive included, only if the caller may change it
+	GetForUpdate(ctx context.Context, id string) (*domain.Bank, error)
 	Update(ctx context.Context, id string, input domain.UpdateBankInput) (*domain.Bank, error)
+	Patch(ctx context.Context, id string, patch domain.BankPatch) (*domain.Bank, error)
 	SetInactive(ctx context.Context, id string) (*domain.Bank, error)
 	SetActive(ctx context.Context, id string) (*domain.Bank, error)
 	List(ctx context.Context, limit, offset int) ([]*domain.Bank, error)
@@ -28,12 +44,53 @@ type BankRepository interface {
 
 // CreditRepository defines the methods for credit repository persistence
 type CreditRepository interface {
-	Create(ctx context.Context, input domain.CreateCreditInput) (*domain.Credit, error)
+	Create(ctx context.Context, input domain.CreateCreditInput, status domain.CreditStatus) (*domain.Credit, error)
 	GetByID(ctx context.Context, id string) (*domain.Credit, error)
 	Update(ctx context.Context, id string, input domain.UpdateCreditInput) (*domain.Credit, error)
+	Patch(ctx context.Context, id string, patch domain.CreditPatch) (*domain.Credit, error)
 	UpdateStatus(ctx context.Context, id string, status domain.CreditStatus) (*domain.Credit, error)
 	SetInactive(ctx context.Context, id string) (*domain.Credit, error)
 	SetActive(ctx context.Context, id string) (*domain.Credit, error)
 	List(ctx context.Context, limit, offset int) ([]*domain.Credit, error)
 	ListByClientID(ctx context.Context, clientID string, limit, offset int) ([]*domain.Credit, error)
 }
+
+// CreditImportRepository defines the methods for bulk credit import persistence
+type CreditImportRepository interface {
+	Create(ctx context.Context, format domain.ImportFormat) (*domain.CreditImport, error)
+	Complete(ctx context.Context, id string, input domain.CompleteCreditImportInput) (*domain.CreditImport, error)
+	GetByID(ctx context.Context, id string) (*domain.CreditImport, error)
+}
+
+// CreditExportRepository defines the methods for streaming credit portfolio exports
+type CreditExportRepository interface {
+	StreamCredits(ctx context.Context, filter domain.CreditExportFilter, fn func(row *domain.CreditExportRow) error) error
+}
+
+// AuditRepository defines the methods for reading the append-only audit trail
+type AuditRepository interface {
+	ListByEntity(ctx context.Context, entity domain.AuditEntity, entityID string, limit, offset int) ([]*domain.AuditEntry, error)
+	ListByRange(ctx context.Context, entity domain.AuditEntity, from, to time.Time, limit, offset int) ([]*domain.AuditEntry, error)
+}
+
+/*
+	WebhookRepository defines the methods for webhook subscriptions and their deliveries
+	Subscriptions, and the deliveries read through them, are tenant-scoped like credit imports; Subscribers,
+	CreateDelivery, ClaimDue and RecordAttempt serve the dispatcher and ignore the tenant
+*/
+
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, input domain.CreateWebhookSubscriptionInput) (*domain.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, id string) (*domain.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, id string, input domain.UpdateWebhookSubscriptionInput) (*domain.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id string) (*domain.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context, limit, offset int) ([]*domain.WebhookSubscription, error)
+	Subscribers(ctx context.Context, eventType domain.EventType, bankID string) ([]*domain.WebhookSubscription, error)
+
+	CreateDelivery(ctx context.Context, input domain.CreateWebhookDeliveryInput) (*domain.WebhookDelivery, error)
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error)
+	RecordAttempt(ctx context.Context, id string, input domain.RecordWebhookAttemptInput) (*domain.WebhookDelivery, error)
+	GetDelivery(ctx context.Context, subscriptionID, id string) (*domain.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, subscriptionID string, status domain.WebhookDeliveryStatus, limit, offset int) ([]*domain.WebhookDelivery, error)
+	Redeliver(ctx context.Context, subscriptionID, id string) (*domain.WebhookDelivery, error)
+}
@@ -0,0 +1,484 @@
+// Package repotest is the conformance suite of the repository interfaces.
+// Every backend runs it from its own tests (see memory and postgres), so the behavior services rely on (soft
+// deletes, is_active filtering, ordering, uniqueness, foreign keys, versions, tenant scoping, audit entries and
+// units of work) cannot drift between them.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/repository"
+	"github.com/tucredito/backend-api/internal/tenant"
+	"github.com/tucredito/backend-api/pkg/apperr"
+)
+
+// Repositories of one backend; Purge permanently removes a row the suite created ("clients", "banks", "credits",
+// "webhook_subscriptions")
+type Backend struct {
+	Clients  repository.ClientRepository
+	Banks    repository.BankRepository
+	Credits  repository.CreditRepository
+	Audit    repository.AuditRepository
+	Webhooks repository.WebhookRepository
+	Tx       repository.TxManager
+	Purge    func(table, id string)
+}
+
+// Runs the suite; open returns the backend under test for each subtest
+func Run(t *testing.T, open func(t *testing.T) Backend) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s *suite)
+	}{
+		{"ClientLifecycle", testClientLifecycle},
+		{"ClientEmailUnique", testClientEmailUnique},
+		{"ClientPatch", testClientPatch},
+		{"ClientListOrderAndPaging", testClientListOrderAndPaging},
+		{"BankListOrder", testBankListOrder},
+		{"CreditForeignKeys", testCreditForeignKeys},
+		{"CreditCheckConstraints", testCreditCheckConstraints},
+		{"CreditSoftDelete", testCreditSoftDelete},
+		{"CreditListOrder", testCreditListOrder},
+		{"VersionMismatch", testVersionMismatch},
+		{"TenantScoping", testTenantScoping},
+		{"AuditTrail", testAuditTrail},
+		{"AuditRange", testAuditRange},
+		{"UnitOfWorkRollback", testUnitOfWorkRollback},
+		{"WebhookSubscriptions", testWebhookSubscriptions},
+		{"WebhookDeliveries", testWebhookDeliveries},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, &suite{Backend: open(t), ctx: context.Background()})
+		})
+	}
+}
+
+type suite struct {
+	Backend
+	ctx context.Context
+}
+
+// Removes id from table when the test ends; rows are purged in reverse creation order
+func (s *suite) cleanup(t *testing.T, table, id string) {
+	if s.Purge != nil {
+		t.Cleanup(func() { s.Purge(table, id) })
+	}
+}
+
+func (s *suite) bank(t *testing.T, name string) *domain.Bank {
+	t.Helper()
+	b, err := s.Banks.Create(s.ctx, domain.CreateBankInput{Name: name, Type: domain.BankTypePrivate})
+	require.NoError(t, err)
+	require.NotNil(t, b)
+	s.cleanup(t, "banks", b.ID)
+	return b
+}
+
+func (s *suite) client(t *testing.T, ctx context.Context) *domain.Client {
+	t.Helper()
+	c, err := s.Clients.Create(ctx, domain.CreateClientInput{
+		FullName: "Conformance Client", Email: uniqueEmail(t),
+		BirthDate: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), Country: "CO",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	s.cleanup(t, "clients", c.ID)
+	return c
+}
+
+func (s *suite) credit(t *testing.T, ctx context.Context, clientID, bankID string) *domain.Credit {
+	t.Helper()
+	c, err := s.Credits.Create(ctx, creditInput(clientID, bankID), domain.CreditStatusPending)
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	s.cleanup(t, "credits", c.ID)
+	return c
+}
+
+func creditInput(clientID, bankID string) domain.CreateCreditInput {
+	return domain.CreateCreditInput{
+		ClientID: clientID, BankID: bankID, MinPayment: 100, MaxPayment: 500,
+		TermMonths: 12, CreditType: domain.CreditTypeAuto,
+	}
+}
+
+func uniqueEmail(t *testing.T) string {
+	safe := strings.ReplaceAll(t.Name(), "/", "-")
+	return "conformance-" + safe + "-" + uuid.New().String()[:8] + "@test.com"
+}
+
+// Asserts err is an *apperr.Error of kind naming field with code
+func assertFieldError(t *testing.T, err error, kind apperr.Kind, field, code string) {
+	t.Helper()
+	e, ok := apperr.As(err)
+	require.True(t, ok, "expected *apperr.Error, got %v", err)
+	assert.Equal(t, kind, e.Kind)
+	require.Len(t, e.Fields, 1)
+	assert.Equal(t, field, e.Fields[0].Field)
+	assert.Equal(t, code, e.Fields[0].Code)
+}
+
+func (s *suite) subscription(t *testing.T, ctx context.Context, types ...domain.EventType) *domain.WebhookSubscription {
+	t.Helper()
+	sub, err := s.Webhooks.CreateSubscription(ctx, domain.CreateWebhookSubscriptionInput{
+		URL: "https://partner.example.com/hooks", EventTypes: types, Secret: "conformance-secret",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, sub)
+	s.cleanup(t, "webhook_subscriptions", sub.ID)
+	return sub
+}
+
+// IDs of list in order; when want is given, only those, so rows of other tests do not interfere
+func idsAmong[T any](list []*T, idOf func(*T) string, want ...string) []string {
+	keep := make(map[string]bool, len(want))
+	for _, id := range want {
+		keep[id] = true
+	}
+	var ids []string
+	for _, v := range list {
+		if id := idOf(v); len(want) == 0 || keep[id] {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func testClientLifecycle(t *testing.T, s *suite) {
+	created := s.client(t, s.ctx)
+	assert.True(t, created.IsActive)
+	assert.Equal(t, 1, created.Version)
+
+	got, err := s.Clients.GetByID(s.ctx, created.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, created.Email, got.Email)
+	assert.True(t, got.BirthDate.Equal(created.BirthDate))
+
+	updated, err := s.Clients.Update(s.ctx, created.ID, domain.UpdateClientInput{
+		FullName: "Renamed", Email: created.Email, BirthDate: created.BirthDate, Country: "MX",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, updated)
+	assert.Equal(t, "Renamed", updated.FullName)
+	assert.Equal(t, 2, updated.Version)
+
+	deleted, err := s.Clients.SetInactive(s.ctx, created.ID)
+	require.NoError(t, err)
+	require.NotNil(t, deleted)
+	assert.False(t, deleted.IsActive)
+	got, err = s.Clients.GetByID(s.ctx, created.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got, "soft-deleted clients are not found")
+
+	restored, err := s.Clients.SetActive(s.ctx, created.ID)
+	require.NoError(t, err)
+	require.NotNil(t, restored)
+	assert.True(t, restored.IsActive)
+	assert.Equal(t, 4, restored.Version)
+
+	missing, err := s.Clients.Update(s.ctx, uuid.New().String(), domain.UpdateClientInput{Email: uniqueEmail(t)})
+	require.NoError(t, err)
+	assert.Nil(t, missing, "missing rows are (nil, nil)")
+}
+
+func testClientEmailUnique(t *testing.T, s *suite) {
+	first := s.client(t, s.ctx)
+	second := s.client(t, s.ctx)
+
+	_, err := s.Clients.Create(s.ctx, domain.CreateClientInput{
+		FullName: "Duplicate", Email: first.Email, BirthDate: first.BirthDate, Country: "CO",
+	})
+	assertFieldError(t, err, apperr.KindConflict, "email", "duplicate")
+
+	_, err = s.Clients.Update(s.ctx, second.ID, domain.UpdateClientInput{
+		FullName: second.FullName, Email: first.Email, BirthDate: second.BirthDate, Country: second.Country,
+	})
+	assertFieldError(t, err, apperr.KindConflict, "email", "duplicate")
+
+	// The email stays taken while its client is soft-deleted
+	_, err = s.Clients.SetInactive(s.ctx, first.ID)
+	require.NoError(t, err)
+	_, err = s.Clients.Create(s.ctx, domain.CreateClientInput{
+		FullName: "Duplicate", Email: first.Email, BirthDate: first.BirthDate, Country: "CO",
+	})
+	assertFieldError(t, err, apperr.KindConflict, "email", "duplicate")
+}
+
+func testClientPatch(t *testing.T, s *suite) {
+	created := s.client(t, s.ctx)
+	country := "AR"
+	patched, err := s.Clients.Patch(s.ctx, created.ID, domain.ClientPatch{Country: &country})
+	require.NoError(t, err)
+	require.NotNil(t, patched)
+	assert.Equal(t, "AR", patched.Country)
+	assert.Equal(t, created.FullName, patched.FullName)
+	assert.Equal(t, created.Email, patched.Email)
+	assert.Equal(t, 2, patched.Version)
+}
+
+func testClientListOrderAndPaging(t *testing.T, s *suite) {
+	// Clients onboarded by a fresh bank are the only ones that bank lists
+	bank := s.bank(t, "Conformance Paging Bank")
+	ctx := tenant.WithBank(s.ctx, bank.ID)
+	oldest := s.client(t, ctx)
+	middle := s.client(t, ctx)
+	newest := s.client(t, ctx)
+
+	list, err := s.Clients.List(ctx, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{newest.ID, middle.ID, oldest.ID}, idsAmong(list, clientID))
+
+	list, err = s.Clients.List(ctx, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{middle.ID}, idsAmong(list, clientID))
+
+	_, err = s.Clients.SetInactive(ctx, middle.ID)
+	require.NoError(t, err)
+	list, err = s.Clients.List(ctx, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{newest.ID, oldest.ID}, idsAmong(list, clientID))
+
+	list, err = s.Clients.List(ctx, 10, 5)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func testBankListOrder(t *testing.T, s *suite) {
+	suffix := uuid.New().String()[:8]
+	c := s.bank(t, "Conformance C "+suffix)
+	a := s.bank(t, "Conformance A "+suffix)
+	b := s.bank(t, "Conformance B "+suffix)
+	_, err := s.Banks.SetInactive(s.ctx, b.ID)
+	require.NoError(t, err)
+
+	list, err := s.Banks.List(s.ctx, 1000, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{a.ID, c.ID}, idsAmong(list, bankID, a.ID, b.ID, c.ID))
+
+	got, err := s.Banks.GetByID(s.ctx, b.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got, "soft-deleted banks are not found")
+}
+
+func testCreditForeignKeys(t *testing.T, s *suite) {
+	bank := s.bank(t, "Conformance FK Bank")
+	client := s.client(t, s.ctx)
+
+	_, err := s.Credits.Create(s.ctx, creditInput(uuid.New().String(), bank.ID), domain.CreditStatusPending)
+	assertFieldError(t, err, apperr.KindValidation, "client_id", "not_found")
+
+	_, err = s.Credits.Create(s.ctx, creditInput(client.ID, uuid.New().String()), domain.CreditStatusPending)
+	assertFieldError(t, err, apperr.KindValidation, "bank_id", "not_found")
+
+	// Soft-deleted parents still satisfy the foreign keys
+	_, err = s.Clients.SetInactive(s.ctx, client.ID)
+	require.NoError(t, err)
+	s.credit(t, s.ctx, client.ID, bank.ID)
+}
+
+func testCreditCheckConstraints(t *testing.T, s *suite) {
+	bank := s.bank(t, "Conformance Check Bank")
+	client := s.client(t, s.ctx)
+
+	input := creditInput(client.ID, bank.ID)
+	input.TermMonths = 0
+	_, err := s.Credits.Create(s.ctx, input, domain.CreditStatusPending)
+	assertFieldError(t, err, apperr.KindValidation, "term_months", "out_of_range")
+
+	input = creditInput(client.ID, bank.ID)
+	input.CreditType = "PERSONAL"
+	_, err = s.Credits.Create(s.ctx, input, domain.CreditStatusPending)
+	assertFieldError(t, err, apperr.KindValidation, "credit_type", "out_of_range")
+
+	credit := s.credit(t, s.ctx, client.ID, bank.ID)
+	_, err = s.Credits.UpdateStatus(s.ctx, credit.ID, "CANCELLED")
+	assertFieldError(t, err, apperr.KindValidation, "status", "out_of_range")
+}
+
+func testCreditSoftDelete(t *testing.T, s *suite) {
+	bank := s.bank(t, "Conformance Soft Delete Bank")
+	client := s.client(t, s.ctx)
+	credit := s.credit(t, s.ctx, client.ID, bank.ID)
+
+	deleted, err := s.Credits.SetInactive(s.ctx, credit.ID)
+	require.NoError(t, err)
+	require.NotNil(t, deleted)
+	assert.False(t, deleted.IsActive)
+
+	// Unlike clients and banks, inactive credits can still be fetched by ID
+	got, err := s.Credits.GetByID(s.ctx, credit.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.False(t, got.IsActive)
+
+	byClient, err := s.Credits.ListByClientID(s.ctx, client.ID, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, byClient)
+
+	restored, err := s.Credits.SetActive(s.ctx, credit.ID)
+	require.NoError(t, err)
+	require.NotNil(t, restored)
+	byClient, err = s.Credits.ListByClientID(s.ctx, client.ID, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{credit.ID}, idsAmong(byClient, creditID, credit.ID))
+}
+
+func testCreditListOrder(t *testing.T, s *suite) {
+	bank := s.bank(t, "Conformance Credit Order Bank")
+	ctx := tenant.WithBank(s.ctx, bank.ID)
+	client := s.client(t, ctx)
+	first := s.credit(t, ctx, client.ID, bank.ID)
+	second := s.credit(t, ctx, client.ID, bank.ID)
+
+	list, err := s.Credits.List(ctx, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{second.ID, first.ID}, idsAmong(list, creditID))
+
+	approved, err := s.Credits.UpdateStatus(ctx, first.ID, domain.CreditStatusApproved)
+	require.NoError(t, err)
+	require.NotNil(t, approved)
+	assert.Equal(t, domain.CreditStatusApproved, approved.Status)
+	assert.Equal(t, 2, approved.Version)
+}
+
+func testVersionMismatch(t *testing.T, s *suite) {
+	created := s.client(t, s.ctx)
+
+	stale := repository.WithExpectedVersion(s.ctx, created.Version+1)
+	_, err := s.Clients.SetInactive(stale, created.ID)
+	assert.ErrorIs(t, err, repository.ErrVersionMismatch)
+
+	current := repository.WithExpectedVersion(s.ctx, created.Version)
+	updated, err := s.Clients.SetInactive(current, created.ID)
+	require.NoError(t, err)
+	require.NotNil(t, updated)
+	assert.Equal(t, created.Version+1, updated.Version)
+}
+
+func testTenantScoping(t *testing.T, s *suite) {
+	own := s.bank(t, "Conformance Own Bank")
+	other := s.bank(t, "Conformance Other Bank")
+	ownCtx := tenant.WithBank(s.ctx, own.ID)
+	otherCtx := tenant.WithBank(s.ctx, other.ID)
+
+	_, err := s.Banks.Create(ownCtx, domain.CreateBankInput{Name: "Not Allowed", Type: domain.BankTypePrivate})
+	assert.ErrorIs(t, err, tenant.ErrCrossTenant)
+
+	client := s.client(t, ownCtx)
+	_, err = s.Credits.Create(ownCtx, creditInput(client.ID, other.ID), domain.CreditStatusPending)
+	assert.ErrorIs(t, err, tenant.ErrCrossTenant)
+
+	got, err := s.Clients.GetByID(otherCtx, client.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got, "clients of other banks are invisible")
+
+	// A credit with the client makes it visible to the other bank, but not changeable by it
+	credit := s.credit(t, otherCtx, client.ID, other.ID)
+	got, err = s.Clients.GetByID(otherCtx, client.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, got)
+	changed, err := s.Clients.SetInactive(otherCtx, client.ID)
+	require.NoError(t, err)
+	assert.Nil(t, changed)
+
+	gotCredit, err := s.Credits.GetByID(ownCtx, credit.ID)
+	require.NoError(t, err)
+	assert.Nil(t, gotCredit, "credits of other banks are invisible")
+	changedCredit, err := s.Credits.UpdateStatus(ownCtx, credit.ID, domain.CreditStatusApproved)
+	require.NoError(t, err)
+	assert.Nil(t, changedCredit)
+
+	gotBank, err := s.Banks.GetByID(ownCtx, other.ID)
+	require.NoError(t, err)
+	assert.Nil(t, gotBank)
+	banks, err := s.Banks.List(ownCtx, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{own.ID}, idsAmong(banks, bankID))
+}
+
+func testAuditTrail(t *testing.T, s *suite) {
+	created := s.client(t, s.ctx)
+	_, err := s.Clients.SetInactive(s.ctx, created.ID)
+	require.NoError(t, err)
+
+	entries, err := s.Audit.ListByEntity(s.ctx, domain.AuditEntityClient, created.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, domain.AuditActionCreate, entries[0].Action)
+	assert.Equal(t, domain.AuditActionDeactivate, entries[1].Action)
+	assert.JSONEq(t, `{"is_active":{"before":true,"after":false},"version":{"before":1,"after":2}}`, string(entries[1].Changes))
+
+	other := s.bank(t, "Conformance Audit Bank")
+	entries, err = s.Audit.ListByEntity(tenant.WithBank(s.ctx, other.ID), domain.AuditEntityClient, created.ID, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "history follows the entity's visibility")
+}
+
+func testAuditRange(t *testing.T, s *suite) {
+	created := s.client(t, s.ctx)
+	_, err := s.Clients.SetInactive(s.ctx, created.ID)
+	require.NoError(t, err)
+	history, err := s.Audit.ListByEntity(s.ctx, domain.AuditEntityClient, created.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	from, to := history[0].OccurredAt, history[1].OccurredAt.Add(time.Microsecond)
+
+	ofClient := func(ctx context.Context, entity domain.AuditEntity, from, to time.Time) []domain.AuditAction {
+		t.Helper()
+		entries, err := s.Audit.ListByRange(ctx, entity, from, to, 1000, 0)
+		require.NoError(t, err)
+		var actions []domain.AuditAction
+		for i, e := range entries {
+			if i > 0 {
+				assert.False(t, e.OccurredAt.Before(entries[i-1].OccurredAt), "oldest first")
+			}
+			if e.EntityID == created.ID {
+				actions = append(actions, e.Action)
+			}
+		}
+		return actions
+	}
+	assert.Equal(t, []domain.AuditAction{domain.AuditActionCreate, domain.AuditActionDeactivate}, ofClient(s.ctx, domain.AuditEntityClient, from, to))
+	assert.Empty(t, ofClient(s.ctx, domain.AuditEntityClient, from, from), "the range excludes its end")
+	assert.Empty(t, ofClient(s.ctx, domain.AuditEntityBank, from, to), "other entity types are not listed")
+
+	other := s.bank(t, "Conformance Audit Range Bank")
+	assert.Empty(t, ofClient(tenant.WithBank(s.ctx, other.ID), domain.AuditEntityClient, from, to), "ranges follow the entity's visibility")
+}
+
+func testUnitOfWorkRollback(t *testing.T, s *suite) {
+	var created *domain.Client
+	failure := errors.New("abort")
+	err := s.Tx.WithinTx(s.ctx, func(ctx context.Context) error {
+		var err error
+		created, err = s.Clients.Create(ctx, domain.CreateClientInput{
+			FullName: "Rolled Back", Email: uniqueEmail(t),
+			BirthDate: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), Country: "CO",
+		})
+		require.NoError(t, err)
+		return failure
+	})
+	assert.ErrorIs(t, err, failure)
+	require.NotNil(t, created)
+
+	got, err := s.Clients.GetByID(s.ctx, created.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got, "a failed unit of work leaves nothing behind")
+	entries, err := s.Audit.ListByEntity(s.ctx, domain.AuditEntityClient, created.ID, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func clientID(c *domain.Client) string { return c.ID }
+func bankID(b *domain.Bank) string     { return b.ID }
+func creditID(c *domain.Credit) string { return c.ID }
@@ -0,0 +1,177 @@
+package repotest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/tenant"
+	"github.com/tucredito/backend-api/pkg/apperr"
+)
+
+func testWebhookSubscriptions(t *testing.T, s *suite) {
+	own := s.bank(t, "Conformance Webhook Bank")
+	other := s.bank(t, "Conformance Other Webhook Bank")
+	ownCtx := tenant.WithBank(s.ctx, own.ID)
+	otherCtx := tenant.WithBank(s.ctx, other.ID)
+
+	platform := s.subscription(t, s.ctx, domain.EventCreditApproved, domain.EventCreditRejected)
+	assert.Empty(t, platform.BankID)
+	assert.True(t, platform.IsActive)
+	owned := s.subscription(t, ownCtx, domain.EventCreditApproved)
+	assert.Equal(t, own.ID, owned.BankID)
+
+	got, err := s.Webhooks.GetSubscription(s.ctx, owned.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "conformance-secret", got.Secret, "the dispatcher reads the secret back")
+	assert.Equal(t, []domain.EventType{domain.EventCreditApproved}, got.EventTypes)
+
+	got, err = s.Webhooks.GetSubscription(otherCtx, owned.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got, "subscriptions of other banks are invisible")
+	list, err := s.Webhooks.ListSubscriptions(ownCtx, 100, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{owned.ID}, idsAmong(list, subscriptionID, owned.ID, platform.ID))
+	list, err = s.Webhooks.ListSubscriptions(s.ctx, 100, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{owned.ID, platform.ID}, idsAmong(list, subscriptionID, owned.ID, platform.ID), "newest first")
+
+	subscribers := func(eventType domain.EventType, bankID string) []string {
+		t.Helper()
+		subs, err := s.Webhooks.Subscribers(otherCtx, eventType, bankID)
+		require.NoError(t, err)
+		return idsAmong(subs, subscriptionID, owned.ID, platform.ID)
+	}
+	assert.Equal(t, []string{platform.ID, owned.ID}, subscribers(domain.EventCreditApproved, own.ID), "the tenant in ctx is ignored")
+	assert.Equal(t, []string{platform.ID}, subscribers(domain.EventCreditApproved, other.ID))
+	assert.Equal(t, []string{platform.ID}, subscribers(domain.EventCreditRejected, own.ID))
+	assert.Empty(t, subscribers(domain.EventCreditCreated, own.ID))
+
+	inactive := false
+	updated, err := s.Webhooks.UpdateSubscription(ownCtx, owned.ID, domain.UpdateWebhookSubscriptionInput{
+		URL: "https://partner.example.com/v2", EventTypes: []domain.EventType{domain.EventCreditCreated}, IsActive: &inactive,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, updated)
+	assert.Equal(t, "https://partner.example.com/v2", updated.URL)
+	assert.Equal(t, "conformance-secret", updated.Secret, "an empty secret keeps the current one")
+	assert.False(t, updated.IsActive)
+	assert.Empty(t, subscribers(domain.EventCreditCreated, own.ID), "inactive subscriptions receive nothing")
+
+	_, err = s.Webhooks.UpdateSubscription(s.ctx, owned.ID, domain.UpdateWebhookSubscriptionInput{URL: updated.URL, EventTypes: []domain.EventType{}})
+	assertFieldError(t, err, apperr.KindValidation, "event_types", "out_of_range")
+	missing, err := s.Webhooks.UpdateSubscription(otherCtx, owned.ID, domain.UpdateWebhookSubscriptionInput{URL: updated.URL, EventTypes: updated.EventTypes})
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	deleted, err := s.Webhooks.DeleteSubscription(otherCtx, owned.ID)
+	require.NoError(t, err)
+	assert.Nil(t, deleted)
+	deleted, err = s.Webhooks.DeleteSubscription(ownCtx, owned.ID)
+	require.NoError(t, err)
+	require.NotNil(t, deleted)
+	got, err = s.Webhooks.GetSubscription(s.ctx, owned.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func testWebhookDeliveries(t *testing.T, s *suite) {
+	own := s.bank(t, "Conformance Delivery Bank")
+	other := s.bank(t, "Conformance Other Delivery Bank")
+	sub := s.subscription(t, tenant.WithBank(s.ctx, own.ID), domain.EventCreditApproved)
+	input := domain.CreateWebhookDeliveryInput{
+		SubscriptionID: sub.ID, EventID: uuid.New().String(), EventType: domain.EventCreditApproved, Payload: []byte(`{"id":"evt"}`),
+	}
+
+	created, err := s.Webhooks.CreateDelivery(s.ctx, input)
+	require.NoError(t, err)
+	require.NotNil(t, created)
+	assert.Equal(t, domain.WebhookDeliveryPending, created.Status)
+	assert.Zero(t, created.Attempts)
+	assert.NotNil(t, created.NextAttemptAt)
+	assert.JSONEq(t, `{"id":"evt"}`, string(created.Payload))
+
+	_, err = s.Webhooks.CreateDelivery(s.ctx, input)
+	assertFieldError(t, err, apperr.KindConflict, "subscription_id_event_id", "duplicate")
+	_, err = s.Webhooks.CreateDelivery(s.ctx, domain.CreateWebhookDeliveryInput{
+		SubscriptionID: uuid.New().String(), EventID: uuid.New().String(), EventType: domain.EventCreditApproved, Payload: []byte(`{}`),
+	})
+	assertFieldError(t, err, apperr.KindValidation, "subscription_id", "not_found")
+
+	claim := func() []string {
+		t.Helper()
+		due, err := s.Webhooks.ClaimDue(s.ctx, 1000, time.Minute)
+		require.NoError(t, err)
+		return idsAmong(due, deliveryID, created.ID)
+	}
+	assert.Equal(t, []string{created.ID}, claim())
+	assert.Empty(t, claim(), "claimed deliveries are leased")
+
+	status := 500
+	retryAt := time.Now().Add(time.Hour)
+	failed, err := s.Webhooks.RecordAttempt(s.ctx, created.ID, domain.RecordWebhookAttemptInput{
+		StatusCode: &status, Error: "HTTP 500", Duration: 20 * time.Millisecond,
+		Status: domain.WebhookDeliveryPending, NextAttemptAt: &retryAt,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, failed)
+	assert.Equal(t, 1, failed.Attempts)
+	require.NotNil(t, failed.LastStatusCode)
+	assert.Equal(t, 500, *failed.LastStatusCode)
+	assert.NotNil(t, failed.LastAttemptAt)
+	assert.Empty(t, claim(), "retries wait for their next attempt")
+
+	dead, err := s.Webhooks.RecordAttempt(s.ctx, created.ID, domain.RecordWebhookAttemptInput{
+		Error: "connection refused", Status: domain.WebhookDeliveryDead,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, dead)
+	assert.Equal(t, domain.WebhookDeliveryDead, dead.Status)
+	assert.Equal(t, 2, dead.Attempts)
+	assert.Nil(t, dead.NextAttemptAt)
+	assert.Nil(t, dead.LastStatusCode)
+
+	got, err := s.Webhooks.GetDelivery(s.ctx, sub.ID, created.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Len(t, got.AttemptLog, 2)
+	assert.Equal(t, 1, got.AttemptLog[0].Attempt)
+	assert.Equal(t, int64(20), got.AttemptLog[0].DurationMS)
+	assert.Equal(t, "connection refused", got.AttemptLog[1].Error)
+	got, err = s.Webhooks.GetDelivery(tenant.WithBank(s.ctx, other.ID), sub.ID, created.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got, "deliveries follow their subscription's visibility")
+	got, err = s.Webhooks.GetDelivery(s.ctx, uuid.New().String(), created.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	list, err := s.Webhooks.ListDeliveries(s.ctx, sub.ID, domain.WebhookDeliveryDead, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{created.ID}, idsAmong(list, deliveryID))
+	list, err = s.Webhooks.ListDeliveries(s.ctx, sub.ID, domain.WebhookDeliverySucceeded, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	redelivered, err := s.Webhooks.Redeliver(tenant.WithBank(s.ctx, own.ID), sub.ID, created.ID)
+	require.NoError(t, err)
+	require.NotNil(t, redelivered)
+	assert.Equal(t, domain.WebhookDeliveryPending, redelivered.Status)
+	assert.Zero(t, redelivered.Attempts)
+	assert.Equal(t, []string{created.ID}, claim(), "a redelivery is due immediately")
+	missing, err := s.Webhooks.Redeliver(tenant.WithBank(s.ctx, other.ID), sub.ID, created.ID)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	_, err = s.Webhooks.DeleteSubscription(s.ctx, sub.ID)
+	require.NoError(t, err)
+	got, err = s.Webhooks.GetDelivery(s.ctx, sub.ID, created.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got, "deleting a subscription deletes its deliveries")
+}
+
+func subscriptionID(s *domain.WebhookSubscription) string { return s.ID }
+func deliveryID(d *domain.WebhookDelivery) string         { return d.ID }
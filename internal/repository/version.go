@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/tucredito/backend-api/pkg/apperr"
+)
+
+/*
+	Optimistic concurrency
+	Clients, banks and credits carry a version that every update increments. A request that read version n
+	(the ETag) and sends If-Match gets its versions threaded through the context; the repositories compare them
+	with the locked row and refuse the write with ErrVersionMismatch instead of overwriting a newer change
+	Without expected versions in the context writes are unconditional, as they were before versions existed
+*/
+
+// The row changed since the caller read it
+var ErrVersionMismatch = apperr.PreconditionFailed("the resource was modified since it was read; fetch it again and retry")
+
+type expectedVersionsKey struct{}
+
+// Returns ctx requiring the mutated row to be at one of versions; no versions means no version can match
+func WithExpectedVersion(ctx context.Context, versions ...int) context.Context {
+	if versions == nil {
+		versions = []int{}
+	}
+	return context.WithValue(ctx, expectedVersionsKey{}, versions)
+}
+
+// Returns the versions set by WithExpectedVersion; ok is false for unconditional writes
+func ExpectedVersions(ctx context.Context) (versions []int, ok bool) {
+	versions, ok = ctx.Value(expectedVersionsKey{}).([]int)
+	return versions, ok
+}
+
+// Returns ErrVersionMismatch unless current satisfies the expected versions in ctx
+func CheckVersion(ctx context.Context, current int) error {
+	versions, ok := ExpectedVersions(ctx)
+	if !ok {
+		return nil
+	}
+	for _, v := range versions {
+		if v == current {
+			return nil
+		}
+	}
+	return ErrVersionMismatch
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/tucredito/backend-api/internal/auth"
+)
+
+// Credentials accepted by the API; see README "Authentication"
+type AuthConfig struct {
+	Disabled    bool
+	JWTSecret   string
+	JWKSFile    string
+	JWTIssuer   string
+	JWTAudience string
+	APIKeys     string
+}
+
+// Builds the authenticator from cfg; nil means authentication is disabled
+func newAuthenticator(cfg AuthConfig) (*auth.Authenticator, error) {
+	if cfg.Disabled {
+		return nil, nil
+	}
+
+	keys := auth.NewKeySet()
+	if cfg.JWTSecret != "" {
+		keys.AddHMAC("", []byte(cfg.JWTSecret))
+	}
+	if cfg.JWKSFile != "" {
+		if err := keys.LoadJWKSFile(cfg.JWKSFile); err != nil {
+			return nil, err
+		}
+	}
+	apiKeys, err := auth.ParseAPIKeys(cfg.APIKeys)
+	if err != nil {
+		return nil, err
+	}
+	if keys.Empty() && apiKeys.Len() == 0 {
+		return nil, errors.New("auth enabled but no JWT keys or API keys configured (set AUTH_DISABLED=true for local development)")
+	}
+
+	verifier := auth.NewJWTVerifier(auth.JWTConfig{Keys: keys, Issuer: cfg.JWTIssuer, Audience: cfg.JWTAudience})
+	return auth.NewAuthenticator(verifier, apiKeys), nil
+}
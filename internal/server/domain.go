@@ -0,0 +1,40 @@
+package server
+
+import (
+	"time"
+
+	"github.com/tucredito/backend-api/internal/decision"
+	"github.com/tucredito/backend-api/internal/event"
+	"github.com/tucredito/backend-api/internal/repository"
+	"github.com/tucredito/backend-api/internal/service"
+	"go.uber.org/zap"
+)
+
+// The publisher, webhook outbox and decision engine every process that changes credits must share
+type Domain struct {
+	Publisher event.Publisher
+	// The outbox credit events are stored in; only the API runs its dispatcher
+	Webhooks service.WebhookService
+	Engine   decision.Engine
+}
+
+/*
+	Builds the credit domain's collaborators the way the API does; the admin CLI uses it too, so its changes
+	publish the same events, queue the same webhooks and are decided by the same rules
+*/
+
+func NewDomain(webhooks repository.WebhookRepository, cfg WebhookConfig, log *zap.Logger) Domain {
+	engine := decision.NewRuleEngine()
+	engine.RegisterRule(decision.PaymentRangeRule{})
+	engine.RegisterRule(decision.BankTypeRule{})
+	return Domain{
+		Publisher: event.WithTracing(event.WithMetrics(event.NewMockPublisher())),
+		Webhooks: service.NewWebhookService(webhooks, service.WebhookConfig{
+			MaxAttempts: cfg.MaxAttempts,
+			RetryBase:   time.Duration(cfg.RetryBaseSeconds) * time.Second,
+			RetryMax:    time.Duration(cfg.RetryMaxSeconds) * time.Second,
+			Timeout:     time.Duration(cfg.TimeoutSeconds) * time.Second,
+		}, log),
+		Engine: engine,
+	}
+}
@@ -2,51 +2,148 @@ package server
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/tucredito/backend-api/internal/auth"
 	"github.com/tucredito/backend-api/internal/cache"
-	"github.com/tucredito/backend-api/internal/decision"
+	"github.com/tucredito/backend-api/internal/clientip"
+	"github.com/tucredito/backend-api/internal/domain"
 	"github.com/tucredito/backend-api/internal/event"
 	"github.com/tucredito/backend-api/internal/handler"
 	"github.com/tucredito/backend-api/internal/metrics"
 	"github.com/tucredito/backend-api/internal/middleware"
-	"github.com/tucredito/backend-api/internal/repository/postgres"
+	"github.com/tucredito/backend-api/internal/ratelimit"
 	"github.com/tucredito/backend-api/internal/service"
+	"github.com/tucredito/backend-api/internal/tracing"
 	"go.uber.org/zap"
 )
 
 type Server struct {
 	httpServer *http.Server
 	creditSvc  service.CreditService
+	stopBg     context.CancelFunc // stops background listeners (cache invalidation)
+	tracer     *tracing.Tracer
+	traceFile  io.Closer // nil unless spans are exported to a file
 	log        *zap.Logger
 }
 
+// Rate-limit budgets (see middleware.RateLimit); Routes uses ratelimit.ParseRoutes syntax
+type RateLimitConfig struct {
+	MaxRequests     int
+	WindowSeconds   int
+	TenantLimits    map[string]int
+	PrincipalLimits map[string]int
+	Routes          string
+}
+
+// Comma-separated IPs/CIDRs of the proxies allowed to set X-Forwarded-For/Forwarded, and of the callers
+// to accept (empty accepts all) or reject
+type ClientIPConfig struct {
+	TrustedProxies string
+	// The forwarding header the trusted proxies set: X-Forwarded-For (default) or Forwarded
+	ForwardedHeader string
+	Allow           string
+	Deny            string
+}
+
+// Sizes and TTL caps of the cache tiers (see cache.TieredCache)
+type CacheConfig struct {
+	LocalCapacity int
+	LocalTTL      int
+	RemoteTTL     int
+}
+
+// Outbound webhook retry policy; durations in seconds
+type WebhookConfig struct {
+	// Attempts before a delivery is dead-lettered
+	MaxAttempts      int
+	RetryBaseSeconds int
+	RetryMaxSeconds  int
+	// Bound of one attempt, response included
+	TimeoutSeconds int
+}
+
 type Config struct {
-	HTTPPort     int
+	HTTPPort int
+	// StoragePostgres (default) or StorageMemory
+	Storage      string
 	DBConnString string
 	RedisAddr    string
 	RedisPass    string
 	RedisDB      int
-	Log          *zap.Logger
+	Auth         AuthConfig
+	RateLimit    RateLimitConfig
+	ClientIP     ClientIPConfig
+	Cache        CacheConfig
+	Tracing      TracingConfig
+	// Answer 428 to PUT/PATCH/DELETE on clients, banks and credits without If-Match
+	RequireIfMatch bool
+	// Apply the embedded migrations at startup (Postgres storage only)
+	AutoMigrate bool
+	Webhooks    WebhookConfig
+	Log         *zap.Logger
 }
 
 func New(ctx context.Context, cfg *Config) (*Server, error) {
-	// Create the database pool
-	pool, err := postgres.NewPool(ctx, cfg.DBConnString)
+	// Create the authenticator before touching the database so bad credentials fail fast
+	authenticator, err := newAuthenticator(cfg.Auth)
 	if err != nil {
 		return nil, err
 	}
+	if authenticator == nil {
+		cfg.Log.Warn("authentication disabled, every request is treated as admin")
+	}
 
-	// Create repositories
-	clientRepo := postgres.NewClientRepository(pool)
-	bankRepo := postgres.NewBankRepository(pool)
-	creditRepo := postgres.NewCreditRepository(pool)
+	// Parse the rate-limit policies before touching the database so a bad spec fails fast
+	rateLimits, err := newRateLimitPolicies(cfg.RateLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	trusted, err := clientip.ParsePrefixes(cfg.ClientIP.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("trusted proxies: %w", err)
+	}
+	forwardedHeader, err := clientip.ParseHeader(cfg.ClientIP.ForwardedHeader)
+	if err != nil {
+		return nil, err
+	}
+	allowIPs, err := clientip.ParsePrefixes(cfg.ClientIP.Allow)
+	if err != nil {
+		return nil, fmt.Errorf("ip allowlist: %w", err)
+	}
+	denyIPs, err := clientip.ParsePrefixes(cfg.ClientIP.Deny)
+	if err != nil {
+		return nil, fmt.Errorf("ip denylist: %w", err)
+	}
+
+	// Create the tracer before the pool so startup queries are traced too
+	tracer, traceFile, err := newTracer(cfg.Tracing, cfg.Log)
+	if err != nil {
+		return nil, err
+	}
+	tracing.SetGlobal(tracer)
+
+	// Create the repositories on the configured storage
+	repos, err := newRepositories(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	pool := repos.pool
+	if pool == nil {
+		cfg.Log.Warn("storage mode: memory, data is lost on restart")
+	}
 
-	// Create the cache
-	var c cache.Cache
+	// Create the cache: memory in front of Redis, or memory only when Redis is not available
+	bgCtx, stopBg := context.WithCancel(context.Background())
+	local := cache.NewMemoryCache(cfg.Cache.LocalCapacity)
+	var c cache.Cache = local
 	var redisClient *redis.Client
 	if cfg.RedisAddr != "" {
 		redisClient = redis.NewClient(&redis.Options{
@@ -54,77 +151,193 @@ func New(ctx context.Context, cfg *Config) (*Server, error) {
 			Password: cfg.RedisPass,
 			DB:       cfg.RedisDB,
 		})
-		var errCache error
-		c, errCache = cache.NewRedisCacheFromClient(redisClient)
+		redisClient.AddHook(cache.TracingHook{})
+		remote, errCache := cache.NewRedisCacheFromClient(redisClient)
 		if errCache != nil {
-			cfg.Log.Warn("redis unavailable, running without cache", zap.Error(errCache))
+			cfg.Log.Warn("redis unavailable, using in-memory cache only", zap.Error(errCache))
 			_ = redisClient.Close()
-			c = nil
 			redisClient = nil
+		} else {
+			tiered := cache.NewTieredCache(local, remote, cache.TieredConfig{
+				LocalTTL:  cfg.Cache.LocalTTL,
+				RemoteTTL: cfg.Cache.RemoteTTL,
+			})
+			go func() {
+				if err := tiered.ListenInvalidations(bgCtx); err != nil {
+					cfg.Log.Warn("cache invalidation listener stopped", zap.Error(err))
+				}
+			}()
+			c = tiered
 		}
 	}
+	// Create the rate limiter: shared buckets in Redis, per-instance buckets without it or while it fails
+	var limiter ratelimit.Limiter = ratelimit.NewMemoryLimiter()
+	if redisClient != nil {
+		var lastWarn atomic.Int64 // unix seconds; warn at most once a minute during an outage
+		limiter = ratelimit.WithFallback(ratelimit.NewRedisLimiter(redisClient), limiter, func(err error) {
+			if now := time.Now().Unix(); now-lastWarn.Load() >= 60 {
+				lastWarn.Store(now)
+				cfg.Log.Warn("redis rate limiter failed, using in-memory limits", zap.Error(err))
+			}
+		})
+	}
 
-	// Create the publisher and engine
-	publisher := event.NewMockPublisher()
-	engine := decision.NewRuleEngine()
-	engine.RegisterRule(decision.PaymentRangeRule{})
-	engine.RegisterRule(decision.BankTypeRule{})
+	if redisClient == nil {
+		cfg.Log.Info("cache mode: memory", zap.Int("capacity", cfg.Cache.LocalCapacity))
+	} else {
+		cfg.Log.Info("cache mode: memory+redis", zap.Int("local_ttl", cfg.Cache.LocalTTL), zap.Int("remote_ttl", cfg.Cache.RemoteTTL))
+	}
+
+	// Export connection pool stats at scrape time
+	if pool != nil {
+		metrics.SetDBPoolSource(func() metrics.DBPoolStats {
+			st := pool.Stat()
+			return metrics.DBPoolStats{
+				Acquired:     st.AcquiredConns(),
+				Idle:         st.IdleConns(),
+				Total:        st.TotalConns(),
+				Max:          st.MaxConns(),
+				WaitCount:    st.EmptyAcquireCount(),
+				WaitDuration: st.AcquireDuration(),
+			}
+		})
+	}
+	if redisClient != nil {
+		metrics.SetRedisPoolSource(func() metrics.RedisPoolStats {
+			st := redisClient.PoolStats()
+			return metrics.RedisPoolStats{
+				Hits: st.Hits, Misses: st.Misses, Timeouts: st.Timeouts,
+				Total: st.TotalConns, Idle: st.IdleConns, Stale: st.StaleConns,
+			}
+		})
+	}
+
+	// Create the publisher, webhook outbox and engine
+	dom := NewDomain(repos.webhooks, cfg.Webhooks, cfg.Log)
+	publisher, webhookSvc := dom.Publisher, dom.Webhooks
 
 	// Create the services
-	clientSvc := service.NewClientService(clientRepo)
-	bankSvc := service.NewBankService(bankRepo)
-	creditSvc := service.NewCreditService(creditRepo, clientRepo, bankRepo, c, publisher, engine, cfg.Log)
+	clientSvc := service.NewClientService(repos.clients, c)
+	bankSvc := service.NewBankService(repos.banks, c)
+	creditSvc := service.NewCreditService(repos.credits, repos.tx, clientSvc, bankSvc, c, publisher, webhookSvc, dom.Engine, cfg.Log)
+	creditImportSvc := service.NewCreditImportService(repos.creditImport, creditSvc, cfg.Log)
+	exportSvc := service.NewExportService(repos.creditExport)
+	auditSvc := service.NewAuditService(repos.audit)
 
 	// Create the handlers
 	clientH := handler.NewClientHandler(clientSvc, cfg.Log)
 	bankH := handler.NewBankHandler(bankSvc, cfg.Log)
 	creditH := handler.NewCreditHandler(creditSvc, cfg.Log)
+	creditImportH := handler.NewCreditImportHandler(creditImportSvc, cfg.Log)
+	exportH := handler.NewExportHandler(exportSvc, cfg.Log)
+	auditH := handler.NewAuditHandler(auditSvc, cfg.Log)
+	webhookH := handler.NewWebhookHandler(webhookSvc, cfg.Log)
 	healthH := handler.NewHealthHandler(pool, redisClient)
+	if repos.migrator != nil {
+		healthH.RegisterCheck(handler.HealthCheck{Name: "schema", Check: repos.migrator.Check, Critical: true})
+	}
+	healthH.RegisterCheck(handler.HealthCheck{Name: "workers", Check: creditSvc.PingWorkers})
+	healthH.RegisterCheck(handler.HealthCheck{Name: "events", Check: func(ctx context.Context) error {
+		return event.Ping(ctx, publisher)
+	}})
 
 	// Initialize the HTTP server
 	mux := http.NewServeMux()
 	const apiVersion = "/v1"
 
-	// Register the health check endpoints
+	// Registers an API route that requires at least role
+	route := func(pattern string, role auth.Role, h http.HandlerFunc) {
+		mux.Handle(pattern, middleware.RequireRole(role)(h))
+	}
+
+	// Wraps writes to versioned resources; If-Match is honored either way, RequireIfMatch makes it mandatory
+	conditional := func(h http.HandlerFunc) http.HandlerFunc {
+		if !cfg.RequireIfMatch {
+			return h
+		}
+		return middleware.RequireIfMatch(h).ServeHTTP
+	}
+
+	// Registers an API route reserved for principals not bound to a bank
+	platformRoute := func(pattern string, role auth.Role, h http.HandlerFunc) {
+		mux.Handle(pattern, middleware.RequireRole(role)(middleware.RequirePlatform(h)))
+	}
+
+	// Register the health check endpoints (public)
 	mux.HandleFunc("GET /health", healthH.Live)
 	mux.HandleFunc("GET /ready", healthH.Ready)
+	mux.HandleFunc("GET /startup", healthH.Startup)
 	mux.HandleFunc("GET /metrics", metrics.Handler)
 
 	// Register the client endpoints
-	mux.HandleFunc("POST "+apiVersion+"/clients", clientH.Create)
-	mux.HandleFunc("GET "+apiVersion+"/clients", clientH.List)
-	mux.HandleFunc("GET "+apiVersion+"/clients/{id}", clientH.GetByID)
-	mux.HandleFunc("PUT "+apiVersion+"/clients/{id}", clientH.Update)
-	mux.HandleFunc("DELETE "+apiVersion+"/clients/{id}", clientH.Delete)
-	mux.HandleFunc("POST "+apiVersion+"/clients/{id}/reenable", clientH.Reenable)
-	mux.HandleFunc("GET "+apiVersion+"/clients/{id}/credits", creditH.ListByClientID)
+	route("POST "+apiVersion+"/clients", auth.RoleUnderwriter, clientH.Create)
+	route("GET "+apiVersion+"/clients", auth.RoleViewer, clientH.List)
+	route("GET "+apiVersion+"/clients/{id}", auth.RoleViewer, clientH.GetByID)
+	route("PUT "+apiVersion+"/clients/{id}", auth.RoleUnderwriter, conditional(clientH.Update))
+	route("PATCH "+apiVersion+"/clients/{id}", auth.RoleUnderwriter, conditional(clientH.Patch))
+	route("DELETE "+apiVersion+"/clients/{id}", auth.RoleAdmin, conditional(clientH.Delete))
+	route("POST "+apiVersion+"/clients/{id}/reenable", auth.RoleAdmin, clientH.Reenable)
+	route("GET "+apiVersion+"/clients/{id}/credits", auth.RoleViewer, creditH.ListByClientID)
+	route("GET "+apiVersion+"/clients/{id}/history", auth.RoleAnalyst, auditH.History(domain.AuditEntityClient))
 
 	// Register the bank endpoints
-	mux.HandleFunc("POST "+apiVersion+"/banks", bankH.Create)
-	mux.HandleFunc("GET "+apiVersion+"/banks", bankH.List)
-	mux.HandleFunc("GET "+apiVersion+"/banks/{id}", bankH.GetByID)
-	mux.HandleFunc("PUT "+apiVersion+"/banks/{id}", bankH.Update)
-	mux.HandleFunc("DELETE "+apiVersion+"/banks/{id}", bankH.Delete)
-	mux.HandleFunc("POST "+apiVersion+"/banks/{id}/reenable", bankH.Reenable)
+	platformRoute("POST "+apiVersion+"/banks", auth.RoleAdmin, bankH.Create)
+	route("GET "+apiVersion+"/banks", auth.RoleViewer, bankH.List)
+	route("GET "+apiVersion+"/banks/{id}", auth.RoleViewer, bankH.GetByID)
+	route("PUT "+apiVersion+"/banks/{id}", auth.RoleAdmin, conditional(bankH.Update))
+	route("PATCH "+apiVersion+"/banks/{id}", auth.RoleAdmin, conditional(bankH.Patch))
+	platformRoute("DELETE "+apiVersion+"/banks/{id}", auth.RoleAdmin, conditional(bankH.Delete))
+	platformRoute("POST "+apiVersion+"/banks/{id}/reenable", auth.RoleAdmin, bankH.Reenable)
+	route("GET "+apiVersion+"/banks/{id}/history", auth.RoleAnalyst, auditH.History(domain.AuditEntityBank))
 
 	// Register the credit endpoints
-	mux.HandleFunc("POST "+apiVersion+"/credits", creditH.Create)
-	mux.HandleFunc("GET "+apiVersion+"/credits", creditH.List)
-	mux.HandleFunc("GET "+apiVersion+"/credits/{id}", creditH.GetByID)
-	mux.HandleFunc("PUT "+apiVersion+"/credits/{id}", creditH.Update)
-	mux.HandleFunc("DELETE "+apiVersion+"/credits/{id}", creditH.Delete)
-	mux.HandleFunc("POST "+apiVersion+"/credits/{id}/reenable", creditH.Reenable)
+	route("POST "+apiVersion+"/credits", auth.RoleUnderwriter, creditH.Create)
+	route("GET "+apiVersion+"/credits", auth.RoleViewer, creditH.List)
+	route("GET "+apiVersion+"/credits/{id}", auth.RoleViewer, creditH.GetByID)
+	route("PUT "+apiVersion+"/credits/{id}", auth.RoleUnderwriter, conditional(creditH.Update))
+	route("PATCH "+apiVersion+"/credits/{id}", auth.RoleUnderwriter, conditional(creditH.Patch))
+	route("DELETE "+apiVersion+"/credits/{id}", auth.RoleAdmin, conditional(creditH.Delete))
+	route("POST "+apiVersion+"/credits/{id}/reenable", auth.RoleAdmin, creditH.Reenable)
+	route("GET "+apiVersion+"/credits/{id}/history", auth.RoleAnalyst, auditH.History(domain.AuditEntityCredit))
+
+	// Register the bulk credit import endpoints
+	route("POST "+apiVersion+"/credits/bulk", auth.RoleUnderwriter, creditImportH.Import)
+	route("GET "+apiVersion+"/credits/bulk/{id}/report", auth.RoleAnalyst, creditImportH.GetByID)
+
+	// Register the export endpoints
+	route("GET "+apiVersion+"/exports/credits", auth.RoleAnalyst, exportH.Credits)
+
+	// Register the webhook endpoints
+	route("POST "+apiVersion+"/webhooks", auth.RoleAdmin, webhookH.Create)
+	route("GET "+apiVersion+"/webhooks", auth.RoleAnalyst, webhookH.List)
+	route("GET "+apiVersion+"/webhooks/{id}", auth.RoleAnalyst, webhookH.GetByID)
+	route("PUT "+apiVersion+"/webhooks/{id}", auth.RoleAdmin, webhookH.Update)
+	route("DELETE "+apiVersion+"/webhooks/{id}", auth.RoleAdmin, webhookH.Delete)
+	route("GET "+apiVersion+"/webhooks/{id}/deliveries", auth.RoleAnalyst, webhookH.ListDeliveries)
+	route("GET "+apiVersion+"/webhooks/{id}/deliveries/{deliveryID}", auth.RoleAnalyst, webhookH.GetDelivery)
+	route("POST "+apiVersion+"/webhooks/{id}/deliveries/{deliveryID}/redeliver", auth.RoleAdmin, webhookH.Redeliver)
+
+	// Send queued webhook deliveries until shutdown
+	go webhookSvc.Run(bgCtx)
 
 	// Create the middleware
+	// Logging sits outside everything that can reject a request, so 403s and 429s are logged too
 	var handler http.Handler = mux
-	handler = middleware.Logging(cfg.Log)(handler)
-	handler = middleware.RateLimit(c, 100, 60)(handler)
+	handler = middleware.Audit(handler)
+	handler = middleware.RateLimit(limiter, rateLimits)(handler)
+	handler = middleware.Metrics(mux)(handler)
+	handler = middleware.LogContext(handler)
+	handler = middleware.Tracing(mux)(handler)
+	handler = middleware.Authenticate(authenticator)(handler)
+	handler = middleware.ClientIP(clientip.NewResolver(trusted, forwardedHeader), allowIPs, denyIPs)(handler)
 	handler = middleware.Recovery(cfg.Log)(handler)
+	handler = middleware.Logging(cfg.Log, mux)(handler)
+	handler = middleware.RequestID(handler)
 
 	// Create the HTTP server
 	httpServer := &http.Server{
 		Addr:         ":" + strconv.Itoa(cfg.HTTPPort),
-		Handler:      metricsMiddleware(handler),
+		Handler:      handler,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
@@ -133,6 +346,9 @@ func New(ctx context.Context, cfg *Config) (*Server, error) {
 	return &Server{
 		httpServer: httpServer,
 		creditSvc:  creditSvc,
+		stopBg:     stopBg,
+		tracer:     tracer,
+		traceFile:  traceFile,
 		log:        cfg.Log,
 	}, nil
 }
@@ -142,19 +358,34 @@ func (s *Server) ListenAndServe() error {
 	return s.httpServer.ListenAndServe()
 }
 
-// Gracefully stops the server
+// Gracefully stops the server, then exports the remaining spans
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.creditSvc.Shutdown()
-	return s.httpServer.Shutdown(ctx)
+	s.stopBg()
+	err := s.httpServer.Shutdown(ctx)
+	if errTrace := s.tracer.Shutdown(ctx); errTrace != nil {
+		s.log.Warn("span export on shutdown failed", zap.Error(errTrace))
+	}
+	if s.traceFile != nil {
+		_ = s.traceFile.Close()
+	}
+	return err
 }
 
-// Records request count and duration per path
-func metricsMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		path := r.Method + " " + r.URL.Path
-		metrics.IncHTTPRequest(path)
-		next.ServeHTTP(w, r)
-		metrics.ObserveHTTPDuration(path, time.Since(start))
-	})
+// Builds the rate-limit policies from configuration
+func newRateLimitPolicies(cfg RateLimitConfig) (ratelimit.Policies, error) {
+	window := time.Duration(cfg.WindowSeconds) * time.Second
+	if window <= 0 {
+		window = time.Minute
+	}
+	routes, err := ratelimit.ParseRoutes(cfg.Routes, window)
+	if err != nil {
+		return ratelimit.Policies{}, err
+	}
+	return ratelimit.Policies{
+		Default:    ratelimit.Policy{Limit: cfg.MaxRequests, Window: window},
+		Tenants:    cfg.TenantLimits,
+		Principals: cfg.PrincipalLimits,
+		Routes:     routes,
+	}, nil
 }
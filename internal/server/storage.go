@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tucredito/backend-api/internal/repository"
+	"github.com/tucredito/backend-api/internal/repository/memory"
+	"github.com/tucredito/backend-api/internal/repository/postgres"
+	"github.com/tucredito/backend-api/migrations"
+	"go.uber.org/zap"
+)
+
+// Storage backends selectable with Config.Storage
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+)
+
+// The repositories of one storage backend
+type repositories struct {
+	clients      repository.ClientRepository
+	banks        repository.BankRepository
+	credits      repository.CreditRepository
+	creditImport repository.CreditImportRepository
+	creditExport repository.CreditExportRepository
+	audit        repository.AuditRepository
+	webhooks     repository.WebhookRepository
+	tx           repository.TxManager
+	pool         *pgxpool.Pool      // nil unless the storage is Postgres
+	migrator     *postgres.Migrator // nil unless the storage is Postgres
+}
+
+/*
+	Creates the repositories of cfg.Storage
+	Memory storage needs no database and starts empty; its data is lost on restart, so it is meant for local
+	development and demos only
+	With Postgres and cfg.AutoMigrate the embedded migrations are applied before anything else touches the
+	database; the migrator's advisory lock lets replicas start together
+*/
+
+func newRepositories(ctx context.Context, cfg *Config) (*repositories, error) {
+	switch cfg.Storage {
+	case StorageMemory:
+		store := memory.NewStore()
+		return &repositories{
+			clients:      memory.NewClientRepository(store),
+			banks:        memory.NewBankRepository(store),
+			credits:      memory.NewCreditRepository(store),
+			creditImport: memory.NewCreditImportRepository(store),
+			creditExport: memory.NewCreditExportRepository(store),
+			audit:        memory.NewAuditRepository(store),
+			webhooks:     memory.NewWebhookRepository(store),
+			tx:           memory.NewTxManager(store),
+		}, nil
+	case "", StoragePostgres:
+		pool, err := postgres.NewPool(ctx, cfg.DBConnString)
+		if err != nil {
+			return nil, err
+		}
+		migrator, err := postgres.NewMigrator(pool, migrations.FS)
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+		if cfg.AutoMigrate {
+			applied, err := migrator.Up(ctx)
+			if err != nil {
+				pool.Close()
+				return nil, fmt.Errorf("auto-migrate: %w", err)
+			}
+			cfg.Log.Info("schema migrated", zap.Int64s("applied", applied), zap.Int64("version", migrator.Latest()))
+		}
+		return &repositories{
+			clients:      postgres.NewClientRepository(pool),
+			banks:        postgres.NewBankRepository(pool),
+			credits:      postgres.NewCreditRepository(pool),
+			creditImport: postgres.NewCreditImportRepository(pool),
+			creditExport: postgres.NewCreditExportRepository(pool),
+			audit:        postgres.NewAuditRepository(pool),
+			webhooks:     postgres.NewWebhookRepository(pool),
+			tx:           postgres.NewTxManager(pool),
+			pool:         pool,
+			migrator:     migrator,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown storage %q (want %s or %s)", cfg.Storage, StoragePostgres, StorageMemory)
+	}
+}
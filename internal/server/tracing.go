@@ -0,0 +1,59 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/tucredito/backend-api/internal/tracing"
+	"go.uber.org/zap"
+)
+
+// Span export; see README "Tracing"
+type TracingConfig struct {
+	Exporter    string  // "none" (default), "otlp" or "file"
+	Endpoint    string  // OTLP/HTTP traces endpoint for "otlp"
+	File        string  // path appended to for "file"
+	SampleRatio float64 // share of new traces sampled; incoming traceparent flags are honoured
+	ServiceName string
+}
+
+/*
+	Builds the tracer from cfg; the returned closer releases the trace file (nil otherwise)
+	With no exporter the tracer is a no-op, but incoming traceparent headers are still forwarded to events
+*/
+
+func newTracer(cfg TracingConfig, log *zap.Logger) (*tracing.Tracer, io.Closer, error) {
+	var exporter tracing.Exporter
+	var closer io.Closer
+	switch cfg.Exporter {
+	case "", "none":
+	case "otlp":
+		exporter = tracing.NewHTTPExporter(cfg.Endpoint, cfg.ServiceName, &http.Client{Timeout: 10 * time.Second})
+	case "file":
+		f, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("tracing file: %w", err)
+		}
+		exporter, closer = tracing.NewWriterExporter(f, cfg.ServiceName), f
+	default:
+		return nil, nil, fmt.Errorf("tracing exporter %q: want none, otlp or file", cfg.Exporter)
+	}
+
+	var lastWarn atomic.Int64 // unix seconds; warn at most once a minute while the collector is down
+	tracer := tracing.NewTracer(tracing.Config{
+		ServiceName: cfg.ServiceName,
+		Sampler:     tracing.ParentBased(tracing.TraceIDRatio(cfg.SampleRatio)),
+		Exporter:    exporter,
+		OnError: func(err error) {
+			if now := time.Now().Unix(); now-lastWarn.Load() >= 60 {
+				lastWarn.Store(now)
+				log.Warn("span export failed", zap.Error(err))
+			}
+		},
+	})
+	return tracer, closer, nil
+}
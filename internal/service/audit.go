@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/repository"
+)
+
+type auditService struct {
+	repository repository.AuditRepository
+}
+
+func NewAuditService(repository repository.AuditRepository) AuditService {
+	return &auditService{
+		repository: repository,
+	}
+}
+
+// Lists the change history of an entity, oldest first; never returns a nil slice
+func (s *auditService) History(ctx context.Context, entity domain.AuditEntity, entityID string, limit, offset int) ([]*domain.AuditEntry, error) {
+	switch entity {
+	case domain.AuditEntityClient, domain.AuditEntityBank, domain.AuditEntityCredit:
+	default:
+		return nil, ErrInvalidInput
+	}
+	if entityID == "" || offset < 0 {
+		return nil, ErrInvalidInput
+	}
+
+	entries, err := s.repository.ListByEntity(ctx, entity, entityID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []*domain.AuditEntry{}
+	}
+	return entries, nil
+}
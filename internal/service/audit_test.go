@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/domain"
+	repomocks "github.com/tucredito/backend-api/internal/repository/mocks"
+)
+
+func TestAuditService_History(t *testing.T) {
+	repo := &repomocks.AuditRepository{}
+	repo.ListByEntityFunc = func(ctx context.Context, entity domain.AuditEntity, entityID string, limit, offset int) ([]*domain.AuditEntry, error) {
+		assert.Equal(t, domain.AuditEntityCredit, entity)
+		assert.Equal(t, "cr1", entityID)
+		assert.Equal(t, 10, limit)
+		return []*domain.AuditEntry{{ID: 1, Entity: entity, EntityID: entityID, Action: domain.AuditActionCreate}}, nil
+	}
+	svc := NewAuditService(repo)
+
+	entries, err := svc.History(context.Background(), domain.AuditEntityCredit, "cr1", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, domain.AuditActionCreate, entries[0].Action)
+}
+
+func TestAuditService_History_Empty(t *testing.T) {
+	svc := NewAuditService(&repomocks.AuditRepository{})
+
+	entries, err := svc.History(context.Background(), domain.AuditEntityBank, "b1", 20, 0)
+	require.NoError(t, err)
+	assert.NotNil(t, entries)
+	assert.Empty(t, entries)
+}
+
+func TestAuditService_History_InvalidEntity(t *testing.T) {
+	svc := NewAuditService(&repomocks.AuditRepository{})
+
+	_, err := svc.History(context.Background(), domain.AuditEntity("LOAN"), "x", 20, 0)
+	assert.Equal(t, ErrInvalidInput, err)
+}
@@ -3,43 +3,79 @@ package service
 import (
 	"context"
 
+	"github.com/tucredito/backend-api/internal/cache"
 	"github.com/tucredito/backend-api/internal/domain"
 	"github.com/tucredito/backend-api/internal/repository"
 )
 
 type bankService struct {
 	repository repository.BankRepository
+	cache      *entityCache[domain.Bank]
 }
 
-func NewBankService(repository repository.BankRepository) BankService {
+// c may be nil to disable the read-through cache
+func NewBankService(repository repository.BankRepository, c cache.Cache) BankService {
 	return &bankService{
 		repository: repository,
+		cache:      newEntityCache[domain.Bank](c, "bank"),
 	}
 }
 
 // Creates a new bank
 func (s *bankService) Create(ctx context.Context, input domain.CreateBankInput) (*domain.Bank, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
 	return s.repository.Create(ctx, input)
 }
 
-// Gets a bank by ID
+// Gets a bank by ID (read-through cache)
 func (s *bankService) GetByID(ctx context.Context, id string) (*domain.Bank, error) {
-	return s.repository.GetByID(ctx, id)
+	return s.cache.get(ctx, id, s.repository.GetByID)
 }
 
 // Updates a bank
 func (s *bankService) Update(ctx context.Context, id string, input domain.UpdateBankInput) (*domain.Bank, error) {
-	return s.repository.Update(ctx, id, input)
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	b, err := s.repository.Update(ctx, id, input)
+	if err == nil {
+		s.cache.invalidate(ctx, id)
+	}
+	return b, err
+}
+
+// Applies a merge patch; the merged result is validated like an Update (see applyPatch)
+func (s *bankService) Patch(ctx context.Context, id string, patch domain.BankPatch) (*domain.Bank, error) {
+	b, err := applyPatch(ctx, patch.IsEmpty(),
+		func(ctx context.Context) (*domain.Bank, error) { return s.repository.GetForUpdate(ctx, id) },
+		func(b *domain.Bank) int { return b.Version },
+		func(current *domain.Bank) error { return patch.Apply(current).Validate() },
+		func(ctx context.Context) (*domain.Bank, error) { return s.repository.Patch(ctx, id, patch) },
+	)
+	if err == nil && !patch.IsEmpty() {
+		s.cache.invalidate(ctx, id)
+	}
+	return b, err
 }
 
 // Soft-deletes a bank
 func (s *bankService) Delete(ctx context.Context, id string) (*domain.Bank, error) {
-	return s.repository.SetInactive(ctx, id)
+	b, err := s.repository.SetInactive(ctx, id)
+	if err == nil {
+		s.cache.invalidate(ctx, id)
+	}
+	return b, err
 }
 
 // Re-enables a bank
 func (s *bankService) Reenable(ctx context.Context, id string) (*domain.Bank, error) {
-	return s.repository.SetActive(ctx, id)
+	b, err := s.repository.SetActive(ctx, id)
+	if err == nil {
+		s.cache.invalidate(ctx, id)
+	}
+	return b, err
 }
 
 // Lists banks with pagination
@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/cache"
 	"github.com/tucredito/backend-api/internal/domain"
 	repomocks "github.com/tucredito/backend-api/internal/repository/mocks"
 )
@@ -19,7 +20,7 @@ func TestBankService_Create(t *testing.T) {
 		out.Type = input.Type
 		return &out, nil
 	}
-	svc := NewBankService(repo)
+	svc := NewBankService(repo, nil)
 
 	got, err := svc.Create(context.Background(), domain.CreateBankInput{
 		Name: "Test Bank", Type: domain.BankTypePrivate,
@@ -40,7 +41,7 @@ func TestBankService_GetByID_Found(t *testing.T) {
 		}
 		return nil, nil
 	}
-	svc := NewBankService(repo)
+	svc := NewBankService(repo, nil)
 
 	got, err := svc.GetByID(context.Background(), "b1")
 	require.NoError(t, err)
@@ -54,7 +55,7 @@ func TestBankService_GetByID_NotFound(t *testing.T) {
 	repo.GetByIDFunc = func(ctx context.Context, id string) (*domain.Bank, error) {
 		return nil, nil
 	}
-	svc := NewBankService(repo)
+	svc := NewBankService(repo, nil)
 
 	got, err := svc.GetByID(context.Background(), "none")
 	require.NoError(t, err)
@@ -70,7 +71,7 @@ func TestBankService_Update(t *testing.T) {
 		out.Type = input.Type
 		return &out, nil
 	}
-	svc := NewBankService(repo)
+	svc := NewBankService(repo, nil)
 
 	got, err := svc.Update(context.Background(), "b1", domain.UpdateBankInput{
 		Name: "Bank Updated", Type: domain.BankTypePrivate,
@@ -85,7 +86,7 @@ func TestBankService_Update_NotFound(t *testing.T) {
 	repo.UpdateFunc = func(ctx context.Context, id string, input domain.UpdateBankInput) (*domain.Bank, error) {
 		return nil, nil
 	}
-	svc := NewBankService(repo)
+	svc := NewBankService(repo, nil)
 
 	got, err := svc.Update(context.Background(), "none", domain.UpdateBankInput{
 		Name: "X", Type: domain.BankTypePrivate,
@@ -103,7 +104,7 @@ func TestBankService_Delete(t *testing.T) {
 		}
 		return nil, nil
 	}
-	svc := NewBankService(repo)
+	svc := NewBankService(repo, nil)
 
 	got, err := svc.Delete(context.Background(), "b1")
 	require.NoError(t, err)
@@ -120,7 +121,7 @@ func TestBankService_Reenable(t *testing.T) {
 		}
 		return nil, nil
 	}
-	svc := NewBankService(repo)
+	svc := NewBankService(repo, nil)
 
 	got, err := svc.Reenable(context.Background(), "b1")
 	require.NoError(t, err)
@@ -133,7 +134,7 @@ func TestBankService_Reenable_NotFound(t *testing.T) {
 	repo.SetActiveFunc = func(ctx context.Context, id string) (*domain.Bank, error) {
 		return nil, nil
 	}
-	svc := NewBankService(repo)
+	svc := NewBankService(repo, nil)
 
 	got, err := svc.Reenable(context.Background(), "none")
 	require.NoError(t, err)
@@ -148,10 +149,44 @@ func TestBankService_List(t *testing.T) {
 	repo.ListFunc = func(ctx context.Context, limit, offset int) ([]*domain.Bank, error) {
 		return list, nil
 	}
-	svc := NewBankService(repo)
+	svc := NewBankService(repo, nil)
 
 	got, err := svc.List(context.Background(), 10, 0)
 	require.NoError(t, err)
 	require.Len(t, got, 1)
 	assert.Equal(t, "b1", got[0].ID)
 }
+
+func TestBankService_GetByID_ReadThroughCache(t *testing.T) {
+	calls := 0
+	active := true
+	repo := &repomocks.BankRepository{}
+	repo.GetByIDFunc = func(ctx context.Context, id string) (*domain.Bank, error) {
+		calls++
+		if !active {
+			return nil, nil
+		}
+		return &domain.Bank{ID: id, Name: "Bank One", IsActive: true}, nil
+	}
+	repo.SetInactiveFunc = func(ctx context.Context, id string) (*domain.Bank, error) {
+		active = false
+		return &domain.Bank{ID: id}, nil
+	}
+	svc := NewBankService(repo, cache.NewMemoryCache(0))
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		got, err := svc.GetByID(ctx, "b1")
+		require.NoError(t, err)
+		require.NotNil(t, got)
+	}
+	assert.Equal(t, 1, calls)
+
+	// A deactivated bank is not served from the cache
+	_, err := svc.Delete(ctx, "b1")
+	require.NoError(t, err)
+	got, err := svc.GetByID(ctx, "b1")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+	assert.Equal(t, 2, calls)
+}
@@ -3,43 +3,79 @@ package service
 import (
 	"context"
 
+	"github.com/tucredito/backend-api/internal/cache"
 	"github.com/tucredito/backend-api/internal/domain"
 	"github.com/tucredito/backend-api/internal/repository"
 )
 
 type clientService struct {
 	repository repository.ClientRepository
+	cache      *entityCache[domain.Client]
 }
 
-func NewClientService(repository repository.ClientRepository) ClientService {
+// c may be nil to disable the read-through cache
+func NewClientService(repository repository.ClientRepository, c cache.Cache) ClientService {
 	return &clientService{
 		repository: repository,
+		cache:      newEntityCache[domain.Client](c, "client"),
 	}
 }
 
 // Creates a new client
 func (s *clientService) Create(ctx context.Context, input domain.CreateClientInput) (*domain.Client, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
 	return s.repository.Create(ctx, input)
 }
 
-// Gets a client by ID
+// Gets a client by ID (read-through cache)
 func (s *clientService) GetByID(ctx context.Context, id string) (*domain.Client, error) {
-	return s.repository.GetByID(ctx, id)
+	return s.cache.get(ctx, id, s.repository.GetByID)
 }
 
 // Updates a client
 func (s *clientService) Update(ctx context.Context, id string, input domain.UpdateClientInput) (*domain.Client, error) {
-	return s.repository.Update(ctx, id, input)
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	c, err := s.repository.Update(ctx, id, input)
+	if err == nil {
+		s.cache.invalidate(ctx, id)
+	}
+	return c, err
+}
+
+// Applies a merge patch; the merged result is validated like an Update (see applyPatch)
+func (s *clientService) Patch(ctx context.Context, id string, patch domain.ClientPatch) (*domain.Client, error) {
+	c, err := applyPatch(ctx, patch.IsEmpty(),
+		func(ctx context.Context) (*domain.Client, error) { return s.repository.GetForUpdate(ctx, id) },
+		func(c *domain.Client) int { return c.Version },
+		func(current *domain.Client) error { return patch.Apply(current).Validate() },
+		func(ctx context.Context) (*domain.Client, error) { return s.repository.Patch(ctx, id, patch) },
+	)
+	if err == nil && !patch.IsEmpty() {
+		s.cache.invalidate(ctx, id)
+	}
+	return c, err
 }
 
 // Soft-deletes a client
 func (s *clientService) Delete(ctx context.Context, id string) (*domain.Client, error) {
-	return s.repository.SetInactive(ctx, id)
+	c, err := s.repository.SetInactive(ctx, id)
+	if err == nil {
+		s.cache.invalidate(ctx, id)
+	}
+	return c, err
 }
 
 // Re-enables a client
 func (s *clientService) Reenable(ctx context.Context, id string) (*domain.Client, error) {
-	return s.repository.SetActive(ctx, id)
+	c, err := s.repository.SetActive(ctx, id)
+	if err == nil {
+		s.cache.invalidate(ctx, id)
+	}
+	return c, err
 }
 
 // Lists clients with pagination
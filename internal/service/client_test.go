@@ -7,8 +7,12 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/cache"
 	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/repository"
 	repomocks "github.com/tucredito/backend-api/internal/repository/mocks"
+	"github.com/tucredito/backend-api/internal/tenant"
+	"github.com/tucredito/backend-api/pkg/apperr"
 )
 
 func TestClientService_Create(t *testing.T) {
@@ -24,10 +28,10 @@ func TestClientService_Create(t *testing.T) {
 		out.Country = input.Country
 		return &out, nil
 	}
-	svc := NewClientService(repo)
+	svc := NewClientService(repo, nil)
 
 	got, err := svc.Create(context.Background(), domain.CreateClientInput{
-		FullName: "Jane Doe", Email: "jane@example.com", Country: "US",
+		FullName: "Jane Doe", Email: "jane@example.com", BirthDate: time.Date(1990, 5, 15, 0, 0, 0, 0, time.UTC), Country: "US",
 	})
 	require.NoError(t, err)
 	require.NotNil(t, got)
@@ -37,7 +41,7 @@ func TestClientService_Create(t *testing.T) {
 }
 
 func TestClientService_GetByID_Found(t *testing.T) {
-	client := &domain.Client{ID: "c1", FullName: "Jane", Email: "j@x.com", Country: "US", IsActive: true}
+	client := &domain.Client{ID: "c1", FullName: "Jane", Email: "j@x.com", BirthDate: time.Date(1990, 5, 15, 0, 0, 0, 0, time.UTC), Country: "US", IsActive: true}
 	repo := &repomocks.ClientRepository{}
 	repo.GetByIDFunc = func(ctx context.Context, id string) (*domain.Client, error) {
 		if id == "c1" {
@@ -45,7 +49,7 @@ func TestClientService_GetByID_Found(t *testing.T) {
 		}
 		return nil, nil
 	}
-	svc := NewClientService(repo)
+	svc := NewClientService(repo, nil)
 
 	got, err := svc.GetByID(context.Background(), "c1")
 	require.NoError(t, err)
@@ -58,7 +62,7 @@ func TestClientService_GetByID_NotFound(t *testing.T) {
 	repo.GetByIDFunc = func(ctx context.Context, id string) (*domain.Client, error) {
 		return nil, nil
 	}
-	svc := NewClientService(repo)
+	svc := NewClientService(repo, nil)
 
 	got, err := svc.GetByID(context.Background(), "none")
 	require.NoError(t, err)
@@ -66,7 +70,7 @@ func TestClientService_GetByID_NotFound(t *testing.T) {
 }
 
 func TestClientService_Update(t *testing.T) {
-	updated := &domain.Client{ID: "c1", FullName: "Jane Updated", Email: "j2@x.com", Country: "US", IsActive: true}
+	updated := &domain.Client{ID: "c1", FullName: "Jane Updated", Email: "j2@x.com", BirthDate: time.Date(1990, 5, 15, 0, 0, 0, 0, time.UTC), Country: "US", IsActive: true}
 	repo := &repomocks.ClientRepository{}
 	repo.UpdateFunc = func(ctx context.Context, id string, input domain.UpdateClientInput) (*domain.Client, error) {
 		out := *updated
@@ -75,10 +79,10 @@ func TestClientService_Update(t *testing.T) {
 		out.Country = input.Country
 		return &out, nil
 	}
-	svc := NewClientService(repo)
+	svc := NewClientService(repo, nil)
 
 	got, err := svc.Update(context.Background(), "c1", domain.UpdateClientInput{
-		FullName: "Jane Updated", Email: "j2@x.com", Country: "US",
+		FullName: "Jane Updated", Email: "j2@x.com", BirthDate: time.Date(1990, 5, 15, 0, 0, 0, 0, time.UTC), Country: "US",
 	})
 	require.NoError(t, err)
 	require.NotNil(t, got)
@@ -90,15 +94,74 @@ func TestClientService_Update_NotFound(t *testing.T) {
 	repo.UpdateFunc = func(ctx context.Context, id string, input domain.UpdateClientInput) (*domain.Client, error) {
 		return nil, nil
 	}
-	svc := NewClientService(repo)
+	svc := NewClientService(repo, nil)
 
 	got, err := svc.Update(context.Background(), "none", domain.UpdateClientInput{
-		FullName: "X", Email: "x@x.com", Country: "US",
+		FullName: "X", Email: "x@x.com", BirthDate: time.Date(1990, 5, 15, 0, 0, 0, 0, time.UTC), Country: "US",
 	})
 	require.NoError(t, err)
 	require.Nil(t, got)
 }
 
+func TestClientService_Patch_ValidatesMergedResult(t *testing.T) {
+	current := &domain.Client{ID: "c1", FullName: "Jane", Email: "j@x.com", BirthDate: time.Date(1990, 5, 15, 0, 0, 0, 0, time.UTC), Country: "US", IsActive: true, Version: 2}
+	repo := &repomocks.ClientRepository{}
+	repo.GetForUpdateFunc = func(ctx context.Context, id string) (*domain.Client, error) { return current, nil }
+	var pinned []int
+	repo.PatchFunc = func(ctx context.Context, id string, patch domain.ClientPatch) (*domain.Client, error) {
+		pinned, _ = repository.ExpectedVersions(ctx)
+		out := *current
+		out.Country = *patch.Country
+		out.Version++
+		return &out, nil
+	}
+	svc := NewClientService(repo, nil)
+
+	invalid := "usa"
+	_, err := svc.Patch(context.Background(), "c1", domain.ClientPatch{Country: &invalid})
+	e, ok := apperr.As(err)
+	require.True(t, ok)
+	assert.Equal(t, apperr.KindValidation, e.Kind)
+	assert.Nil(t, pinned, "an invalid patch must not be written")
+
+	country := "MX"
+	got, err := svc.Patch(context.Background(), "c1", domain.ClientPatch{Country: &country})
+	require.NoError(t, err)
+	assert.Equal(t, "MX", got.Country)
+	assert.Equal(t, "Jane", got.FullName)
+	assert.Equal(t, []int{2}, pinned, "the write is pinned to the validated version")
+}
+
+func TestClientService_Patch_RetriesVersionRace(t *testing.T) {
+	current := &domain.Client{ID: "c1", FullName: "Jane", Email: "j@x.com", BirthDate: time.Date(1990, 5, 15, 0, 0, 0, 0, time.UTC), Country: "US", IsActive: true, Version: 1}
+	repo := &repomocks.ClientRepository{}
+	loads := 0
+	repo.GetForUpdateFunc = func(ctx context.Context, id string) (*domain.Client, error) {
+		loads++
+		out := *current
+		out.Version = loads
+		return &out, nil
+	}
+	repo.PatchFunc = func(ctx context.Context, id string, patch domain.ClientPatch) (*domain.Client, error) {
+		if loads == 1 {
+			return nil, repository.ErrVersionMismatch // another writer got in between
+		}
+		return current, nil
+	}
+	svc := NewClientService(repo, nil)
+	name := "Janet"
+
+	_, err := svc.Patch(context.Background(), "c1", domain.ClientPatch{FullName: &name})
+	require.NoError(t, err)
+	assert.Equal(t, 2, loads)
+
+	// With If-Match the caller owns the version and gets the mismatch
+	loads = 0
+	_, err = svc.Patch(repository.WithExpectedVersion(context.Background(), 1), "c1", domain.ClientPatch{FullName: &name})
+	assert.ErrorIs(t, err, repository.ErrVersionMismatch)
+	assert.Equal(t, 1, loads)
+}
+
 func TestClientService_Delete(t *testing.T) {
 	softDeleted := &domain.Client{ID: "c1", FullName: "Jane", IsActive: false}
 	repo := &repomocks.ClientRepository{}
@@ -108,7 +171,7 @@ func TestClientService_Delete(t *testing.T) {
 		}
 		return nil, nil
 	}
-	svc := NewClientService(repo)
+	svc := NewClientService(repo, nil)
 
 	got, err := svc.Delete(context.Background(), "c1")
 	require.NoError(t, err)
@@ -125,7 +188,7 @@ func TestClientService_Reenable(t *testing.T) {
 		}
 		return nil, nil
 	}
-	svc := NewClientService(repo)
+	svc := NewClientService(repo, nil)
 
 	got, err := svc.Reenable(context.Background(), "c1")
 	require.NoError(t, err)
@@ -138,7 +201,7 @@ func TestClientService_Reenable_NotFound(t *testing.T) {
 	repo.SetActiveFunc = func(ctx context.Context, id string) (*domain.Client, error) {
 		return nil, nil
 	}
-	svc := NewClientService(repo)
+	svc := NewClientService(repo, nil)
 
 	got, err := svc.Reenable(context.Background(), "none")
 	require.NoError(t, err)
@@ -147,16 +210,54 @@ func TestClientService_Reenable_NotFound(t *testing.T) {
 
 func TestClientService_List(t *testing.T) {
 	list := []*domain.Client{
-		{ID: "c1", FullName: "A", Email: "a@b.com", Country: "US", IsActive: true},
+		{ID: "c1", FullName: "A", Email: "a@b.com", BirthDate: time.Date(1990, 5, 15, 0, 0, 0, 0, time.UTC), Country: "US", IsActive: true},
 	}
 	repo := &repomocks.ClientRepository{}
 	repo.ListFunc = func(ctx context.Context, limit, offset int) ([]*domain.Client, error) {
 		return list, nil
 	}
-	svc := NewClientService(repo)
+	svc := NewClientService(repo, nil)
 
 	got, err := svc.List(context.Background(), 10, 0)
 	require.NoError(t, err)
 	require.Len(t, got, 1)
 	assert.Equal(t, "c1", got[0].ID)
 }
+
+func TestClientService_GetByID_CacheIsTenantScoped(t *testing.T) {
+	calls := map[string]int{}
+	repo := &repomocks.ClientRepository{}
+	repo.GetByIDFunc = func(ctx context.Context, id string) (*domain.Client, error) {
+		label := tenant.Label(ctx)
+		calls[label]++
+		if label == "bank-b" {
+			return nil, nil // not owned by bank-b and no credit with it
+		}
+		return &domain.Client{ID: id, FullName: "A"}, nil
+	}
+	repo.UpdateFunc = func(ctx context.Context, id string, input domain.UpdateClientInput) (*domain.Client, error) {
+		return &domain.Client{ID: id, FullName: input.FullName}, nil
+	}
+	svc := NewClientService(repo, cache.NewMemoryCache(0))
+	bankA := tenant.WithBank(context.Background(), "bank-a")
+	bankB := tenant.WithBank(context.Background(), "bank-b")
+
+	for i := 0; i < 2; i++ {
+		got, err := svc.GetByID(bankA, "c1")
+		require.NoError(t, err)
+		require.NotNil(t, got)
+	}
+	assert.Equal(t, 1, calls["bank-a"])
+
+	// Another tenant is not served bank-a's cached copy
+	got, err := svc.GetByID(bankB, "c1")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+	assert.Equal(t, 1, calls["bank-b"])
+
+	// Updates invalidate the entry
+	_, err = svc.Update(bankA, "c1", domain.UpdateClientInput{FullName: "B", Email: "b@example.com", BirthDate: time.Date(1990, 5, 15, 0, 0, 0, 0, time.UTC), Country: "MX"})
+	require.NoError(t, err)
+	_, _ = svc.GetByID(bankA, "c1")
+	assert.Equal(t, 2, calls["bank-a"])
+}
@@ -6,45 +6,60 @@ import (
 	"sync"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/tucredito/backend-api/internal/cache"
 	"github.com/tucredito/backend-api/internal/decision"
 	"github.com/tucredito/backend-api/internal/domain"
 	"github.com/tucredito/backend-api/internal/event"
 	"github.com/tucredito/backend-api/internal/metrics"
 	"github.com/tucredito/backend-api/internal/repository"
+	"github.com/tucredito/backend-api/internal/tracing"
+	"github.com/tucredito/backend-api/pkg/apperr"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 )
 
 var (
-	ErrClientNotFound = errors.New("client not found")
-	ErrBankNotFound   = errors.New("bank not found")
-	ErrInvalidInput   = errors.New("invalid input")
+	ErrClientNotFound = apperr.NotFound("client not found")
+	ErrBankNotFound   = apperr.NotFound("bank not found")
+	ErrInvalidInput   = apperr.Validation("invalid input")
+	ErrCreditBusy     = &apperr.Error{Kind: apperr.KindConflict, Message: "credit is being updated, retry later", RetryAfter: time.Second}
+	ErrWorkersStopped = apperr.Unavailable("credit worker pool is shut down", nil)
 )
 
 const (
 	cacheTTLSeconds      = 300
 	creditCacheKeyPrefix = "credit:"
 	workerPoolSize       = 10
+
+	creditStatusLockPrefix = "lock:credit-status:"
+	statusLockTTL          = 10 * time.Second
+	statusLockWait         = 3 * time.Second
 )
 
 type creditService struct {
 	creditRepo repository.CreditRepository
-	clientRepo repository.ClientRepository
-	bankRepo   repository.BankRepository
+	tx         repository.TxManager
+	clientRepo ClientLookup
+	bankRepo   BankLookup
 	cache      cache.Cache
 	publisher  event.Publisher
+	outbox     event.Outbox
 	engine     decision.Engine
 	log        *zap.Logger
 	jobCh      chan creditJob
 	done       chan struct{}
 	wg         sync.WaitGroup
+	flights    singleflight.Group // coalesces concurrent cache misses, see credit_cache.go
+	// statusLockTTL, shortened by tests
+	statusLockTTL time.Duration
 }
 
 type creditJob struct {
-	ctx    context.Context
-	input  domain.CreateCreditInput
-	result chan creditResult
+	probe    bool // liveness probe from PingWorkers; answered without processing
+	ctx      context.Context
+	input    domain.CreateCreditInput
+	result   chan creditResult
+	enqueued time.Time
 }
 
 type creditResult struct {
@@ -52,25 +67,38 @@ type creditResult struct {
 	err    error
 }
 
+// tx may be nil when the repositories are not transactional; units of work then run directly
 func NewCreditService(
 	creditRepo repository.CreditRepository,
-	clientRepo repository.ClientRepository,
-	bankRepo repository.BankRepository,
+	tx repository.TxManager,
+	clientRepo ClientLookup,
+	bankRepo BankLookup,
 	cache cache.Cache,
 	publisher event.Publisher,
+	outbox event.Outbox,
 	engine decision.Engine,
 	log *zap.Logger,
 ) CreditService {
+	if tx == nil {
+		tx = directTx{}
+	}
+	if outbox == nil {
+		outbox = discardOutbox{}
+	}
 	s := &creditService{
 		creditRepo: creditRepo,
+		tx:         tx,
 		clientRepo: clientRepo,
 		bankRepo:   bankRepo,
 		cache:      cache,
 		publisher:  publisher,
+		outbox:     outbox,
 		engine:     engine,
 		log:        log,
 		jobCh:      make(chan creditJob, 100),
 		done:       make(chan struct{}),
+
+		statusLockTTL: statusLockTTL,
 	}
 	for i := 0; i < workerPoolSize; i++ {
 		s.wg.Add(1)
@@ -87,7 +115,20 @@ func (s *creditService) worker(_ int) {
 		case <-s.done:
 			return
 		case job := <-s.jobCh:
-			credit, err := s.createCredit(job.ctx, job.input)
+			if job.probe {
+				job.result <- creditResult{}
+				continue
+			}
+			start := time.Now()
+			metrics.ObserveCreditJobStarted(start.Sub(job.enqueued))
+			// job.ctx carries the caller's span, so the worker's spans join the request's trace
+			_, wait := tracing.Start(job.ctx, "credit.queue_wait", tracing.WithStartTime(job.enqueued))
+			wait.End()
+			ctx, span := tracing.Start(job.ctx, "credit.process")
+			credit, err := s.createCredit(ctx, job.input)
+			span.RecordError(err)
+			span.End()
+			metrics.ObserveCreditJobDone(time.Since(start), err)
 			job.result <- creditResult{credit: credit, err: err}
 		}
 	}
@@ -95,14 +136,18 @@ func (s *creditService) worker(_ int) {
 
 // Creates a credit
 func (s *creditService) Create(ctx context.Context, input domain.CreateCreditInput) (*domain.Credit, error) {
-	if input.ClientID == "" || input.BankID == "" || input.MaxPayment < input.MinPayment || input.TermMonths <= 0 {
-		return nil, ErrInvalidInput
+	if err := input.Validate(); err != nil {
+		return nil, err
 	}
 
+	ctx, span := tracing.Start(ctx, "credit.create")
+	defer span.End()
+
 	resultCh := make(chan creditResult, 1)
 
 	select {
-	case s.jobCh <- creditJob{ctx: ctx, input: input, result: resultCh}:
+	case s.jobCh <- creditJob{ctx: ctx, input: input, result: resultCh, enqueued: time.Now()}:
+		metrics.IncCreditJobQueued()
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	}
@@ -149,107 +194,252 @@ func (s *creditService) createCredit(ctx context.Context, input domain.CreateCre
 		return nil, err
 	}
 
-	credit, err := s.creditRepo.Create(ctx, input)
-	if err != nil {
-		return nil, err
-	}
-
-	metrics.IncCreditsCreated()
-
+	// The credit is inserted in its decided status with its events in the outbox; publishing, metrics and
+	// the cache follow the commit
+	status := domain.CreditStatusPending
 	if result != nil && result.Approved {
-		credit, err = s.creditRepo.UpdateStatus(ctx, credit.ID, domain.CreditStatusApproved)
+		status = domain.CreditStatusApproved
+	}
+	var credit *domain.Credit
+	var events []*domain.DomainEvent
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		credit, err = s.creditRepo.Create(ctx, input, status)
 		if err != nil {
-			s.log.Warn("failed to update credit status to approved", zap.Error(err), zap.String("credit_id", credit.ID))
-		} else {
-			metrics.IncCreditsApproved()
-			_ = s.emitCreditApproved(ctx, credit)
+			return err
 		}
+		created, err := creditCreatedEvent(ctx, credit)
+		if err != nil {
+			return err
+		}
+		events = append(events, created)
+		if credit.Status == domain.CreditStatusApproved {
+			approved, err := statusChangeEvent(ctx, credit, domain.CreditStatusApproved)
+			if err != nil {
+				return err
+			}
+			events = append(events, approved)
+		}
+		return s.stage(ctx, events...)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	_ = s.emitCreditCreated(ctx, credit)
+	metrics.IncCreditsCreated(credit.BankID)
+	if credit.Status == domain.CreditStatusApproved {
+		metrics.IncCreditsApproved(credit.BankID)
+	}
+	s.publish(ctx, events...)
 	s.cacheCredit(ctx, credit)
 
 	return credit, nil
 }
 
-// Caches a credit
-func (s *creditService) cacheCredit(ctx context.Context, c *domain.Credit) {
-	if s.cache == nil {
-		return
-	}
-
-	key := creditCacheKeyPrefix + c.ID
-	if cacheWithJSON, ok := s.cache.(interface {
-		SetJSON(context.Context, string, interface{}, int) error
-	}); ok {
-		_ = cacheWithJSON.SetJSON(ctx, key, c, cacheTTLSeconds)
+// Updates a credit; status changes are serialized per credit (see withStatusLock)
+func (s *creditService) Update(ctx context.Context, id string, input domain.UpdateCreditInput) (*domain.Credit, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
 	}
-}
-
-// Gets a credit by ID
-func (s *creditService) GetByID(ctx context.Context, id string) (*domain.Credit, error) {
-	if s.cache != nil {
-		if cacheWithJSON, ok := s.cache.(interface {
-			GetJSON(context.Context, string, interface{}) error
-		}); ok {
-			var c domain.Credit
-			if err := cacheWithJSON.GetJSON(ctx, creditCacheKeyPrefix+id, &c); err == nil && c.ID != "" {
-				return &c, nil
-			}
+	var credit *domain.Credit
+	err := s.withStatusLock(ctx, id, func(ctx context.Context) error {
+		var evt *domain.DomainEvent
+		var err error
+		credit, evt, err = s.writeStatus(ctx, input.Status, func(ctx context.Context) (*domain.Credit, error) {
+			return s.creditRepo.Update(ctx, id, input)
+		})
+		if err != nil || credit == nil {
+			return err
 		}
+		s.invalidateCredit(ctx, credit)
+		s.recordStatusChange(ctx, credit, input.Status, evt)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	return s.creditRepo.GetByID(ctx, id)
+	return credit, nil
 }
 
-// Updates a credit
-func (s *creditService) Update(ctx context.Context, id string, input domain.UpdateCreditInput) (*domain.Credit, error) {
-	credit, err := s.creditRepo.Update(ctx, id, input)
+// Applies a merge patch under the status lock; the merged result is validated like an Update (see applyPatch)
+func (s *creditService) Patch(ctx context.Context, id string, patch domain.CreditPatch) (*domain.Credit, error) {
+	var status domain.CreditStatus
+	if patch.Status != nil {
+		status = *patch.Status
+	}
+	var credit *domain.Credit
+	err := s.withStatusLock(ctx, id, func(ctx context.Context) error {
+		var evt *domain.DomainEvent
+		var err error
+		credit, err = applyPatch(ctx, patch.IsEmpty(),
+			func(ctx context.Context) (*domain.Credit, error) { return s.creditRepo.GetByID(ctx, id) },
+			func(c *domain.Credit) int { return c.Version },
+			func(current *domain.Credit) error { return patch.Apply(current).Validate() },
+			func(ctx context.Context) (*domain.Credit, error) {
+				credit, staged, err := s.writeStatus(ctx, status, func(ctx context.Context) (*domain.Credit, error) {
+					return s.creditRepo.Patch(ctx, id, patch)
+				})
+				evt = staged
+				return credit, err
+			},
+		)
+		if err != nil || credit == nil || patch.IsEmpty() {
+			return err
+		}
+		s.invalidateCredit(ctx, credit)
+		s.recordStatusChange(ctx, credit, status, evt)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	if credit == nil {
-		return nil, nil
-	}
-	if s.cache != nil {
-		_ = s.cache.Delete(ctx, creditCacheKeyPrefix+id)
-	}
-	switch input.Status {
-	case domain.CreditStatusApproved:
-		metrics.IncCreditsApproved()
-		_ = s.emitCreditApproved(ctx, credit)
-	case domain.CreditStatusRejected:
-		metrics.IncCreditsRejected()
-		_ = s.emitCreditRejected(ctx, credit)
-	}
 	return credit, nil
 }
 
 // Updates a credit status
 func (s *creditService) UpdateStatus(ctx context.Context, id string, status domain.CreditStatus) (*domain.Credit, error) {
-	credit, err := s.creditRepo.UpdateStatus(ctx, id, status)
+	if err := (domain.UpdateCreditStatusInput{Status: status}).Validate(); err != nil {
+		return nil, err
+	}
+	var credit *domain.Credit
+	err := s.withStatusLock(ctx, id, func(ctx context.Context) error {
+		var evt *domain.DomainEvent
+		var err error
+		credit, evt, err = s.writeStatus(ctx, status, func(ctx context.Context) (*domain.Credit, error) {
+			return s.creditRepo.UpdateStatus(ctx, id, status)
+		})
+		if err != nil || credit == nil {
+			return err
+		}
+		s.recordStatusChange(ctx, credit, status, evt)
+		s.invalidateCredit(ctx, credit)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return credit, nil
+}
 
-	if credit == nil {
-		return nil, nil
+/*
+	Re-runs the decision engine for a credit with its current client, bank and the registered rules
+	A pending credit that now passes is approved like any status change (event and cache invalidation);
+	one that still fails stays pending. Approved and rejected credits are only evaluated, keeping their status
+	Returns (nil, nil, nil) when the credit does not exist
+*/
+
+func (s *creditService) Redecide(ctx context.Context, id string) (*domain.Credit, *decision.EligibilityResult, error) {
+	var credit *domain.Credit
+	var result *decision.EligibilityResult
+	err := s.withStatusLock(ctx, id, func(ctx context.Context) error {
+		var err error
+		credit, err = s.creditRepo.GetByID(ctx, id)
+		if err != nil || credit == nil {
+			return err
+		}
+		client, err := s.clientRepo.GetByID(ctx, credit.ClientID)
+		if err != nil {
+			return err
+		}
+		if client == nil {
+			return ErrClientNotFound
+		}
+		bank, err := s.bankRepo.GetByID(ctx, credit.BankID)
+		if err != nil {
+			return err
+		}
+		if bank == nil {
+			return ErrBankNotFound
+		}
+
+		result, err = s.engine.Evaluate(ctx, &decision.EligibilityInput{
+			Client:     client,
+			Bank:       bank,
+			MinPayment: credit.MinPayment,
+			MaxPayment: credit.MaxPayment,
+			TermMonths: credit.TermMonths,
+			CreditType: credit.CreditType,
+		})
+		if err != nil || result == nil || !result.Approved || credit.Status != domain.CreditStatusPending {
+			return err
+		}
+		var evt *domain.DomainEvent
+		credit, evt, err = s.writeStatus(ctx, domain.CreditStatusApproved, func(ctx context.Context) (*domain.Credit, error) {
+			return s.creditRepo.UpdateStatus(ctx, id, domain.CreditStatusApproved)
+		})
+		if err != nil || credit == nil {
+			return err
+		}
+		s.recordStatusChange(ctx, credit, domain.CreditStatusApproved, evt)
+		s.invalidateCredit(ctx, credit)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
 	}
+	return credit, result, nil
+}
+
+/*
+	Runs fn holding the credit's status lock, waiting up to statusLockWait (ErrCreditBusy after that)
+	The row lock already serializes the database writes; holding the distributed lock across the write,
+	the cache invalidation and the event also keeps approved/rejected events in commit order across instances
+	The lock is renewed while fn runs; fn's context is cancelled if it is lost anyway, so the write rolls back
+	and the caller gets ErrCreditBusy
+	Without a cache that provides locks fn runs unguarded
+*/
+
+func (s *creditService) withStatusLock(ctx context.Context, id string, fn func(ctx context.Context) error) error {
+	locker, ok := s.cache.(cache.Locker)
+	if !ok {
+		return fn(ctx)
+	}
+	err := cache.WithLock(ctx, locker, creditStatusLockPrefix+id, s.statusLockTTL, statusLockWait, func(ctx context.Context, _ *cache.Lock) error {
+		return fn(ctx)
+	})
+	if errors.Is(err, cache.ErrLockBusy) || errors.Is(err, cache.ErrLockNotHeld) {
+		return ErrCreditBusy
+	}
+	return err
+}
+
+/*
+	Runs write in one unit of work with the outbox entry of the approval or rejection it makes, so the event
+	is stored exactly when the change commits; other statuses stage nothing
+	Returns the event to publish once committed (see recordStatusChange)
+*/
+
+func (s *creditService) writeStatus(ctx context.Context, status domain.CreditStatus, write func(ctx context.Context) (*domain.Credit, error)) (*domain.Credit, *domain.DomainEvent, error) {
+	var credit *domain.Credit
+	var evt *domain.DomainEvent
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		credit, err = write(ctx)
+		if err != nil || credit == nil {
+			return err
+		}
+		if evt, err = statusChangeEvent(ctx, credit, status); err != nil || evt == nil {
+			return err
+		}
+		return s.stage(ctx, evt)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return credit, evt, nil
+}
 
+// Counts an approval or rejection and publishes its committed event (nil for other statuses)
+func (s *creditService) recordStatusChange(ctx context.Context, credit *domain.Credit, status domain.CreditStatus, evt *domain.DomainEvent) {
 	switch status {
 	case domain.CreditStatusApproved:
-		metrics.IncCreditsApproved()
-		_ = s.emitCreditApproved(ctx, credit)
+		metrics.IncCreditsApproved(credit.BankID)
 	case domain.CreditStatusRejected:
-		metrics.IncCreditsRejected()
-		_ = s.emitCreditRejected(ctx, credit)
+		metrics.IncCreditsRejected(credit.BankID)
 	}
-
-	if s.cache != nil {
-		_ = s.cache.Delete(ctx, creditCacheKeyPrefix+id)
+	if evt != nil {
+		s.publish(ctx, evt)
 	}
-
-	return credit, nil
 }
 
 // Soft-deletes a credit
@@ -258,8 +448,8 @@ func (s *creditService) Delete(ctx context.Context, id string) (*domain.Credit,
 	if err != nil {
 		return nil, err
 	}
-	if credit != nil && s.cache != nil {
-		_ = s.cache.Delete(ctx, creditCacheKeyPrefix+id)
+	if credit != nil {
+		s.invalidateCredit(ctx, credit)
 	}
 	return credit, nil
 }
@@ -270,8 +460,8 @@ func (s *creditService) Reenable(ctx context.Context, id string) (*domain.Credit
 	if err != nil {
 		return nil, err
 	}
-	if credit != nil && s.cache != nil {
-		_ = s.cache.Delete(ctx, creditCacheKeyPrefix+id)
+	if credit != nil {
+		s.invalidateCredit(ctx, credit)
 	}
 	return credit, nil
 }
@@ -286,93 +476,98 @@ func (s *creditService) ListByClientID(ctx context.Context, clientID string, lim
 	return s.creditRepo.ListByClientID(ctx, clientID, limit, offset)
 }
 
+/*
+	Checks that a worker picks up a job before ctx is done
+	The probe queues behind real jobs, so a saturated pool fails the check the same way a dead one does
+*/
+
+func (s *creditService) PingWorkers(ctx context.Context) error {
+	resultCh := make(chan creditResult, 1)
+	select {
+	case s.jobCh <- creditJob{probe: true, result: resultCh}:
+	case <-s.done:
+		return ErrWorkersStopped
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-resultCh:
+		return nil
+	case <-s.done:
+		return ErrWorkersStopped
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // Shuts down the worker pool gracefully
 func (s *creditService) Shutdown() {
 	close(s.done)
 	s.wg.Wait()
 }
 
-// Emits a credit created event
-func (s *creditService) emitCreditCreated(ctx context.Context, c *domain.Credit) error {
-	payload := domain.CreditCreatedPayload{
+// Builds a credit created event
+func creditCreatedEvent(ctx context.Context, c *domain.Credit) (*domain.DomainEvent, error) {
+	return event.New(ctx, domain.EventCreditCreated, domain.CreditCreatedPayload{
 		CreditID:   c.ID,
 		ClientID:   c.ClientID,
 		BankID:     c.BankID,
 		CreditType: c.CreditType,
 		Status:     c.Status,
 		CreatedAt:  c.CreatedAt,
-	}
-
-	payloadBytes, err := domain.MarshalPayload(payload)
-	if err != nil {
-		return err
-	}
-
-	evt := &domain.DomainEvent{
-		ID:         uuid.New().String(),
-		Type:       domain.EventCreditCreated,
-		Payload:    payloadBytes,
-		OccurredAt: time.Now().UTC(),
-	}
-
-	return s.publisher.Publish(ctx, evt)
+	})
 }
 
-// Emits a credit approved event
-func (s *creditService) emitCreditApproved(ctx context.Context, c *domain.Credit) error {
-	payload := domain.CreditApprovedPayload{
-		CreditID:   c.ID,
-		ClientID:   c.ClientID,
-		BankID:     c.BankID,
-		ApprovedAt: time.Now().UTC(),
-	}
-
-	payloadBytes, err := domain.MarshalPayload(payload)
-	if err != nil {
-		return err
-	}
-
-	evt := &domain.DomainEvent{
-		ID:         uuid.New().String(),
-		Type:       domain.EventCreditApproved,
-		Payload:    payloadBytes,
-		OccurredAt: time.Now().UTC(),
-	}
-
-	return s.publisher.Publish(ctx, evt)
+// Builds the approved or rejected event of a status change; nil for other statuses
+func statusChangeEvent(ctx context.Context, c *domain.Credit, status domain.CreditStatus) (*domain.DomainEvent, error) {
+	switch status {
+	case domain.CreditStatusApproved:
+		return event.New(ctx, domain.EventCreditApproved, domain.CreditApprovedPayload{
+			CreditID:   c.ID,
+			ClientID:   c.ClientID,
+			BankID:     c.BankID,
+			ApprovedAt: time.Now().UTC(),
+		})
+	case domain.CreditStatusRejected:
+		return event.New(ctx, domain.EventCreditRejected, domain.CreditRejectedPayload{
+			CreditID:   c.ID,
+			ClientID:   c.ClientID,
+			BankID:     c.BankID,
+			RejectedAt: time.Now().UTC(),
+		})
+	}
+	return nil, nil
 }
 
-// Emits a credit rejected event
-func (s *creditService) emitCreditRejected(ctx context.Context, c *domain.Credit) error {
-	payload := domain.CreditRejectedPayload{
-		CreditID:   c.ID,
-		ClientID:   c.ClientID,
-		BankID:     c.BankID,
-		RejectedAt: time.Now().UTC(),
-	}
-
-	payloadBytes, err := domain.MarshalPayload(payload)
-	if err != nil {
-		return err
+// Stores events in the outbox; call inside the unit of work of the change that produced them
+func (s *creditService) stage(ctx context.Context, events ...*domain.DomainEvent) error {
+	for _, evt := range events {
+		if err := s.outbox.Enqueue(ctx, evt); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	evt := &domain.DomainEvent{
-		ID:         uuid.New().String(),
-		Type:       domain.EventCreditRejected,
-		Payload:    payloadBytes,
-		OccurredAt: time.Now().UTC(),
+// Publishes committed events; failures are the publisher's to report (see event.WithMetrics)
+func (s *creditService) publish(ctx context.Context, events ...*domain.DomainEvent) {
+	for _, evt := range events {
+		_ = s.publisher.Publish(ctx, evt)
 	}
-
-	return s.publisher.Publish(ctx, evt)
 }
 
 // Creates a credit synchronously
 func (s *creditService) CreateSync(ctx context.Context, input domain.CreateCreditInput) (*domain.Credit, error) {
-	if input.ClientID == "" || input.BankID == "" || input.MaxPayment < input.MinPayment || input.TermMonths <= 0 {
-		return nil, ErrInvalidInput
+	if err := input.Validate(); err != nil {
+		return nil, err
 	}
 
-	return s.createCredit(ctx, input)
+	ctx, span := tracing.Start(ctx, "credit.create")
+	defer span.End()
+	credit, err := s.createCredit(ctx, input)
+	span.RecordError(err)
+	return credit, err
 }
 
 // Validates eligibility concurrently
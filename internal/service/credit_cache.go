@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/tucredito/backend-api/internal/cache"
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/metrics"
+	"github.com/tucredito/backend-api/internal/tenant"
+)
+
+/*
+	Credit read cache with stampede protection
+	- Entries carry a FreshUntil time (TTL plus up to 10% jitter) and stay in the cache for staleWindowSeconds
+	  after it: a stale hit is served immediately while a single background load refreshes it
+	- Misses for the same tenant and ID are coalesced into one database query per instance (singleflight);
+	  when the cache is shared (Redis) a short lock (cache.Locker) lets one instance load while the others
+	  wait briefly for it to fill the cache
+	- IDs that do not exist for a tenant are cached as negative entries for negativeCacheTTLSeconds
+*/
+
+const (
+	creditCacheName         = "credit"
+	creditMissingKeyPrefix  = "credit:missing:"
+	creditFillLockPrefix    = "lock:credit:"
+	negativeCacheTTLSeconds = 30
+	staleWindowSeconds      = 60
+	fillLockTTL             = 5 * time.Second
+	fillLockWait            = 50 * time.Millisecond
+	fillLockRetries         = 4
+	fillTimeout             = 10 * time.Second
+)
+
+// Cached form of a credit
+type cachedCredit struct {
+	Credit     *domain.Credit `json:"credit"`
+	FreshUntil time.Time      `json:"fresh_until"`
+}
+
+// The JSON helpers of cache.RedisCache, MemoryCache and TieredCache
+type jsonStore interface {
+	GetJSON(ctx context.Context, key string, v interface{}) error
+	SetJSON(ctx context.Context, key string, v interface{}, ttlSeconds int) error
+}
+
+// Gets a credit by ID
+func (s *creditService) GetByID(ctx context.Context, id string) (*domain.Credit, error) {
+	store, ok := s.cache.(jsonStore)
+	if !ok {
+		return s.creditRepo.GetByID(ctx, id)
+	}
+
+	// The cache is shared by all tenants, so a hit is only served to a tenant that owns the credit
+	if entry, ok := s.cachedCredit(ctx, store, id); ok {
+		metrics.IncCacheHit(creditCacheName)
+		if time.Now().After(entry.FreshUntil) {
+			s.revalidateCredit(ctx, id)
+		}
+		return entry.Credit, nil
+	}
+	if missing, _ := s.cache.Get(ctx, creditMissingKey(tenant.Label(ctx), id)); missing != "" {
+		metrics.IncCacheHit(creditCacheName)
+		return nil, nil
+	}
+	metrics.IncCacheMiss(creditCacheName)
+
+	// Followers wait on the leader's load but still honour their own context
+	leader := false
+	ch := s.flights.DoChan(creditFlightKey(ctx, id), func() (interface{}, error) {
+		leader = true
+		return s.loadCredit(ctx, id)
+	})
+	select {
+	case res := <-ch:
+		if res.Shared && !leader {
+			metrics.IncCacheCoalesced(creditCacheName)
+		}
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*domain.Credit), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Returns the cached entry for id if the caller's tenant may see it
+func (s *creditService) cachedCredit(ctx context.Context, store jsonStore, id string) (*cachedCredit, bool) {
+	var entry cachedCredit
+	if err := store.GetJSON(ctx, creditCacheKeyPrefix+id, &entry); err != nil || entry.Credit == nil || entry.Credit.ID == "" {
+		return nil, false
+	}
+	if !tenant.CanAccessBank(ctx, entry.Credit.BankID) {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// Loads a credit from the repository and caches the outcome; runs once per flight key.
+// The load is detached from the caller's cancellation since other callers may be waiting on it
+func (s *creditService) loadCredit(ctx context.Context, id string) (*domain.Credit, error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), fillTimeout)
+	defer cancel()
+
+	if locker, ok := s.cache.(cache.Locker); ok {
+		lock, err := locker.AcquireUnfenced(ctx, creditFillLockPrefix+tenant.Label(ctx)+":"+id, fillLockTTL)
+		switch {
+		case err == nil && lock != nil:
+			defer func() { _ = locker.Release(ctx, lock) }()
+		case err == nil:
+			// Another instance is loading this credit; give it a moment to fill the cache
+			if c, found := s.awaitCreditFill(ctx, id); found {
+				return c, nil
+			}
+		}
+	}
+
+	c, err := s.creditRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		_ = s.cache.Set(ctx, creditMissingKey(tenant.Label(ctx), id), "1", negativeCacheTTLSeconds)
+		return nil, nil
+	}
+	s.cacheCredit(ctx, c)
+	return c, nil
+}
+
+// Polls the cache while another instance holds the fill lock; found is false if it did not fill in time
+func (s *creditService) awaitCreditFill(ctx context.Context, id string) (c *domain.Credit, found bool) {
+	store := s.cache.(jsonStore)
+	for i := 0; i < fillLockRetries; i++ {
+		select {
+		case <-time.After(fillLockWait):
+		case <-ctx.Done():
+			return nil, false
+		}
+		if entry, ok := s.cachedCredit(ctx, store, id); ok {
+			return entry.Credit, true
+		}
+		if missing, _ := s.cache.Get(ctx, creditMissingKey(tenant.Label(ctx), id)); missing != "" {
+			return nil, true
+		}
+	}
+	return nil, false
+}
+
+// Refreshes a stale entry in the background; concurrent refreshes and misses share one load
+func (s *creditService) revalidateCredit(ctx context.Context, id string) {
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		c, err, _ := s.flights.Do(creditFlightKey(ctx, id), func() (interface{}, error) {
+			return s.loadCredit(ctx, id)
+		})
+		// The caller could see the credit, so a miss now means it is gone for its owner too
+		if err == nil && c.(*domain.Credit) == nil {
+			_ = s.cache.Delete(ctx, creditCacheKeyPrefix+id)
+		}
+	}()
+}
+
+// Caches a credit as fresh for cacheTTLSeconds (with jitter) plus the stale window
+func (s *creditService) cacheCredit(ctx context.Context, c *domain.Credit) {
+	store, ok := s.cache.(jsonStore)
+	if !ok {
+		return
+	}
+	ttl := jitterTTL(cacheTTLSeconds)
+	entry := cachedCredit{Credit: c, FreshUntil: time.Now().Add(time.Duration(ttl) * time.Second)}
+	_ = store.SetJSON(ctx, creditCacheKeyPrefix+c.ID, entry, ttl+staleWindowSeconds)
+}
+
+// Drops the cached credit and the negative entries of the tenants that can see it
+func (s *creditService) invalidateCredit(ctx context.Context, c *domain.Credit) {
+	if s.cache == nil {
+		return
+	}
+	_ = s.cache.Delete(ctx, creditCacheKeyPrefix+c.ID)
+	_ = s.cache.Delete(ctx, creditMissingKey(tenant.Platform, c.ID))
+	_ = s.cache.Delete(ctx, creditMissingKey(c.BankID, c.ID))
+}
+
+// Negative entries are per tenant: a credit hidden from one bank may exist for another
+func creditMissingKey(tenantLabel, id string) string {
+	return creditMissingKeyPrefix + tenantLabel + ":" + id
+}
+
+// Repository reads are tenant scoped, so only callers of the same tenant share a load
+func creditFlightKey(ctx context.Context, id string) string {
+	return tenant.Label(ctx) + ":" + id
+}
+
+// Adds up to 10% random jitter so entries written together do not expire together
+func jitterTTL(ttl int) int {
+	return ttl + rand.Intn(ttl/10+1)
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/cache"
+	"github.com/tucredito/backend-api/internal/decision"
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/event"
+	repomocks "github.com/tucredito/backend-api/internal/repository/mocks"
+	"go.uber.org/zap"
+)
+
+func newCachedCreditService(t *testing.T, c cache.Cache, creditRepo *repomocks.CreditRepository) CreditService {
+	svc := NewCreditService(creditRepo, nil, &repomocks.ClientRepository{}, &repomocks.BankRepository{}, c, event.NewMockPublisher(), nil, decision.NewRuleEngine(), zap.NewNop())
+	t.Cleanup(svc.Shutdown)
+	return svc
+}
+
+func TestCreditService_GetByID_CoalescesConcurrentMisses(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	creditRepo := &repomocks.CreditRepository{}
+	creditRepo.GetByIDFunc = func(ctx context.Context, id string) (*domain.Credit, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return &domain.Credit{ID: id, BankID: "bank-a"}, nil
+	}
+	svc := newCachedCreditService(t, cache.NewMemoryCache(0), creditRepo)
+
+	var wg sync.WaitGroup
+	results := make([]*domain.Credit, 20)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = svc.GetByID(context.Background(), "cr1")
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for _, c := range results {
+		require.NotNil(t, c)
+		assert.Equal(t, "cr1", c.ID)
+	}
+
+	// Later reads are cache hits
+	_, err := svc.GetByID(context.Background(), "cr1")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestCreditService_GetByID_NegativeCache(t *testing.T) {
+	calls := 0
+	creditRepo := &repomocks.CreditRepository{}
+	creditRepo.GetByIDFunc = func(ctx context.Context, id string) (*domain.Credit, error) {
+		calls++
+		return nil, nil
+	}
+	creditRepo.SetActiveFunc = func(ctx context.Context, id string) (*domain.Credit, error) {
+		return &domain.Credit{ID: id, BankID: "bank-a"}, nil
+	}
+	svc := newCachedCreditService(t, cache.NewMemoryCache(0), creditRepo)
+
+	for i := 0; i < 3; i++ {
+		got, err := svc.GetByID(context.Background(), "missing")
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	}
+	assert.Equal(t, 1, calls)
+
+	// Mutations drop negative entries
+	_, err := svc.Reenable(context.Background(), "missing")
+	require.NoError(t, err)
+	_, _ = svc.GetByID(context.Background(), "missing")
+	assert.Equal(t, 2, calls)
+}
+
+func TestCreditService_GetByID_StaleWhileRevalidate(t *testing.T) {
+	c := cache.NewMemoryCache(0)
+	stale := cachedCredit{Credit: &domain.Credit{ID: "cr1", Status: domain.CreditStatusPending}, FreshUntil: time.Now().Add(-time.Second)}
+	require.NoError(t, c.SetJSON(context.Background(), creditCacheKeyPrefix+"cr1", stale, 60))
+
+	refreshed := make(chan struct{})
+	creditRepo := &repomocks.CreditRepository{}
+	creditRepo.GetByIDFunc = func(ctx context.Context, id string) (*domain.Credit, error) {
+		defer close(refreshed)
+		return &domain.Credit{ID: id, Status: domain.CreditStatusApproved}, nil
+	}
+	svc := newCachedCreditService(t, c, creditRepo)
+
+	got, err := svc.GetByID(context.Background(), "cr1")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, domain.CreditStatusPending, got.Status, "stale value is served immediately")
+
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("stale entry was not revalidated")
+	}
+	assert.Eventually(t, func() bool {
+		got, _ := svc.GetByID(context.Background(), "cr1")
+		return got != nil && got.Status == domain.CreditStatusApproved
+	}, time.Second, 10*time.Millisecond)
+}
+
+// lockedCache simulates another instance holding the fill lock and filling the cache shortly after
+type lockedCache struct {
+	*cache.MemoryCache
+}
+
+func (c *lockedCache) AcquireUnfenced(context.Context, string, time.Duration) (*cache.Lock, error) {
+	return nil, nil
+}
+
+func TestCreditService_GetByID_WaitsForOtherInstanceFill(t *testing.T) {
+	c := &lockedCache{MemoryCache: cache.NewMemoryCache(0)}
+	creditRepo := &repomocks.CreditRepository{}
+	creditRepo.GetByIDFunc = func(ctx context.Context, id string) (*domain.Credit, error) {
+		t.Error("repository should not be queried while another instance fills the cache")
+		return nil, nil
+	}
+	svc := newCachedCreditService(t, c, creditRepo)
+
+	go func() {
+		time.Sleep(fillLockWait / 2)
+		entry := cachedCredit{Credit: &domain.Credit{ID: "cr1"}, FreshUntil: time.Now().Add(time.Minute)}
+		_ = c.SetJSON(context.Background(), creditCacheKeyPrefix+"cr1", entry, 60)
+	}()
+
+	got, err := svc.GetByID(context.Background(), "cr1")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "cr1", got.ID)
+}
+
+func TestJitterTTL(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		ttl := jitterTTL(300)
+		assert.GreaterOrEqual(t, ttl, 300)
+		assert.LessOrEqual(t, ttl, 330)
+	}
+}
+
+func TestCreditService_UpdateStatus_HoldsStatusLock(t *testing.T) {
+	c := cache.NewMemoryCache(0)
+	var mu sync.Mutex
+	inside, maxInside := 0, 0
+	creditRepo := &repomocks.CreditRepository{}
+	creditRepo.UpdateStatusFunc = func(ctx context.Context, id string, status domain.CreditStatus) (*domain.Credit, error) {
+		// Another request cannot take the lock while this write is running
+		other, err := c.Acquire(ctx, creditStatusLockPrefix+id, time.Second)
+		require.NoError(t, err)
+		assert.Nil(t, other)
+
+		mu.Lock()
+		inside++
+		if inside > maxInside {
+			maxInside = inside
+		}
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		inside--
+		mu.Unlock()
+		return &domain.Credit{ID: id, Status: status}, nil
+	}
+	svc := newCachedCreditService(t, c, creditRepo)
+
+	var wg sync.WaitGroup
+	for _, status := range []domain.CreditStatus{domain.CreditStatusApproved, domain.CreditStatusRejected, domain.CreditStatusApproved} {
+		wg.Add(1)
+		go func(status domain.CreditStatus) {
+			defer wg.Done()
+			got, err := svc.UpdateStatus(context.Background(), "cr1", status)
+			assert.NoError(t, err)
+			assert.NotNil(t, got)
+		}(status)
+	}
+	wg.Wait()
+	assert.Equal(t, 1, maxInside)
+
+	// Released afterwards
+	lock, err := c.Acquire(context.Background(), creditStatusLockPrefix+"cr1", time.Second)
+	require.NoError(t, err)
+	assert.NotNil(t, lock)
+}
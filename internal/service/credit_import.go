@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tucredito/backend-api/internal/bulk"
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/repository"
+	"github.com/tucredito/backend-api/pkg/apperr"
+	"github.com/tucredito/backend-api/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Rows in flight at once; matches the credit worker pool so the queue stays short
+const importConcurrency = workerPoolSize
+
+type creditImportService struct {
+	repository repository.CreditImportRepository
+	credits    CreditService
+	log        *zap.Logger
+}
+
+func NewCreditImportService(repository repository.CreditImportRepository, credits CreditService, log *zap.Logger) CreditImportService {
+	return &creditImportService{
+		repository: repository,
+		credits:    credits,
+		log:        log,
+	}
+}
+
+/*
+	Import streams rows from r and creates one credit per row through the worker pool
+	Row failures (parse, validation, not found, decision) are recorded in the report and never abort the batch
+	Only an unreadable header or unsupported format fails the whole import (before anything is stored)
+*/
+
+func (s *creditImportService) Import(ctx context.Context, format domain.ImportFormat, r io.Reader) (*domain.CreditImport, error) {
+	rows, err := bulk.NewReader(format, r)
+	if err != nil {
+		return nil, err
+	}
+
+	imp, err := s.repository.Create(ctx, format)
+	if err != nil {
+		return nil, err
+	}
+
+	results := s.process(ctx, rows)
+
+	input := domain.CompleteCreditImportInput{Total: len(results), Results: results}
+	for _, res := range results {
+		if res.CreditID != "" {
+			input.Succeeded++
+		} else {
+			input.Failed++
+		}
+	}
+
+	// The report must be stored even if the client went away mid-upload
+	completed, err := s.repository.Complete(context.WithoutCancel(ctx), imp.ID, input)
+	if err != nil {
+		return nil, err
+	}
+	if completed == nil {
+		return nil, errors.New("credit import disappeared before completion")
+	}
+	return completed, nil
+}
+
+// Reads every row and fans credit creation out with bounded concurrency
+func (s *creditImportService) process(ctx context.Context, rows bulk.Reader) []domain.CreditImportResult {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results []domain.CreditImportResult
+		lastRow int
+	)
+	record := func(res domain.CreditImportResult) {
+		mu.Lock()
+		results = append(results, res)
+		mu.Unlock()
+	}
+
+	sem := make(chan struct{}, importConcurrency)
+	for {
+		row, err := rows.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// The stream itself is broken; report it as one failed row after the last one read
+			logger.FromContext(ctx, s.log).Warn("credit import stream error", zap.Error(err))
+			record(domain.CreditImportResult{Row: lastRow + 1, Code: "PARSE_ERROR", Error: err.Error()})
+			break
+		}
+		lastRow = row.Number
+
+		if row.Err != nil {
+			record(domain.CreditImportResult{Row: row.Number, Code: "PARSE_ERROR", Error: row.Err.Error()})
+			continue
+		}
+		// Same rules as the API; invalid rows never reach the worker pool
+		if err := row.Input.Validate(); err != nil {
+			record(s.resultFor(ctx, row.Number, nil, err))
+			continue
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(row *bulk.Row) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			credit, err := s.credits.Create(ctx, row.Input)
+			record(s.resultFor(ctx, row.Number, credit, err))
+		}(row)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Row < results[j].Row })
+	return results
+}
+
+// Maps the outcome of a single creation to a report row without leaking internals
+func (s *creditImportService) resultFor(ctx context.Context, row int, credit *domain.Credit, err error) domain.CreditImportResult {
+	res := domain.CreditImportResult{Row: row}
+	switch {
+	case err == nil && credit != nil:
+		res.CreditID = credit.ID
+		res.Status = credit.Status
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		res.Code, res.Error = "CANCELED", "import interrupted before this row was processed"
+	case apperr.KindOf(err) != apperr.KindInternal:
+		e, _ := apperr.As(err)
+		res.Code, res.Error = e.ErrorCode(), reportMessage(e)
+	default:
+		logger.FromContext(ctx, s.log).Error("credit import row", zap.Int("row", row), zap.Error(err))
+		res.Code, res.Error = "INTERNAL", "failed to create credit"
+	}
+	return res
+}
+
+// Gets a credit import with its report by ID
+func (s *creditImportService) GetByID(ctx context.Context, id string) (*domain.CreditImport, error) {
+	return s.repository.GetByID(ctx, id)
+}
+
+// Flattens field errors into the report's single error column, e.g. "term_months: must be at least 1; credit_type: is required"
+func reportMessage(e *apperr.Error) string {
+	if len(e.Fields) == 0 {
+		return e.Message
+	}
+	parts := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		parts[i] = f.Field + ": " + f.Message
+	}
+	return strings.Join(parts, "; ")
+}
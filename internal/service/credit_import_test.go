@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/decision"
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/event"
+	repomocks "github.com/tucredito/backend-api/internal/repository/mocks"
+	"go.uber.org/zap"
+)
+
+func TestCreditImportService_Import_PartialFailure(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	var seq int64
+	creditRepo := &repomocks.CreditRepository{}
+	creditRepo.CreateFunc = func(ctx context.Context, input domain.CreateCreditInput, status domain.CreditStatus) (*domain.Credit, error) {
+		n := atomic.AddInt64(&seq, 1)
+		return &domain.Credit{
+			ID: "cr" + strconv.FormatInt(n, 10), ClientID: input.ClientID, BankID: input.BankID,
+			Status: status, CreatedAt: time.Now(),
+		}, nil
+	}
+	creditRepo.UpdateStatusFunc = func(ctx context.Context, id string, status domain.CreditStatus) (*domain.Credit, error) {
+		return &domain.Credit{ID: id, Status: status}, nil
+	}
+	clientRepo := &repomocks.ClientRepository{}
+	clientRepo.GetByIDFunc = func(ctx context.Context, id string) (*domain.Client, error) {
+		if id == "missing" {
+			return nil, nil
+		}
+		return &domain.Client{ID: id}, nil
+	}
+	bankRepo := &repomocks.BankRepository{}
+	bankRepo.GetByIDFunc = func(ctx context.Context, id string) (*domain.Bank, error) {
+		return &domain.Bank{ID: id, Type: domain.BankTypePrivate}, nil
+	}
+	engine := decision.NewRuleEngine()
+	engine.RegisterRule(decision.PaymentRangeRule{})
+	credits := NewCreditService(creditRepo, nil, clientRepo, bankRepo, nil, event.NewMockPublisher(), nil, engine, log)
+	defer credits.Shutdown()
+
+	var completed domain.CompleteCreditImportInput
+	importRepo := &repomocks.CreditImportRepository{}
+	importRepo.CreateFunc = func(ctx context.Context, format domain.ImportFormat) (*domain.CreditImport, error) {
+		return &domain.CreditImport{ID: "imp1", Format: format, Status: domain.ImportStatusProcessing}, nil
+	}
+	importRepo.CompleteFunc = func(ctx context.Context, id string, input domain.CompleteCreditImportInput) (*domain.CreditImport, error) {
+		completed = input
+		return &domain.CreditImport{
+			ID: id, Status: domain.ImportStatusCompleted,
+			Total: input.Total, Succeeded: input.Succeeded, Failed: input.Failed, Results: input.Results,
+		}, nil
+	}
+	svc := NewCreditImportService(importRepo, credits, log)
+
+	body := strings.Join([]string{
+		`{"client_id":"c1","bank_id":"b1","min_payment":100,"max_payment":500,"term_months":12,"credit_type":"AUTO"}`,
+		`{"client_id":"missing","bank_id":"b1","min_payment":100,"max_payment":500,"term_months":12,"credit_type":"AUTO"}`,
+		`{oops`,
+		`{"client_id":"c1","bank_id":"b1","min_payment":500,"max_payment":100,"term_months":12,"credit_type":"AUTO"}`,
+		`{"client_id":"c1","bank_id":"b1","min_payment":100,"max_payment":500,"term_months":12,"credit_type":"BOAT"}`,
+		`{"client_id":"c2","bank_id":"b1","min_payment":100,"max_payment":500,"term_months":12,"credit_type":"MORTGAGE"}`,
+	}, "\n")
+
+	imp, err := svc.Import(context.Background(), domain.ImportFormatNDJSON, strings.NewReader(body))
+	require.NoError(t, err)
+	require.NotNil(t, imp)
+	assert.Equal(t, domain.ImportStatusCompleted, imp.Status)
+	assert.Equal(t, 6, completed.Total)
+	assert.Equal(t, 2, completed.Succeeded)
+	assert.Equal(t, 4, completed.Failed)
+
+	require.Len(t, imp.Results, 6)
+	for i, res := range imp.Results {
+		assert.Equal(t, i+1, res.Row)
+	}
+	assert.NotEmpty(t, imp.Results[0].CreditID)
+	assert.Equal(t, domain.CreditStatusApproved, imp.Results[0].Status)
+	assert.Equal(t, "NOT_FOUND", imp.Results[1].Code)
+	assert.Equal(t, "PARSE_ERROR", imp.Results[2].Code)
+	assert.Equal(t, "VALIDATION", imp.Results[3].Code)
+	assert.Equal(t, "max_payment: must be greater than or equal to min_payment", imp.Results[3].Error)
+	assert.Equal(t, "VALIDATION", imp.Results[4].Code)
+	assert.NotEmpty(t, imp.Results[5].CreditID)
+}
+
+func TestCreditImportService_Import_MissingColumn(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	importRepo := &repomocks.CreditImportRepository{}
+	importRepo.CreateFunc = func(ctx context.Context, format domain.ImportFormat) (*domain.CreditImport, error) {
+		t.Fatal("import must not be stored when the header is invalid")
+		return nil, nil
+	}
+	svc := NewCreditImportService(importRepo, nil, log)
+
+	_, err := svc.Import(context.Background(), domain.ImportFormatCSV, strings.NewReader("client_id,bank_id\nc1,b1\n"))
+	require.Error(t, err)
+}
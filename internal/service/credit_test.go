@@ -2,15 +2,20 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/cache"
 	"github.com/tucredito/backend-api/internal/decision"
 	"github.com/tucredito/backend-api/internal/event"
 	repomocks "github.com/tucredito/backend-api/internal/repository/mocks"
+	"github.com/tucredito/backend-api/internal/tenant"
+	"github.com/tucredito/backend-api/internal/tracing"
+	"github.com/tucredito/backend-api/pkg/apperr"
 	"go.uber.org/zap"
 )
 
@@ -23,7 +28,7 @@ func TestCreditService_CreateSync_InvalidInput(t *testing.T) {
 	engine := decision.NewRuleEngine()
 	engine.RegisterRule(decision.PaymentRangeRule{})
 
-	svc := NewCreditService(creditRepo, clientRepo, bankRepo, nil, publisher, engine, log)
+	svc := NewCreditService(creditRepo, nil, clientRepo, bankRepo, nil, publisher, nil, engine, log)
 	defer svc.Shutdown()
 
 	_, err := svc.CreateSync(context.Background(), domain.CreateCreditInput{
@@ -34,7 +39,13 @@ func TestCreditService_CreateSync_InvalidInput(t *testing.T) {
 		TermMonths: 12,
 	})
 	require.Error(t, err)
-	assert.Equal(t, ErrInvalidInput, err)
+	e, ok := apperr.As(err)
+	require.True(t, ok)
+	assert.Equal(t, apperr.KindValidation, e.Kind)
+	assert.Equal(t, []apperr.FieldError{
+		{Field: "max_payment", Code: "out_of_range", Message: "must be greater than or equal to min_payment"},
+		{Field: "credit_type", Code: "required", Message: "is required"},
+	}, e.Fields, "every invalid field is reported at once")
 }
 
 func TestCreditService_CreateSync_ClientNotFound(t *testing.T) {
@@ -49,7 +60,7 @@ func TestCreditService_CreateSync_ClientNotFound(t *testing.T) {
 	engine := decision.NewRuleEngine()
 	engine.RegisterRule(decision.PaymentRangeRule{})
 
-	svc := NewCreditService(creditRepo, clientRepo, bankRepo, nil, publisher, engine, log)
+	svc := NewCreditService(creditRepo, nil, clientRepo, bankRepo, nil, publisher, nil, engine, log)
 	defer svc.Shutdown()
 
 	_, err := svc.CreateSync(context.Background(), domain.CreateCreditInput{
@@ -75,8 +86,10 @@ func TestCreditService_CreateSync_Success(t *testing.T) {
 		CreatedAt: time.Now(),
 	}
 	creditRepo := &repomocks.CreditRepository{}
-	creditRepo.CreateFunc = func(ctx context.Context, input domain.CreateCreditInput) (*domain.Credit, error) {
-		return credit, nil
+	creditRepo.CreateFunc = func(ctx context.Context, input domain.CreateCreditInput, status domain.CreditStatus) (*domain.Credit, error) {
+		c := *credit
+		c.Status = status
+		return &c, nil
 	}
 	creditRepo.UpdateStatusFunc = func(ctx context.Context, id string, status domain.CreditStatus) (*domain.Credit, error) {
 		c := *credit
@@ -95,7 +108,7 @@ func TestCreditService_CreateSync_Success(t *testing.T) {
 	engine := decision.NewRuleEngine()
 	engine.RegisterRule(decision.PaymentRangeRule{})
 
-	svc := NewCreditService(creditRepo, clientRepo, bankRepo, nil, publisher, engine, log)
+	svc := NewCreditService(creditRepo, nil, clientRepo, bankRepo, nil, publisher, nil, engine, log)
 	defer svc.Shutdown()
 
 	out, err := svc.CreateSync(context.Background(), domain.CreateCreditInput{
@@ -113,6 +126,207 @@ func TestCreditService_CreateSync_Success(t *testing.T) {
 	assert.GreaterOrEqual(t, len(events), 1)
 }
 
+func TestCreditService_CreateSync_InsertsDecidedStatus(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	creditRepo := &repomocks.CreditRepository{}
+	var inserted []domain.CreditStatus
+	creditRepo.CreateFunc = func(ctx context.Context, input domain.CreateCreditInput, status domain.CreditStatus) (*domain.Credit, error) {
+		inserted = append(inserted, status)
+		return &domain.Credit{ID: "cr1", ClientID: input.ClientID, BankID: input.BankID, Status: status}, nil
+	}
+	creditRepo.UpdateStatusFunc = func(ctx context.Context, id string, status domain.CreditStatus) (*domain.Credit, error) {
+		t.Fatal("the decided status is inserted, never applied afterwards")
+		return nil, nil
+	}
+	clientRepo := &repomocks.ClientRepository{}
+	clientRepo.GetByIDFunc = func(ctx context.Context, id string) (*domain.Client, error) { return &domain.Client{ID: id}, nil }
+	bankRepo := &repomocks.BankRepository{}
+	bankRepo.GetByIDFunc = func(ctx context.Context, id string) (*domain.Bank, error) { return &domain.Bank{ID: id}, nil }
+	tx := &repomocks.TxManager{}
+	publisher := event.NewMockPublisher()
+	engine := decision.NewRuleEngine()
+	engine.RegisterRule(decision.PaymentRangeRule{})
+
+	svc := NewCreditService(creditRepo, tx, clientRepo, bankRepo, nil, publisher, nil, engine, log)
+	defer svc.Shutdown()
+
+	out, err := svc.CreateSync(context.Background(), domain.CreateCreditInput{
+		ClientID: "c1", BankID: "b1", MinPayment: 100, MaxPayment: 500, TermMonths: 12, CreditType: domain.CreditTypeAuto,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.CreditStatusApproved, out.Status)
+	assert.Equal(t, []domain.CreditStatus{domain.CreditStatusApproved}, inserted)
+	assert.Equal(t, int32(1), tx.Committed.Load())
+	events := publisher.Events()
+	require.Len(t, events, 2)
+	assert.Equal(t, domain.EventCreditCreated, events[0].Type)
+	assert.Equal(t, domain.EventCreditApproved, events[1].Type)
+}
+
+func TestCreditService_CreateSync_RolledBackUnitPublishesNothing(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	creditRepo := &repomocks.CreditRepository{}
+	creditRepo.CreateFunc = func(ctx context.Context, input domain.CreateCreditInput, status domain.CreditStatus) (*domain.Credit, error) {
+		return &domain.Credit{ID: "cr1", Status: status}, nil
+	}
+	clientRepo := &repomocks.ClientRepository{}
+	clientRepo.GetByIDFunc = func(ctx context.Context, id string) (*domain.Client, error) { return &domain.Client{ID: id}, nil }
+	bankRepo := &repomocks.BankRepository{}
+	bankRepo.GetByIDFunc = func(ctx context.Context, id string) (*domain.Bank, error) { return &domain.Bank{ID: id}, nil }
+	commitErr := apperr.Unavailable("database unavailable", nil)
+	tx := &repomocks.TxManager{}
+	tx.WithinTxFunc = func(ctx context.Context, fn func(ctx context.Context) error) error {
+		if err := fn(ctx); err != nil {
+			return err
+		}
+		return commitErr // the commit fails after the insert succeeded
+	}
+	publisher := event.NewMockPublisher()
+
+	svc := NewCreditService(creditRepo, tx, clientRepo, bankRepo, nil, publisher, nil, decision.NewRuleEngine(), log)
+	defer svc.Shutdown()
+
+	_, err := svc.CreateSync(context.Background(), domain.CreateCreditInput{
+		ClientID: "c1", BankID: "b1", MinPayment: 100, MaxPayment: 500, TermMonths: 12, CreditType: domain.CreditTypeAuto,
+	})
+	assert.ErrorIs(t, err, commitErr)
+	assert.Equal(t, int32(1), tx.RolledBack.Load())
+	assert.Empty(t, publisher.Events())
+}
+
+// Adapts a function to event.Outbox
+type outboxFunc func(ctx context.Context, evt *domain.DomainEvent) error
+
+func (f outboxFunc) Enqueue(ctx context.Context, evt *domain.DomainEvent) error { return f(ctx, evt) }
+
+func TestCreditService_UpdateStatus_StagesEventInUnitOfWork(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	creditRepo := &repomocks.CreditRepository{}
+	creditRepo.UpdateStatusFunc = func(ctx context.Context, id string, status domain.CreditStatus) (*domain.Credit, error) {
+		return &domain.Credit{ID: id, BankID: "b1", Status: status}, nil
+	}
+	tx := &repomocks.TxManager{}
+	publisher := event.NewMockPublisher()
+	var staged []domain.EventType
+	outboxErr := apperr.Unavailable("database unavailable", nil)
+	failing := false
+	outbox := outboxFunc(func(ctx context.Context, evt *domain.DomainEvent) error {
+		assert.Zero(t, tx.Committed.Load()+tx.RolledBack.Load(), "staged outside the unit of work")
+		if failing {
+			return outboxErr
+		}
+		staged = append(staged, evt.Type)
+		return nil
+	})
+
+	svc := NewCreditService(creditRepo, tx, &repomocks.ClientRepository{}, &repomocks.BankRepository{}, nil, publisher, outbox, decision.NewRuleEngine(), log)
+	defer svc.Shutdown()
+
+	_, err := svc.UpdateStatus(context.Background(), "cr1", domain.CreditStatusApproved)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.EventType{domain.EventCreditApproved}, staged)
+	require.Len(t, publisher.Events(), 1)
+
+	// An outbox failure rolls the change back and publishes nothing
+	tx = &repomocks.TxManager{}
+	publisher = event.NewMockPublisher()
+	failing = true
+	svc = NewCreditService(creditRepo, tx, &repomocks.ClientRepository{}, &repomocks.BankRepository{}, nil, publisher, outbox, decision.NewRuleEngine(), log)
+	defer svc.Shutdown()
+
+	_, err = svc.UpdateStatus(context.Background(), "cr1", domain.CreditStatusRejected)
+	assert.ErrorIs(t, err, outboxErr)
+	assert.Equal(t, int32(1), tx.RolledBack.Load())
+	assert.Empty(t, publisher.Events())
+}
+
+// renewFailingCache is a MemoryCache whose locks are taken over by another instance once acquired
+type renewFailingCache struct {
+	*cache.MemoryCache
+}
+
+func (renewFailingCache) Renew(context.Context, *cache.Lock, time.Duration) error {
+	return cache.ErrLockNotHeld
+}
+
+func TestCreditService_UpdateStatus_HoldsLockBeyondTTL(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	c := cache.NewMemoryCache(0)
+	ttl := 60 * time.Millisecond
+	creditRepo := &repomocks.CreditRepository{}
+	creditRepo.UpdateStatusFunc = func(ctx context.Context, id string, status domain.CreditStatus) (*domain.Credit, error) {
+		// A slow write: the lock must still be held after several TTLs
+		for i := 0; i < 4; i++ {
+			time.Sleep(ttl / 2)
+			other, err := c.Acquire(context.Background(), creditStatusLockPrefix+id, ttl)
+			require.NoError(t, err)
+			assert.Nil(t, other, "another instance took the status lock during the write")
+		}
+		return &domain.Credit{ID: id, Status: status}, ctx.Err()
+	}
+	publisher := event.NewMockPublisher()
+	svc := NewCreditService(creditRepo, nil, &repomocks.ClientRepository{}, &repomocks.BankRepository{}, c, publisher, nil, decision.NewRuleEngine(), log)
+	defer svc.Shutdown()
+	svc.(*creditService).statusLockTTL = ttl
+
+	_, err := svc.UpdateStatus(context.Background(), "cr1", domain.CreditStatusApproved)
+	require.NoError(t, err)
+	assert.Len(t, publisher.Events(), 1)
+}
+
+func TestCreditService_UpdateStatus_LostLockAbortsWrite(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	creditRepo := &repomocks.CreditRepository{}
+	creditRepo.UpdateStatusFunc = func(ctx context.Context, id string, status domain.CreditStatus) (*domain.Credit, error) {
+		<-ctx.Done() // the transaction cannot commit once the lock is lost
+		return nil, ctx.Err()
+	}
+	publisher := event.NewMockPublisher()
+	c := renewFailingCache{cache.NewMemoryCache(0)}
+	svc := NewCreditService(creditRepo, nil, &repomocks.ClientRepository{}, &repomocks.BankRepository{}, c, publisher, nil, decision.NewRuleEngine(), log)
+	defer svc.Shutdown()
+	svc.(*creditService).statusLockTTL = 30 * time.Millisecond
+
+	_, err := svc.UpdateStatus(context.Background(), "cr1", domain.CreditStatusApproved)
+	assert.ErrorIs(t, err, ErrCreditBusy)
+	assert.Empty(t, publisher.Events())
+}
+
+func TestCreditService_Patch_Status(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	current := &domain.Credit{
+		ID: "cr1", ClientID: "c1", BankID: "b1", MinPayment: 100, MaxPayment: 500, TermMonths: 12,
+		CreditType: domain.CreditTypeAuto, Status: domain.CreditStatusPending, IsActive: true, Version: 1,
+	}
+	creditRepo := &repomocks.CreditRepository{}
+	creditRepo.GetByIDFunc = func(ctx context.Context, id string) (*domain.Credit, error) { return current, nil }
+	var got domain.CreditPatch
+	creditRepo.PatchFunc = func(ctx context.Context, id string, patch domain.CreditPatch) (*domain.Credit, error) {
+		got = patch
+		out := *current
+		out.Status = *patch.Status
+		out.Version++
+		return &out, nil
+	}
+	publisher := event.NewMockPublisher()
+	svc := NewCreditService(creditRepo, nil, &repomocks.ClientRepository{}, &repomocks.BankRepository{}, nil, publisher, nil, decision.NewRuleEngine(), log)
+	defer svc.Shutdown()
+
+	// max_payment below the current min_payment is rejected on the merged result
+	low := 50.0
+	_, err := svc.Patch(context.Background(), "cr1", domain.CreditPatch{MaxPayment: &low})
+	assert.Equal(t, apperr.KindValidation, apperr.KindOf(err))
+
+	approved := domain.CreditStatusApproved
+	out, err := svc.Patch(context.Background(), "cr1", domain.CreditPatch{Status: &approved})
+	require.NoError(t, err)
+	assert.Equal(t, domain.CreditStatusApproved, out.Status)
+	assert.Nil(t, got.MinPayment, "only provided fields reach the repository")
+	events := publisher.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, domain.EventCreditApproved, events[0].Type)
+}
+
 func TestCreditService_Reenable(t *testing.T) {
 	reenabled := &domain.Credit{ID: "cr1", ClientID: "c1", BankID: "b1", IsActive: true}
 	creditRepo := &repomocks.CreditRepository{}
@@ -127,7 +341,7 @@ func TestCreditService_Reenable(t *testing.T) {
 	publisher := event.NewMockPublisher()
 	engine := decision.NewRuleEngine()
 	log, _ := zap.NewDevelopment()
-	svc := NewCreditService(creditRepo, clientRepo, bankRepo, nil, publisher, engine, log)
+	svc := NewCreditService(creditRepo, nil, clientRepo, bankRepo, nil, publisher, nil, engine, log)
 	defer svc.Shutdown()
 
 	got, err := svc.Reenable(context.Background(), "cr1")
@@ -146,10 +360,191 @@ func TestCreditService_Reenable_NotFound(t *testing.T) {
 	publisher := event.NewMockPublisher()
 	engine := decision.NewRuleEngine()
 	log, _ := zap.NewDevelopment()
-	svc := NewCreditService(creditRepo, clientRepo, bankRepo, nil, publisher, engine, log)
+	svc := NewCreditService(creditRepo, nil, clientRepo, bankRepo, nil, publisher, nil, engine, log)
 	defer svc.Shutdown()
 
 	got, err := svc.Reenable(context.Background(), "none")
 	require.NoError(t, err)
 	require.Nil(t, got)
 }
+
+// jsonCache is an in-memory cache.Cache with the JSON helpers the credit service looks for
+type jsonCache struct {
+	data map[string]string
+}
+
+func (c *jsonCache) Get(_ context.Context, key string) (string, error) { return c.data[key], nil }
+func (c *jsonCache) Set(_ context.Context, key, value string, _ int) error {
+	c.data[key] = value
+	return nil
+}
+func (c *jsonCache) Incr(context.Context, string) (int64, error) { return 0, nil }
+func (c *jsonCache) Expire(context.Context, string, int) error   { return nil }
+func (c *jsonCache) Delete(_ context.Context, key string) error  { delete(c.data, key); return nil }
+func (c *jsonCache) GetJSON(ctx context.Context, key string, v interface{}) error {
+	s, _ := c.Get(ctx, key)
+	if s == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(s), v)
+}
+func (c *jsonCache) SetJSON(ctx context.Context, key string, v interface{}, ttl int) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Set(ctx, key, string(b), ttl)
+}
+
+func TestCreditService_GetByID_CacheHitIsTenantScoped(t *testing.T) {
+	c := &jsonCache{data: map[string]string{}}
+	entry := cachedCredit{Credit: &domain.Credit{ID: "cr1", BankID: "bank-b"}, FreshUntil: time.Now().Add(time.Minute)}
+	require.NoError(t, c.SetJSON(context.Background(), creditCacheKeyPrefix+"cr1", entry, 60))
+
+	repoCalls := 0
+	creditRepo := &repomocks.CreditRepository{}
+	creditRepo.GetByIDFunc = func(ctx context.Context, id string) (*domain.Credit, error) {
+		repoCalls++
+		return nil, nil // the scoped repository hides bank-b's credit from bank-a
+	}
+	log, _ := zap.NewDevelopment()
+	svc := NewCreditService(creditRepo, nil, &repomocks.ClientRepository{}, &repomocks.BankRepository{}, c, event.NewMockPublisher(), nil, decision.NewRuleEngine(), log)
+	defer svc.Shutdown()
+
+	got, err := svc.GetByID(tenant.WithBank(context.Background(), "bank-a"), "cr1")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+	assert.Equal(t, 1, repoCalls)
+
+	got, err = svc.GetByID(tenant.WithBank(context.Background(), "bank-b"), "cr1")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, 1, repoCalls, "owner is served from cache")
+}
+
+func TestCreditService_Create_TraceSurvivesWorkerHop(t *testing.T) {
+	rec := &tracing.Recorder{}
+	tracer := tracing.NewTracer(tracing.Config{Exporter: rec})
+	tracing.SetGlobal(tracer)
+	defer tracing.SetGlobal(nil)
+
+	log, _ := zap.NewDevelopment()
+	creditRepo := &repomocks.CreditRepository{}
+	creditRepo.CreateFunc = func(ctx context.Context, input domain.CreateCreditInput, status domain.CreditStatus) (*domain.Credit, error) {
+		return &domain.Credit{ID: "cr1", ClientID: input.ClientID, BankID: input.BankID, Status: status}, nil
+	}
+	creditRepo.UpdateStatusFunc = func(ctx context.Context, id string, status domain.CreditStatus) (*domain.Credit, error) {
+		return &domain.Credit{ID: id, ClientID: "c1", BankID: "b1", Status: status}, nil
+	}
+	clientRepo := &repomocks.ClientRepository{}
+	clientRepo.GetByIDFunc = func(ctx context.Context, id string) (*domain.Client, error) {
+		return &domain.Client{ID: id}, nil
+	}
+	bankRepo := &repomocks.BankRepository{}
+	bankRepo.GetByIDFunc = func(ctx context.Context, id string) (*domain.Bank, error) {
+		return &domain.Bank{ID: id, Type: domain.BankTypePrivate}, nil
+	}
+	publisher := event.NewMockPublisher()
+	engine := decision.NewRuleEngine()
+	engine.RegisterRule(decision.PaymentRangeRule{})
+	svc := NewCreditService(creditRepo, nil, NewClientService(clientRepo, nil), NewBankService(bankRepo, nil), nil, event.WithTracing(publisher), nil, engine, log)
+	defer svc.Shutdown()
+
+	// An incoming request's trace context
+	parent, _ := tracing.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := tracing.ContextWithRemoteParent(context.Background(), parent)
+	_, err := svc.Create(ctx, domain.CreateCreditInput{
+		ClientID: "c1", BankID: "b1", MinPayment: 100, MaxPayment: 500, TermMonths: 12, CreditType: domain.CreditTypeAuto,
+	})
+	require.NoError(t, err)
+	require.NoError(t, tracer.Flush(context.Background()))
+
+	create := rec.Find("credit.create")
+	require.NotNil(t, create)
+	assert.Equal(t, parent.SpanID, create.Parent())
+	for _, name := range []string{"credit.queue_wait", "credit.process"} {
+		span := rec.Find(name)
+		require.NotNil(t, span, name)
+		assert.Equal(t, parent.TraceID, span.SpanContext().TraceID, name)
+		assert.Equal(t, create.SpanContext().SpanID, span.Parent(), name)
+	}
+	process := rec.Find("credit.process")
+	for _, name := range []string{"client.get", "bank.get", "decision.evaluate", "event.publish CreditCreated"} {
+		span := rec.Find(name)
+		require.NotNil(t, span, name)
+		assert.Equal(t, parent.TraceID, span.SpanContext().TraceID, name)
+	}
+	assert.Equal(t, process.SpanContext().SpanID, rec.Find("client.get").Parent())
+
+	// Events carry the traceparent of their publish span
+	for _, evt := range publisher.Events() {
+		sc, ok := tracing.ParseTraceparent(evt.Headers[tracing.TraceparentHeader])
+		require.True(t, ok)
+		assert.Equal(t, parent.TraceID, sc.TraceID)
+	}
+}
+
+func TestCreditService_PingWorkers(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	svc := NewCreditService(&repomocks.CreditRepository{}, nil, &repomocks.ClientRepository{}, &repomocks.BankRepository{}, nil, event.NewMockPublisher(), nil, decision.NewRuleEngine(), log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, svc.PingWorkers(ctx))
+
+	svc.Shutdown()
+	assert.ErrorIs(t, svc.PingWorkers(ctx), ErrWorkersStopped)
+}
+
+func TestCreditService_Redecide(t *testing.T) {
+	log, _ := zap.NewDevelopment()
+	credits := map[string]*domain.Credit{
+		"pending": {ID: "pending", ClientID: "c1", BankID: "b1", MinPayment: 100, MaxPayment: 500, TermMonths: 12,
+			CreditType: domain.CreditTypeAuto, Status: domain.CreditStatusPending},
+		"rejected": {ID: "rejected", ClientID: "c1", BankID: "b1", MinPayment: 100, MaxPayment: 500, TermMonths: 12,
+			CreditType: domain.CreditTypeAuto, Status: domain.CreditStatusRejected},
+	}
+	creditRepo := &repomocks.CreditRepository{}
+	creditRepo.GetByIDFunc = func(ctx context.Context, id string) (*domain.Credit, error) { return credits[id], nil }
+	var updated []string
+	creditRepo.UpdateStatusFunc = func(ctx context.Context, id string, status domain.CreditStatus) (*domain.Credit, error) {
+		updated = append(updated, id)
+		c := *credits[id]
+		c.Status = status
+		return &c, nil
+	}
+	clientRepo := &repomocks.ClientRepository{}
+	clientRepo.GetByIDFunc = func(ctx context.Context, id string) (*domain.Client, error) { return &domain.Client{ID: id}, nil }
+	bankRepo := &repomocks.BankRepository{}
+	bankRepo.GetByIDFunc = func(ctx context.Context, id string) (*domain.Bank, error) {
+		return &domain.Bank{ID: id, Type: domain.BankTypePrivate}, nil
+	}
+	publisher := event.NewMockPublisher()
+	engine := decision.NewRuleEngine()
+	engine.RegisterRule(decision.PaymentRangeRule{})
+	svc := NewCreditService(creditRepo, nil, clientRepo, bankRepo, nil, publisher, nil, engine, log)
+	defer svc.Shutdown()
+
+	// A pending credit that passes is approved and announced
+	out, result, err := svc.Redecide(context.Background(), "pending")
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.True(t, result.Approved)
+	assert.Equal(t, domain.CreditStatusApproved, out.Status)
+	events := publisher.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, domain.EventCreditApproved, events[0].Type)
+
+	// A decided credit is only evaluated
+	out, result, err = svc.Redecide(context.Background(), "rejected")
+	require.NoError(t, err)
+	assert.True(t, result.Approved)
+	assert.Equal(t, domain.CreditStatusRejected, out.Status)
+	assert.Equal(t, []string{"pending"}, updated)
+	assert.Len(t, publisher.Events(), 1)
+
+	out, result, err = svc.Redecide(context.Background(), "missing")
+	require.NoError(t, err)
+	assert.Nil(t, out)
+	assert.Nil(t, result)
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/tucredito/backend-api/internal/cache"
+	"github.com/tucredito/backend-api/internal/metrics"
+	"github.com/tucredito/backend-api/internal/tenant"
+	"github.com/tucredito/backend-api/internal/tracing"
+	"golang.org/x/sync/singleflight"
+)
+
+/*
+	entityCache is the read-through cache behind clientService and bankService lookups
+	One key per ID holds the row and the tenants a scoped load has already shown it to; repository
+	reads are tenant scoped (a client is visible to its owner bank and to banks it holds credits with),
+	so a hit is only served to those tenants and anyone else goes to the repository once
+	Writes delete the key; with a TieredCache the delete is broadcast so other instances drop their local copy
+*/
+
+type entityCache[T any] struct {
+	cache   cache.Cache
+	name    string // metrics label and key prefix
+	flights singleflight.Group
+}
+
+// Cached form of an entity
+type cachedEntity[T any] struct {
+	Value   *T       `json:"value"`
+	Tenants []string `json:"tenants"`
+}
+
+func newEntityCache[T any](c cache.Cache, name string) *entityCache[T] {
+	return &entityCache[T]{cache: c, name: name}
+}
+
+// Returns the entity for id from the cache, or loads it (once per tenant and ID) and caches it
+func (e *entityCache[T]) get(ctx context.Context, id string, load func(ctx context.Context, id string) (*T, error)) (*T, error) {
+	ctx, span := tracing.Start(ctx, e.name+".get")
+	defer span.End()
+
+	store, ok := e.cache.(jsonStore)
+	if !ok {
+		v, err := load(ctx, id)
+		span.RecordError(err)
+		return v, err
+	}
+	label := tenant.Label(ctx)
+	var entry cachedEntity[T]
+	if err := store.GetJSON(ctx, e.key(id), &entry); err == nil && entry.Value != nil && slices.Contains(entry.Tenants, label) {
+		metrics.IncCacheHit(e.name)
+		span.SetAttributes(tracing.Bool("cache.hit", true))
+		return entry.Value, nil
+	}
+	metrics.IncCacheMiss(e.name)
+	span.SetAttributes(tracing.Bool("cache.hit", false))
+
+	leader := false
+	v, err, shared := e.flights.Do(label+":"+id, func() (interface{}, error) {
+		leader = true
+		v, err := load(ctx, id)
+		if err != nil || v == nil {
+			return v, err
+		}
+		// Keep the tenants the cached row was already shown to, unless the row is gone
+		tenants := []string{label}
+		if entry.Value != nil {
+			tenants = append(entry.Tenants, label)
+		}
+		_ = store.SetJSON(ctx, e.key(id), cachedEntity[T]{Value: v, Tenants: tenants}, jitterTTL(cacheTTLSeconds))
+		return v, nil
+	})
+	if shared && !leader {
+		metrics.IncCacheCoalesced(e.name)
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return v.(*T), nil
+}
+
+// Drops the cached entity for id
+func (e *entityCache[T]) invalidate(ctx context.Context, id string) {
+	if e.cache == nil {
+		return
+	}
+	_ = e.cache.Delete(ctx, e.key(id))
+}
+
+func (e *entityCache[T]) key(id string) string {
+	return e.name + ":" + id
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/tucredito/backend-api/internal/audit"
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/event"
+	"github.com/tucredito/backend-api/internal/repository"
+)
+
+// Audit entries read per page while replaying
+const replayPageSize = 500
+
+type eventReplayService struct {
+	auditRepo  repository.AuditRepository
+	creditRepo repository.CreditRepository
+	publisher  event.Publisher
+	outbox     event.Outbox
+}
+
+func NewEventReplayService(auditRepo repository.AuditRepository, creditRepo repository.CreditRepository, publisher event.Publisher, outbox event.Outbox) EventReplayService {
+	if outbox == nil {
+		outbox = discardOutbox{}
+	}
+	return &eventReplayService{
+		auditRepo:  auditRepo,
+		creditRepo: creditRepo,
+		publisher:  publisher,
+		outbox:     outbox,
+	}
+}
+
+/*
+	Rebuilds the credit lifecycle events recorded in [from, to) from the audit trail and publishes them in
+	their original order, storing each in the outbox first; with dryRun they are only returned
+	Events get new IDs, the original time as OccurredAt, the original request as correlation and the
+	HeaderReplay header, so consumers can deduplicate by credit and type
+*/
+
+func (s *eventReplayService) Republish(ctx context.Context, from, to time.Time, dryRun bool) ([]*domain.DomainEvent, error) {
+	if from.IsZero() || !to.After(from) {
+		return nil, ErrInvalidInput
+	}
+	credits := make(map[string]*domain.Credit)
+	events := []*domain.DomainEvent{}
+	for offset := 0; ; offset += replayPageSize {
+		entries, err := s.auditRepo.ListByRange(ctx, domain.AuditEntityCredit, from, to, replayPageSize, offset)
+		if err != nil {
+			return events, err
+		}
+		for _, entry := range entries {
+			status, ok := statusChange(entry)
+			if !ok {
+				continue
+			}
+			credit, ok := credits[entry.EntityID]
+			if !ok {
+				if credit, err = s.creditRepo.GetByID(ctx, entry.EntityID); err != nil {
+					return events, err
+				}
+				credits[entry.EntityID] = credit
+			}
+			if credit == nil {
+				continue
+			}
+			for _, evt := range replayedEvents(ctx, entry, credit, status) {
+				if !dryRun {
+					if err := s.outbox.Enqueue(ctx, evt); err != nil {
+						return events, err
+					}
+					if err := s.publisher.Publish(ctx, evt); err != nil {
+						return events, err
+					}
+				}
+				events = append(events, evt)
+			}
+		}
+		if len(entries) < replayPageSize {
+			return events, nil
+		}
+	}
+}
+
+// Returns the status a credit entered with entry: its initial status on create, the new one on a change
+func statusChange(entry *domain.AuditEntry) (domain.CreditStatus, bool) {
+	var changes map[string]audit.Change
+	if err := json.Unmarshal(entry.Changes, &changes); err != nil {
+		return "", false
+	}
+	change, ok := changes["status"]
+	if !ok {
+		return "", false
+	}
+	after, ok := change.After.(string)
+	if !ok {
+		return "", false
+	}
+	status := domain.CreditStatus(after)
+	if entry.Action == domain.AuditActionCreate {
+		return status, true
+	}
+	return status, status == domain.CreditStatusApproved || status == domain.CreditStatusRejected
+}
+
+// The events the service emitted for entry (see createCredit and recordStatusChange)
+func replayedEvents(ctx context.Context, entry *domain.AuditEntry, c *domain.Credit, status domain.CreditStatus) []*domain.DomainEvent {
+	var events []*domain.DomainEvent
+	emit := func(t domain.EventType, payload interface{}) {
+		evt, err := event.New(ctx, t, payload)
+		if err != nil {
+			return
+		}
+		evt.OccurredAt = entry.OccurredAt
+		if entry.RequestID != "" {
+			evt.CorrelationID, evt.CausationID = entry.RequestID, entry.RequestID
+		}
+		evt.Headers = map[string]string{event.HeaderReplay: "true"}
+		events = append(events, evt)
+	}
+
+	created := entry.Action == domain.AuditActionCreate
+	if created {
+		emit(domain.EventCreditCreated, domain.CreditCreatedPayload{
+			CreditID: c.ID, ClientID: c.ClientID, BankID: c.BankID, CreditType: c.CreditType, Status: status, CreatedAt: c.CreatedAt,
+		})
+	}
+	switch {
+	case status == domain.CreditStatusApproved:
+		emit(domain.EventCreditApproved, domain.CreditApprovedPayload{CreditID: c.ID, ClientID: c.ClientID, BankID: c.BankID, ApprovedAt: entry.OccurredAt})
+	case status == domain.CreditStatusRejected && !created:
+		emit(domain.EventCreditRejected, domain.CreditRejectedPayload{CreditID: c.ID, ClientID: c.ClientID, BankID: c.BankID, RejectedAt: entry.OccurredAt})
+	}
+	return events
+}
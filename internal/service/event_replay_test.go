@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/event"
+	repomocks "github.com/tucredito/backend-api/internal/repository/mocks"
+)
+
+func TestEventReplayService_Republish(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	at := func(m int) time.Time { return from.Add(time.Duration(m) * time.Minute) }
+	entries := []*domain.AuditEntry{
+		{ID: 1, Entity: domain.AuditEntityCredit, EntityID: "cr1", Action: domain.AuditActionCreate, RequestID: "req-1", OccurredAt: at(1),
+			Changes: json.RawMessage(`{"status":{"before":null,"after":"PENDING"},"min_payment":{"before":null,"after":100}}`)},
+		{ID: 2, Entity: domain.AuditEntityCredit, EntityID: "cr1", Action: domain.AuditActionUpdate, OccurredAt: at(2),
+			Changes: json.RawMessage(`{"min_payment":{"before":100,"after":150}}`)},
+		{ID: 3, Entity: domain.AuditEntityCredit, EntityID: "cr1", Action: domain.AuditActionUpdateStatus, RequestID: "req-3", OccurredAt: at(3),
+			Changes: json.RawMessage(`{"status":{"before":"PENDING","after":"REJECTED"}}`)},
+		{ID: 4, Entity: domain.AuditEntityCredit, EntityID: "cr2", Action: domain.AuditActionCreate, OccurredAt: at(4),
+			Changes: json.RawMessage(`{"status":{"before":null,"after":"APPROVED"}}`)},
+		{ID: 5, Entity: domain.AuditEntityCredit, EntityID: "cr2", Action: domain.AuditActionDeactivate, OccurredAt: at(5),
+			Changes: json.RawMessage(`{"is_active":{"before":true,"after":false}}`)},
+	}
+	auditRepo := &repomocks.AuditRepository{}
+	auditRepo.ListByRangeFunc = func(ctx context.Context, entity domain.AuditEntity, gotFrom, gotTo time.Time, limit, offset int) ([]*domain.AuditEntry, error) {
+		assert.Equal(t, domain.AuditEntityCredit, entity)
+		assert.Equal(t, from, gotFrom)
+		assert.Equal(t, to, gotTo)
+		if offset > 0 {
+			return nil, nil
+		}
+		return entries, nil
+	}
+	creditRepo := &repomocks.CreditRepository{}
+	loads := 0
+	creditRepo.GetByIDFunc = func(ctx context.Context, id string) (*domain.Credit, error) {
+		loads++
+		return &domain.Credit{ID: id, ClientID: "c1", BankID: "b1", CreditType: domain.CreditTypeAuto, CreatedAt: at(0)}, nil
+	}
+	publisher := event.NewMockPublisher()
+	svc := NewEventReplayService(auditRepo, creditRepo, publisher, nil)
+
+	events, err := svc.Republish(context.Background(), from, to, true)
+	require.NoError(t, err)
+	assert.Empty(t, publisher.Events(), "a dry run publishes nothing")
+	var types []domain.EventType
+	for _, evt := range events {
+		types = append(types, evt.Type)
+		assert.Equal(t, "true", evt.Headers[event.HeaderReplay])
+	}
+	assert.Equal(t, []domain.EventType{
+		domain.EventCreditCreated, domain.EventCreditRejected, domain.EventCreditCreated, domain.EventCreditApproved,
+	}, types)
+	assert.Equal(t, 2, loads, "each credit is loaded once")
+
+	var created domain.CreditCreatedPayload
+	require.NoError(t, json.Unmarshal(events[0].Payload, &created))
+	assert.Equal(t, domain.CreditStatusPending, created.Status)
+	assert.Equal(t, "b1", created.BankID)
+	assert.Equal(t, at(1), events[0].OccurredAt)
+	assert.Equal(t, "req-1", events[0].CorrelationID)
+	var rejected domain.CreditRejectedPayload
+	require.NoError(t, json.Unmarshal(events[1].Payload, &rejected))
+	assert.Equal(t, at(3), rejected.RejectedAt)
+
+	events, err = svc.Republish(context.Background(), from, to, false)
+	require.NoError(t, err)
+	assert.Len(t, publisher.Events(), len(events))
+}
+
+func TestEventReplayService_Republish_InvalidRange(t *testing.T) {
+	svc := NewEventReplayService(&repomocks.AuditRepository{}, &repomocks.CreditRepository{}, event.NewMockPublisher(), nil)
+	now := time.Now()
+
+	_, err := svc.Republish(context.Background(), now, now, false)
+	assert.Equal(t, ErrInvalidInput, err)
+	_, err = svc.Republish(context.Background(), time.Time{}, now, false)
+	assert.Equal(t, ErrInvalidInput, err)
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"io"
+
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/export"
+	"github.com/tucredito/backend-api/internal/repository"
+)
+
+type exportService struct {
+	repository repository.CreditExportRepository
+}
+
+func NewExportService(repository repository.CreditExportRepository) ExportService {
+	return &exportService{
+		repository: repository,
+	}
+}
+
+// Streams the credit portfolio matching filter to w, one encoded row at a time
+func (s *exportService) ExportCredits(ctx context.Context, format domain.ExportFormat, filter domain.CreditExportFilter, w io.Writer) error {
+	if filter.Status != "" && filter.Status != domain.CreditStatusPending && filter.Status != domain.CreditStatusApproved && filter.Status != domain.CreditStatusRejected {
+		return ErrInvalidInput
+	}
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedTo.After(*filter.CreatedFrom) {
+		return ErrInvalidInput
+	}
+
+	enc, err := export.NewWriter(format, w)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repository.StreamCredits(ctx, filter, enc.Write); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/domain"
+	repomocks "github.com/tucredito/backend-api/internal/repository/mocks"
+)
+
+func TestExportService_ExportCredits(t *testing.T) {
+	var gotFilter domain.CreditExportFilter
+	repo := &repomocks.CreditExportRepository{}
+	repo.StreamCreditsFunc = func(ctx context.Context, filter domain.CreditExportFilter, fn func(row *domain.CreditExportRow) error) error {
+		gotFilter = filter
+		for _, id := range []string{"cr1", "cr2"} {
+			if err := fn(&domain.CreditExportRow{CreditID: id, Status: domain.CreditStatusApproved}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	svc := NewExportService(repo)
+
+	var buf bytes.Buffer
+	err := svc.ExportCredits(context.Background(), domain.ExportFormatNDJSON, domain.CreditExportFilter{ClientID: "c1"}, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, "c1", gotFilter.ClientID)
+	assert.Equal(t, 2, strings.Count(buf.String(), "\n"))
+}
+
+func TestExportService_ExportCredits_InvalidFilter(t *testing.T) {
+	svc := NewExportService(&repomocks.CreditExportRepository{})
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, -1, 0)
+
+	err := svc.ExportCredits(context.Background(), domain.ExportFormatCSV, domain.CreditExportFilter{CreatedFrom: &from, CreatedTo: &to}, &bytes.Buffer{})
+	assert.Equal(t, ErrInvalidInput, err)
+
+	err = svc.ExportCredits(context.Background(), domain.ExportFormatCSV, domain.CreditExportFilter{Status: "DONE"}, &bytes.Buffer{})
+	assert.Equal(t, ErrInvalidInput, err)
+}
+
+func TestExportService_ExportCredits_StopsOnWriteError(t *testing.T) {
+	repo := &repomocks.CreditExportRepository{}
+	repo.StreamCreditsFunc = func(ctx context.Context, filter domain.CreditExportFilter, fn func(row *domain.CreditExportRow) error) error {
+		return fn(&domain.CreditExportRow{CreditID: "cr1"})
+	}
+	svc := NewExportService(repo)
+
+	err := svc.ExportCredits(context.Background(), domain.ExportFormatNDJSON, domain.CreditExportFilter{}, failingWriter{})
+	require.Error(t, err)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/tucredito/backend-api/internal/repository"
+)
+
+// Attempts of a merge patch racing other writers before ErrVersionMismatch is returned
+const patchAttempts = 3
+
+/*
+	Applies a merge patch as read, validate, write
+	validate checks the patch merged onto the current row; the write is then pinned to the version that was
+	validated, so a concurrent change to a field the patch leaves alone can never produce an invalid row.
+	A caller that sent If-Match has pinned the version itself and gets the mismatch; otherwise the patch is
+	re-applied to the fresh row up to patchAttempts times
+	An empty patch writes nothing and returns the current row
+*/
+
+func applyPatch[T any](
+	ctx context.Context,
+	empty bool,
+	load func(ctx context.Context) (*T, error),
+	versionOf func(*T) int,
+	validate func(current *T) error,
+	save func(ctx context.Context) (*T, error),
+) (*T, error) {
+	_, pinned := repository.ExpectedVersions(ctx)
+	for attempt := 1; ; attempt++ {
+		current, err := load(ctx)
+		if err != nil || current == nil {
+			return nil, err
+		}
+		if err := repository.CheckVersion(ctx, versionOf(current)); err != nil {
+			return nil, err
+		}
+		if err := validate(current); err != nil {
+			return nil, err
+		}
+		if empty {
+			return current, nil
+		}
+		if pinned {
+			return save(ctx)
+		}
+		updated, err := save(repository.WithExpectedVersion(ctx, versionOf(current)))
+		if errors.Is(err, repository.ErrVersionMismatch) && attempt < patchAttempts {
+			continue
+		}
+		return updated, err
+	}
+}
@@ -2,7 +2,10 @@ package service
 
 import (
 	"context"
+	"io"
+	"time"
 
+	"github.com/tucredito/backend-api/internal/decision"
 	"github.com/tucredito/backend-api/internal/domain"
 )
 
@@ -11,6 +14,7 @@ type ClientService interface {
 	Create(ctx context.Context, input domain.CreateClientInput) (*domain.Client, error)
 	GetByID(ctx context.Context, id string) (*domain.Client, error)
 	Update(ctx context.Context, id string, input domain.UpdateClientInput) (*domain.Client, error)
+	Patch(ctx context.Context, id string, patch domain.ClientPatch) (*domain.Client, error)
 	Delete(ctx context.Context, id string) (*domain.Client, error)
 	Reenable(ctx context.Context, id string) (*domain.Client, error)
 	List(ctx context.Context, limit, offset int) ([]*domain.Client, error)
@@ -21,21 +25,70 @@ type BankService interface {
 	Create(ctx context.Context, input domain.CreateBankInput) (*domain.Bank, error)
 	GetByID(ctx context.Context, id string) (*domain.Bank, error)
 	Update(ctx context.Context, id string, input domain.UpdateBankInput) (*domain.Bank, error)
+	Patch(ctx context.Context, id string, patch domain.BankPatch) (*domain.Bank, error)
 	Delete(ctx context.Context, id string) (*domain.Bank, error)
 	Reenable(ctx context.Context, id string) (*domain.Bank, error)
 	List(ctx context.Context, limit, offset int) ([]*domain.Bank, error)
 }
 
+// ClientLookup is the client read the credit service needs; satisfied by ClientService (cached) and the repository
+type ClientLookup interface {
+	GetByID(ctx context.Context, id string) (*domain.Client, error)
+}
+
+// BankLookup is the bank read the credit service needs; satisfied by BankService (cached) and the repository
+type BankLookup interface {
+	GetByID(ctx context.Context, id string) (*domain.Bank, error)
+}
+
 // CreditService defines the methods for credit service logic
 type CreditService interface {
 	Create(ctx context.Context, input domain.CreateCreditInput) (*domain.Credit, error)
 	CreateSync(ctx context.Context, input domain.CreateCreditInput) (*domain.Credit, error)
 	GetByID(ctx context.Context, id string) (*domain.Credit, error)
 	Update(ctx context.Context, id string, input domain.UpdateCreditInput) (*domain.Credit, error)
+	Patch(ctx context.Context, id string, patch domain.CreditPatch) (*domain.Credit, error)
 	UpdateStatus(ctx context.Context, id string, status domain.CreditStatus) (*domain.Credit, error)
+	Redecide(ctx context.Context, id string) (*domain.Credit, *decision.EligibilityResult, error)
 	Delete(ctx context.Context, id string) (*domain.Credit, error)
 	Reenable(ctx context.Context, id string) (*domain.Credit, error)
 	List(ctx context.Context, limit, offset int) ([]*domain.Credit, error)
 	ListByClientID(ctx context.Context, clientID string, limit, offset int) ([]*domain.Credit, error)
+	PingWorkers(ctx context.Context) error
 	Shutdown()
 }
+
+// CreditImportService defines the methods for bulk credit import logic
+type CreditImportService interface {
+	Import(ctx context.Context, format domain.ImportFormat, r io.Reader) (*domain.CreditImport, error)
+	GetByID(ctx context.Context, id string) (*domain.CreditImport, error)
+}
+
+// ExportService defines the methods for credit portfolio exports
+type ExportService interface {
+	ExportCredits(ctx context.Context, format domain.ExportFormat, filter domain.CreditExportFilter, w io.Writer) error
+}
+
+// AuditService defines the methods for reading the audit trail
+type AuditService interface {
+	History(ctx context.Context, entity domain.AuditEntity, entityID string, limit, offset int) ([]*domain.AuditEntry, error)
+}
+
+// EventReplayService defines the methods for re-publishing credit lifecycle events
+type EventReplayService interface {
+	Republish(ctx context.Context, from, to time.Time, dryRun bool) ([]*domain.DomainEvent, error)
+}
+
+// WebhookService defines the methods for webhook subscriptions, their delivery log and the delivery dispatcher
+type WebhookService interface {
+	Create(ctx context.Context, input domain.CreateWebhookSubscriptionInput) (*domain.WebhookSubscription, error)
+	GetByID(ctx context.Context, id string) (*domain.WebhookSubscription, error)
+	Update(ctx context.Context, id string, input domain.UpdateWebhookSubscriptionInput) (*domain.WebhookSubscription, error)
+	Delete(ctx context.Context, id string) (*domain.WebhookSubscription, error)
+	List(ctx context.Context, limit, offset int) ([]*domain.WebhookSubscription, error)
+	ListDeliveries(ctx context.Context, subscriptionID string, status domain.WebhookDeliveryStatus, limit, offset int) ([]*domain.WebhookDelivery, error)
+	GetDelivery(ctx context.Context, subscriptionID, id string) (*domain.WebhookDelivery, error)
+	Redeliver(ctx context.Context, subscriptionID, id string) (*domain.WebhookDelivery, error)
+	Enqueue(ctx context.Context, evt *domain.DomainEvent) error
+	Run(ctx context.Context)
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"context"
+
+	"github.com/tucredito/backend-api/internal/domain"
+)
+
+// Runs units of work directly, for services built without a repository.TxManager
+type directTx struct{}
+
+func (directTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// Drops staged events, for services built without an event.Outbox
+type discardOutbox struct{}
+
+func (discardOutbox) Enqueue(context.Context, *domain.DomainEvent) error { return nil }
@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/event"
+	"github.com/tucredito/backend-api/internal/metrics"
+	"github.com/tucredito/backend-api/internal/repository"
+	"github.com/tucredito/backend-api/internal/webhook"
+	"github.com/tucredito/backend-api/pkg/apperr"
+	"github.com/tucredito/backend-api/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// Random bytes of a generated signing secret
+	webhookSecretBytes = 32
+	// How often the dispatcher looks for due deliveries when the last batch was not full
+	webhookPollInterval = time.Second
+	webhookBatchSize    = 50
+	// Attempts in flight at once
+	webhookConcurrency = 8
+)
+
+var ErrWebhookNotFound = apperr.NotFound("webhook subscription not found")
+
+// Retry policy of outbound webhooks
+type WebhookConfig struct {
+	// Attempts before a delivery is dead-lettered
+	MaxAttempts int
+	// First retry delay and upper bound of the exponential backoff
+	RetryBase time.Duration
+	RetryMax  time.Duration
+	// Bound of one attempt, response included
+	Timeout time.Duration
+}
+
+type webhookService struct {
+	repository  repository.WebhookRepository
+	sender      *webhook.Sender
+	backoff     webhook.Backoff
+	maxAttempts int
+	lease       time.Duration
+	log         *zap.Logger
+}
+
+func NewWebhookService(repository repository.WebhookRepository, cfg WebhookConfig, log *zap.Logger) WebhookService {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+	return &webhookService{
+		repository:  repository,
+		sender:      webhook.NewSender(cfg.Timeout),
+		backoff:     webhook.Backoff{Base: cfg.RetryBase, Max: cfg.RetryMax},
+		maxAttempts: cfg.MaxAttempts,
+		// A claimed delivery may wait for every earlier attempt of its batch; it must not be claimed again meanwhile
+		lease: time.Duration(webhookBatchSize/webhookConcurrency+1)*cfg.Timeout + time.Minute,
+		log:   log,
+	}
+}
+
+// Creates a subscription; the secret is generated when none is given and only returned here
+func (s *webhookService) Create(ctx context.Context, input domain.CreateWebhookSubscriptionInput) (*domain.WebhookSubscription, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	if input.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		input.Secret = secret
+	}
+	return s.repository.CreateSubscription(ctx, input)
+}
+
+// Gets a subscription by ID
+func (s *webhookService) GetByID(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	sub, err := s.repository.GetSubscription(ctx, id)
+	return withoutSecret(sub), err
+}
+
+// Updates a subscription; the secret is returned only when it is changed
+func (s *webhookService) Update(ctx context.Context, id string, input domain.UpdateWebhookSubscriptionInput) (*domain.WebhookSubscription, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	sub, err := s.repository.UpdateSubscription(ctx, id, input)
+	if input.Secret == "" {
+		sub = withoutSecret(sub)
+	}
+	return sub, err
+}
+
+// Deletes a subscription with its delivery log; pending deliveries are dropped
+func (s *webhookService) Delete(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	sub, err := s.repository.DeleteSubscription(ctx, id)
+	return withoutSecret(sub), err
+}
+
+// Lists subscriptions with pagination
+func (s *webhookService) List(ctx context.Context, limit, offset int) ([]*domain.WebhookSubscription, error) {
+	list, err := s.repository.ListSubscriptions(ctx, limit, offset)
+	for _, sub := range list {
+		withoutSecret(sub)
+	}
+	return list, err
+}
+
+// Lists a subscription's deliveries, newest first; ErrWebhookNotFound when the subscription is not visible
+func (s *webhookService) ListDeliveries(ctx context.Context, subscriptionID string, status domain.WebhookDeliveryStatus, limit, offset int) ([]*domain.WebhookDelivery, error) {
+	sub, err := s.repository.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		return nil, ErrWebhookNotFound
+	}
+	return s.repository.ListDeliveries(ctx, subscriptionID, status, limit, offset)
+}
+
+// Gets a delivery with its attempt log
+func (s *webhookService) GetDelivery(ctx context.Context, subscriptionID, id string) (*domain.WebhookDelivery, error) {
+	return s.repository.GetDelivery(ctx, subscriptionID, id)
+}
+
+// Queues a delivery again, whatever its status, with a fresh attempt budget; the dispatcher sends it on its next poll
+func (s *webhookService) Redeliver(ctx context.Context, subscriptionID, id string) (*domain.WebhookDelivery, error) {
+	return s.repository.Redeliver(ctx, subscriptionID, id)
+}
+
+/*
+	Queues evt for every active subscription that wants it: the platform's and those of the bank named by the
+	payload's bank_id. This is the credit service's event.Outbox: called inside the unit of work of the change
+	that produced evt, so the deliveries are stored exactly when it commits; they survive restarts and any
+	instance's dispatcher may send them
+*/
+
+func (s *webhookService) Enqueue(ctx context.Context, evt *domain.DomainEvent) error {
+	err := s.enqueue(ctx, evt)
+	if err != nil {
+		logger.FromContext(ctx, s.log).Warn("webhook enqueue failed",
+			zap.String("event_id", evt.ID), zap.String("event_type", string(evt.Type)), zap.Error(err))
+	}
+	return err
+}
+
+func (s *webhookService) enqueue(ctx context.Context, evt *domain.DomainEvent) error {
+	var owner struct {
+		BankID string `json:"bank_id"`
+	}
+	if err := json.Unmarshal(evt.Payload, &owner); err != nil {
+		return err
+	}
+	subs, err := s.repository.Subscribers(ctx, evt.Type, owner.BankID)
+	if err != nil || len(subs) == 0 {
+		return err
+	}
+	body, err := json.Marshal(domain.WebhookEnvelope{
+		ID:            evt.ID,
+		Type:          evt.Type,
+		OccurredAt:    evt.OccurredAt.UTC(),
+		CorrelationID: evt.CorrelationID,
+		Replay:        evt.Headers[event.HeaderReplay] == "true",
+		Data:          evt.Payload,
+	})
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		_, err := s.repository.CreateDelivery(ctx, domain.CreateWebhookDeliveryInput{
+			SubscriptionID: sub.ID, EventID: evt.ID, EventType: evt.Type, Payload: body,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+	Run is the dispatcher: it sends due deliveries until ctx is done
+	It polls every webhookPollInterval, or right away while batches come back full. Claims are leased in the
+	database, so every instance can run a dispatcher without sending a delivery twice
+*/
+
+func (s *webhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+	for ctx.Err() == nil {
+		n, err := s.deliverDue(ctx)
+		if err != nil && ctx.Err() == nil {
+			s.log.Warn("webhook dispatch failed", zap.Error(err))
+		}
+		if n == webhookBatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+}
+
+// Claims one batch of due deliveries and attempts them; returns the batch size
+func (s *webhookService) deliverDue(ctx context.Context) (int, error) {
+	due, err := s.repository.ClaimDue(ctx, webhookBatchSize, s.lease)
+	if err != nil {
+		return 0, err
+	}
+
+	subs := make(map[string]*domain.WebhookSubscription)
+	sem := make(chan struct{}, webhookConcurrency)
+	var wg sync.WaitGroup
+	for _, d := range due {
+		sub, ok := subs[d.SubscriptionID]
+		if !ok {
+			if sub, err = s.repository.GetSubscription(ctx, d.SubscriptionID); err != nil {
+				// The lease runs out and the delivery is claimed again
+				s.log.Warn("webhook subscription lookup failed", zap.String("delivery_id", d.ID), zap.Error(err))
+				continue
+			}
+			subs[d.SubscriptionID] = sub
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(d *domain.WebhookDelivery, sub *domain.WebhookSubscription) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			s.attempt(ctx, d, sub)
+		}(d, sub)
+	}
+	wg.Wait()
+	return len(due), nil
+}
+
+/*
+	Sends one delivery and records the outcome: SUCCEEDED on 2xx, otherwise PENDING with a backoff delay, or
+	DEAD once maxAttempts attempts have failed. Deliveries of a disabled subscription are dead-lettered unsent
+	An attempt cut short by shutdown is not recorded; its lease runs out and it is sent again
+*/
+
+func (s *webhookService) attempt(ctx context.Context, d *domain.WebhookDelivery, sub *domain.WebhookSubscription) {
+	var input domain.RecordWebhookAttemptInput
+	if sub == nil || !sub.IsActive {
+		input = domain.RecordWebhookAttemptInput{Status: domain.WebhookDeliveryDead, Error: "subscription is disabled"}
+	} else {
+		start := time.Now()
+		code, err := s.sender.Send(ctx, webhook.Request{
+			URL: sub.URL, Secret: sub.Secret, DeliveryID: d.ID, EventType: d.EventType, Body: d.Payload,
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		input.Duration = time.Since(start)
+		if code != 0 {
+			input.StatusCode = &code
+		}
+		switch {
+		case err == nil:
+			input.Status = domain.WebhookDeliverySucceeded
+		case d.Attempts+1 >= s.maxAttempts:
+			input.Status, input.Error = domain.WebhookDeliveryDead, err.Error()
+		default:
+			next := time.Now().Add(s.backoff.Delay(d.Attempts + 1))
+			input.Status, input.Error, input.NextAttemptAt = domain.WebhookDeliveryPending, err.Error(), &next
+		}
+	}
+
+	if _, err := s.repository.RecordAttempt(ctx, d.ID, input); err != nil {
+		s.log.Warn("webhook attempt not recorded", zap.String("delivery_id", d.ID), zap.Error(err))
+		return
+	}
+	switch input.Status {
+	case domain.WebhookDeliverySucceeded:
+		metrics.IncWebhookAttempt(string(d.EventType), "succeeded")
+	case domain.WebhookDeliveryDead:
+		metrics.IncWebhookAttempt(string(d.EventType), "dead")
+		s.log.Warn("webhook delivery dead-lettered", zap.String("delivery_id", d.ID),
+			zap.String("subscription_id", d.SubscriptionID), zap.Int("attempts", d.Attempts+1), zap.String("error", input.Error))
+	default:
+		metrics.IncWebhookAttempt(string(d.EventType), "retrying")
+	}
+}
+
+// Clears the secret of a subscription read back through the API
+func withoutSecret(sub *domain.WebhookSubscription) *domain.WebhookSubscription {
+	if sub != nil {
+		sub.Secret = ""
+	}
+	return sub
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/event"
+	"github.com/tucredito/backend-api/internal/repository/memory"
+	"github.com/tucredito/backend-api/internal/tenant"
+	"github.com/tucredito/backend-api/internal/webhook"
+	"github.com/tucredito/backend-api/pkg/apperr"
+	"go.uber.org/zap"
+)
+
+// Records the requests it receives and answers with the next status of statuses (the last one repeats)
+type webhookReceiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	requests []receivedWebhook
+}
+
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+func newWebhookReceiver(t *testing.T, statuses ...int) *webhookReceiver {
+	rcv := &webhookReceiver{statuses: statuses}
+	rcv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rcv.mu.Lock()
+		rcv.requests = append(rcv.requests, receivedWebhook{header: r.Header.Clone(), body: body})
+		status := rcv.statuses[0]
+		if len(rcv.statuses) > 1 {
+			rcv.statuses = rcv.statuses[1:]
+		}
+		rcv.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(rcv.Close)
+	return rcv
+}
+
+func (r *webhookReceiver) received() []receivedWebhook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedWebhook(nil), r.requests...)
+}
+
+func newTestWebhookService(store *memory.Store, cfg WebhookConfig) *webhookService {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = time.Second
+	}
+	svc := NewWebhookService(memory.NewWebhookRepository(store), cfg, zap.NewNop()).(*webhookService)
+	// The receivers listen on loopback
+	svc.sender = webhook.NewUnrestrictedSender(cfg.Timeout)
+	return svc
+}
+
+// Subscribes a test receiver; Create refuses its loopback URL, so the subscription is stored directly
+func subscribe(t *testing.T, ctx context.Context, svc *webhookService, url string, types ...domain.EventType) *domain.WebhookSubscription {
+	t.Helper()
+	secret, err := newWebhookSecret()
+	require.NoError(t, err)
+	sub, err := svc.repository.CreateSubscription(ctx, domain.CreateWebhookSubscriptionInput{URL: url, EventTypes: types, Secret: secret})
+	require.NoError(t, err)
+	return sub
+}
+
+func enqueueApproved(t *testing.T, outbox event.Outbox, creditID, bankID string) *domain.DomainEvent {
+	t.Helper()
+	evt, err := event.New(context.Background(), domain.EventCreditApproved, domain.CreditApprovedPayload{CreditID: creditID, BankID: bankID})
+	require.NoError(t, err)
+	require.NoError(t, outbox.Enqueue(context.Background(), evt))
+	return evt
+}
+
+func TestWebhookService_Subscriptions(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestWebhookService(memory.NewStore(), WebhookConfig{MaxAttempts: 3})
+
+	for _, url := range []string{"ftp://partner.example.com", "http://127.0.0.1:8080/hooks"} {
+		_, err := svc.Create(ctx, domain.CreateWebhookSubscriptionInput{URL: url, EventTypes: []domain.EventType{domain.EventCreditApproved}})
+		e, ok := apperr.As(err)
+		require.True(t, ok, url)
+		assert.Equal(t, apperr.KindValidation, e.Kind, url)
+	}
+
+	created, err := svc.Create(ctx, domain.CreateWebhookSubscriptionInput{URL: "https://partner.example.com/hooks", EventTypes: []domain.EventType{domain.EventCreditApproved}})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Secret, "whsec_"), "a secret is generated and returned once")
+	assert.True(t, created.IsActive)
+
+	got, err := svc.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Empty(t, got.Secret, "reads never return the secret")
+	list, err := svc.List(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Empty(t, list[0].Secret)
+
+	updated, err := svc.Update(ctx, created.ID, domain.UpdateWebhookSubscriptionInput{URL: created.URL, EventTypes: created.EventTypes})
+	require.NoError(t, err)
+	assert.Empty(t, updated.Secret)
+	rotated, err := svc.Update(ctx, created.ID, domain.UpdateWebhookSubscriptionInput{
+		URL: created.URL, EventTypes: created.EventTypes, Secret: "a-partner-chosen-secret",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "a-partner-chosen-secret", rotated.Secret, "a changed secret is echoed back")
+
+	_, err = svc.ListDeliveries(ctx, "missing", "", 10, 0)
+	assert.ErrorIs(t, err, ErrWebhookNotFound)
+}
+
+func TestWebhookService_DeliversSignedEvents(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	bank, err := memory.NewBankRepository(store).Create(ctx, domain.CreateBankInput{Name: "Banco", Type: domain.BankTypePrivate})
+	require.NoError(t, err)
+	svc := newTestWebhookService(store, WebhookConfig{MaxAttempts: 3})
+
+	platformRcv := newWebhookReceiver(t, http.StatusOK)
+	bankRcv := newWebhookReceiver(t, http.StatusNoContent)
+	platform := subscribe(t, ctx, svc, platformRcv.URL, domain.EventCreditApproved, domain.EventCreditRejected)
+	owned := subscribe(t, tenant.WithBank(ctx, bank.ID), svc, bankRcv.URL, domain.EventCreditApproved)
+	subscribe(t, ctx, svc, platformRcv.URL+"/created", domain.EventCreditCreated)
+
+	evt := enqueueApproved(t, svc, "cr-1", bank.ID)
+	enqueueApproved(t, svc, "cr-2", "another-bank")
+
+	n, err := svc.deliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n, "both events reach the platform, only its own reaches the bank")
+	require.Len(t, platformRcv.received(), 2)
+	require.Len(t, bankRcv.received(), 1)
+
+	req := bankRcv.received()[0]
+	assert.NoError(t, webhook.Verify(owned.Secret, req.header, req.body, time.Minute, time.Now()))
+	assert.ErrorIs(t, webhook.Verify(platform.Secret, req.header, req.body, time.Minute, time.Now()), webhook.ErrInvalidSignature)
+	assert.Equal(t, "CreditApproved", req.header.Get(webhook.HeaderEvent))
+	var envelope domain.WebhookEnvelope
+	require.NoError(t, json.Unmarshal(req.body, &envelope))
+	assert.Equal(t, evt.ID, envelope.ID)
+	assert.Equal(t, domain.EventCreditApproved, envelope.Type)
+	assert.JSONEq(t, string(evt.Payload), string(envelope.Data))
+
+	deliveries, err := svc.ListDeliveries(ctx, owned.ID, domain.WebhookDeliverySucceeded, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, req.header.Get(webhook.HeaderDelivery), deliveries[0].ID)
+	delivery, err := svc.GetDelivery(ctx, owned.ID, deliveries[0].ID)
+	require.NoError(t, err)
+	require.Len(t, delivery.AttemptLog, 1)
+	assert.Equal(t, http.StatusNoContent, *delivery.AttemptLog[0].StatusCode)
+
+	n, err = svc.deliverDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "delivered events are not sent again")
+}
+
+func TestWebhookService_RetriesAndDeadLetters(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	// A zero backoff makes every retry due immediately
+	svc := newTestWebhookService(store, WebhookConfig{MaxAttempts: 3})
+
+	flaky := newWebhookReceiver(t, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusOK)
+	down := newWebhookReceiver(t, http.StatusBadGateway)
+	flakySub := subscribe(t, ctx, svc, flaky.URL, domain.EventCreditApproved)
+	downSub := subscribe(t, ctx, svc, down.URL, domain.EventCreditApproved)
+	enqueueApproved(t, svc, "cr-1", "bank-1")
+
+	for i := 0; i < 4; i++ {
+		_, err := svc.deliverDue(ctx)
+		require.NoError(t, err)
+	}
+	assert.Len(t, flaky.received(), 3)
+	assert.Len(t, down.received(), 3, "no attempts after the last one")
+
+	recovered, err := svc.ListDeliveries(ctx, flakySub.ID, "", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, recovered, 1)
+	assert.Equal(t, domain.WebhookDeliverySucceeded, recovered[0].Status)
+	assert.Equal(t, 3, recovered[0].Attempts)
+
+	dead, err := svc.ListDeliveries(ctx, downSub.ID, domain.WebhookDeliveryDead, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, "unexpected status 502", dead[0].LastError)
+	log, err := svc.GetDelivery(ctx, downSub.ID, dead[0].ID)
+	require.NoError(t, err)
+	require.Len(t, log.AttemptLog, 3)
+	assert.Equal(t, []int{1, 2, 3}, []int{log.AttemptLog[0].Attempt, log.AttemptLog[1].Attempt, log.AttemptLog[2].Attempt})
+
+	redelivered, err := svc.Redeliver(ctx, downSub.ID, dead[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.WebhookDeliveryPending, redelivered.Status)
+	_, err = svc.deliverDue(ctx)
+	require.NoError(t, err)
+	assert.Len(t, down.received(), 4, "a redelivery is sent on the next poll")
+}
+
+func TestWebhookService_BackoffAndDisabledSubscriptions(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	svc := newTestWebhookService(store, WebhookConfig{MaxAttempts: 5, RetryBase: time.Hour, RetryMax: 4 * time.Hour})
+
+	down := newWebhookReceiver(t, http.StatusInternalServerError)
+	sub := subscribe(t, ctx, svc, down.URL, domain.EventCreditApproved)
+	enqueueApproved(t, svc, "cr-1", "bank-1")
+
+	before := time.Now()
+	_, err := svc.deliverDue(ctx)
+	require.NoError(t, err)
+	pending, err := svc.ListDeliveries(ctx, sub.ID, domain.WebhookDeliveryPending, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.NotNil(t, pending[0].NextAttemptAt)
+	assert.WithinRange(t, *pending[0].NextAttemptAt, before.Add(30*time.Minute), time.Now().Add(time.Hour), "the first retry waits half to all of RetryBase")
+
+	disabled := false
+	_, err = svc.repository.UpdateSubscription(ctx, sub.ID, domain.UpdateWebhookSubscriptionInput{URL: sub.URL, EventTypes: sub.EventTypes, IsActive: &disabled})
+	require.NoError(t, err)
+	_, err = svc.Redeliver(ctx, sub.ID, pending[0].ID)
+	require.NoError(t, err)
+	_, err = svc.deliverDue(ctx)
+	require.NoError(t, err)
+	assert.Len(t, down.received(), 1, "disabled subscriptions are not called")
+	got, err := svc.GetDelivery(ctx, sub.ID, pending[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.WebhookDeliveryDead, got.Status)
+	assert.Equal(t, "subscription is disabled", got.LastError)
+
+	enqueueApproved(t, svc, "cr-2", "bank-1")
+	all, err := svc.ListDeliveries(ctx, sub.ID, "", 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, all, 1, "disabled subscriptions receive no new deliveries")
+}
@@ -0,0 +1,49 @@
+package tenant
+
+import (
+	"context"
+
+	"github.com/tucredito/backend-api/pkg/apperr"
+)
+
+/*
+	A tenant is a partner bank; requests scoped to a tenant only see and change
+	that bank's data. Requests without a bank scope run as the platform and see everything.
+	Repositories read the scope from the context and apply it to every query.
+*/
+
+// Tenant of principals that are not bound to a bank
+const Platform = "platform"
+
+// Returned when a scoped request tries to create or move data into another tenant
+var ErrCrossTenant = apperr.Forbidden("cross-tenant access denied")
+
+type bankKey struct{}
+
+// Returns a copy of ctx scoped to bankID; Platform or "" leave ctx unscoped
+func WithBank(ctx context.Context, bankID string) context.Context {
+	if bankID == "" || bankID == Platform {
+		return ctx
+	}
+	return context.WithValue(ctx, bankKey{}, bankID)
+}
+
+// Returns the bank ctx is scoped to; ok is false for platform requests
+func BankID(ctx context.Context) (bankID string, ok bool) {
+	bankID, ok = ctx.Value(bankKey{}).(string)
+	return bankID, ok
+}
+
+// Reports whether ctx may access data owned by bankID
+func CanAccessBank(ctx context.Context, bankID string) bool {
+	scoped, ok := BankID(ctx)
+	return !ok || scoped == bankID
+}
+
+// Tenant name for logs and metric labels: the bank ID or Platform
+func Label(ctx context.Context) string {
+	if bankID, ok := BankID(ctx); ok {
+		return bankID
+	}
+	return Platform
+}
@@ -0,0 +1,26 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScope(t *testing.T) {
+	ctx := context.Background()
+	_, ok := BankID(ctx)
+	assert.False(t, ok)
+	assert.True(t, CanAccessBank(ctx, "b1"))
+	assert.Equal(t, Platform, Label(ctx))
+
+	assert.Equal(t, ctx, WithBank(ctx, Platform))
+
+	scoped := WithBank(ctx, "b1")
+	id, ok := BankID(scoped)
+	assert.True(t, ok)
+	assert.Equal(t, "b1", id)
+	assert.True(t, CanAccessBank(scoped, "b1"))
+	assert.False(t, CanAccessBank(scoped, "b2"))
+	assert.Equal(t, "b1", Label(scoped))
+}
@@ -0,0 +1,216 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+/*
+	OTLP/JSON encoding of ExportTraceServiceRequest (opentelemetry-proto, trace/v1)
+	IDs are lowercase hex and 64-bit integers are decimal strings, as the OTLP JSON mapping requires
+*/
+
+// Instrumentation scope reported with every span
+const scopeName = "github.com/tucredito/backend-api/internal/tracing"
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              SpanKind       `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    StatusCode `json:"code,omitempty"`
+	Message string     `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// Encodes spans as one OTLP/JSON export request for serviceName
+func EncodeOTLP(serviceName string, spans []*Span) ([]byte, error) {
+	out := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		start, end := s.Times()
+		code, msg := s.Status()
+		span := otlpSpan{
+			TraceID:           s.sc.TraceID.String(),
+			SpanID:            s.sc.SpanID.String(),
+			Name:              s.name,
+			Kind:              s.kind,
+			StartTimeUnixNano: strconv.FormatInt(start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(end.UnixNano(), 10),
+			Attributes:        otlpAttributes(s.Attributes()),
+			Status:            otlpStatus{Code: code, Message: msg},
+		}
+		if s.parent.IsValid() {
+			span.ParentSpanID = s.parent.String()
+		}
+		out = append(out, span)
+	}
+
+	return json.Marshal(otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: otlpAttributes([]Attribute{String("service.name", serviceName)})},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: scopeName}, Spans: out}},
+	}}})
+}
+
+func otlpAttributes(attrs []Attribute) []otlpKeyValue {
+	out := make([]otlpKeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		var v otlpValue
+		switch x := a.Value.(type) {
+		case string:
+			v.StringValue = &x
+		case bool:
+			v.BoolValue = &x
+		case int64:
+			s := strconv.FormatInt(x, 10)
+			v.IntValue = &s
+		case float64:
+			v.DoubleValue = &x
+		default:
+			s := fmt.Sprint(x)
+			v.StringValue = &s
+		}
+		out = append(out, otlpKeyValue{Key: a.Key, Value: v})
+	}
+	return out
+}
+
+// Posts spans to an OTLP/HTTP collector (e.g. http://localhost:4318/v1/traces) as JSON
+type HTTPExporter struct {
+	endpoint string
+	service  string
+	client   *http.Client
+}
+
+// Creates an exporter for endpoint; a nil client uses http.DefaultClient
+func NewHTTPExporter(endpoint, serviceName string, client *http.Client) *HTTPExporter {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPExporter{endpoint: endpoint, service: serviceName, client: client}
+}
+
+// Sends one export request; non-2xx responses are errors
+func (e *HTTPExporter) Export(ctx context.Context, spans []*Span) error {
+	body, err := EncodeOTLP(e.service, spans)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("otlp export: %s", resp.Status)
+	}
+	return nil
+}
+
+// Writes each batch as one line of OTLP/JSON (the collector's file exporter format)
+type WriterExporter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	service string
+}
+
+// Creates an exporter writing to w
+func NewWriterExporter(w io.Writer, serviceName string) *WriterExporter {
+	return &WriterExporter{w: w, service: serviceName}
+}
+
+// Appends the batch as a JSON line
+func (e *WriterExporter) Export(_ context.Context, spans []*Span) error {
+	body, err := EncodeOTLP(e.service, spans)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err = e.w.Write(append(body, '\n'))
+	return err
+}
+
+// Keeps exported spans in memory, for tests
+type Recorder struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+// Records the batch
+func (r *Recorder) Export(_ context.Context, spans []*Span) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, spans...)
+	return nil
+}
+
+// Returns the recorded spans in export order
+func (r *Recorder) Spans() []*Span {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*Span(nil), r.spans...)
+}
+
+// Returns the first recorded span named name, nil when there is none
+func (r *Recorder) Find(name string) *Span {
+	for _, s := range r.Spans() {
+		if s.name == name {
+			return s
+		}
+	}
+	return nil
+}
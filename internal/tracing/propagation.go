@@ -0,0 +1,60 @@
+package tracing
+
+import "context"
+
+type spanKey struct{}
+type remoteKey struct{}
+
+// Returns a context carrying s as the current span
+func ContextWithSpan(ctx context.Context, s *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, s)
+}
+
+// Returns the current span, nil when there is none
+func SpanFromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// Returns a context carrying a parent received from another process
+func ContextWithRemoteParent(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// Returns the current span's context, else the remote parent's, else the zero SpanContext
+func SpanContextFrom(ctx context.Context) SpanContext {
+	if s := SpanFromContext(ctx); s != nil {
+		return s.sc
+	}
+	sc, _ := ctx.Value(remoteKey{}).(SpanContext)
+	return sc
+}
+
+// Read/write access to propagation headers; http.Header satisfies it
+type Carrier interface {
+	Get(key string) string
+	Set(key, value string)
+}
+
+// Carrier over a plain map, e.g. DomainEvent headers
+type MapCarrier map[string]string
+
+func (m MapCarrier) Get(key string) string { return m[key] }
+
+func (m MapCarrier) Set(key, value string) { m[key] = value }
+
+// Returns ctx with the remote parent from carrier's traceparent; malformed values are ignored
+func Extract(ctx context.Context, carrier Carrier) context.Context {
+	sc, ok := ParseTraceparent(carrier.Get(TraceparentHeader))
+	if !ok {
+		return ctx
+	}
+	return ContextWithRemoteParent(ctx, sc)
+}
+
+// Writes the current trace context to carrier; does nothing outside a trace
+func Inject(ctx context.Context, carrier Carrier) {
+	if sc := SpanContextFrom(ctx); sc.IsValid() {
+		carrier.Set(TraceparentHeader, sc.Traceparent())
+	}
+}
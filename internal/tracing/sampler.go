@@ -0,0 +1,44 @@
+package tracing
+
+import "encoding/binary"
+
+// Decides whether a new span is sampled; parent is the zero SpanContext for root spans
+type Sampler func(parent SpanContext, traceID TraceID) bool
+
+// Samples every span
+func AlwaysSample() Sampler {
+	return func(SpanContext, TraceID) bool { return true }
+}
+
+// Samples nothing
+func NeverSample() Sampler {
+	return func(SpanContext, TraceID) bool { return false }
+}
+
+/*
+	Samples a fraction of traces, decided from the random low 8 bytes of the trace ID
+	so every service sampling with the same ratio keeps the same traces
+*/
+
+func TraceIDRatio(ratio float64) Sampler {
+	switch {
+	case ratio >= 1:
+		return AlwaysSample()
+	case ratio <= 0:
+		return NeverSample()
+	}
+	bound := uint64(ratio * (1 << 63))
+	return func(_ SpanContext, traceID TraceID) bool {
+		return binary.BigEndian.Uint64(traceID[8:])>>1 < bound
+	}
+}
+
+// Follows the parent's decision when there is one and asks root for new traces
+func ParentBased(root Sampler) Sampler {
+	return func(parent SpanContext, traceID TraceID) bool {
+		if parent.IsValid() {
+			return parent.Sampled
+		}
+		return root(parent, traceID)
+	}
+}
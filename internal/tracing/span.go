@@ -0,0 +1,169 @@
+package tracing
+
+import (
+	"sync"
+	"time"
+)
+
+// Role of a span in the trace, with OTLP numbering
+type SpanKind int
+
+const (
+	KindInternal SpanKind = 1
+	KindServer   SpanKind = 2
+	KindClient   SpanKind = 3
+	KindProducer SpanKind = 4
+	KindConsumer SpanKind = 5
+)
+
+// Outcome of a span, with OTLP numbering
+type StatusCode int
+
+const (
+	StatusUnset StatusCode = 0
+	StatusOK    StatusCode = 1
+	StatusError StatusCode = 2
+)
+
+// A key/value annotation; Value is a string, bool, int64 or float64
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// String attribute
+func String(key, value string) Attribute { return Attribute{Key: key, Value: value} }
+
+// Integer attribute
+func Int(key string, value int) Attribute { return Attribute{Key: key, Value: int64(value)} }
+
+// Boolean attribute
+func Bool(key string, value bool) Attribute { return Attribute{Key: key, Value: value} }
+
+// Floating-point attribute
+func Float(key string, value float64) Attribute { return Attribute{Key: key, Value: value} }
+
+/*
+	Span is one timed operation
+	A nil *Span is valid and ignores every call, so instrumented code never checks whether tracing is on
+	Unsampled spans keep their context for propagation but are not exported
+*/
+
+type Span struct {
+	tracer *Tracer
+	name   string
+	kind   SpanKind
+	sc     SpanContext
+	parent SpanID
+	start  time.Time
+
+	mu        sync.Mutex
+	end       time.Time
+	attrs     []Attribute
+	status    StatusCode
+	statusMsg string
+	ended     bool
+}
+
+// Returns the span's identifiers
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+// Adds attributes to the span
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil || !s.sc.Sampled {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.attrs = append(s.attrs, attrs...)
+	}
+}
+
+// Marks the span as failed with err's message; a nil err is ignored
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.SetStatus(StatusError, err.Error())
+}
+
+// Sets the span status
+func (s *Span) SetStatus(code StatusCode, msg string) {
+	if s == nil || !s.sc.Sampled {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.status, s.statusMsg = code, msg
+	}
+}
+
+// Ends the span and hands it to the exporter; later calls are no-ops
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = s.tracer.now()
+	s.mu.Unlock()
+
+	if s.sc.Sampled {
+		s.tracer.enqueue(s)
+	}
+}
+
+// Accessors for exporters; only meaningful once the span has ended
+
+// Returns the span name
+func (s *Span) Name() string { return s.name }
+
+// Returns the span kind
+func (s *Span) Kind() SpanKind { return s.kind }
+
+// Returns the parent span ID, zero for a root span
+func (s *Span) Parent() SpanID { return s.parent }
+
+// Returns the start and end times
+func (s *Span) Times() (start, end time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.start, s.end
+}
+
+// Returns a copy of the attributes
+func (s *Span) Attributes() []Attribute {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Attribute(nil), s.attrs...)
+}
+
+// Returns the value of the attribute key, if set
+func (s *Span) Attribute(key string) (any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.attrs) - 1; i >= 0; i-- {
+		if s.attrs[i].Key == key {
+			return s.attrs[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+// Returns the status code and message
+func (s *Span) Status() (StatusCode, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status, s.statusMsg
+}
@@ -0,0 +1,131 @@
+package tracing
+
+import (
+	"encoding/hex"
+	"math/rand/v2"
+)
+
+/*
+	W3C trace context (https://www.w3.org/TR/trace-context/)
+	traceparent: version "-" trace-id "-" parent-id "-" trace-flags, e.g.
+	00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+*/
+
+// Header carrying the trace context across processes
+const TraceparentHeader = "traceparent"
+
+const (
+	traceparentLen = 55
+	flagSampled    = 0x01
+)
+
+// 16-byte trace identifier, shared by every span of a trace
+type TraceID [16]byte
+
+// 8-byte span identifier
+type SpanID [8]byte
+
+// Returns the lowercase hex form
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+
+// Reports whether t is not all zeros
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+// Returns the lowercase hex form
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+
+// Reports whether s is not all zeros
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+// Identifies a span and carries the sampling decision to its children
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// Reports whether both identifiers are set
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Formats sc as a version 00 traceparent value
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+/*
+	Parses a traceparent value; ok is false for malformed values, version ff and all-zero IDs
+	Versions above 00 are accepted when they start with a valid 00 layout, as the spec requires
+*/
+
+func ParseTraceparent(s string) (sc SpanContext, ok bool) {
+	if len(s) < traceparentLen || (len(s) > traceparentLen && s[traceparentLen] != '-') {
+		return SpanContext{}, false
+	}
+	if s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return SpanContext{}, false
+	}
+	version, ok := decodeLowerHex(s[0:2], 1)
+	if !ok || version[0] == 0xff || (version[0] == 0 && len(s) != traceparentLen) {
+		return SpanContext{}, false
+	}
+	traceID, ok := decodeLowerHex(s[3:35], 16)
+	if !ok {
+		return SpanContext{}, false
+	}
+	spanID, ok := decodeLowerHex(s[36:52], 8)
+	if !ok {
+		return SpanContext{}, false
+	}
+	flags, ok := decodeLowerHex(s[53:55], 1)
+	if !ok {
+		return SpanContext{}, false
+	}
+
+	copy(sc.TraceID[:], traceID)
+	copy(sc.SpanID[:], spanID)
+	sc.Sampled = flags[0]&flagSampled != 0
+	return sc, sc.IsValid()
+}
+
+// Decodes exactly n bytes of lowercase hex (uppercase is invalid in traceparent)
+func decodeLowerHex(s string, n int) ([]byte, bool) {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c >= 'A' && c <= 'F' {
+			return nil, false
+		}
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != n {
+		return nil, false
+	}
+	return b, true
+}
+
+func newTraceID() TraceID {
+	var t TraceID
+	for !t.IsValid() {
+		putUint64(t[:8], rand.Uint64())
+		putUint64(t[8:], rand.Uint64())
+	}
+	return t
+}
+
+func newSpanID() SpanID {
+	var s SpanID
+	for !s.IsValid() {
+		putUint64(s[:], rand.Uint64())
+	}
+	return s
+}
+
+func putUint64(b []byte, v uint64) {
+	for i := 0; i < 8; i++ {
+		b[i] = byte(v >> (56 - 8*i))
+	}
+}
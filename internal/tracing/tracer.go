@@ -0,0 +1,263 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/*
+	Tracer creates spans and exports the sampled ones in batches from a background goroutine
+	The process-wide tracer is set once at startup (SetGlobal) and used through Start, like the metrics package
+	Without a global tracer, or with one that has no exporter, Start returns nil spans and costs almost nothing
+*/
+
+const (
+	defaultQueueSize     = 2048
+	defaultBatchSize     = 512
+	defaultFlushInterval = 5 * time.Second
+)
+
+// Receives batches of ended, sampled spans
+type Exporter interface {
+	Export(ctx context.Context, spans []*Span) error
+}
+
+// Tracer settings
+type Config struct {
+	ServiceName string
+	Sampler     Sampler
+	Exporter    Exporter
+	// Spans buffered before dropping, spans per export, and the longest a span waits for export
+	QueueSize     int
+	BatchSize     int
+	FlushInterval time.Duration
+	// Called with export errors from the background goroutine
+	OnError func(error)
+}
+
+type Tracer struct {
+	service  string
+	sampler  Sampler
+	exporter Exporter
+	onError  func(error)
+	now      func() time.Time
+
+	batchSize int
+	interval  time.Duration
+	queue     chan *Span
+	flushReq  chan chan struct{}
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	dropped   atomic.Int64
+}
+
+// Creates a tracer and starts its export loop when an exporter is configured
+func NewTracer(cfg Config) *Tracer {
+	t := &Tracer{
+		service:   cfg.ServiceName,
+		sampler:   cfg.Sampler,
+		exporter:  cfg.Exporter,
+		onError:   cfg.OnError,
+		now:       time.Now,
+		batchSize: cfg.BatchSize,
+		interval:  cfg.FlushInterval,
+	}
+	if t.sampler == nil {
+		t.sampler = ParentBased(AlwaysSample())
+	}
+	if t.batchSize <= 0 {
+		t.batchSize = defaultBatchSize
+	}
+	if t.interval <= 0 {
+		t.interval = defaultFlushInterval
+	}
+	if t.exporter != nil {
+		size := cfg.QueueSize
+		if size <= 0 {
+			size = defaultQueueSize
+		}
+		t.queue = make(chan *Span, size)
+		t.flushReq = make(chan chan struct{})
+		t.stop = make(chan struct{})
+		t.done = make(chan struct{})
+		go t.run()
+	}
+	return t
+}
+
+// Returns the service name reported with exported spans
+func (t *Tracer) ServiceName() string { return t.service }
+
+// Returns how many spans were dropped because the export queue was full
+func (t *Tracer) Dropped() int64 { return t.dropped.Load() }
+
+// Options for Start
+type SpanOption func(*spanOptions)
+
+type spanOptions struct {
+	kind  SpanKind
+	attrs []Attribute
+	start time.Time
+}
+
+// Sets the span kind (default KindInternal)
+func WithKind(k SpanKind) SpanOption {
+	return func(o *spanOptions) { o.kind = k }
+}
+
+// Sets initial attributes
+func WithAttributes(attrs ...Attribute) SpanOption {
+	return func(o *spanOptions) { o.attrs = append(o.attrs, attrs...) }
+}
+
+// Backdates the span start, for work measured before the span could be created (e.g. queue waits)
+func WithStartTime(t time.Time) SpanOption {
+	return func(o *spanOptions) { o.start = t }
+}
+
+/*
+	Starts a span as a child of the span (or remote parent) in ctx and returns a context carrying it
+	Returns ctx unchanged and a nil span when the tracer exports nowhere
+*/
+
+func (t *Tracer) Start(ctx context.Context, name string, opts ...SpanOption) (context.Context, *Span) {
+	if t == nil || t.exporter == nil {
+		return ctx, nil
+	}
+	o := spanOptions{kind: KindInternal}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.start.IsZero() {
+		o.start = t.now()
+	}
+
+	parent := SpanContextFrom(ctx)
+	sc := SpanContext{TraceID: parent.TraceID, SpanID: newSpanID()}
+	if !parent.IsValid() {
+		sc.TraceID = newTraceID()
+	}
+	sc.Sampled = t.sampler(parent, sc.TraceID)
+
+	s := &Span{tracer: t, name: name, kind: o.kind, sc: sc, parent: parent.SpanID, start: o.start}
+	if sc.Sampled {
+		s.attrs = o.attrs
+	}
+	return ContextWithSpan(ctx, s), s
+}
+
+// Queues an ended span for export, dropping it when the queue is full
+func (t *Tracer) enqueue(s *Span) {
+	select {
+	case t.queue <- s:
+	default:
+		t.dropped.Add(1)
+	}
+}
+
+// Batches queued spans until Shutdown
+func (t *Tracer) run() {
+	defer close(t.done)
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, t.batchSize)
+	export := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := t.exporter.Export(ctx, batch); err != nil && t.onError != nil {
+			t.onError(err)
+		}
+		cancel()
+		batch = make([]*Span, 0, t.batchSize)
+	}
+	drain := func() {
+		for {
+			select {
+			case s := <-t.queue:
+				batch = append(batch, s)
+				if len(batch) >= t.batchSize {
+					export()
+				}
+			default:
+				export()
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case s := <-t.queue:
+			batch = append(batch, s)
+			if len(batch) >= t.batchSize {
+				export()
+			}
+		case <-ticker.C:
+			export()
+		case ack := <-t.flushReq:
+			drain()
+			close(ack)
+		case <-t.stop:
+			drain()
+			return
+		}
+	}
+}
+
+// Exports every span ended so far, waiting until done or ctx expires
+func (t *Tracer) Flush(ctx context.Context) error {
+	if t == nil || t.exporter == nil {
+		return nil
+	}
+	ack := make(chan struct{})
+	select {
+	case t.flushReq <- ack:
+	case <-t.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-ack:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Exports the remaining spans and stops the export loop
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t == nil || t.exporter == nil {
+		return nil
+	}
+	t.closeOnce.Do(func() { close(t.stop) })
+	select {
+	case <-t.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+var global atomic.Pointer[Tracer]
+
+// Sets the process-wide tracer used by Start (nil disables tracing)
+func SetGlobal(t *Tracer) {
+	global.Store(t)
+}
+
+// Returns the process-wide tracer, nil when tracing is disabled
+func Global() *Tracer {
+	return global.Load()
+}
+
+// Starts a span with the process-wide tracer
+func Start(ctx context.Context, name string, opts ...SpanOption) (context.Context, *Span) {
+	return global.Load().Start(ctx, name, opts...)
+}
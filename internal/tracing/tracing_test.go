@@ -0,0 +1,184 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestParseTraceparent(t *testing.T) {
+	sc, ok := ParseTraceparent(sampleTraceparent)
+	require.True(t, ok)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+	assert.True(t, sc.Sampled)
+	assert.Equal(t, sampleTraceparent, sc.Traceparent())
+
+	// Future versions may append fields
+	_, ok = ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra")
+	assert.True(t, ok)
+
+	for _, bad := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", // version 00 has no extra fields
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736_00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47zz-00f067aa0ba902b7-01",
+	} {
+		_, ok := ParseTraceparent(bad)
+		assert.False(t, ok, bad)
+	}
+}
+
+func TestTracer_ParentChildAndFlush(t *testing.T) {
+	rec := &Recorder{}
+	tr := NewTracer(Config{Exporter: rec})
+	defer func() { _ = tr.Shutdown(context.Background()) }()
+
+	ctx, root := tr.Start(context.Background(), "root", WithKind(KindServer))
+	_, child := tr.Start(ctx, "child", WithAttributes(String("k", "v")))
+	child.RecordError(errors.New("boom"))
+	child.End()
+	root.End()
+	root.End() // idempotent
+	require.NoError(t, tr.Flush(context.Background()))
+
+	spans := rec.Spans()
+	require.Len(t, spans, 2)
+	got := rec.Find("child")
+	assert.Equal(t, root.SpanContext().TraceID, got.SpanContext().TraceID)
+	assert.Equal(t, root.SpanContext().SpanID, got.Parent())
+	assert.False(t, rec.Find("root").Parent().IsValid())
+	v, _ := got.Attribute("k")
+	assert.Equal(t, "v", v)
+	code, msg := got.Status()
+	assert.Equal(t, StatusError, code)
+	assert.Equal(t, "boom", msg)
+}
+
+func TestTracer_SamplingFollowsParent(t *testing.T) {
+	rec := &Recorder{}
+	tr := NewTracer(Config{Exporter: rec, Sampler: ParentBased(AlwaysSample())})
+	defer func() { _ = tr.Shutdown(context.Background()) }()
+
+	// An unsampled caller's trace is continued (for propagation) but not exported
+	parent, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	ctx, span := tr.Start(ContextWithRemoteParent(context.Background(), parent), "op")
+	span.End()
+	require.NoError(t, tr.Flush(context.Background()))
+	assert.Empty(t, rec.Spans())
+
+	carrier := MapCarrier{}
+	Inject(ctx, carrier)
+	sc, ok := ParseTraceparent(carrier.Get(TraceparentHeader))
+	require.True(t, ok)
+	assert.Equal(t, parent.TraceID, sc.TraceID)
+	assert.Equal(t, span.SpanContext().SpanID, sc.SpanID)
+	assert.False(t, sc.Sampled)
+}
+
+func TestTraceIDRatio(t *testing.T) {
+	half := TraceIDRatio(0.5)
+	sampled := 0
+	for i := 0; i < 10000; i++ {
+		if half(SpanContext{}, newTraceID()) {
+			sampled++
+		}
+	}
+	assert.InDelta(t, 5000, sampled, 500)
+
+	// The decision depends only on the trace ID
+	id := newTraceID()
+	assert.Equal(t, half(SpanContext{}, id), half(SpanContext{}, id))
+	assert.False(t, TraceIDRatio(0)(SpanContext{}, id))
+	assert.True(t, TraceIDRatio(1)(SpanContext{}, id))
+}
+
+func TestDisabledTracer(t *testing.T) {
+	var tr *Tracer
+	ctx := context.Background()
+	got, span := tr.Start(ctx, "op")
+	assert.Equal(t, ctx, got)
+	assert.Nil(t, span)
+	span.SetAttributes(String("k", "v"))
+	span.RecordError(errors.New("ignored"))
+	span.End()
+
+	// Incoming context still propagates
+	h := http.Header{}
+	h.Set(TraceparentHeader, sampleTraceparent)
+	out := http.Header{}
+	Inject(Extract(ctx, h), out)
+	assert.Equal(t, sampleTraceparent, out.Get(TraceparentHeader))
+}
+
+func TestHTTPExporter_OTLPJSON(t *testing.T) {
+	var body map[string]any
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		raw, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(raw, &body))
+	}))
+	defer collector.Close()
+
+	tr := NewTracer(Config{ServiceName: "svc", Exporter: NewHTTPExporter(collector.URL, "svc", collector.Client())})
+	_, span := tr.Start(context.Background(), "op", WithKind(KindClient), WithAttributes(Int("n", 3), Bool("ok", true)))
+	span.End()
+	require.NoError(t, tr.Shutdown(context.Background()))
+
+	rs := body["resourceSpans"].([]any)[0].(map[string]any)
+	resource := rs["resource"].(map[string]any)["attributes"].([]any)[0].(map[string]any)
+	assert.Equal(t, "service.name", resource["key"])
+	assert.Equal(t, "svc", resource["value"].(map[string]any)["stringValue"])
+
+	s := rs["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)[0].(map[string]any)
+	assert.Equal(t, "op", s["name"])
+	assert.Equal(t, float64(KindClient), s["kind"])
+	assert.Equal(t, span.SpanContext().TraceID.String(), s["traceId"])
+	assert.NotContains(t, s, "parentSpanId")
+	attrs := s["attributes"].([]any)
+	assert.Equal(t, "3", attrs[0].(map[string]any)["value"].(map[string]any)["intValue"])
+	assert.Equal(t, true, attrs[1].(map[string]any)["value"].(map[string]any)["boolValue"])
+}
+
+func TestHTTPExporter_ErrorStatus(t *testing.T) {
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer collector.Close()
+
+	exp := NewHTTPExporter(collector.URL, "svc", collector.Client())
+	tr := NewTracer(Config{Exporter: &Recorder{}})
+	_, span := tr.Start(context.Background(), "op")
+	span.End()
+	err := exp.Export(context.Background(), []*Span{span})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "503")
+}
+
+func TestWriterExporter_OneLinePerBatch(t *testing.T) {
+	var out strings.Builder
+	tr := NewTracer(Config{Exporter: NewWriterExporter(&out, "svc")})
+	for i := 0; i < 3; i++ {
+		_, span := tr.Start(context.Background(), "op")
+		span.End()
+	}
+	require.NoError(t, tr.Shutdown(context.Background()))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 1)
+	assert.Equal(t, 3, strings.Count(lines[0], `"name":"op"`))
+}
@@ -0,0 +1,224 @@
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tucredito/backend-api/pkg/apperr"
+)
+
+/*
+	Declarative input validation
+	Each field is described once as a chain of rules; every failing field is collected, so a client gets all
+	of its mistakes in one response instead of fixing them one request at a time. Only the first failing rule
+	of a field is reported, since later ones usually restate the same problem
+	Err returns an apperr validation error (400 with the field list) or nil
+*/
+
+// Field error codes
+const (
+	CodeRequired      = "required"
+	CodeTooLong       = "too_long"
+	CodeInvalidFormat = "invalid_format"
+	CodeOutOfRange    = "out_of_range"
+	CodeNotAllowed    = "not_allowed"
+)
+
+// Collects field errors for one input
+type Validator struct {
+	errs []apperr.FieldError
+}
+
+func New() *Validator {
+	return &Validator{}
+}
+
+// Records a field error unless the field already has one
+func (v *Validator) Add(field, code, msg string) {
+	for _, e := range v.errs {
+		if e.Field == field {
+			return
+		}
+	}
+	v.errs = append(v.errs, apperr.Field(field, code, msg))
+}
+
+// Records a field error when ok is false
+func (v *Validator) Check(ok bool, field, code, msg string) {
+	if !ok {
+		v.Add(field, code, msg)
+	}
+}
+
+// Returns the collected field errors
+func (v *Validator) Fields() []apperr.FieldError {
+	return v.errs
+}
+
+// Returns nil, or a validation error carrying every field error
+func (v *Validator) Err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return apperr.Validation("request has invalid fields", v.errs...)
+}
+
+// Rules for a string field
+type StringField struct {
+	v     *Validator
+	name  string
+	value string
+}
+
+func (v *Validator) String(name, value string) *StringField {
+	return &StringField{v: v, name: name, value: value}
+}
+
+// Fails on an empty or blank value
+func (f *StringField) Required() *StringField {
+	f.v.Check(strings.TrimSpace(f.value) != "", f.name, CodeRequired, "is required")
+	return f
+}
+
+// Fails when the value has more than n characters
+func (f *StringField) MaxLen(n int) *StringField {
+	f.v.Check(utf8.RuneCountInString(f.value) <= n, f.name, CodeTooLong, fmt.Sprintf("must be at most %d characters", n))
+	return f
+}
+
+// Fails unless the value is a bare address (no display name), e.g. jane@example.com
+func (f *StringField) Email() *StringField {
+	if f.value == "" {
+		return f
+	}
+	addr, err := mail.ParseAddress(f.value)
+	f.v.Check(err == nil && addr.Address == f.value && strings.Contains(addr.Address[strings.LastIndex(addr.Address, "@"):], "."),
+		f.name, CodeInvalidFormat, "must be a valid email address")
+	return f
+}
+
+// Fails unless the value is an ISO 3166-1 alpha-2 country code (upper case)
+func (f *StringField) Country() *StringField {
+	if f.value == "" {
+		return f
+	}
+	f.v.Check(IsCountry(f.value), f.name, CodeInvalidFormat, "must be an ISO 3166-1 alpha-2 country code")
+	return f
+}
+
+// Fails unless the value is an absolute http or https URL with a host, e.g. https://partner.example.com/hooks
+func (f *StringField) URL() *StringField {
+	if f.value == "" {
+		return f
+	}
+	u, err := url.Parse(f.value)
+	f.v.Check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && u.User == nil,
+		f.name, CodeInvalidFormat, "must be an absolute http or https URL")
+	return f
+}
+
+/*
+	Fails when the URL's host is localhost or an IP address that is not public (see IsPublicAddr)
+	Host names are only resolved when dialed, so callers reaching the URL must check the address again then
+*/
+
+func (f *StringField) PublicHost() *StringField {
+	u, err := url.Parse(f.value)
+	if f.value == "" || err != nil {
+		return f
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	public := host != "localhost" && !strings.HasSuffix(host, ".localhost")
+	if addr, err := netip.ParseAddr(host); err == nil {
+		public = IsPublicAddr(addr)
+	}
+	f.v.Check(public, f.name, CodeNotAllowed, "must not point at a loopback, private or reserved address")
+	return f
+}
+
+// Fails unless the value is one of allowed (empty values are left to Required)
+func (f *StringField) OneOf(allowed ...string) *StringField {
+	if f.value == "" {
+		return f
+	}
+	for _, a := range allowed {
+		if f.value == a {
+			return f
+		}
+	}
+	f.v.Add(f.name, CodeNotAllowed, "must be one of "+strings.Join(allowed, ", "))
+	return f
+}
+
+// Rules for a numeric field
+type NumberField struct {
+	v     *Validator
+	name  string
+	value float64
+}
+
+func (v *Validator) Number(name string, value float64) *NumberField {
+	return &NumberField{v: v, name: name, value: value}
+}
+
+func (v *Validator) Int(name string, value int) *NumberField {
+	return &NumberField{v: v, name: name, value: float64(value)}
+}
+
+// Fails unless value > 0
+func (f *NumberField) Positive() *NumberField {
+	f.v.Check(f.value > 0, f.name, CodeOutOfRange, "must be greater than 0")
+	return f
+}
+
+// Fails when value < min
+func (f *NumberField) Min(min float64) *NumberField {
+	f.v.Check(f.value >= min, f.name, CodeOutOfRange, "must be at least "+formatNumber(min))
+	return f
+}
+
+// Fails when value > max
+func (f *NumberField) Max(max float64) *NumberField {
+	f.v.Check(f.value <= max, f.name, CodeOutOfRange, "must be at most "+formatNumber(max))
+	return f
+}
+
+// Rules for a date/time field
+type TimeField struct {
+	v     *Validator
+	name  string
+	value time.Time
+	now   time.Time
+}
+
+func (v *Validator) Time(name string, value time.Time) *TimeField {
+	return &TimeField{v: v, name: name, value: value, now: time.Now()}
+}
+
+// Fails on the zero time
+func (f *TimeField) Required() *TimeField {
+	f.v.Check(!f.value.IsZero(), f.name, CodeRequired, "is required")
+	return f
+}
+
+// Fails unless the value is a birth date giving an age between minAge and maxAge years today
+func (f *TimeField) Age(minAge, maxAge int) *TimeField {
+	if f.value.IsZero() {
+		return f
+	}
+	latest := f.now.AddDate(-minAge, 0, 0)
+	earliest := f.now.AddDate(-maxAge, 0, 0)
+	f.v.Check(!f.value.After(latest) && !f.value.Before(earliest), f.name, CodeOutOfRange,
+		fmt.Sprintf("must be a birth date for an age between %d and %d", minAge, maxAge))
+	return f
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
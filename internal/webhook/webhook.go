@@ -0,0 +1,200 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/tucredito/backend-api/internal/domain"
+	"github.com/tucredito/backend-api/internal/validate"
+)
+
+/*
+	Outbound webhook transport: signing, sending and retry timing
+	Every request is a JSON POST carrying the event type, the delivery ID, a Unix timestamp and the signature
+	v1=hex(HMAC-SHA256(secret, timestamp + "." + body)). Receivers recompute it over the raw body and reject
+	timestamps outside a tolerance (see Verify), so a captured request cannot be replayed later
+	The signature is computed per attempt, so retries carry a fresh timestamp
+	Subscribers choose the URLs, so the sender only connects to public addresses, checked when dialing (after
+	DNS resolution, so a public name pointing at an internal host is refused too), and never keeps response
+	bodies: only the status code of an attempt is recorded
+*/
+
+const (
+	HeaderEvent     = "X-TuCredito-Event"
+	HeaderDelivery  = "X-TuCredito-Delivery"
+	HeaderTimestamp = "X-TuCredito-Timestamp"
+	HeaderSignature = "X-TuCredito-Signature"
+
+	signatureVersion = "v1"
+	userAgent        = "TuCredito-Webhooks/1.0"
+	// Response bytes drained so the connection can be reused
+	maxDrainBody = 64 << 10
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrStaleTimestamp   = errors.New("webhook timestamp outside tolerance")
+	// The destination resolved to a loopback, private or reserved address (see validate.IsPublicAddr)
+	ErrForbiddenDestination = errors.New("webhook destination address not allowed")
+)
+
+// Signature header value for body sent at timestamp
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+/*
+	Checks a received request the way partners should: the signature must match body and the timestamp header,
+	and the timestamp must be within tolerance of now
+	The signature header may list several comma-separated values (e.g. during a secret rotation on the receiver)
+*/
+
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration, now time.Time) error {
+	unix, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	timestamp := time.Unix(unix, 0)
+	if d := now.Sub(timestamp); d > tolerance || d < -tolerance {
+		return ErrStaleTimestamp
+	}
+	want := Sign(secret, timestamp, body)
+	for _, got := range strings.Split(header.Get(HeaderSignature), ",") {
+		if hmac.Equal([]byte(strings.TrimSpace(got)), []byte(want)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// One delivery attempt
+type Request struct {
+	URL        string
+	Secret     string
+	DeliveryID string
+	EventType  domain.EventType
+	Body       []byte
+}
+
+// Posts signed webhook requests
+type Sender struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+// timeout bounds a whole attempt, including reading the response; redirects are not followed
+func NewSender(timeout time.Duration) *Sender {
+	return newSender(timeout, validate.IsPublicAddr)
+}
+
+// Like NewSender but also connects to loopback and private addresses; for tests and local receivers only
+func NewUnrestrictedSender(timeout time.Duration) *Sender {
+	return newSender(timeout, nil)
+}
+
+func newSender(timeout time.Duration, allow func(netip.Addr) bool) *Sender {
+	dialer := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}
+	if allow != nil {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			addr, err := netip.ParseAddrPort(address)
+			if err != nil || !allow(addr.Addr()) {
+				return ErrForbiddenDestination
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Always dial the destination itself, so Control sees its address rather than a proxy's
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &Sender{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		now: time.Now,
+	}
+}
+
+/*
+	Sends req and returns the response status (0 when none was received)
+	Any status other than 2xx is an error naming only the status; the response body is discarded
+*/
+
+func (s *Sender) Send(ctx context.Context, req Request) (int, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return 0, err
+	}
+	now := s.now()
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", userAgent)
+	httpReq.Header.Set(HeaderEvent, string(req.EventType))
+	httpReq.Header.Set(HeaderDelivery, req.DeliveryID)
+	httpReq.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	httpReq.Header.Set(HeaderSignature, Sign(req.Secret, now, req.Body))
+
+	resp, err := s.client.Do(httpReq)
+	if errors.Is(err, ErrForbiddenDestination) {
+		// The dial error names the resolved address, which must not reach the subscriber's delivery log
+		return 0, ErrForbiddenDestination
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBody))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Exponential retry delays: Base, 2*Base, 4*Base... capped at Max
+type Backoff struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+/*
+	Delay before retry n (1 for the retry after the first failure), with "equal jitter": half of the
+	exponential delay is fixed and half random, so deliveries that failed together during a receiver's outage
+	do not all come back at the same instant
+*/
+
+func (b Backoff) Delay(n int) time.Duration {
+	d := b.Base
+	for i := 1; i < n && d < b.Max; i++ {
+		d *= 2
+	}
+	if d > b.Max {
+		d = b.Max
+	}
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
@@ -0,0 +1,112 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tucredito/backend-api/internal/domain"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Unix(1_760_000_000, 0)
+	body := []byte(`{"id":"evt-1"}`)
+	header := http.Header{}
+	header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	header.Set(HeaderSignature, Sign("secret", now, body))
+
+	assert.NoError(t, Verify("secret", header, body, time.Minute, now.Add(30*time.Second)))
+	assert.ErrorIs(t, Verify("other", header, body, time.Minute, now), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("secret", header, []byte(`{"id":"evt-2"}`), time.Minute, now), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("secret", header, body, time.Minute, now.Add(2*time.Minute)), ErrStaleTimestamp)
+
+	// The timestamp is signed: moving it forward invalidates the signature
+	header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix()+90, 10))
+	assert.ErrorIs(t, Verify("secret", header, body, time.Minute, now.Add(90*time.Second)), ErrInvalidSignature)
+
+	header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	header.Set(HeaderSignature, "v1=deadbeef, "+Sign("secret", now, body))
+	assert.NoError(t, Verify("secret", header, body, time.Minute, now), "any listed signature may match")
+}
+
+func TestSender_Send(t *testing.T) {
+	body := []byte(`{"id":"evt-1","type":"CreditApproved"}`)
+	var received http.Header
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ := io.ReadAll(r.Body)
+		received = r.Header.Clone()
+		if err := Verify("secret", r.Header, got, time.Minute, time.Now()); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	sender := NewUnrestrictedSender(time.Second)
+	req := Request{URL: receiver.URL, Secret: "secret", DeliveryID: "dlv-1", EventType: domain.EventCreditApproved, Body: body}
+	status, err := sender.Send(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, status)
+	assert.Equal(t, "CreditApproved", received.Get(HeaderEvent))
+	assert.Equal(t, "dlv-1", received.Get(HeaderDelivery))
+	assert.Equal(t, "application/json", received.Get("Content-Type"))
+
+	req.Secret = "wrong"
+	status, err = sender.Send(context.Background(), req)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.EqualError(t, err, "unexpected status 401", "the response body is not recorded")
+}
+
+func TestSender_RefusesNonPublicDestinations(t *testing.T) {
+	hit := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+	}))
+	defer receiver.Close()
+
+	sender := NewSender(time.Second)
+	for _, url := range []string{receiver.URL, strings.Replace(receiver.URL, "127.0.0.1", "localhost", 1)} {
+		status, err := sender.Send(context.Background(), Request{URL: url, Body: []byte(`{}`)})
+		assert.Zero(t, status, url)
+		assert.Equal(t, ErrForbiddenDestination, err, "checked at dial time, without naming the address: "+url)
+	}
+	assert.False(t, hit)
+}
+
+func TestSender_SendFailures(t *testing.T) {
+	redirect := httptest.NewServer(http.RedirectHandler("https://elsewhere.example.com", http.StatusFound))
+	defer redirect.Close()
+	status, err := NewUnrestrictedSender(time.Second).Send(context.Background(), Request{URL: redirect.URL, Body: []byte(`{}`)})
+	assert.Equal(t, http.StatusFound, status, "redirects are not followed")
+	assert.Error(t, err)
+
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+	defer close(release)
+	status, err = NewUnrestrictedSender(50*time.Millisecond).Send(context.Background(), Request{URL: slow.URL, Body: []byte(`{}`)})
+	assert.Zero(t, status)
+	assert.Error(t, err, "the timeout bounds an attempt")
+}
+
+func TestBackoff_Delay(t *testing.T) {
+	b := Backoff{Base: 10 * time.Second, Max: time.Minute}
+	for n, full := range map[int]time.Duration{1: 10 * time.Second, 2: 20 * time.Second, 3: 40 * time.Second, 4: time.Minute, 50: time.Minute} {
+		for i := 0; i < 100; i++ {
+			d := b.Delay(n)
+			assert.GreaterOrEqual(t, d, full/2, "retry %d", n)
+			assert.LessOrEqual(t, d, full, "retry %d", n)
+		}
+	}
+	assert.Zero(t, Backoff{}.Delay(3))
+}
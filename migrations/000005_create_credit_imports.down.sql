@@ -0,0 +1,4 @@
+-- 000005_create_credit_imports.down.sql
+
+-- Drop the credit imports table
+DROP TABLE IF EXISTS credit_imports;
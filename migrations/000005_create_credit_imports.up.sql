@@ -0,0 +1,16 @@
+-- 000005_create_credit_imports.up.sql
+
+-- Bulk credit imports with their per-row report
+CREATE TABLE IF NOT EXISTS credit_imports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    format VARCHAR(10) NOT NULL CHECK (format IN ('CSV', 'NDJSON')),
+    status VARCHAR(20) NOT NULL DEFAULT 'PROCESSING' CHECK (status IN ('PROCESSING', 'COMPLETED')),
+    total INT NOT NULL DEFAULT 0,
+    succeeded INT NOT NULL DEFAULT 0,
+    failed INT NOT NULL DEFAULT 0,
+    results JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_credit_imports_created_at ON credit_imports(created_at);
@@ -0,0 +1,6 @@
+-- 000006_create_audit_log.down.sql
+
+-- Drop the audit trail
+DROP TRIGGER IF EXISTS audit_log_no_update_delete ON audit_log;
+DROP FUNCTION IF EXISTS audit_log_append_only();
+DROP TABLE IF EXISTS audit_log;
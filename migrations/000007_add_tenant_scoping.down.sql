@@ -0,0 +1,8 @@
+-- 000007_add_tenant_scoping.down.sql
+
+-- Drop tenant scoping columns and indexes
+DROP INDEX IF EXISTS idx_credit_imports_bank_id;
+DROP INDEX IF EXISTS idx_credits_bank_id_client_id;
+DROP INDEX IF EXISTS idx_clients_owner_bank_id;
+ALTER TABLE credit_imports DROP COLUMN IF EXISTS bank_id;
+ALTER TABLE clients DROP COLUMN IF EXISTS owner_bank_id;
@@ -0,0 +1,12 @@
+-- 000007_add_tenant_scoping.up.sql
+
+-- Bank that onboarded the client (NULL for platform-created clients)
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS owner_bank_id UUID REFERENCES banks(id) ON DELETE RESTRICT;
+
+-- Bank that ran the import (NULL for platform imports)
+ALTER TABLE credit_imports ADD COLUMN IF NOT EXISTS bank_id UUID REFERENCES banks(id) ON DELETE RESTRICT;
+
+-- Tenant-scoped lookups
+CREATE INDEX IF NOT EXISTS idx_clients_owner_bank_id ON clients(owner_bank_id);
+CREATE INDEX IF NOT EXISTS idx_credits_bank_id_client_id ON credits(bank_id, client_id);
+CREATE INDEX IF NOT EXISTS idx_credit_imports_bank_id ON credit_imports(bank_id);
@@ -0,0 +1,4 @@
+-- 000008_add_audit_client_ip.down.sql
+
+-- Drop the audit client address
+ALTER TABLE audit_log DROP COLUMN IF EXISTS client_ip;
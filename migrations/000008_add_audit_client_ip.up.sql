@@ -0,0 +1,4 @@
+-- 000008_add_audit_client_ip.up.sql
+
+-- Resolved client address of the request that made the change ('' for CLI and background jobs)
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS client_ip VARCHAR(45) NOT NULL DEFAULT '';
@@ -0,0 +1,6 @@
+-- 000009_add_row_versions.down.sql
+
+-- Drop the row versions
+ALTER TABLE credits DROP COLUMN IF EXISTS version;
+ALTER TABLE banks DROP COLUMN IF EXISTS version;
+ALTER TABLE clients DROP COLUMN IF EXISTS version;
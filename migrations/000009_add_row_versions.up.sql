@@ -0,0 +1,6 @@
+-- 000009_add_row_versions.up.sql
+
+-- Row versions for optimistic concurrency (sent as ETag, checked against If-Match); every update increments them
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE banks ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE credits ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
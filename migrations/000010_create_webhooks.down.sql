@@ -0,0 +1,7 @@
+-- 000010_create_webhooks.down.sql
+
+-- Drop the webhook tables
+DROP TABLE IF EXISTS webhook_delivery_attempts;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TRIGGER IF EXISTS update_webhook_subscriptions_updated_at ON webhook_subscriptions;
+DROP TABLE IF EXISTS webhook_subscriptions;
@@ -0,0 +1,9 @@
+// Package migrations embeds the SQL migrations so the server binary can apply them (see postgres.Migrator).
+package migrations
+
+import "embed"
+
+// The numbered up/down migrations in golang-migrate's file layout
+//
+//go:embed *.sql
+var FS embed.FS
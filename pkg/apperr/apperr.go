@@ -0,0 +1,150 @@
+package apperr
+
+import (
+	"errors"
+	"time"
+)
+
+/*
+	Typed application errors
+	Repositories and services return *Error for every failure a caller can act on; httputil.WriteError maps the
+	Kind to an HTTP status and writes Message and Fields, never the wrapped cause. Anything that is not an *Error
+	is treated as internal and answered with a generic message
+*/
+
+// Category of failure; each maps to one HTTP status
+type Kind uint8
+
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindConflict
+	KindValidation
+	KindPreconditionFailed
+	KindForbidden
+	KindUnavailable
+	KindTooLarge
+	KindPreconditionRequired
+)
+
+// Machine-readable code for k, sent as the problem's "code"
+func (k Kind) Code() string {
+	switch k {
+	case KindNotFound:
+		return "NOT_FOUND"
+	case KindConflict:
+		return "CONFLICT"
+	case KindValidation:
+		return "VALIDATION"
+	case KindPreconditionFailed:
+		return "PRECONDITION_FAILED"
+	case KindForbidden:
+		return "FORBIDDEN"
+	case KindUnavailable:
+		return "UNAVAILABLE"
+	case KindTooLarge:
+		return "PAYLOAD_TOO_LARGE"
+	case KindPreconditionRequired:
+		return "PRECONDITION_REQUIRED"
+	default:
+		return "INTERNAL"
+	}
+}
+
+// A problem with one input field; Code is machine-readable (e.g. "required", "too_long"), Message is for people
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type Error struct {
+	Kind       Kind
+	Code       string        // overrides Kind.Code() when set, e.g. "INVALID_JSON"
+	Message    string        // safe to show to API clients
+	Fields     []FieldError  // validation failures per field
+	RetryAfter time.Duration // sent as Retry-After when set
+	Err        error         // cause; logged, never shown
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Returns the *Error in err's chain, if any
+func As(err error) (*Error, bool) {
+	var e *Error
+	ok := errors.As(err, &e)
+	return e, ok
+}
+
+// Returns the code sent to clients for e
+func (e *Error) ErrorCode() string {
+	if e.Code != "" {
+		return e.Code
+	}
+	return e.Kind.Code()
+}
+
+// Returns the Kind of err; errors that are not *Error are KindInternal
+func KindOf(err error) Kind {
+	if e, ok := As(err); ok {
+		return e.Kind
+	}
+	return KindInternal
+}
+
+// The requested resource does not exist (or is not visible to the caller)
+func NotFound(msg string) *Error {
+	return &Error{Kind: KindNotFound, Message: msg}
+}
+
+// The request conflicts with the current state, e.g. a duplicate unique value
+func Conflict(msg string, fields ...FieldError) *Error {
+	return &Error{Kind: KindConflict, Message: msg, Fields: fields}
+}
+
+// The input is invalid; fields lists the offending fields
+func Validation(msg string, fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Message: msg, Fields: fields}
+}
+
+// A request precondition (e.g. If-Match) does not hold
+func PreconditionFailed(msg string) *Error {
+	return &Error{Kind: KindPreconditionFailed, Message: msg}
+}
+
+// The request must be conditional (e.g. carry If-Match) and is not
+func PreconditionRequired(msg string) *Error {
+	return &Error{Kind: KindPreconditionRequired, Message: msg}
+}
+
+// The caller may not perform the operation
+func Forbidden(msg string) *Error {
+	return &Error{Kind: KindForbidden, Message: msg}
+}
+
+// A dependency is down or overloaded; cause is kept for logs
+func Unavailable(msg string, cause error) *Error {
+	return &Error{Kind: KindUnavailable, Message: msg, Err: cause}
+}
+
+// An unexpected failure; cause is kept for logs and msg is what the client sees
+func Internal(msg string, cause error) *Error {
+	return &Error{Kind: KindInternal, Message: msg, Err: cause}
+}
+
+// The request body exceeds the accepted size
+func TooLarge(msg string) *Error {
+	return &Error{Kind: KindTooLarge, Message: msg}
+}
+
+// Shorthand for a FieldError
+func Field(name, code, msg string) FieldError {
+	return FieldError{Field: name, Code: code, Message: msg}
+}
@@ -3,17 +3,59 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 )
 
 // The application configuration from environment.
 type Config struct {
-	HTTPPort     int
+	HTTPPort int
+	// Repository backend: postgres, or memory for a database-free dev mode (data is lost on restart)
+	Storage      string
 	DBConnString string
 	RedisAddr    string
 	RedisPass    string
 	RedisDB      int
 	LogLevel     string
 	PProfEnabled bool
+	AuthDisabled bool
+	JWTSecret    string
+	JWKSFile     string
+	JWTIssuer    string
+	JWTAudience  string
+	APIKeys      string
+	// Default rate limit per caller: requests per window
+	RateLimitMaxRequests   int
+	RateLimitWindowSeconds int
+	// Per-bank request budgets ("bankID=limit,..."), overriding the default rate limit
+	TenantRateLimits map[string]int
+	// Per-principal budgets ("subject=limit,...") and per-route budgets ("METHOD /path=limit[/seconds],...")
+	PrincipalRateLimits map[string]int
+	RouteRateLimits     string
+	// Client IP resolution: trusted proxy CIDRs and allow/deny lists (comma-separated IPs/CIDRs)
+	TrustedProxies string
+	IPAllowlist    string
+	IPDenylist     string
+	// The one forwarding header the trusted proxies set (X-Forwarded-For or Forwarded); the other is ignored
+	ForwardedHeader string
+	// In-process cache tier: max keys and TTL cap in seconds; RemoteTTL caps Redis TTLs (0 = as requested)
+	CacheLocalCapacity int
+	CacheLocalTTL      int
+	CacheRemoteTTL     int
+	// Span export: none, otlp (to TracingEndpoint) or file (appended to TracingFile); share of new traces sampled
+	TracingExporter    string
+	TracingEndpoint    string
+	TracingFile        string
+	TracingSampleRatio float64
+	TracingService     string
+	// Require If-Match on PUT/PATCH/DELETE of clients, banks and credits (428 without it)
+	RequireIfMatch bool
+	// Apply the embedded migrations at startup, under an advisory lock so replicas can start together
+	AutoMigrate bool
+	// Outbound webhooks: attempts before dead-lettering, backoff base and cap, and per-attempt timeout in seconds
+	WebhookMaxAttempts      int
+	WebhookRetryBaseSeconds int
+	WebhookRetryMaxSeconds  int
+	WebhookTimeoutSeconds   int
 }
 
 // Reads configuration from environment variables.
@@ -25,16 +67,80 @@ func Load() *Config {
 	redisPass := getEnv("REDIS_PASSWORD", "")
 	redisDB, _ := strconv.Atoi(getEnv("REDIS_DB", "0"))
 	level := getEnv("LOG_LEVEL", "info")
+	authDisabled, _ := strconv.ParseBool(getEnv("AUTH_DISABLED", "false"))
+	rateLimitMax, _ := strconv.Atoi(getEnv("RATE_LIMIT_MAX_REQUESTS", "100"))
+	rateLimitWindow, _ := strconv.Atoi(getEnv("RATE_LIMIT_WINDOW_SECONDS", "60"))
+	cacheLocalCapacity, _ := strconv.Atoi(getEnv("CACHE_LOCAL_CAPACITY", "10000"))
+	cacheLocalTTL, _ := strconv.Atoi(getEnv("CACHE_LOCAL_TTL_SECONDS", "5"))
+	cacheRemoteTTL, _ := strconv.Atoi(getEnv("CACHE_REMOTE_TTL_SECONDS", "0"))
+	requireIfMatch, _ := strconv.ParseBool(getEnv("REQUIRE_IF_MATCH", "false"))
+	autoMigrate, _ := strconv.ParseBool(getEnv("AUTO_MIGRATE", "false"))
+	sampleRatio, _ := strconv.ParseFloat(getEnv("TRACING_SAMPLE_RATIO", "1"), 64)
+	webhookMaxAttempts, _ := strconv.Atoi(getEnv("WEBHOOK_MAX_ATTEMPTS", "8"))
+	webhookRetryBase, _ := strconv.Atoi(getEnv("WEBHOOK_RETRY_BASE_SECONDS", "30"))
+	webhookRetryMax, _ := strconv.Atoi(getEnv("WEBHOOK_RETRY_MAX_SECONDS", "3600"))
+	webhookTimeout, _ := strconv.Atoi(getEnv("WEBHOOK_TIMEOUT_SECONDS", "10"))
 
 	return &Config{
-		HTTPPort:     port,
-		DBConnString: dbConnString,
-		RedisAddr:    redisAddr,
-		RedisPass:    redisPass,
-		RedisDB:      redisDB,
-		LogLevel:     level,
-		PProfEnabled: pprof,
+		HTTPPort:         port,
+		Storage:          getEnv("STORAGE", "postgres"),
+		DBConnString:     dbConnString,
+		RedisAddr:        redisAddr,
+		RedisPass:        redisPass,
+		RedisDB:          redisDB,
+		LogLevel:         level,
+		PProfEnabled:     pprof,
+		AuthDisabled:     authDisabled,
+		JWTSecret:        getEnv("JWT_HS256_SECRET", ""),
+		JWKSFile:         getEnv("JWT_JWKS_FILE", ""),
+		JWTIssuer:        getEnv("JWT_ISSUER", ""),
+		JWTAudience:      getEnv("JWT_AUDIENCE", ""),
+		APIKeys:          getEnv("API_KEYS", ""),
+		TenantRateLimits: parseLimits(getEnv("TENANT_RATE_LIMITS", "")),
+
+		RateLimitMaxRequests:   rateLimitMax,
+		RateLimitWindowSeconds: rateLimitWindow,
+		PrincipalRateLimits:    parseLimits(getEnv("PRINCIPAL_RATE_LIMITS", "")),
+		RouteRateLimits:        getEnv("ROUTE_RATE_LIMITS", ""),
+
+		TrustedProxies:  getEnv("TRUSTED_PROXIES", ""),
+		IPAllowlist:     getEnv("IP_ALLOWLIST", ""),
+		IPDenylist:      getEnv("IP_DENYLIST", ""),
+		ForwardedHeader: getEnv("FORWARDED_HEADER", "X-Forwarded-For"),
+
+		CacheLocalCapacity: cacheLocalCapacity,
+		CacheLocalTTL:      cacheLocalTTL,
+		CacheRemoteTTL:     cacheRemoteTTL,
+
+		TracingExporter:    getEnv("TRACING_EXPORTER", "none"),
+		TracingEndpoint:    getEnv("TRACING_ENDPOINT", "http://localhost:4318/v1/traces"),
+		TracingFile:        getEnv("TRACING_FILE", "traces.jsonl"),
+		TracingSampleRatio: sampleRatio,
+		TracingService:     getEnv("TRACING_SERVICE_NAME", "tucredito-api"),
+
+		RequireIfMatch: requireIfMatch,
+		AutoMigrate:    autoMigrate,
+
+		WebhookMaxAttempts:      webhookMaxAttempts,
+		WebhookRetryBaseSeconds: webhookRetryBase,
+		WebhookRetryMaxSeconds:  webhookRetryMax,
+		WebhookTimeoutSeconds:   webhookTimeout,
+	}
+}
+
+// Parses "key=limit" pairs separated by commas; malformed entries are ignored.
+func parseLimits(spec string) map[string]int {
+	limits := make(map[string]int)
+	for _, entry := range strings.Split(spec, ",") {
+		key, raw, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || key == "" {
+			continue
+		}
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			limits[key] = n
+		}
 	}
+	return limits
 }
 
 // Gets the environment variable or the default value.
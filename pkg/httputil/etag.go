@@ -0,0 +1,86 @@
+package httputil
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+/*
+	Entity tags for versioned resources
+	The ETag of a client, bank or credit is its row version as a strong tag ("3"), so If-Match can be turned
+	back into the version an update must find and If-None-Match into a cheap 304 for unchanged resources
+*/
+
+// Formats a row version as a strong entity tag
+func ETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+/*
+	Returns the versions listed in r's If-Match header; ok is false when the request is unconditional (no
+	header, or "*" which any existing resource satisfies)
+	Weak and foreign tags never match under If-Match's strong comparison, so a header made only of those
+	yields ok with no versions
+*/
+
+func IfMatch(r *http.Request) (versions []int, ok bool) {
+	header := r.Header.Get("If-Match")
+	if strings.TrimSpace(header) == "" {
+		return nil, false
+	}
+	versions, any := parseETags(header, false)
+	if any {
+		return nil, false
+	}
+	return versions, true
+}
+
+// Writes v as JSON with the ETag of version, or 304 Not Modified when a GET's If-None-Match already has it
+func JSONWithETag(w http.ResponseWriter, r *http.Request, status, version int, v interface{}) {
+	w.Header().Set("ETag", ETag(version))
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		if header := r.Header.Get("If-None-Match"); header != "" && matches(header, version) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	JSON(w, status, v)
+}
+
+// Reports whether an If-None-Match list matches version (weak comparison)
+func matches(header string, version int) bool {
+	versions, any := parseETags(header, true)
+	if any {
+		return true
+	}
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// Parses a comma-separated entity-tag list into versions; weak tags (W/"3") count only when weak is set
+func parseETags(header string, weak bool) (versions []int, any bool) {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return nil, true
+		}
+		if rest, isWeak := strings.CutPrefix(tag, "W/"); isWeak {
+			if !weak {
+				continue
+			}
+			tag = rest
+		}
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		if v, err := strconv.Atoi(tag[1 : len(tag)-1]); err == nil {
+			versions = append(versions, v)
+		}
+	}
+	return versions, false
+}
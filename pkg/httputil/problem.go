@@ -0,0 +1,96 @@
+package httputil
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/tucredito/backend-api/pkg/apperr"
+)
+
+/*
+	Error responses are RFC 7807 problem documents (application/problem+json)
+	"type" is always about:blank, so "title" is the HTTP status text; "detail" says what went wrong and the
+	extension members carry the machine-readable code, the request ID and per-field validation errors
+*/
+
+const ProblemContentType = "application/problem+json"
+
+type Problem struct {
+	Type      string              `json:"type"`
+	Title     string              `json:"title"`
+	Status    int                 `json:"status"`
+	Detail    string              `json:"detail,omitempty"`
+	Instance  string              `json:"instance,omitempty"`
+	Code      string              `json:"code"`
+	RequestID string              `json:"request_id,omitempty"`
+	Errors    []apperr.FieldError `json:"errors,omitempty"`
+}
+
+// Writes p, filling in the type, a missing title and the request ID already set on the response.
+func WriteProblem(w http.ResponseWriter, p Problem) {
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	if p.RequestID == "" {
+		p.RequestID = w.Header().Get(RequestIDHeader)
+	}
+	writeJSON(w, ProblemContentType, p.Status, p)
+}
+
+// HTTP status for each error kind.
+var kindStatus = map[apperr.Kind]int{
+	apperr.KindInternal:             http.StatusInternalServerError,
+	apperr.KindNotFound:             http.StatusNotFound,
+	apperr.KindConflict:             http.StatusConflict,
+	apperr.KindValidation:           http.StatusBadRequest,
+	apperr.KindPreconditionFailed:   http.StatusPreconditionFailed,
+	apperr.KindForbidden:            http.StatusForbidden,
+	apperr.KindUnavailable:          http.StatusServiceUnavailable,
+	apperr.KindTooLarge:             http.StatusRequestEntityTooLarge,
+	apperr.KindPreconditionRequired: http.StatusPreconditionRequired,
+}
+
+// Returns the HTTP status WriteError uses for err.
+func StatusOf(err error) int {
+	return kindStatus[classify(err).Kind]
+}
+
+/*
+	Writes err as a problem document; the single mapping from application errors to HTTP
+	Only the *Error message and fields reach the client: wrapped causes and untyped errors (answered as
+	500 with a generic detail) stay in the logs
+*/
+
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	e := classify(err)
+	if e.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(e.RetryAfter.Seconds()))))
+	}
+	p := Problem{
+		Status: kindStatus[e.Kind],
+		Detail: e.Message,
+		Code:   e.ErrorCode(),
+		Errors: e.Fields,
+	}
+	if r != nil {
+		p.Instance = r.URL.Path
+	}
+	WriteProblem(w, p)
+}
+
+// Returns the *Error for err; deadlines become Unavailable and anything untyped Internal
+func classify(err error) *apperr.Error {
+	if e, ok := apperr.As(err); ok {
+		return e
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return apperr.Unavailable("the request timed out", err)
+	}
+	return apperr.Internal("internal server error", err)
+}
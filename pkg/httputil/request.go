@@ -0,0 +1,135 @@
+package httputil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/tucredito/backend-api/pkg/apperr"
+)
+
+// Default limit for JSON request bodies.
+const MaxJSONBodyBytes = 1 << 20
+
+/*
+	Strictly decodes the JSON request body into v
+	Unknown fields, trailing data and bodies over maxBytes are rejected; the error is an *apperr.Error ready for
+	WriteError (INVALID_JSON for malformed input, VALIDATION naming the field for unknown or mistyped fields,
+	PAYLOAD_TOO_LARGE for oversized bodies)
+*/
+
+func DecodeJSON(w http.ResponseWriter, r *http.Request, v interface{}, maxBytes int64) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return decodeError(err, maxBytes)
+	}
+	if err := dec.Decode(&struct{}{}); err != io.EOF {
+		if err == nil {
+			return invalidJSON("body must contain a single JSON object", nil)
+		}
+		return decodeError(err, maxBytes)
+	}
+	return nil
+}
+
+// Media type of RFC 7396 merge patches; PATCH endpoints also accept application/json
+const MergePatchContentType = "application/merge-patch+json"
+
+/*
+	Decodes an RFC 7396 merge patch into v, a struct of pointer fields where nil means "leave unchanged"
+	The patch must be a JSON object and is decoded as strictly as DecodeJSON. A member set to null would remove
+	the field, which no resource allows, so nulls are reported as field errors
+*/
+
+func DecodeMergePatch(w http.ResponseWriter, r *http.Request, v interface{}, maxBytes int64) error {
+	var members map[string]json.RawMessage
+	if err := DecodeJSON(w, r, &members, maxBytes); err != nil {
+		return err
+	}
+	if members == nil {
+		return invalidJSON("merge patch must be a JSON object", nil)
+	}
+	raw, err := json.Marshal(members)
+	if err != nil {
+		return invalidJSON("malformed JSON", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return decodeError(err, maxBytes)
+	}
+
+	var fields []apperr.FieldError
+	for name, value := range members {
+		if string(value) == "null" {
+			fields = append(fields, apperr.Field(name, "required", "cannot be removed"))
+		}
+	}
+	if len(fields) > 0 {
+		sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+		return apperr.Validation("request has invalid fields", fields...)
+	}
+	return nil
+}
+
+func decodeError(err error, maxBytes int64) error {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		maxErr    *http.MaxBytesError
+	)
+	switch {
+	case errors.As(err, &maxErr):
+		e := apperr.TooLarge(fmt.Sprintf("request body must not exceed %d bytes", maxBytes))
+		e.Err = err
+		return e
+	case errors.As(err, &syntaxErr):
+		return invalidJSON(fmt.Sprintf("malformed JSON at offset %d", syntaxErr.Offset), err)
+	case errors.Is(err, io.EOF):
+		return invalidJSON("request body is empty", err)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return invalidJSON("malformed JSON: unexpected end of body", err)
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			return invalidJSON("body must be a JSON object", err)
+		}
+		e := apperr.Validation("request has invalid fields", apperr.Field(field, "invalid_type", "must be a "+jsonType(typeErr.Type.Kind().String())))
+		e.Err = err
+		return e
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no typed error for DisallowUnknownFields
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		e := apperr.Validation("request has invalid fields", apperr.Field(field, "unknown_field", "is not a known field"))
+		e.Err = err
+		return e
+	default:
+		return invalidJSON("malformed JSON", err)
+	}
+}
+
+func invalidJSON(msg string, cause error) error {
+	return &apperr.Error{Kind: apperr.KindValidation, Code: "INVALID_JSON", Message: msg, Err: cause}
+}
+
+// Names Go kinds the way JSON clients think of them
+func jsonType(kind string) string {
+	switch kind {
+	case "string":
+		return "string"
+	case "bool":
+		return "boolean"
+	case "struct", "map":
+		return "object"
+	case "slice", "array":
+		return "array"
+	default:
+		return "number"
+	}
+}
@@ -5,23 +5,27 @@ import (
 	"net/http"
 )
 
-// The standard error response shape.
-type ErrorBody struct {
-	Error   string `json:"error"`
-	Code    string `json:"code,omitempty"`
-	Details string `json:"details,omitempty"`
-}
+// Header carrying the request ID, set on every response by middleware.RequestID.
+const RequestIDHeader = "X-Request-ID"
 
 // Writes v as JSON with status code.
 func JSON(w http.ResponseWriter, status int, v interface{}) {
-	w.Header().Set("Content-Type", "application/json")
+	writeJSON(w, "application/json", status, v)
+}
+
+func writeJSON(w http.ResponseWriter, contentType string, status int, v interface{}) {
+	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(status)
 	if v != nil {
 		_ = json.NewEncoder(w).Encode(v)
 	}
 }
 
-// Writes an error response as JSON.
-func Error(w http.ResponseWriter, status int, errMsg, code, details string) {
-	JSON(w, status, ErrorBody{Error: errMsg, Code: code, Details: details})
+// Writes an error response as a problem document, echoing the request ID already set on the response.
+// The title is the status text (see Problem); message, followed by detail when given, becomes the detail.
+func Error(w http.ResponseWriter, status int, message, code, detail string) {
+	if detail != "" {
+		message += ": " + detail
+	}
+	WriteProblem(w, Problem{Status: status, Code: code, Detail: message})
 }